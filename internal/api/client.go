@@ -0,0 +1,139 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"five-pillars/internal/database"
+	"five-pillars/internal/services"
+)
+
+// Client клиент HTTP API трекера
+type Client struct {
+	baseURL string
+	// token отправляется как Authorization: Bearer, пустой - без заголовка
+	token string
+	http  *http.Client
+}
+
+func NewClient(baseURL, token string) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// APIError ошибка, которую вернул сервер
+type APIError struct {
+	Status  int
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s (HTTP %d)", e.Message, e.Status)
+}
+
+func (c *Client) Tasks(date string) ([]database.DailyTask, error) {
+	var tasks []database.DailyTask
+	err := c.do(http.MethodGet, "/api/tasks?date="+url.QueryEscape(date), nil, &tasks)
+	return tasks, err
+}
+
+func (c *Client) CompleteTask(id int) (*database.DailyTask, error) {
+	var task database.DailyTask
+	err := c.do(http.MethodPost, fmt.Sprintf("/api/tasks/%d/complete", id), nil, &task)
+	return &task, err
+}
+
+func (c *Client) SnoozeTask(id int, minutes int) (*database.DailyTask, error) {
+	var task database.DailyTask
+	body := map[string]int{"minutes": minutes}
+	err := c.do(http.MethodPost, fmt.Sprintf("/api/tasks/%d/snooze", id), body, &task)
+	return &task, err
+}
+
+func (c *Client) SkipTask(id int, reason string) (*database.DailyTask, error) {
+	var task database.DailyTask
+	body := map[string]string{"reason": reason}
+	err := c.do(http.MethodPost, fmt.Sprintf("/api/tasks/%d/skip", id), body, &task)
+	return &task, err
+}
+
+func (c *Client) SkipReasons() ([]services.SkipReason, error) {
+	var reasons []services.SkipReason
+	err := c.do(http.MethodGet, "/api/skip-reasons", nil, &reasons)
+	return reasons, err
+}
+
+func (c *Client) SaveFeelings(feelings database.DailyFeelings) (*database.DailyFeelings, error) {
+	var saved database.DailyFeelings
+	err := c.do(http.MethodPost, "/api/feelings", feelings, &saved)
+	return &saved, err
+}
+
+func (c *Client) WeeklyAnalytics() (*database.WeeklyAnalytics, error) {
+	var analytics database.WeeklyAnalytics
+	err := c.do(http.MethodGet, "/api/analytics/week", nil, &analytics)
+	return &analytics, err
+}
+
+func (c *Client) Pillars() ([]database.PillarInfo, error) {
+	var pillars []database.PillarInfo
+	err := c.do(http.MethodGet, "/api/pillars", nil, &pillars)
+	return pillars, err
+}
+
+// WellbeingMetrics справочник показателей самочувствия вместе с архивными
+func (c *Client) WellbeingMetrics() ([]database.WellbeingMetric, error) {
+	var metrics []database.WellbeingMetric
+	err := c.do(http.MethodGet, "/api/wellbeing?all=1", nil, &metrics)
+	return metrics, err
+}
+
+func (c *Client) do(method, path string, body interface{}, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("ошибка запроса к API: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		return &APIError{Status: resp.StatusCode, Message: apiErr.Error}
+	}
+
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
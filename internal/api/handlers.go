@@ -0,0 +1,749 @@
+package api
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"five-pillars/internal/database"
+	"five-pillars/internal/export"
+	"five-pillars/internal/ical"
+	"five-pillars/internal/services"
+)
+
+func (s *Server) handleListTasks(w http.ResponseWriter, r *http.Request) {
+	tasks, err := s.services.Task.GetTasks(dateParam(r))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	if tasks == nil {
+		tasks = []database.DailyTask{}
+	}
+	writeJSON(w, http.StatusOK, tasks)
+}
+
+func (s *Server) handleAddTask(w http.ResponseWriter, r *http.Request) {
+	var input services.NewTaskInput
+	if !decodeBody(w, r, &input) {
+		return
+	}
+
+	task, err := s.services.Task.AddTask(input)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, task)
+}
+
+func (s *Server) handleGetTask(w http.ResponseWriter, r *http.Request) {
+	id, ok := taskIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	task, err := s.services.Task.GetTask(id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, task)
+}
+
+func (s *Server) handleUpdateTask(w http.ResponseWriter, r *http.Request) {
+	id, ok := taskIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		TimeUTC string `json:"time_utc"`
+		Date    string `json:"date"`
+		// StartUTC, LeadMinutes меняются, если переданы; "" - сбросить
+		StartUTC    *string `json:"start_utc"`
+		LeadMinutes *string `json:"lead_minutes"`
+		// Status меняется последним, переход проверяется
+		Status string `json:"status"`
+		// Value результат измеримой задачи, открытая задача с ним выполняется
+		Value *float64 `json:"value"`
+		// Tags заменяют теги задачи, [] - убрать
+		Tags *[]string `json:"tags"`
+	}
+	if !decodeBody(w, r, &input) {
+		return
+	}
+
+	var status database.TaskStatus
+	if input.Status != "" {
+		var err error
+		if status, err = services.ParseTaskStatus(input.Status); err != nil {
+			writeServiceError(w, err)
+			return
+		}
+	}
+
+	task, err := s.services.Task.GetTask(id)
+	if input.TimeUTC != "" && err == nil {
+		task, err = s.services.Task.ChangeTime(id, input.TimeUTC)
+	}
+	if input.Date != "" && err == nil {
+		task, err = s.services.Task.ChangeDate(id, input.Date)
+	}
+	if (input.StartUTC != nil || input.LeadMinutes != nil) && err == nil {
+		start, leads := task.StartUTC, task.LeadMinutes
+		if input.StartUTC != nil {
+			start = *input.StartUTC
+		}
+		if input.LeadMinutes != nil {
+			leads = *input.LeadMinutes
+		}
+		task, err = s.services.Task.SetStart(id, start, leads)
+	}
+	if err == nil && status != "" && status != task.Status {
+		task, err = s.services.Task.SetStatus(id, status)
+	}
+	if input.Value != nil && err == nil {
+		task, err = s.services.Task.RecordValue(id, *input.Value)
+	}
+	if input.Tags != nil && err == nil {
+		task, err = s.services.Task.SetTags(id, *input.Tags...)
+	}
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, task)
+}
+
+func (s *Server) handleCompleteTask(w http.ResponseWriter, r *http.Request) {
+	id, ok := taskIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	// Результат измеримой задачи можно передать сразу: {"value": 5}
+	var input struct {
+		Value *float64 `json:"value"`
+	}
+	if !decodeBody(w, r, &input) {
+		return
+	}
+
+	var task *database.DailyTask
+	var err error
+	if input.Value != nil {
+		task, err = s.services.Task.RecordValue(id, *input.Value)
+	} else {
+		task, err = s.services.Task.CompleteTask(id)
+	}
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, task)
+}
+
+// handleSetMetric задает метрику задачи: {"unit": "км", "target": 5, "aggregation": "sum"},
+// пустая единица убирает её
+func (s *Server) handleSetMetric(w http.ResponseWriter, r *http.Request) {
+	id, ok := taskIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Unit        string  `json:"unit"`
+		Target      float64 `json:"target"`
+		Aggregation string  `json:"aggregation"`
+	}
+	if !decodeBody(w, r, &input) {
+		return
+	}
+
+	task, err := s.services.Task.SetMetric(id, services.Metric{
+		Unit:        input.Unit,
+		Target:      input.Target,
+		Aggregation: input.Aggregation,
+	})
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, task)
+}
+
+func (s *Server) handleSnoozeTask(w http.ResponseWriter, r *http.Request) {
+	id, ok := taskIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	input := struct {
+		Minutes int `json:"minutes"`
+	}{Minutes: services.DefaultSnoozeMinutes}
+	if !decodeBody(w, r, &input) {
+		return
+	}
+
+	task, err := s.services.Task.SnoozeTask(id, input.Minutes)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, task)
+}
+
+func (s *Server) handleSkipTask(w http.ResponseWriter, r *http.Request) {
+	id, ok := taskIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Reason string `json:"reason"`
+	}
+	if !decodeBody(w, r, &input) {
+		return
+	}
+
+	task, _, err := s.services.Task.SkipTask(id, input.Reason)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, task)
+}
+
+// handleAddChecklist добавляет пункты в чек-лист задачи: {"items": ["...", "..."]}
+func (s *Server) handleAddChecklist(w http.ResponseWriter, r *http.Request) {
+	id, ok := taskIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Items []string `json:"items"`
+	}
+	if !decodeBody(w, r, &input) {
+		return
+	}
+
+	task, err := s.services.Task.AddChecklist(id, strings.Join(input.Items, "\n"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, task)
+}
+
+func (s *Server) handleToggleChecklistItem(w http.ResponseWriter, r *http.Request) {
+	id, ok := taskIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	task, err := s.services.Task.ToggleChecklistItem(id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, task)
+}
+
+func (s *Server) handleRemoveChecklistItem(w http.ResponseWriter, r *http.Request) {
+	id, ok := taskIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	task, err := s.services.Task.RemoveChecklistItem(id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, task)
+}
+
+func (s *Server) handleSkipReasons(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, services.SkipReasons)
+}
+
+func (s *Server) handleGetFeelings(w http.ResponseWriter, r *http.Request) {
+	feelings, err := s.services.Feelings.Get(dateParam(r))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, feelings)
+}
+
+func (s *Server) handleSaveFeelings(w http.ResponseWriter, r *http.Request) {
+	var input database.DailyFeelings
+	if !decodeBody(w, r, &input) {
+		return
+	}
+
+	feelings, err := s.services.Feelings.Save(input)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, feelings)
+}
+
+// handleGetSleep ночи и режим сна за ?from=&to= или за последние ?days=N дней,
+// по умолчанию за 7
+func (s *Server) handleGetSleep(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var entries []database.SleepEntry
+	var stats *database.SleepStats
+	var err error
+	if from, to := query.Get("from"), query.Get("to"); from != "" || to != "" {
+		if to == "" {
+			to = services.Today()
+		}
+		entries, stats, err = s.services.Sleep.GetBetween(from, to)
+	} else {
+		days := 7
+		if value := query.Get("days"); value != "" {
+			if days, err = strconv.Atoi(value); err != nil || days < 1 {
+				writeError(w, http.StatusBadRequest, "days должен быть положительным числом")
+				return
+			}
+		}
+		entries, stats, err = s.services.Sleep.Get(days)
+	}
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	if entries == nil {
+		entries = []database.SleepEntry{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"entries": entries, "stats": stats})
+}
+
+// handleSaveSleep записывает ночь: {"date": "2026-01-10", "sleep": "23:40-07:10"},
+// date - день пробуждения по UTC, по умолчанию сегодня
+func (s *Server) handleSaveSleep(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Date  string `json:"date"`
+		Sleep string `json:"sleep"`
+	}
+	if !decodeBody(w, r, &input) {
+		return
+	}
+
+	entry, err := s.services.Sleep.Save(input.Date, input.Sleep)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, entry)
+}
+
+func (s *Server) handleDeleteSleep(w http.ResponseWriter, r *http.Request) {
+	if err := s.services.Sleep.Delete(r.PathValue("date")); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleGetJournal(w http.ResponseWriter, r *http.Request) {
+	entries, err := s.services.Journal.Get(dateParam(r))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	if entries == nil {
+		entries = []database.JournalEntry{}
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
+
+// handleAddJournal добавляет запись дневника: {"date": "2026-01-10", "text": "..."},
+// photo_id - file_id фото, уже загруженного в Telegram
+func (s *Server) handleAddJournal(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Date    string `json:"date"`
+		Text    string `json:"text"`
+		PhotoID string `json:"photo_id"`
+	}
+	if !decodeBody(w, r, &input) {
+		return
+	}
+
+	entry, err := s.services.Journal.Add(input.Date, input.Text, input.PhotoID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, entry)
+}
+
+func (s *Server) handleDeleteJournal(w http.ResponseWriter, r *http.Request) {
+	id, ok := taskIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.services.Journal.Delete(id); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleSummary(w http.ResponseWriter, r *http.Request) {
+	summary, err := s.services.Analytics.GetDailySummary(dateParam(r))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, summary)
+}
+
+func (s *Server) handleWeek(w http.ResponseWriter, r *http.Request) {
+	analytics, err := s.services.Analytics.GetWeeklyAnalytics()
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, analytics)
+}
+
+// handleMetrics прогресс измеримых задач: ?period=week|month, по умолчанию неделя
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	period := r.URL.Query().Get("period")
+	if period == "" {
+		period = "week"
+	}
+
+	report, err := s.services.Analytics.GetMetrics(period, time.Now())
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	if report.Metrics == nil {
+		report.Metrics = []database.MetricStat{}
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// handleSearch ищет задачи и ощущения: q - строка как в /find, tag, from,
+// to, status и limit дополняют ее
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	query, err := services.ParseSearchQuery(params.Get("q"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	if len(params["tag"]) > 0 {
+		tags, err := services.ParseTags(params["tag"]...)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		query.Tags = append(query.Tags, strings.Split(tags, ",")...)
+	}
+	if from := params.Get("from"); from != "" {
+		query.From = from
+	}
+	if to := params.Get("to"); to != "" {
+		query.To = to
+	}
+	if value := params.Get("status"); value != "" {
+		for _, name := range strings.Split(value, ",") {
+			status, err := services.ParseTaskStatus(name)
+			if err != nil {
+				writeServiceError(w, err)
+				return
+			}
+			query.Statuses = append(query.Statuses, status)
+		}
+	}
+	if value := params.Get("limit"); value != "" {
+		if query.Limit, err = strconv.Atoi(value); err != nil {
+			writeError(w, http.StatusBadRequest, "limit должен быть числом")
+			return
+		}
+	}
+
+	result, err := s.services.Search.Find(query)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	if result.Tasks == nil {
+		result.Tasks = []database.DailyTask{}
+	}
+	if result.Feelings == nil {
+		result.Feelings = []database.DailyFeelings{}
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	format, err := export.ParseFormat(query.Get("format"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	snapshot, err := s.services.Transfer.Export(query.Get("from"), query.Get("to"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	file, err := export.Render(s.services.Registry(), snapshot, format, query.Get("dataset"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", file.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", file.Name))
+	w.Write(file.Data)
+}
+
+// handleCalendar лента задач в формате iCalendar для подписки из календаря
+func (s *Server) handleCalendar(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if s.calendarToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.calendarToken)) != 1 {
+		writeError(w, http.StatusNotFound, "календарь не найден")
+		return
+	}
+
+	now := time.Now()
+	from, to := s.calendarWindow.Range(now)
+	tasks, err := s.services.Task.GetTasksBetween(from, to)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="five-pillars.ics"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(ical.Render(s.services.Registry(), tasks, "5 Столпов", now))
+}
+
+// handleListGoals цели с прогрессом, ?all=1 - вместе с архивными
+func (s *Server) handleListGoals(w http.ResponseWriter, r *http.Request) {
+	goals, err := s.services.Goals.GetGoals(r.URL.Query().Get("all") == "1")
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	if goals == nil {
+		goals = []database.Goal{}
+	}
+	writeJSON(w, http.StatusOK, goals)
+}
+
+func (s *Server) handleAddGoal(w http.ResponseWriter, r *http.Request) {
+	var input services.NewGoalInput
+	if !decodeBody(w, r, &input) {
+		return
+	}
+
+	goal, err := s.services.Goals.AddGoal(input)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, goal)
+}
+
+func (s *Server) handleGetGoal(w http.ResponseWriter, r *http.Request) {
+	id, ok := taskIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	goal, err := s.services.Goals.GetGoal(id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, goal)
+}
+
+// handleUpdateGoal убирает цель в архив или возвращает: {"archived": true}
+func (s *Server) handleUpdateGoal(w http.ResponseWriter, r *http.Request) {
+	id, ok := taskIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Archived *bool `json:"archived"`
+	}
+	if !decodeBody(w, r, &input) {
+		return
+	}
+
+	if input.Archived != nil {
+		if err := s.services.Goals.Archive(id, *input.Archived); err != nil {
+			writeServiceError(w, err)
+			return
+		}
+	}
+	goal, err := s.services.Goals.GetGoal(id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, goal)
+}
+
+// handleLinkGoal привязывает к цели шаблон или задачу: {"template_id": 3} или {"task_id": 12}
+func (s *Server) handleLinkGoal(w http.ResponseWriter, r *http.Request) {
+	id, ok := taskIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		TemplateID int `json:"template_id"`
+		TaskID     int `json:"task_id"`
+	}
+	if !decodeBody(w, r, &input) {
+		return
+	}
+
+	var goal *database.Goal
+	var err error
+	switch {
+	case input.TemplateID != 0 && input.TaskID == 0:
+		goal, err = s.services.Goals.LinkTemplate(id, input.TemplateID)
+	case input.TaskID != 0 && input.TemplateID == 0:
+		goal, err = s.services.Goals.LinkTask(id, input.TaskID)
+	default:
+		writeError(w, http.StatusBadRequest, "укажите template_id или task_id")
+		return
+	}
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, goal)
+}
+
+func (s *Server) handleUnlinkTaskGoal(w http.ResponseWriter, r *http.Request) {
+	id, ok := taskIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.services.Goals.UnlinkTask(id); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	task, err := s.services.Task.GetTask(id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, task)
+}
+
+func (s *Server) handleUnlinkTemplateGoal(w http.ResponseWriter, r *http.Request) {
+	id, ok := taskIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.services.Goals.UnlinkTemplate(id); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleListPillars(w http.ResponseWriter, r *http.Request) {
+	pillars := s.services.Pillars.List(r.URL.Query().Get("all") == "1")
+	if pillars == nil {
+		pillars = []database.PillarInfo{}
+	}
+	writeJSON(w, http.StatusOK, pillars)
+}
+
+func (s *Server) handleAddPillar(w http.ResponseWriter, r *http.Request) {
+	var input database.PillarInfo
+	if !decodeBody(w, r, &input) {
+		return
+	}
+
+	pillar, err := s.services.Pillars.Add(input)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, pillar)
+}
+
+// handleUpdatePillar меняет переданные поля столпа: {"name": "Деньги", "archived": true}
+func (s *Server) handleUpdatePillar(w http.ResponseWriter, r *http.Request) {
+	var patch services.PillarPatch
+	if !decodeBody(w, r, &patch) {
+		return
+	}
+
+	pillar, err := s.services.Pillars.Update(r.PathValue("key"), patch)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, pillar)
+}
+
+func (s *Server) handleListWellbeing(w http.ResponseWriter, r *http.Request) {
+	metrics := s.services.Wellbeing.List(r.URL.Query().Get("all") == "1")
+	if metrics == nil {
+		metrics = []database.WellbeingMetric{}
+	}
+	writeJSON(w, http.StatusOK, metrics)
+}
+
+// handleAddWellbeing добавляет показатель: {"key": "water", "type": "number", "emoji": "💧", "name": "Вода", "unit": "л"}
+func (s *Server) handleAddWellbeing(w http.ResponseWriter, r *http.Request) {
+	var input database.WellbeingMetric
+	if !decodeBody(w, r, &input) {
+		return
+	}
+
+	metric, err := s.services.Wellbeing.Add(input)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, metric)
+}
+
+// handleUpdateWellbeing меняет переданные поля показателя: {"max": 15, "archived": false}.
+// Тип не меняется, null в границах оставляет их как есть.
+func (s *Server) handleUpdateWellbeing(w http.ResponseWriter, r *http.Request) {
+	var patch services.WellbeingPatch
+	if !decodeBody(w, r, &patch) {
+		return
+	}
+
+	metric, err := s.services.Wellbeing.Update(r.PathValue("key"), patch)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, metric)
+}
@@ -0,0 +1,217 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"five-pillars/internal/ical"
+	"five-pillars/internal/services"
+)
+
+// Server HTTP API трекера - тонкий адаптер над сервисами
+type Server struct {
+	services *services.ServiceManager
+	server   *http.Server
+
+	// token секрет для /api, пустой - сервер слушает только 127.0.0.1
+	token string
+
+	// calendarToken секрет ленты /calendar.ics, пустой - лента выключена
+	calendarToken  string
+	calendarWindow ical.Window
+}
+
+// NewServer создает сервер API. С токеном запросы к /api требуют заголовка
+// Authorization: Bearer <token>, без токена сервер слушает только локальный адрес.
+func NewServer(port, token string, serviceManager *services.ServiceManager) *Server {
+	s := &Server{services: serviceManager, token: token}
+
+	mux := http.NewServeMux()
+	s.registerRoutes(mux)
+
+	addr := ":" + port
+	if token == "" {
+		addr = "127.0.0.1:" + port
+	}
+
+	s.server = &http.Server{
+		Addr:              addr,
+		Handler:           s.authorize(mux),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return s
+}
+
+// EnableCalendar публикует ленту задач /calendar.ics?token=...
+func (s *Server) EnableCalendar(token string, window ical.Window) {
+	s.calendarToken = token
+	s.calendarWindow = window
+}
+
+// Handler возвращает обработчик запросов без запуска сервера
+func (s *Server) Handler() http.Handler {
+	return s.server.Handler
+}
+
+// authorize проверяет токен у запросов к /api. /health и /calendar.ics
+// открыты: лента календаря проверяет свой токен сама.
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.token != "" && strings.HasPrefix(r.URL.Path, "/api/") {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeError(w, http.StatusUnauthorized, "нужен токен API")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) Start() {
+	if s.token == "" {
+		log.Printf("⚠️ API_TOKEN не задан: HTTP API доступно только с этой машины")
+		if inContainer() {
+			log.Printf("🚨 Сервер запущен в контейнере без API_TOKEN и слушает только 127.0.0.1 внутри него: " +
+				"опубликованный порт и лента /calendar.ics снаружи недоступны. Задайте API_TOKEN")
+		}
+	}
+	log.Printf("🌐 HTTP API слушает %s", s.server.Addr)
+	if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Printf("❌ Ошибка HTTP сервера: %v", err)
+	}
+}
+
+// inContainer запущен ли процесс в Docker или Podman
+func inContainer() bool {
+	for _, marker := range []string{"/.dockerenv", "/run/.containerenv"} {
+		if _, err := os.Stat(marker); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) Stop(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+func (s *Server) registerRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /health", s.handleHealth)
+
+	mux.HandleFunc("GET /api/tasks", s.handleListTasks)
+	mux.HandleFunc("POST /api/tasks", s.handleAddTask)
+	mux.HandleFunc("GET /api/tasks/{id}", s.handleGetTask)
+	mux.HandleFunc("PATCH /api/tasks/{id}", s.handleUpdateTask)
+	mux.HandleFunc("POST /api/tasks/{id}/complete", s.handleCompleteTask)
+	mux.HandleFunc("POST /api/tasks/{id}/snooze", s.handleSnoozeTask)
+	mux.HandleFunc("POST /api/tasks/{id}/skip", s.handleSkipTask)
+	mux.HandleFunc("GET /api/skip-reasons", s.handleSkipReasons)
+	mux.HandleFunc("PUT /api/tasks/{id}/metric", s.handleSetMetric)
+	mux.HandleFunc("POST /api/tasks/{id}/checklist", s.handleAddChecklist)
+	mux.HandleFunc("POST /api/checklist/{id}/toggle", s.handleToggleChecklistItem)
+	mux.HandleFunc("DELETE /api/checklist/{id}", s.handleRemoveChecklistItem)
+
+	mux.HandleFunc("GET /api/goals", s.handleListGoals)
+	mux.HandleFunc("POST /api/goals", s.handleAddGoal)
+	mux.HandleFunc("GET /api/goals/{id}", s.handleGetGoal)
+	mux.HandleFunc("PATCH /api/goals/{id}", s.handleUpdateGoal)
+	mux.HandleFunc("POST /api/goals/{id}/links", s.handleLinkGoal)
+	mux.HandleFunc("DELETE /api/tasks/{id}/goal", s.handleUnlinkTaskGoal)
+	mux.HandleFunc("DELETE /api/templates/{id}/goal", s.handleUnlinkTemplateGoal)
+
+	mux.HandleFunc("GET /api/pillars", s.handleListPillars)
+	mux.HandleFunc("POST /api/pillars", s.handleAddPillar)
+	mux.HandleFunc("PATCH /api/pillars/{key}", s.handleUpdatePillar)
+
+	mux.HandleFunc("GET /api/wellbeing", s.handleListWellbeing)
+	mux.HandleFunc("POST /api/wellbeing", s.handleAddWellbeing)
+	mux.HandleFunc("PATCH /api/wellbeing/{key}", s.handleUpdateWellbeing)
+
+	mux.HandleFunc("GET /api/feelings", s.handleGetFeelings)
+	mux.HandleFunc("POST /api/feelings", s.handleSaveFeelings)
+
+	mux.HandleFunc("GET /api/sleep", s.handleGetSleep)
+	mux.HandleFunc("POST /api/sleep", s.handleSaveSleep)
+	mux.HandleFunc("DELETE /api/sleep/{date}", s.handleDeleteSleep)
+
+	mux.HandleFunc("GET /api/journal", s.handleGetJournal)
+	mux.HandleFunc("POST /api/journal", s.handleAddJournal)
+	mux.HandleFunc("DELETE /api/journal/{id}", s.handleDeleteJournal)
+
+	mux.HandleFunc("GET /api/summary", s.handleSummary)
+	mux.HandleFunc("GET /api/analytics/week", s.handleWeek)
+	mux.HandleFunc("GET /api/analytics/metrics", s.handleMetrics)
+
+	mux.HandleFunc("GET /api/search", s.handleSearch)
+	mux.HandleFunc("GET /api/export", s.handleExport)
+
+	mux.HandleFunc("GET /calendar.ics", s.handleCalendar)
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// dateParam дата из query-параметра date, по умолчанию сегодня
+func dateParam(r *http.Request) string {
+	if date := r.URL.Query().Get("date"); date != "" {
+		return date
+	}
+	return services.Today()
+}
+
+func taskIDParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "id должен быть числовой")
+		return 0, false
+	}
+	return id, true
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if r.ContentLength == 0 {
+		return true
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "некорректный JSON: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("⚠️ Ошибка записи ответа API: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// writeServiceError переводит ошибку сервиса в HTTP статус
+func writeServiceError(w http.ResponseWriter, err error) {
+	switch {
+	case services.IsValidationError(err):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrTaskNotFound), errors.Is(err, services.ErrFeelingsNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	default:
+		log.Printf("⚠️ Ошибка API: %v", err)
+		writeError(w, http.StatusInternalServerError, "внутренняя ошибка")
+	}
+}
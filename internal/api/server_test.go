@@ -0,0 +1,73 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"five-pillars/internal/database"
+	"five-pillars/internal/services"
+)
+
+func newTestServer(t *testing.T, token string) *Server {
+	t.Helper()
+	db, err := database.New(filepath.Join(t.TempDir(), "api.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewServer("0", token, services.NewServiceManager(db))
+}
+
+func TestAuthorize(t *testing.T) {
+	s := newTestServer(t, "sekret")
+
+	tests := []struct {
+		name   string
+		path   string
+		header string
+		want   int
+	}{
+		{"api без токена", "/api/pillars", "", http.StatusUnauthorized},
+		{"api с чужим токеном", "/api/pillars", "Bearer wrong", http.StatusUnauthorized},
+		{"api без Bearer", "/api/pillars", "sekret", http.StatusUnauthorized},
+		{"api с токеном", "/api/pillars", "Bearer sekret", http.StatusOK},
+		{"health открыт", "/health", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("%s: статус %d, ожидался %d", tt.path, rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewServerAddr(t *testing.T) {
+	if addr := newTestServer(t, "").server.Addr; addr != "127.0.0.1:0" {
+		t.Errorf("без токена адрес %q, ожидался 127.0.0.1:0", addr)
+	}
+	if addr := newTestServer(t, "sekret").server.Addr; addr != ":0" {
+		t.Errorf("с токеном адрес %q, ожидался :0", addr)
+	}
+}
+
+func TestClientSendsToken(t *testing.T) {
+	s := newTestServer(t, "sekret")
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	if _, err := NewClient(srv.URL, "sekret").Pillars(); err != nil {
+		t.Errorf("клиент с токеном: %v", err)
+	}
+	if _, err := NewClient(srv.URL, "").Pillars(); err == nil {
+		t.Error("клиент без токена получил ответ")
+	}
+}
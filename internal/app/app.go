@@ -2,13 +2,22 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"os"
+	"strings"
 	"time"
 
+	"five-pillars/internal/api"
+	"five-pillars/internal/backup"
 	"five-pillars/internal/config"
 	"five-pillars/internal/database"
+	"five-pillars/internal/ical"
+	"five-pillars/internal/notify"
 	"five-pillars/internal/services"
 	"five-pillars/internal/telegram"
+	"five-pillars/internal/utils"
+	"five-pillars/internal/webhook"
 
 	"github.com/robfig/cron/v3"
 )
@@ -17,6 +26,9 @@ type Application struct {
 	config     *config.Config
 	db         *database.Database
 	bot        *telegram.Bot
+	api        *api.Server
+	backups    *backup.Manager
+	webhooks   *webhook.Dispatcher
 	services   *services.ServiceManager
 	cron       *cron.Cron
 	cancelFunc context.CancelFunc
@@ -30,26 +42,83 @@ func New(cfg *config.Config) (*Application, error) {
 	}
 
 	serviceManager := services.NewServiceManager(db)
-	bot, err := telegram.NewBot(cfg.Telegram.Token, cfg.Telegram.ChatID, db, serviceManager)
+	bot, err := telegram.NewBot(cfg.Telegram.Token, cfg.Telegram.ChatID, cfg.Telegram.APIEndpoint, serviceManager)
 	if err != nil {
 		db.Close()
 		return nil, err
 	}
 
-	serviceManager.SetNotificationSender(bot)
+	router, err := newNotificationRouter(cfg, db.Registry(), bot)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	serviceManager.SetNotificationSender(router)
+
+	quietHours, err := services.ParseQuietHours(cfg.Notify.QuietHours)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	serviceManager.Quiet.SetHours(quietHours)
+
+	rolloverPolicies, err := services.ParseRolloverPolicies(db.Registry(), cfg.Rollover.Policy)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	serviceManager.Rollover.SetPolicies(rolloverPolicies)
+
+	backups := backup.NewManager(db, cfg.Backup.Dir, backup.Retention{
+		Daily:   cfg.Backup.KeepDaily,
+		Weekly:  cfg.Backup.KeepWeekly,
+		Monthly: cfg.Backup.KeepMonthly,
+	})
+	bot.SetBackups(backups)
+
+	calendarRules, err := ical.ParseRules(db.Registry(), cfg.Calendar.ImportRules, cfg.Calendar.ImportPillar)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	bot.SetCalendarImport(calendarRules, cfg.Calendar.ImportDays)
+
+	endpoints, err := webhook.ParseEndpoints(cfg.Webhooks.Endpoints, cfg.Webhooks.Secret)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	var webhooks *webhook.Dispatcher
+	if len(endpoints) > 0 {
+		webhooks = webhook.NewDispatcher(db, endpoints)
+		serviceManager.Subscribe(webhooks)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
+	apiServer := api.NewServer(cfg.Server.Port, cfg.Server.Token, serviceManager)
+	apiServer.EnableCalendar(cfg.Calendar.Token, ical.Window{
+		PastDays:   cfg.Calendar.PastDays,
+		FutureDays: cfg.Calendar.FutureDays,
+	})
+
 	app := &Application{
 		config:     cfg,
 		db:         db,
 		bot:        bot,
+		api:        apiServer,
+		backups:    backups,
+		webhooks:   webhooks,
 		services:   serviceManager,
 		cron:       cron.New(),
 		cancelFunc: cancel,
 		ctx:        ctx,
 	}
 
-	app.setupCronJobs()
+	if err := app.setupCronJobs(); err != nil {
+		db.Close()
+		return nil, err
+	}
 
 	return app, nil
 }
@@ -58,6 +127,10 @@ func (a *Application) Start() error {
 	log.Println("🚀 Запуск приложения...")
 
 	go a.bot.Start(a.ctx)
+	go a.api.Start()
+	if a.webhooks != nil {
+		go a.webhooks.Run(a.ctx)
+	}
 
 	a.cron.Start()
 	time.Sleep(3 * time.Second)
@@ -81,6 +154,12 @@ func (a *Application) Stop() error {
 	a.cancelFunc()
 	a.cron.Stop()
 
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := a.api.Stop(shutdownCtx); err != nil {
+		log.Printf("⚠️ Ошибка остановки HTTP API: %v", err)
+	}
+
 	if err := a.db.Close(); err != nil {
 		log.Printf("⚠️ Ошибка закрытия БД: %v", err)
 	}
@@ -89,7 +168,7 @@ func (a *Application) Stop() error {
 	return nil
 }
 
-func (a *Application) setupCronJobs() {
+func (a *Application) setupCronJobs() error {
 	// Проверка уведомлений каждую минуту
 	_, err := a.cron.AddFunc("* * * * *", func() {
 		a.services.Notification.CheckAndSendNotifications()
@@ -99,11 +178,31 @@ func (a *Application) setupCronJobs() {
 	}
 
 	// Напоминание о задачах на день с 6 утра до 18 каждые 2 часа
-	_, err = a.cron.AddFunc("0 3-18/2 * * *", func() {
-		a.services.Notification.SendAllTodayTaskNotification()
-	})
-	if err != nil {
-		panic(err)
+	if a.config.Rituals.Digest {
+		_, err = a.cron.AddFunc("0 3-18/2 * * *", func() {
+			a.services.Notification.SendAllTodayTaskNotification()
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	// Утренний брифинг и вечернее планирование, время по МСК из настроек
+	if err := a.addDailyJob("MORNING_BRIEFING", a.config.Rituals.Morning, func() {
+		a.services.Notification.SendMorningBriefing()
+	}); err != nil {
+		return err
+	}
+	if err := a.addDailyJob("EVENING_PLANNING", a.config.Rituals.Evening, func() {
+		a.prepareTomorrow()
+		a.services.Notification.SendEveningPlan()
+	}); err != nil {
+		return err
+	}
+
+	// Перенос незакрытых задач в конце дня, время по МСК из настроек
+	if err := a.addDailyJob("ROLLOVER_TIME", a.config.Rollover.Time, a.runRollover); err != nil {
+		return err
 	}
 
 	// Сводка дня в 21:55 UTC+3
@@ -112,20 +211,108 @@ func (a *Application) setupCronJobs() {
 	})
 
 	// Создание задач на следующий день в 22:00 UTC+3
-	a.cron.AddFunc("0 19 * * *", func() {
-		tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
-		if err := a.services.Task.CreateDefaultTasksNextDay(tomorrow); err != nil {
-			log.Printf("⚠️ Ошибка создания задач: %v", err)
-		}
-	})
+	a.cron.AddFunc("0 19 * * *", a.prepareTomorrow)
+
+	// Резервная копия БД в 03:00 UTC+3
+	a.cron.AddFunc("0 0 * * *", a.runBackup)
 
 	// Напоминание о внесении ощущений в 18:00 UTC
 	a.cron.AddFunc("0 18 * * *", func() {
-		a.bot.SendMessage(
-			"📝 Не забудьте оценить свои ощущения за день!\n" +
-				"Используйте команду: /feelings энергия=... контроль=... сон=...",
-		)
+		a.services.Notification.SendFeelingsReminder()
 	})
+
+	// Отчет за неделю в воскресенье в 21:00 UTC+3
+	a.cron.AddFunc("0 18 * * 0", func() {
+		a.services.Notification.SendWeeklyReport()
+	})
+
+	// Прогресс по целям первого числа месяца в 12:00 UTC+3
+	a.cron.AddFunc("0 9 1 * *", func() {
+		a.services.Notification.SendGoalsReport()
+	})
+
+	return nil
+}
+
+// addDailyJob добавляет ежедневную задачу на время mskTime (ЧЧ:ММ по МСК),
+// "off" или пустое значение - задача выключена
+func (a *Application) addDailyJob(name, mskTime string, job func()) error {
+	if mskTime == "" || strings.EqualFold(mskTime, "off") {
+		return nil
+	}
+
+	if err := services.ValidateTime(mskTime); err != nil {
+		return fmt.Errorf("неверный %s: %q, ожидается ЧЧ:ММ по МСК или off", name, mskTime)
+	}
+	utcTime, err := utils.ParseMSKTimeToUTC(mskTime)
+	if err != nil {
+		return fmt.Errorf("неверный %s: %v", name, err)
+	}
+
+	hour, minute, _ := strings.Cut(utcTime, ":")
+	_, err = a.cron.AddFunc(fmt.Sprintf("%s %s * * *", minute, hour), job)
+	return err
+}
+
+// prepareTomorrow создает задачи на завтра по шаблонам, если их еще не создавали
+func (a *Application) prepareTomorrow() {
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+	if err := a.services.Task.PrepareTasks(tomorrow); err != nil {
+		log.Printf("⚠️ Ошибка создания задач: %v", err)
+	}
+}
+
+// runRollover переносит незакрытые задачи и сообщает, что с ними сделано
+func (a *Application) runRollover() {
+	result, err := a.services.Rollover.Run(time.Now())
+	if err != nil {
+		log.Printf("⚠️ Ошибка переноса незакрытых задач: %v", err)
+		return
+	}
+	log.Printf("🌒 Незакрытые задачи: перенесено %d, не сделано %d, ждут решения %d",
+		len(result.Carried), len(result.Missed), len(result.Ask))
+	a.services.Notification.SendRollover(result)
+}
+
+// newNotificationRouter каналы уведомлений: Telegram по умолчанию, остальные
+// по настройкам NOTIFY_ROUTES
+func newNotificationRouter(cfg *config.Config, registry *database.Registry, bot *telegram.Bot) (*notify.Router, error) {
+	channels, err := notify.Channels(cfg, registry, os.Stdout)
+	if err != nil {
+		return nil, err
+	}
+
+	router := notify.NewRouter(registry, notify.ChannelTelegram)
+	router.AddChannel(notify.ChannelTelegram, bot)
+	for name, sender := range channels {
+		router.AddChannel(name, sender)
+	}
+
+	if err := router.SetRoutes(cfg.Notify.Routes); err != nil {
+		return nil, err
+	}
+
+	log.Printf("🔔 Каналы уведомлений: %s", strings.Join(router.Channels(), ", "))
+	return router, nil
+}
+
+// runBackup делает копию БД и удаляет устаревшие по правилам хранения
+func (a *Application) runBackup() {
+	snapshot, err := a.backups.Create()
+	if err != nil {
+		log.Printf("⚠️ Ошибка резервного копирования: %v", err)
+		a.services.Notification.Notify(services.KindSystem, "⚠️ Не удалось сделать резервную копию базы, подробности в логах")
+		return
+	}
+	log.Printf("💾 Резервная копия создана: %s (%d байт)", snapshot.Path, snapshot.Size)
+
+	removed, err := a.backups.Prune()
+	if err != nil {
+		log.Printf("⚠️ Ошибка ротации копий: %v", err)
+	}
+	for _, old := range removed {
+		log.Printf("🗑 Удалена старая копия: %s", old.Name)
+	}
 }
 
 func (a *Application) sendWelcomeMessage() {
@@ -0,0 +1,303 @@
+// Package backup - резервные копии файла SQLite с ротацией и восстановлением.
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"five-pillars/internal/database"
+)
+
+const (
+	filePrefix = "five-pillars-"
+	fileSuffix = ".db"
+	timeLayout = "20060102-150405"
+
+	// preRestorePrefix копия текущей БД перед восстановлением, ротацией не удаляется
+	preRestorePrefix = "pre-restore-"
+)
+
+// ErrNoSnapshots в каталоге нет ни одной копии
+var ErrNoSnapshots = errors.New("резервных копий пока нет")
+
+// Retention сколько копий хранить: последние по дням, неделям и месяцам
+type Retention struct {
+	Daily   int
+	Weekly  int
+	Monthly int
+}
+
+// Snapshot файл резервной копии
+type Snapshot struct {
+	Name      string
+	Path      string
+	CreatedAt time.Time
+	Size      int64
+}
+
+// Manager создает копии работающей БД в каталоге dir и чистит старые
+type Manager struct {
+	db        *database.Database
+	dir       string
+	retention Retention
+}
+
+func NewManager(db *database.Database, dir string, retention Retention) *Manager {
+	return &Manager{
+		db:        db,
+		dir:       dir,
+		retention: retention,
+	}
+}
+
+// Dir каталог с копиями
+func (m *Manager) Dir() string {
+	return m.dir
+}
+
+// Create делает копию БД с отметкой времени в имени
+func (m *Manager) Create() (*Snapshot, error) {
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("ошибка создания каталога копий: %v", err)
+	}
+
+	now := time.Now().UTC()
+	name := filePrefix + now.Format(timeLayout) + fileSuffix
+	path := filepath.Join(m.dir, name)
+
+	// Копия в ту же секунду уже сделана, она и так актуальна
+	if info, err := os.Stat(path); err == nil {
+		return &Snapshot{Name: name, Path: path, CreatedAt: now, Size: info.Size()}, nil
+	}
+
+	if err := m.db.BackupTo(path); err != nil {
+		return nil, err
+	}
+
+	if err := database.CheckIntegrity(path); err != nil {
+		os.Remove(path)
+		return nil, fmt.Errorf("копия не прошла проверку: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения копии: %v", err)
+	}
+
+	return &Snapshot{Name: name, Path: path, CreatedAt: now, Size: info.Size()}, nil
+}
+
+// List возвращает копии от новых к старым
+func (m *Manager) List() ([]Snapshot, error) {
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("ошибка чтения каталога копий: %v", err)
+	}
+
+	var snapshots []Snapshot
+	for _, entry := range entries {
+		createdAt, ok := parseName(entry.Name())
+		if !ok || entry.IsDir() {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		snapshots = append(snapshots, Snapshot{
+			Name:      entry.Name(),
+			Path:      filepath.Join(m.dir, entry.Name()),
+			CreatedAt: createdAt,
+			Size:      info.Size(),
+		})
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt)
+	})
+
+	return snapshots, nil
+}
+
+// Latest самая свежая копия
+func (m *Manager) Latest() (*Snapshot, error) {
+	snapshots, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, ErrNoSnapshots
+	}
+	return &snapshots[0], nil
+}
+
+// Find ищет копию по имени файла в каталоге; "latest" - самая свежая
+func (m *Manager) Find(name string) (*Snapshot, error) {
+	if name == "latest" {
+		return m.Latest()
+	}
+
+	snapshots, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+	for i := range snapshots {
+		if snapshots[i].Name == name {
+			return &snapshots[i], nil
+		}
+	}
+	return nil, fmt.Errorf("копия %s не найдена в %s", name, m.dir)
+}
+
+// Prune удаляет копии, не попадающие под правила хранения, и возвращает
+// удаленные. Самая свежая копия сохраняется всегда.
+func (m *Manager) Prune() ([]Snapshot, error) {
+	snapshots, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+
+	keep := retained(snapshots, m.retention)
+
+	var removed []Snapshot
+	for _, snapshot := range snapshots {
+		if keep[snapshot.Name] {
+			continue
+		}
+		if err := os.Remove(snapshot.Path); err != nil {
+			return removed, fmt.Errorf("ошибка удаления копии %s: %v", snapshot.Name, err)
+		}
+		removed = append(removed, snapshot)
+	}
+
+	return removed, nil
+}
+
+// retained отбирает копии для хранения: по одной самой свежей на каждый из
+// последних Daily дней, Weekly недель (ISO) и Monthly месяцев.
+// snapshots должны быть отсортированы от новых к старым.
+func retained(snapshots []Snapshot, retention Retention) map[string]bool {
+	keep := make(map[string]bool)
+	if len(snapshots) == 0 {
+		return keep
+	}
+	keep[snapshots[0].Name] = true
+
+	rules := []struct {
+		limit  int
+		bucket func(time.Time) string
+	}{
+		{retention.Daily, func(t time.Time) string { return t.Format("2006-01-02") }},
+		{retention.Weekly, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		}},
+		{retention.Monthly, func(t time.Time) string { return t.Format("2006-01") }},
+	}
+
+	for _, rule := range rules {
+		seen := make(map[string]bool)
+		for _, snapshot := range snapshots {
+			if len(seen) >= rule.limit {
+				break
+			}
+			bucket := rule.bucket(snapshot.CreatedAt)
+			if seen[bucket] {
+				continue
+			}
+			seen[bucket] = true
+			keep[snapshot.Name] = true
+		}
+	}
+
+	return keep
+}
+
+// Restore заменяет файл БД dbPath копией src. Копия сначала проверяется,
+// текущая БД сохраняется в dir как pre-restore-*.db. Приложение на время
+// восстановления должно быть остановлено.
+func Restore(src, dbPath, dir string) (string, error) {
+	if err := database.CheckIntegrity(src); err != nil {
+		return "", fmt.Errorf("копия %s не прошла проверку: %v", src, err)
+	}
+
+	var saved string
+	if _, err := os.Stat(dbPath); err == nil {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return "", fmt.Errorf("ошибка создания каталога копий: %v", err)
+		}
+		saved = filepath.Join(dir, preRestorePrefix+time.Now().UTC().Format(timeLayout)+fileSuffix)
+		if err := copyFile(dbPath, saved); err != nil {
+			return "", fmt.Errorf("ошибка сохранения текущей БД: %v", err)
+		}
+	}
+
+	tmp := dbPath + ".restore"
+	if err := copyFile(src, tmp); err != nil {
+		os.Remove(tmp)
+		return saved, fmt.Errorf("ошибка копирования: %v", err)
+	}
+	if err := os.Rename(tmp, dbPath); err != nil {
+		os.Remove(tmp)
+		return saved, fmt.Errorf("ошибка замены файла БД: %v", err)
+	}
+
+	// Журналы старой БД не должны накатиться на восстановленный файл
+	for _, suffix := range []string{"-wal", "-shm", "-journal"} {
+		os.Remove(dbPath + suffix)
+	}
+
+	if err := database.CheckIntegrity(dbPath); err != nil {
+		return saved, fmt.Errorf("восстановленная БД не прошла проверку: %v", err)
+	}
+
+	return saved, nil
+}
+
+// parseName извлекает время создания из имени five-pillars-YYYYMMDD-HHMMSS.db
+func parseName(name string) (time.Time, bool) {
+	if !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, fileSuffix) {
+		return time.Time{}, false
+	}
+
+	stamp := strings.TrimSuffix(strings.TrimPrefix(name, filePrefix), fileSuffix)
+	createdAt, err := time.Parse(timeLayout, stamp)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return createdAt, true
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,99 @@
+package backup
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+)
+
+// snapshots копии каждые 12 часов за days дней до now, от новых к старым
+func snapshots(now time.Time, days int) []Snapshot {
+	var result []Snapshot
+	for at := now; at.After(now.AddDate(0, 0, -days)); at = at.Add(-12 * time.Hour) {
+		result = append(result, Snapshot{Name: at.Format(timeLayout), CreatedAt: at})
+	}
+	return result
+}
+
+func kept(keep map[string]bool) []string {
+	var names []string
+	for name := range keep {
+		names = append(names, name)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	return names
+}
+
+func TestRetained(t *testing.T) {
+	// Среда, 12:00
+	now := time.Date(2030, 1, 16, 12, 0, 0, 0, time.UTC)
+	name := func(day, hour int) string {
+		return time.Date(2030, 1, day, hour, 0, 0, 0, time.UTC).Format(timeLayout)
+	}
+
+	tests := []struct {
+		name      string
+		snapshots []Snapshot
+		retention Retention
+		want      []string
+	}{
+		{
+			name:      "пусто",
+			retention: Retention{Daily: 7},
+		},
+		{
+			name:      "самая свежая остается всегда",
+			snapshots: snapshots(now, 3),
+			want:      []string{name(16, 12)},
+		},
+		{
+			name:      "по одной на день",
+			snapshots: snapshots(now, 3),
+			retention: Retention{Daily: 2},
+			want:      []string{name(16, 12), name(15, 12)},
+		},
+		{
+			name:      "по неделям ISO",
+			snapshots: snapshots(now, 15),
+			retention: Retention{Weekly: 3},
+			// Недели ISO с 14 и 7 января и с 31 декабря
+			want: []string{name(16, 12), name(13, 12), name(6, 12)},
+		},
+		{
+			name:      "по месяцам",
+			snapshots: snapshots(now, 20),
+			retention: Retention{Monthly: 2},
+			want:      []string{name(16, 12), time.Date(2029, 12, 31, 12, 0, 0, 0, time.UTC).Format(timeLayout)},
+		},
+		{
+			name:      "правила пересекаются",
+			snapshots: snapshots(now, 15),
+			retention: Retention{Daily: 2, Weekly: 2},
+			want:      []string{name(16, 12), name(15, 12), name(13, 12)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := kept(retained(tt.snapshots, tt.retention))
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("retained() = %v, ожидалось %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseName(t *testing.T) {
+	at := time.Date(2030, 1, 16, 3, 4, 5, 0, time.UTC)
+	name := filePrefix + at.Format(timeLayout) + fileSuffix
+
+	got, ok := parseName(name)
+	if !ok || !got.Equal(at) {
+		t.Errorf("parseName(%q) = %s, %v", name, got, ok)
+	}
+	for _, bad := range []string{"pre-restore-20300116-030405.db", filePrefix + "2030.db", "notes.txt"} {
+		if _, ok := parseName(bad); ok {
+			t.Errorf("parseName(%q) принял чужой файл", bad)
+		}
+	}
+}
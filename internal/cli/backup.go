@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"five-pillars/internal/backup"
+	"five-pillars/internal/database"
+)
+
+func (c *CLI) backupManager() *backup.Manager {
+	return backup.NewManager(c.db, c.config.Backup.Dir, backup.Retention{
+		Daily:   c.config.Backup.KeepDaily,
+		Weekly:  c.config.Backup.KeepWeekly,
+		Monthly: c.config.Backup.KeepMonthly,
+	})
+}
+
+func (c *CLI) backup(args []string) error {
+	fs := c.newFlagSet("backup")
+	list := fs.Bool("list", false, "только показать имеющиеся копии")
+	prune := fs.Bool("prune", true, "удалить устаревшие копии по правилам хранения")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+
+	manager := c.backupManager()
+
+	if *list {
+		snapshots, err := manager.List()
+		if err != nil {
+			return err
+		}
+		if len(snapshots) == 0 {
+			c.println("Копий нет: %s", manager.Dir())
+			return nil
+		}
+		for _, snapshot := range snapshots {
+			c.println("%s  %s  %d байт", snapshot.CreatedAt.Format("2006-01-02 15:04:05"), snapshot.Name, snapshot.Size)
+		}
+		return nil
+	}
+
+	snapshot, err := manager.Create()
+	if err != nil {
+		return err
+	}
+	c.println("✅ Копия создана: %s", snapshot.Path)
+
+	if *prune {
+		removed, err := manager.Prune()
+		if err != nil {
+			return err
+		}
+		for _, old := range removed {
+			c.println("🗑 Удалена старая копия: %s", old.Name)
+		}
+	}
+	return nil
+}
+
+// restore заменяет БД копией. Работает без открытой БД: бот на время
+// восстановления нужно остановить.
+func (c *CLI) restore(args []string) error {
+	fs := c.newFlagSet("restore")
+	yes := fs.Bool("yes", false, "не спрашивать подтверждение")
+	check := fs.Bool("check", false, "только проверить копию")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+	if fs.NArg() != 1 {
+		c.println("Использование: restore [--check] [--yes] <файл|имя копии|latest>")
+		return ErrUsage
+	}
+
+	src, err := c.resolveBackup(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if err := database.CheckIntegrity(src); err != nil {
+		return fmt.Errorf("копия %s не прошла проверку: %v", src, err)
+	}
+	c.println("✅ Копия %s прошла проверку целостности", src)
+	if *check {
+		return nil
+	}
+
+	if !*yes {
+		fmt.Fprintf(c.out, "Заменить %s этой копией? Бот должен быть остановлен. [y/N] ", c.config.Database.Path)
+		answer, _ := bufio.NewReader(c.in).ReadString('\n')
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		if answer != "y" && answer != "yes" && answer != "д" && answer != "да" {
+			c.println("Отменено")
+			return nil
+		}
+	}
+
+	saved, err := backup.Restore(src, c.config.Database.Path, c.config.Backup.Dir)
+	if saved != "" {
+		c.println("💾 Прежняя БД сохранена: %s", saved)
+	}
+	if err != nil {
+		return err
+	}
+
+	c.println("✅ БД восстановлена из %s", src)
+	return nil
+}
+
+// resolveBackup принимает путь к файлу, имя копии из каталога копий или latest
+func (c *CLI) resolveBackup(name string) (string, error) {
+	if _, err := os.Stat(name); err == nil {
+		return name, nil
+	}
+
+	snapshot, err := backup.NewManager(nil, c.config.Backup.Dir, backup.Retention{}).Find(name)
+	if err != nil {
+		return "", err
+	}
+	return snapshot.Path, nil
+}
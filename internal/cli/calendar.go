@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"five-pillars/internal/ical"
+)
+
+func (c *CLI) calendar(args []string) error {
+	fs := c.newFlagSet("ical")
+	past := fs.Int("past", c.config.Calendar.PastDays, "дней истории до сегодня")
+	future := fs.Int("future", c.config.Calendar.FutureDays, "дней вперед от сегодня")
+	out := fs.String("out", "", "файл .ics, по умолчанию stdout")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+
+	now := time.Now()
+	from, to := ical.Window{PastDays: *past, FutureDays: *future}.Range(now)
+	tasks, err := c.services.Task.GetTasksBetween(from, to)
+	if err != nil {
+		return err
+	}
+
+	data := ical.Render(c.services.Registry(), tasks, "5 Столпов", now)
+	if *out == "" {
+		_, err := c.out.Write(data)
+		return err
+	}
+
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		return fmt.Errorf("ошибка записи файла: %v", err)
+	}
+	c.println("✅ Календарь %s — %s, событий: %d → %s", from, to, len(tasks), *out)
+	return nil
+}
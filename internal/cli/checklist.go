@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+
+	"five-pillars/internal/database"
+	"five-pillars/internal/services"
+)
+
+const checklistUsage = "Использование: checklist <id задачи> | checklist add <id задачи> пункт; пункт | " +
+	"checklist toggle|remove <id пункта> | checklist template <id шаблона> [пункт; пункт]"
+
+func (c *CLI) checklistCmd(args []string) error {
+	if len(args) == 0 {
+		c.println(checklistUsage)
+		return ErrUsage
+	}
+
+	switch args[0] {
+	case "add":
+		if len(args) < 3 {
+			c.println(checklistUsage)
+			return ErrUsage
+		}
+		id, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("id должен быть числовой: %s", args[1])
+		}
+		task, err := c.services.Task.AddChecklist(id, joinArgs(args[2:]))
+		if err != nil {
+			return err
+		}
+		c.printChecklist(task)
+		return nil
+	case "toggle", "remove":
+		if len(args) != 2 {
+			c.println(checklistUsage)
+			return ErrUsage
+		}
+		id, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("id должен быть числовой: %s", args[1])
+		}
+		var task *database.DailyTask
+		if args[0] == "toggle" {
+			task, err = c.services.Task.ToggleChecklistItem(id)
+		} else {
+			task, err = c.services.Task.RemoveChecklistItem(id)
+		}
+		if err != nil {
+			return err
+		}
+		c.printChecklist(task)
+		return nil
+	case "template":
+		if len(args) < 2 {
+			c.println(checklistUsage)
+			return ErrUsage
+		}
+		id, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("id должен быть числовой: %s", args[1])
+		}
+		if err := c.services.Task.SetTemplateChecklist(id, joinArgs(args[2:])); err != nil {
+			return err
+		}
+		if len(args) == 2 {
+			c.println("✅ Чек-лист шаблона #%d убран", id)
+			return nil
+		}
+		c.println("✅ Чек-лист шаблона #%d обновлен, его получат новые задачи", id)
+		return nil
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		c.println(checklistUsage)
+		return ErrUsage
+	}
+	task, err := c.services.Task.GetTask(id)
+	if err != nil {
+		return err
+	}
+	c.printChecklist(task)
+	return nil
+}
+
+func (c *CLI) printChecklist(task *database.DailyTask) {
+	c.println("#%d %s (%s)", task.ID, task.Description, taskStatus(*task))
+	if len(task.Checklist) == 0 {
+		c.println("Чек-листа нет")
+		return
+	}
+	for _, item := range task.Checklist {
+		c.println("  %s #%d %s", services.ChecklistMark(item), item.ID, item.Text)
+	}
+}
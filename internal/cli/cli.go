@@ -0,0 +1,251 @@
+// Package cli - консольные команды для работы с базой трекера без Telegram.
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"five-pillars/internal/config"
+	"five-pillars/internal/database"
+	"five-pillars/internal/services"
+	"five-pillars/internal/webhook"
+)
+
+// ErrUsage неверные аргументы команды, справка уже выведена
+var ErrUsage = errors.New("неверные аргументы команды")
+
+type command struct {
+	usage string
+	run   func(c *CLI, args []string) error
+	// lazyDB команда сама решает, нужна ли ей локальная БД (см. CLI.openDB)
+	lazyDB bool
+}
+
+var commands = map[string]command{
+	"migrate":   {usage: "migrate - создать/обновить схему БД", run: (*CLI).migrate},
+	"tasks":     {usage: "tasks list|add|complete|value - управление задачами", run: (*CLI).tasks},
+	"feelings":  {usage: "feelings [--date D] | feelings set [--date D] энергия=8 контроль=7 ... - ощущения", run: (*CLI).feelings},
+	"wellbeing": {usage: "wellbeing [--all] | add <ключ> <тип> <эмодзи> <название> | set <ключ> <поле> <значение> | archive|restore <ключ> - показатели самочувствия", run: (*CLI).wellbeingCmd},
+	"sleep":     {usage: "sleep [--days N] | add [--date D] 23:40-07:10 | delete <дата> - журнал сна и режим", run: (*CLI).sleep},
+	"journal":   {usage: "journal [--date D] | add [--date D] <текст> | delete <id> - дневник дня", run: (*CLI).journal},
+	"report":    {usage: "report week|month - аналитика за неделю, измеримые цели за месяц", run: (*CLI).report},
+	"generate":  {usage: "generate --date YYYY-MM-DD - создать задачи по умолчанию на дату", run: (*CLI).generate},
+	"export":    {usage: "export [--from D] [--to D] [--format json|csv|md] [--dataset tasks] [--out файл] - выгрузить данные", run: (*CLI).export},
+	"import":    {usage: "import [--dry-run] [--from D --to D] <файл.csv|.json|.ics> - загрузить задачи, ощущения и шаблоны или события календаря", run: (*CLI).importData},
+	"templates": {usage: "templates - список шаблонов задач", run: (*CLI).templates},
+	"metric":    {usage: "metric task|template <id> <единица> [цель] [sum|avg|max] | off - измеримый результат задач и шаблонов", run: (*CLI).metricCmd},
+	"tag":       {usage: "tag task|template <id> [#тег ...] | off - теги задач и шаблонов", run: (*CLI).tagCmd},
+	"find":      {usage: "find [--limit N] <запрос> - поиск по задачам, тегам и заметкам: слова #тег from:D to:D status:done", run: (*CLI).find},
+	"goals":     {usage: "goals [--all] | add <столп> <срок> <цель> <описание> | show <id> | link <id> template|task <id> | unlink template|task <id> | archive|restore <id> - долгосрочные цели", run: (*CLI).goalsCmd},
+	"pillars":   {usage: "pillars [--all] | add <ключ> <эмодзи> <название> | set <ключ> <поле> <значение> | archive|restore <ключ> - справочник столпов", run: (*CLI).pillarsCmd},
+	"checklist": {usage: "checklist <id> | add <id> пункт; пункт | toggle|remove <id пункта> | template <id> [пункты] - чек-листы задач и шаблонов", run: (*CLI).checklistCmd},
+	"backup":    {usage: "backup [--list] [--prune=false] - сделать резервную копию БД (или показать копии)", run: (*CLI).backup},
+	"restore":   {usage: "restore [--check] [--yes] <файл|имя копии|latest> - восстановить БД из копии", run: (*CLI).restore, lazyDB: true},
+	"ical":      {usage: "ical [--past N] [--future N] [--out файл.ics] - календарь задач в формате iCalendar", run: (*CLI).calendar},
+	"rollover":  {usage: "rollover [run | template ID carry|miss|ask|auto] - перенос незакрытых задач: правила, запустить сейчас, правило шаблона", run: (*CLI).rolloverCmd},
+	"outbox":    {usage: "outbox [retry] - исходящая очередь бота: состояние, повторить недоставленные", run: (*CLI).outboxCmd},
+	"webhooks":  {usage: "webhooks [deliver|retry] - очередь вебхуков: состояние, отправить сейчас, повторить неотправленные", run: (*CLI).webhooksCmd},
+	"notify":    {usage: "notify [--channel stdout|email|push] reminders|digest|summary|week|goals|feelings|morning|evening - отправить уведомление", run: (*CLI).notifyCmd},
+	"tui":       {usage: "tui [--api URL] [--token T] - терминальный клиент (локальная БД или HTTP API)", run: (*CLI).tui, lazyDB: true},
+}
+
+// CLI выполняет консольные команды поверх тех же сервисов, что и бот
+type CLI struct {
+	config   *config.Config
+	db       *database.Database
+	services *services.ServiceManager
+	webhooks *webhook.Dispatcher
+	in       io.Reader
+	out      io.Writer
+}
+
+// Run выполняет команду args[0] с аргументами args[1:]
+func Run(cfg *config.Config, args []string, out io.Writer) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		PrintUsage(out)
+		return nil
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		PrintUsage(out)
+		return fmt.Errorf("неизвестная команда: %s", args[0])
+	}
+
+	c := &CLI{
+		config: cfg,
+		in:     os.Stdin,
+		out:    out,
+	}
+	defer c.close()
+
+	if !cmd.lazyDB {
+		if err := c.openDB(); err != nil {
+			return err
+		}
+	}
+
+	return cmd.run(c, args[1:])
+}
+
+// openDB открывает БД по DB_PATH и создает сервисы
+func (c *CLI) openDB() error {
+	db, err := database.New(c.config.Database.Path)
+	if err != nil {
+		return err
+	}
+
+	c.db = db
+	c.services = services.NewServiceManager(db)
+
+	quietHours, err := services.ParseQuietHours(c.config.Notify.QuietHours)
+	if err != nil {
+		return err
+	}
+	c.services.Quiet.SetHours(quietHours)
+
+	rolloverPolicies, err := services.ParseRolloverPolicies(db.Registry(), c.config.Rollover.Policy)
+	if err != nil {
+		return err
+	}
+	c.services.Rollover.SetPolicies(rolloverPolicies)
+
+	// События из консоли попадают в ту же очередь вебхуков, отправит её сервер
+	endpoints, err := webhook.ParseEndpoints(c.config.Webhooks.Endpoints, c.config.Webhooks.Secret)
+	if err != nil {
+		return err
+	}
+	if len(endpoints) > 0 {
+		c.webhooks = webhook.NewDispatcher(db, endpoints)
+		c.services.Subscribe(c.webhooks)
+	}
+	return nil
+}
+
+func (c *CLI) close() {
+	if c.db != nil {
+		c.db.Close()
+	}
+}
+
+// PrintUsage выводит список команд
+func PrintUsage(out io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(out, "Использование: five-pillars [команда] [аргументы]")
+	fmt.Fprintln(out, "\nБез команды или с командой serve запускается бот и HTTP API.")
+	fmt.Fprintln(out, "\nКоманды:")
+	for _, name := range names {
+		fmt.Fprintf(out, "  %s\n", commands[name].usage)
+	}
+}
+
+func (c *CLI) newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.out)
+	return fs
+}
+
+func (c *CLI) println(format string, args ...interface{}) {
+	fmt.Fprintf(c.out, format+"\n", args...)
+}
+
+func (c *CLI) migrate(args []string) error {
+	c.println("✅ Схема БД актуальна: %s", c.config.Database.Path)
+	return nil
+}
+
+func (c *CLI) generate(args []string) error {
+	fs := c.newFlagSet("generate")
+	date := fs.String("date", "", "дата YYYY-MM-DD")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+	if *date == "" {
+		fs.Usage()
+		return ErrUsage
+	}
+	if err := services.ValidateDate(*date); err != nil {
+		return err
+	}
+
+	if err := c.services.Task.CreateDefaultTasksNextDay(*date); err != nil {
+		return err
+	}
+
+	tasks, err := c.services.Task.GetTasks(*date)
+	if err != nil {
+		return err
+	}
+	c.println("✅ Задачи на %s созданы, всего задач на дату: %d", *date, len(tasks))
+	return nil
+}
+
+func (c *CLI) report(args []string) error {
+	if len(args) == 0 || (args[0] != "week" && args[0] != "month") {
+		c.println("Использование: report week|month")
+		return ErrUsage
+	}
+	if args[0] == "month" {
+		return c.reportMonth()
+	}
+
+	analytics, err := c.services.Analytics.GetWeeklyAnalytics()
+	if err != nil {
+		return err
+	}
+
+	c.println("Аналитика за неделю %d (%s - %s)", analytics.WeekNumber, analytics.StartDate, analytics.EndDate)
+	c.println("Выполнено: %d/%d (%.0f%%), пропущено: %d, не сделано: %d, впереди: %d",
+		analytics.TotalDone, analytics.Resolved(), analytics.CompletionRate(), analytics.TotalSkipped,
+		analytics.TotalMissed, analytics.TotalOpen)
+	if analytics.TotalPartial > 0 {
+		c.println("Чек-листы выполнены частично: %d, с их учетом %.0f%%", analytics.TotalPartial, analytics.ProgressRate())
+	}
+
+	c.println("\nПо столпам:")
+	for _, pillar := range database.SortedPillars(c.services.Registry(), analytics.PillarStats) {
+		stats := analytics.PillarStats[pillar]
+		c.println("  %s: %d/%d (%.0f%%), пропущено: %d, не сделано: %d",
+			c.services.Registry().PillarName(database.Pillar(pillar)), stats.Completed, stats.Resolved(), stats.CompletionRate(),
+			stats.Skipped, stats.Missed)
+	}
+
+	if len(analytics.Metrics) > 0 {
+		c.println("\nИзмеримые цели:")
+		c.printMetrics(analytics.Metrics)
+	}
+
+	if len(analytics.AvgFeelings) > 0 {
+		c.println("\nСредние ощущения:")
+		for _, line := range services.WellbeingAverageLines(c.services.Registry(), analytics.AvgFeelings) {
+			c.println("  %s", line)
+		}
+	}
+
+	if analytics.Sleep != nil {
+		c.println("\nСон:")
+		for _, line := range strings.Split(strings.TrimRight(services.FormatSleepStats(analytics.Sleep), "\n"), "\n") {
+			c.println("  %s", line)
+		}
+	}
+
+	if analytics.Insights != "" {
+		c.println("\nИнсайты:\n%s", analytics.Insights)
+	}
+	return nil
+}
+
+// joinArgs собирает позиционные аргументы обратно в строку
+func joinArgs(args []string) string {
+	return strings.TrimSpace(strings.Join(args, " "))
+}
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"strings"
+
+	"five-pillars/internal/services"
+)
+
+func (c *CLI) feelings(args []string) error {
+	if len(args) > 0 && args[0] == "set" {
+		return c.feelingsSet(args[1:])
+	}
+
+	fs := c.newFlagSet("feelings")
+	date := fs.String("date", services.Today(), "дата YYYY-MM-DD")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+
+	feelings, err := c.services.Feelings.Get(*date)
+	if err != nil {
+		return err
+	}
+
+	c.println("Ощущения за %s:", feelings.Date)
+	for _, line := range services.FeelingsLines(c.services.Registry(), *feelings) {
+		c.println("  %s", line)
+	}
+	if feelings.Notes != "" {
+		c.println("  Заметки: %s", feelings.Notes)
+	}
+	return nil
+}
+
+func (c *CLI) feelingsSet(args []string) error {
+	fs := c.newFlagSet("feelings set")
+	date := fs.String("date", services.Today(), "дата YYYY-MM-DD")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+
+	input, err := services.ParseFeelings(c.services.Registry(), joinArgs(fs.Args()))
+	if err != nil {
+		return err
+	}
+	input.Date = *date
+
+	feelings, err := c.services.Feelings.Save(input)
+	if err != nil {
+		return err
+	}
+
+	c.println("✅ Ощущения за %s сохранены: %s", feelings.Date, strings.Join(services.FeelingsLines(c.services.Registry(), *feelings), ", "))
+	return nil
+}
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"five-pillars/internal/database"
+	"five-pillars/internal/services"
+)
+
+const goalsUsage = "Использование: goals [--all] | goals add [--start D] <столп> <срок YYYY-MM-DD> <цель, например 500км> <описание> | " +
+	"goals show <id> | goals link <id> template|task <id> | goals unlink template|task <id> | goals archive|restore <id>"
+
+func (c *CLI) goalsCmd(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return c.goalsList(args)
+	}
+
+	switch args[0] {
+	case "add":
+		return c.goalsAdd(args[1:])
+	case "show":
+		if len(args) != 2 {
+			c.println(goalsUsage)
+			return ErrUsage
+		}
+		id, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("id должен быть числовой: %s", args[1])
+		}
+		goal, err := c.services.Goals.GetGoal(id)
+		if err != nil {
+			return err
+		}
+		c.printGoal(goal)
+		return nil
+	case "link":
+		if len(args) != 4 || (args[2] != "template" && args[2] != "task") {
+			c.println(goalsUsage)
+			return ErrUsage
+		}
+		goalID, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("id должен быть числовой: %s", args[1])
+		}
+		id, err := strconv.Atoi(args[3])
+		if err != nil {
+			return fmt.Errorf("id должен быть числовой: %s", args[3])
+		}
+		var goal *database.Goal
+		if args[2] == "template" {
+			goal, err = c.services.Goals.LinkTemplate(goalID, id)
+		} else {
+			goal, err = c.services.Goals.LinkTask(goalID, id)
+		}
+		if err != nil {
+			return err
+		}
+		c.printGoal(goal)
+		return nil
+	case "unlink":
+		if len(args) != 3 || (args[1] != "template" && args[1] != "task") {
+			c.println(goalsUsage)
+			return ErrUsage
+		}
+		id, err := strconv.Atoi(args[2])
+		if err != nil {
+			return fmt.Errorf("id должен быть числовой: %s", args[2])
+		}
+		if args[1] == "template" {
+			err = c.services.Goals.UnlinkTemplate(id)
+		} else {
+			err = c.services.Goals.UnlinkTask(id)
+		}
+		if err != nil {
+			return err
+		}
+		c.println("✅ Отвязано от цели: %s #%d", args[1], id)
+		return nil
+	case "archive", "restore":
+		if len(args) != 2 {
+			c.println(goalsUsage)
+			return ErrUsage
+		}
+		id, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("id должен быть числовой: %s", args[1])
+		}
+		if err := c.services.Goals.Archive(id, args[0] == "archive"); err != nil {
+			return err
+		}
+		if args[0] == "archive" {
+			c.println("📦 Цель #%d в архиве", id)
+		} else {
+			c.println("✅ Цель #%d снова активна", id)
+		}
+		return nil
+	default:
+		c.println(goalsUsage)
+		return ErrUsage
+	}
+}
+
+func (c *CLI) goalsList(args []string) error {
+	fs := c.newFlagSet("goals")
+	all := fs.Bool("all", false, "вместе с архивными")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+
+	goals, err := c.services.Goals.GetGoals(*all)
+	if err != nil {
+		return err
+	}
+	if len(goals) == 0 {
+		c.println("🎯 Целей нет")
+		return nil
+	}
+	c.println("%s", strings.TrimRight(services.FormatGoals(c.services.Registry(), goals, time.Now()), "\n"))
+	return nil
+}
+
+func (c *CLI) goalsAdd(args []string) error {
+	fs := c.newFlagSet("goals add")
+	start := fs.String("start", services.Today(), "начало цели YYYY-MM-DD")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+
+	rest := fs.Args()
+	if len(rest) < 4 {
+		c.println(goalsUsage)
+		return ErrUsage
+	}
+	target, unit, err := services.ParseGoalTarget(rest[2])
+	if err != nil {
+		return err
+	}
+
+	goal, err := c.services.Goals.AddGoal(services.NewGoalInput{
+		Pillar:      rest[0],
+		Deadline:    rest[1],
+		Target:      target,
+		Unit:        unit,
+		StartDate:   *start,
+		Description: joinArgs(rest[3:]),
+	})
+	if err != nil {
+		return err
+	}
+	c.println("✅ Добавлена цель #%d", goal.ID)
+	c.printGoal(goal)
+	return nil
+}
+
+func (c *CLI) printGoal(goal *database.Goal) {
+	c.println("%s", strings.TrimRight(services.FormatGoal(c.services.Registry(), *goal, time.Now()), "\n"))
+	c.println("Период: %s - %s, выполнено задач: %d из %d", goal.StartDate, goal.Deadline, goal.Progress.Done, goal.Progress.Tasks)
+	if len(goal.Templates) > 0 {
+		ids := make([]string, len(goal.Templates))
+		for i, id := range goal.Templates {
+			ids[i] = strconv.Itoa(id)
+		}
+		c.println("Шаблоны: %s", strings.Join(ids, ", "))
+	}
+}
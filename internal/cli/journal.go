@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+
+	"five-pillars/internal/services"
+)
+
+const journalUsage = "Использование: journal [--date D] | journal add [--date D] <текст> | journal delete <id>"
+
+func (c *CLI) journal(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "add":
+			return c.journalAdd(args[1:])
+		case "delete":
+			return c.journalDelete(args[1:])
+		}
+	}
+
+	fs := c.newFlagSet("journal")
+	date := fs.String("date", services.Today(), "дата YYYY-MM-DD")
+	if err := fs.Parse(args); err != nil || fs.NArg() > 0 {
+		c.println(journalUsage)
+		return ErrUsage
+	}
+
+	entries, err := c.services.Journal.Get(*date)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		c.println("📭 Записей в дневнике за %s нет", *date)
+		return nil
+	}
+
+	c.println("📓 Дневник за %s", *date)
+	fmt.Fprint(c.out, services.FormatJournal(entries))
+	return nil
+}
+
+func (c *CLI) journalAdd(args []string) error {
+	fs := c.newFlagSet("journal add")
+	date := fs.String("date", services.Today(), "дата YYYY-MM-DD")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+	if fs.NArg() == 0 {
+		c.println(journalUsage)
+		return ErrUsage
+	}
+
+	entry, err := c.services.Journal.Add(*date, joinArgs(fs.Args()), "")
+	if err != nil {
+		return err
+	}
+	c.println("📝 Запись #%d добавлена в дневник за %s", entry.ID, entry.Date)
+	return nil
+}
+
+func (c *CLI) journalDelete(args []string) error {
+	if len(args) != 1 {
+		c.println(journalUsage)
+		return ErrUsage
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("id должен быть числовой: %s", args[0])
+	}
+	if err := c.services.Journal.Delete(id); err != nil {
+		return err
+	}
+	c.println("🗑 Запись дневника #%d удалена", id)
+	return nil
+}
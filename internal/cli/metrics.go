@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"five-pillars/internal/database"
+	"five-pillars/internal/services"
+)
+
+const metricUsage = "Использование: metric task|template <id> <единица> [цель] [sum|avg|max] | metric task|template <id> off"
+
+func (c *CLI) metricCmd(args []string) error {
+	if len(args) < 3 || (args[0] != "task" && args[0] != "template") {
+		c.println(metricUsage)
+		return ErrUsage
+	}
+
+	id, err := strconv.Atoi(args[1])
+	if err != nil {
+		return fmt.Errorf("id должен быть числовой: %s", args[1])
+	}
+	metric, err := services.ParseMetricArgs(args[2:])
+	if err != nil {
+		return err
+	}
+
+	if args[0] == "template" {
+		if err := c.services.Task.SetTemplateMetric(id, metric); err != nil {
+			return err
+		}
+		if metric.Unit == "" {
+			c.println("✅ Метрика шаблона #%d убрана", id)
+			return nil
+		}
+		c.println("✅ Метрика шаблона #%d обновлена, её получат новые задачи", id)
+		return nil
+	}
+
+	task, err := c.services.Task.SetMetric(id, metric)
+	if err != nil {
+		return err
+	}
+	if task.MetricUnit == "" {
+		c.println("✅ Метрика задачи #%d убрана", task.ID)
+		return nil
+	}
+	c.println("✅ Задача #%d: %s %s", task.ID, task.Description, services.FormatTaskValue(*task))
+	return nil
+}
+
+func (c *CLI) tasksValue(args []string) error {
+	if len(args) != 2 {
+		c.println("Использование: tasks value <id> <число>")
+		return ErrUsage
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("id должен быть числовой: %s", args[0])
+	}
+	value, err := services.ParseValue(args[1])
+	if err != nil {
+		return err
+	}
+
+	task, err := c.services.Task.RecordValue(id, value)
+	if err != nil {
+		return err
+	}
+	c.println("✅ Задача #%d (%s): %s", task.ID, taskStatus(*task), task.Description)
+	return nil
+}
+
+// reportMonth прогресс измеримых задач за текущий месяц
+func (c *CLI) reportMonth() error {
+	report, err := c.services.Analytics.GetMetrics("month", time.Now())
+	if err != nil {
+		return err
+	}
+
+	c.println("Измеримые цели за месяц (%s - %s)", report.From, report.To)
+	if len(report.Metrics) == 0 {
+		c.println("Измеримых задач нет")
+		return nil
+	}
+	c.printMetrics(report.Metrics)
+	return nil
+}
+
+func (c *CLI) printMetrics(stats []database.MetricStat) {
+	for _, line := range strings.Split(strings.TrimRight(services.FormatMetrics(c.services.Registry(), stats), "\n"), "\n") {
+		c.println("  %s", line)
+	}
+}
+
+// metricLabel метрика шаблона для таблицы: "5 км, sum"
+func metricLabel(template database.TaskTemplate) string {
+	if template.MetricUnit == "" {
+		return "-"
+	}
+	if template.MetricTarget > 0 {
+		return fmt.Sprintf("%s %s, %s", services.FormatAmount(template.MetricTarget), template.MetricUnit, template.MetricAgg)
+	}
+	return template.MetricUnit + ", " + template.MetricAgg
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"five-pillars/internal/notify"
+	"five-pillars/internal/services"
+)
+
+// notifyJobs задачи уведомлений, которые можно запустить из консоли
+var notifyJobs = map[string]func(ns *services.NotificationService){
+	"reminders": (*services.NotificationService).CheckAndSendNotifications,
+	"digest":    (*services.NotificationService).SendAllTodayTaskNotification,
+	"summary":   (*services.NotificationService).SendDailySummary,
+	"week":      (*services.NotificationService).SendWeeklyReport,
+	"goals":     (*services.NotificationService).SendGoalsReport,
+	"feelings":  (*services.NotificationService).SendFeelingsReminder,
+	"morning":   (*services.NotificationService).SendMorningBriefing,
+	"evening":   (*services.NotificationService).SendEveningPlan,
+}
+
+// notifyCmd отправляет уведомление в один канал, по умолчанию в консоль
+func (c *CLI) notifyCmd(args []string) error {
+	fs := c.newFlagSet("notify")
+	channel := fs.String("channel", notify.ChannelStdout, "канал: stdout, email, push")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+
+	jobs := make([]string, 0, len(notifyJobs))
+	for name := range notifyJobs {
+		jobs = append(jobs, name)
+	}
+	sort.Strings(jobs)
+
+	job, ok := notifyJobs[fs.Arg(0)]
+	if fs.NArg() != 1 || !ok {
+		c.println("Использование: notify [--channel stdout|email|push] %s", strings.Join(jobs, "|"))
+		return ErrUsage
+	}
+
+	channels, err := notify.Channels(c.config, c.services.Registry(), c.out)
+	if err != nil {
+		return err
+	}
+	sender, ok := channels[*channel]
+	if !ok {
+		return fmt.Errorf("канал %s не настроен", *channel)
+	}
+
+	c.services.SetNotificationSender(sender)
+	job(c.services.Notification)
+	return nil
+}
@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"five-pillars/internal/database"
+)
+
+func (c *CLI) outboxCmd(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "retry":
+			count, err := c.services.Outbox.RetryDead()
+			if err != nil {
+				return err
+			}
+			c.println("🔁 Возвращено в очередь: %d (отправит запущенный бот)", count)
+		default:
+			c.println("Использование: outbox [retry]")
+			return ErrUsage
+		}
+	}
+
+	counts, err := c.services.Outbox.Counts()
+	if err != nil {
+		return err
+	}
+	c.println("Сообщения бота: в очереди %d, отправлено %d, не доставлено %d",
+		counts[database.OutboxPending], counts[database.OutboxSent], counts[database.OutboxDead])
+
+	dead, err := c.services.Outbox.Dead(10)
+	if err != nil {
+		return err
+	}
+	for _, msg := range dead {
+		c.println("  ❌ #%d %s: %s (попыток: %d)", msg.ID, msg.Method, msg.LastError, msg.Attempts)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"five-pillars/internal/database"
+	"five-pillars/internal/services"
+)
+
+const pillarsUsage = "Использование: pillars [--all] | pillars add [--color #RRGGBB] [--aliases a,b] <ключ> <эмодзи> <название> | " +
+	"pillars set <ключ> name|emoji|color|aliases|position <значение> | pillars archive|restore <ключ>"
+
+func (c *CLI) pillarsCmd(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return c.pillarsList(args)
+	}
+
+	switch args[0] {
+	case "add":
+		return c.pillarsAdd(args[1:])
+	case "set":
+		if len(args) < 4 {
+			c.println(pillarsUsage)
+			return ErrUsage
+		}
+		patch, err := services.ParsePillarPatch(args[2], joinArgs(args[3:]))
+		if err != nil {
+			return err
+		}
+		pillar, err := c.services.Pillars.Update(args[1], patch)
+		if err != nil {
+			return err
+		}
+		c.println("✅ Столп изменен")
+		return c.printPillars([]database.PillarInfo{*pillar})
+	case "archive", "restore":
+		if len(args) != 2 {
+			c.println(pillarsUsage)
+			return ErrUsage
+		}
+		archived := args[0] == "archive"
+		pillar, err := c.services.Pillars.Update(args[1], services.PillarPatch{Archived: &archived})
+		if err != nil {
+			return err
+		}
+		if archived {
+			c.println("📦 Столп %s в архиве", pillar.Title())
+		} else {
+			c.println("✅ Столп %s снова активен", pillar.Title())
+		}
+		return nil
+	default:
+		c.println(pillarsUsage)
+		return ErrUsage
+	}
+}
+
+func (c *CLI) pillarsList(args []string) error {
+	fs := c.newFlagSet("pillars")
+	all := fs.Bool("all", false, "вместе с архивными")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+
+	return c.printPillars(c.services.Pillars.List(*all))
+}
+
+func (c *CLI) pillarsAdd(args []string) error {
+	fs := c.newFlagSet("pillars add")
+	color := fs.String("color", "", "цвет #RRGGBB")
+	aliases := fs.String("aliases", "", "синонимы через запятую")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+
+	rest := fs.Args()
+	if len(rest) < 3 {
+		c.println(pillarsUsage)
+		return ErrUsage
+	}
+
+	pillar, err := c.services.Pillars.Add(database.PillarInfo{
+		Key:     database.Pillar(rest[0]),
+		Emoji:   rest[1],
+		Name:    joinArgs(rest[2:]),
+		Color:   *color,
+		Aliases: strings.Split(*aliases, ","),
+	})
+	if err != nil {
+		return err
+	}
+	c.println("✅ Добавлен столп")
+	return c.printPillars([]database.PillarInfo{*pillar})
+}
+
+func (c *CLI) printPillars(pillars []database.PillarInfo) error {
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "№\tКЛЮЧ\tСТОЛП\tЦВЕТ\tАРХИВ\tСИНОНИМЫ")
+	for _, pillar := range pillars {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%v\t%s\n",
+			pillar.Position, pillar.Key, pillar.Title(), pillar.Color, pillar.Archived, strings.Join(pillar.Aliases, ", "))
+	}
+	return w.Flush()
+}
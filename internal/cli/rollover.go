@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"strconv"
+	"time"
+
+	"five-pillars/internal/database"
+	"five-pillars/internal/notify"
+	"five-pillars/internal/services"
+)
+
+func (c *CLI) rolloverCmd(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "run":
+			result, err := c.services.Rollover.Run(time.Now())
+			if err != nil {
+				return err
+			}
+			if result.Empty() {
+				c.println("✅ Незакрытых задач нет")
+				return nil
+			}
+			c.println("%s", notify.PlainText(services.FormatRollover(c.services.Registry(), result)))
+			return nil
+		case "template":
+			if len(args) != 3 {
+				c.println("Использование: rollover template ID carry|miss|ask|auto")
+				return ErrUsage
+			}
+			id, err := strconv.Atoi(args[1])
+			if err != nil {
+				c.println("Использование: rollover template ID carry|miss|ask|auto")
+				return ErrUsage
+			}
+			policy, err := services.ParseRolloverPolicy(args[2])
+			if err != nil {
+				return err
+			}
+			if err := c.services.Rollover.SetTemplatePolicy(id, policy); err != nil {
+				return err
+			}
+			c.println("✅ Шаблон #%d: %s", id, policyName(policy))
+			return nil
+		default:
+			c.println("Использование: rollover [run | template ID carry|miss|ask|auto]")
+			return ErrUsage
+		}
+	}
+
+	c.println("Перенос незакрытых задач в %s МСК", c.config.Rollover.Time)
+	c.println("\nПо столпам (разовые / по шаблону):")
+	for _, pillar := range c.services.Registry().Pillars(false) {
+		c.println("  %s: %s / %s", pillar.Title(),
+			policyName(c.services.Rollover.Policy(database.DailyTask{Pillar: pillar.Key}, nil)),
+			policyName(c.services.Rollover.Policy(database.DailyTask{Pillar: pillar.Key, TemplateID: -1}, nil)))
+	}
+
+	templates, err := c.services.Task.GetTemplates(false)
+	if err != nil {
+		return err
+	}
+	header := false
+	for _, template := range templates {
+		if template.Rollover == "" {
+			continue
+		}
+		if !header {
+			c.println("\nШаблоны:")
+			header = true
+		}
+		c.println("  #%d %s: %s", template.ID, template.Description, policyName(services.RolloverPolicy(template.Rollover)))
+	}
+	return nil
+}
+
+func policyName(policy services.RolloverPolicy) string {
+	switch policy {
+	case services.RolloverCarry:
+		return "перенести на завтра"
+	case services.RolloverMiss:
+		return "отметить несделанной"
+	case services.RolloverAsk:
+		return "спросить"
+	default:
+		return "по столпу"
+	}
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"five-pillars/internal/services"
+)
+
+const tagUsage = "Использование: tag task|template <id> [#тег ...] | tag task|template <id> off"
+
+func (c *CLI) find(args []string) error {
+	fs := c.newFlagSet("find")
+	limit := fs.Int("limit", 0, "сколько задач и дней показать, по умолчанию 20")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+	if fs.NArg() == 0 {
+		c.println("Использование: find [--limit N] <слова> [#тег] [from:YYYY-MM-DD] [to:YYYY-MM-DD] [status:done,skipped]")
+		return ErrUsage
+	}
+
+	query, err := services.ParseSearchQuery(joinArgs(fs.Args()))
+	if err != nil {
+		return err
+	}
+	query.Limit = *limit
+
+	result, err := c.services.Search.Find(query)
+	if err != nil {
+		return err
+	}
+	if len(result.Tasks) == 0 && len(result.Feelings) == 0 {
+		c.println("🔍 Ничего не найдено")
+		return nil
+	}
+	fmt.Fprint(c.out, services.FormatSearchResult(c.services.Registry(), result))
+	return nil
+}
+
+func (c *CLI) tagCmd(args []string) error {
+	if len(args) < 2 || (args[0] != "task" && args[0] != "template") {
+		c.println(tagUsage)
+		return ErrUsage
+	}
+
+	id, err := strconv.Atoi(args[1])
+	if err != nil {
+		return fmt.Errorf("id должен быть числовой: %s", args[1])
+	}
+	tags := args[2:]
+	if len(tags) == 1 && strings.EqualFold(tags[0], "off") {
+		tags = nil
+	}
+
+	if args[0] == "template" {
+		if err := c.services.Task.SetTemplateTags(id, tags...); err != nil {
+			return err
+		}
+		c.println("✅ Теги шаблона #%d обновлены, их получат новые задачи", id)
+		return nil
+	}
+
+	task, err := c.services.Task.SetTags(id, tags...)
+	if err != nil {
+		return err
+	}
+	if task.Tags == "" {
+		c.println("✅ Теги задачи #%d убраны", task.ID)
+		return nil
+	}
+	c.println("✅ Задача #%d: %s %s", task.ID, task.Description, services.FormatTags(task.Tags))
+	return nil
+}
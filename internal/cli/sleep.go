@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"fmt"
+
+	"five-pillars/internal/services"
+)
+
+const sleepUsage = "Использование: sleep [--days N] | sleep add [--date D] 23:40-07:10 | sleep delete <дата>"
+
+func (c *CLI) sleep(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "add":
+			return c.sleepAdd(args[1:])
+		case "delete":
+			return c.sleepDelete(args[1:])
+		}
+	}
+
+	fs := c.newFlagSet("sleep")
+	days := fs.Int("days", 7, "за сколько дней")
+	if err := fs.Parse(args); err != nil || fs.NArg() > 0 || *days < 1 {
+		c.println(sleepUsage)
+		return ErrUsage
+	}
+
+	entries, stats, err := c.services.Sleep.Get(*days)
+	if err != nil {
+		return err
+	}
+	if stats == nil {
+		c.println("📭 За %d дн. сон не записан", *days)
+		return nil
+	}
+
+	c.println("😴 Сон за %d дн.", *days)
+	fmt.Fprint(c.out, services.FormatSleep(entries))
+	fmt.Fprint(c.out, services.FormatSleepStats(stats))
+	return nil
+}
+
+func (c *CLI) sleepAdd(args []string) error {
+	fs := c.newFlagSet("sleep add")
+	date := fs.String("date", services.Today(), "день пробуждения YYYY-MM-DD (UTC)")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+	if fs.NArg() != 1 {
+		c.println(sleepUsage)
+		return ErrUsage
+	}
+
+	entry, err := c.services.Sleep.Save(*date, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	c.println("😴 Сон за %s: %s → %s, %.1f ч", entry.Date, entry.Bedtime, entry.WakeTime, entry.Hours)
+	return nil
+}
+
+func (c *CLI) sleepDelete(args []string) error {
+	if len(args) != 1 {
+		c.println(sleepUsage)
+		return ErrUsage
+	}
+
+	if err := c.services.Sleep.Delete(args[0]); err != nil {
+		return err
+	}
+	c.println("🗑 Сон за %s удален из журнала", args[0])
+	return nil
+}
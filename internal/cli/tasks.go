@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"five-pillars/internal/database"
+	"five-pillars/internal/services"
+)
+
+func (c *CLI) tasks(args []string) error {
+	if len(args) == 0 {
+		c.println("Использование: tasks list [--date D] | tasks add [--date D] [--notes N] [--start HH:mm] [--lead 30,5] [--checklist пункт;пункт] [--metric 'км 5 sum'] [--tags work,family] <столп> <HH:mm> <описание> | tasks complete <id> | tasks value <id> <число>")
+		return ErrUsage
+	}
+
+	switch args[0] {
+	case "list":
+		return c.tasksList(args[1:])
+	case "add":
+		return c.tasksAdd(args[1:])
+	case "complete":
+		return c.tasksComplete(args[1:])
+	case "value":
+		return c.tasksValue(args[1:])
+	default:
+		return fmt.Errorf("неизвестная подкоманда tasks: %s", args[0])
+	}
+}
+
+func (c *CLI) tasksList(args []string) error {
+	fs := c.newFlagSet("tasks list")
+	date := fs.String("date", services.Today(), "дата YYYY-MM-DD")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+
+	tasks, err := c.services.Task.GetTasks(*date)
+	if err != nil {
+		return err
+	}
+
+	if len(tasks) == 0 {
+		c.println("📭 На %s задач нет", *date)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tВРЕМЯ UTC\tСТОЛП\tСТАТУС\tТЕГИ\tОПИСАНИЕ")
+	for _, task := range tasks {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n",
+			task.ID, task.TimeUTC, task.Pillar, taskStatus(task), services.FormatTags(task.Tags), task.Description)
+	}
+	return w.Flush()
+}
+
+func (c *CLI) tasksAdd(args []string) error {
+	fs := c.newFlagSet("tasks add")
+	date := fs.String("date", services.Today(), "дата YYYY-MM-DD")
+	notes := fs.String("notes", "Добавлено через CLI", "заметка к задаче")
+	start := fs.String("start", "", "время начала HH:mm UTC, если позже времени задачи")
+	lead := fs.String("lead", "", "напомнить заранее, минуты через запятую: 30,5")
+	checklist := fs.String("checklist", "", "пункты чек-листа через ;")
+	metricArgs := fs.String("metric", "", "измеримый результат: единица, цель и способ подсчета, например \"км 5 sum\"")
+	tags := fs.String("tags", "", "теги через запятую: work,family")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+
+	var metric services.Metric
+	if *metricArgs != "" {
+		var err error
+		if metric, err = services.ParseMetricArgs(strings.Fields(*metricArgs)); err != nil {
+			return err
+		}
+	}
+
+	rest := fs.Args()
+	if len(rest) < 3 {
+		c.println("Использование: tasks add [--date D] [--notes N] [--start HH:mm] [--lead 30,5] [--checklist пункт;пункт] [--metric 'км 5 sum'] [--tags work,family] <столп> <HH:mm> <описание>")
+		return ErrUsage
+	}
+
+	task, err := c.services.Task.AddTask(services.NewTaskInput{
+		Pillar:      rest[0],
+		TimeUTC:     rest[1],
+		Description: joinArgs(rest[2:]),
+		Date:        *date,
+		Notes:       *notes,
+		StartUTC:    *start,
+		LeadMinutes: *lead,
+		Checklist:   services.ParseChecklist(*checklist),
+
+		MetricUnit:   metric.Unit,
+		MetricTarget: metric.Target,
+		MetricAgg:    metric.Aggregation,
+		Tags:         []string{*tags},
+	})
+	if err != nil {
+		return err
+	}
+
+	c.println("✅ Добавлена задача #%d: %s %s в %s UTC на %s",
+		task.ID, c.services.Registry().PillarName(task.Pillar), task.Description, task.TimeUTC, task.Date)
+	return nil
+}
+
+func (c *CLI) tasksComplete(args []string) error {
+	if len(args) != 1 {
+		c.println("Использование: tasks complete <id>")
+		return ErrUsage
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("id должен быть числовой: %s", args[0])
+	}
+
+	task, err := c.services.Task.CompleteTask(id)
+	if err != nil {
+		return err
+	}
+
+	c.println("✅ Задача #%d выполнена: %s", task.ID, task.Description)
+	return nil
+}
+
+func taskStatus(task database.DailyTask) string {
+	status := services.StatusNames[services.DisplayStatus(task, time.Now())]
+	if done, total := task.ChecklistProgress(); total > 0 {
+		status += fmt.Sprintf(" %d/%d", done, total)
+	}
+	if value := services.FormatTaskValue(task); value != "" {
+		status += " " + value
+	}
+	return status
+}
+
+func (c *CLI) templates(args []string) error {
+	templates, err := c.services.Task.GetTemplates(false)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tВРЕМЯ UTC\tСТОЛП\tДНИ\tАКТИВЕН\tЧЕК-ЛИСТ\tМЕТРИКА\tТЕГИ\tОПИСАНИЕ")
+	for _, template := range templates {
+		weekdays := template.Weekdays
+		if weekdays == "" {
+			weekdays = "каждый день"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%v\t%d\t%s\t%s\t%s\n",
+			template.ID, template.TimeUTC, template.Pillar, weekdays, template.Active, len(template.ChecklistItems()),
+			metricLabel(template), services.FormatTags(template.Tags), template.Description)
+	}
+	return w.Flush()
+}
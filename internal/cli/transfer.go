@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"five-pillars/internal/export"
+	"five-pillars/internal/ical"
+	"five-pillars/internal/importer"
+	"five-pillars/internal/services"
+	"five-pillars/internal/utils"
+)
+
+func (c *CLI) export(args []string) error {
+	fs := c.newFlagSet("export")
+	from := fs.String("from", "", "начало периода YYYY-MM-DD")
+	to := fs.String("to", "", "конец периода YYYY-MM-DD")
+	formatName := fs.String("format", "json", "формат: json, csv, md")
+	dataset := fs.String("dataset", export.DatasetTasks, "набор данных для csv: "+strings.Join(export.Datasets, ", "))
+	out := fs.String("out", "", "файл для выгрузки, по умолчанию stdout")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+
+	format, err := export.ParseFormat(*formatName)
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := c.services.Transfer.Export(*from, *to)
+	if err != nil {
+		return err
+	}
+
+	file, err := export.Render(c.services.Registry(), snapshot, format, *dataset)
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		_, err := c.out.Write(file.Data)
+		return err
+	}
+
+	if err := os.WriteFile(*out, file.Data, 0o644); err != nil {
+		return fmt.Errorf("ошибка записи файла: %v", err)
+	}
+	c.println("✅ Выгружено задач: %d, ощущений: %d → %s", len(snapshot.Tasks), len(snapshot.Feelings), *out)
+	return nil
+}
+
+func (c *CLI) importData(args []string) error {
+	fs := c.newFlagSet("import")
+	dryRun := fs.Bool("dry-run", false, "только проверить файл, ничего не записывая")
+	now := time.Now().UTC()
+	from := fs.String("from", now.Format("2006-01-02"), "для .ics: начало периода повторений")
+	to := fs.String("to", now.AddDate(0, 0, c.config.Calendar.ImportDays).Format("2006-01-02"), "для .ics: конец периода повторений")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+	if fs.NArg() != 1 {
+		c.println("Использование: import [--dry-run] [--from D --to D] <файл.csv|файл.json|файл.ics>")
+		return ErrUsage
+	}
+
+	name := fs.Arg(0)
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return fmt.Errorf("ошибка чтения файла: %v", err)
+	}
+
+	var batch *services.ImportBatch
+	if strings.EqualFold(filepath.Ext(name), ".ics") {
+		rules, err := ical.ParseRules(c.services.Registry(), c.config.Calendar.ImportRules, c.config.Calendar.ImportPillar)
+		if err != nil {
+			return err
+		}
+		batch, err = ical.Batch(data, ical.ImportOptions{Rules: rules, From: *from, To: *to, Location: utils.MoscowLocation()})
+		if err != nil {
+			return err
+		}
+	} else {
+		batch, err = importer.Parse(c.services.Registry(), name, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	report, err := c.services.Transfer.Import(batch, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	c.println("%s", importer.Summary(report, 0))
+	return nil
+}
@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"fmt"
+
+	"five-pillars/internal/api"
+	"five-pillars/internal/database"
+	"five-pillars/internal/tui"
+)
+
+func (c *CLI) tui(args []string) error {
+	fs := c.newFlagSet("tui")
+	apiURL := fs.String("api", "", "адрес HTTP API, например http://localhost:8080; без него используется локальная БД")
+	token := fs.String("token", c.config.Server.Token, "токен HTTP API, по умолчанию API_TOKEN")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+
+	var backend tui.Backend
+	var registry *database.Registry
+	if *apiURL != "" {
+		client := api.NewClient(*apiURL, *token)
+		pillars, err := client.Pillars()
+		if err != nil {
+			return fmt.Errorf("ошибка получения столпов: %v", err)
+		}
+		metrics, err := client.WellbeingMetrics()
+		if err != nil {
+			return fmt.Errorf("ошибка получения показателей самочувствия: %v", err)
+		}
+		// Справочники сервера, своей БД у клиента нет
+		registry = database.NewRegistry()
+		registry.UsePillars(pillars)
+		registry.UseWellbeingMetrics(metrics)
+		backend = client
+	} else {
+		if err := c.openDB(); err != nil {
+			return err
+		}
+		registry = c.services.Registry()
+		backend = tui.NewLocalBackend(c.services)
+	}
+
+	return tui.New(backend, registry, c.in, c.out).Run()
+}
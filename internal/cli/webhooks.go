@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"time"
+
+	"five-pillars/internal/database"
+)
+
+func (c *CLI) webhooksCmd(args []string) error {
+	repo := database.NewRepository(c.db)
+
+	if len(args) > 0 {
+		switch args[0] {
+		case "deliver":
+			if c.webhooks == nil {
+				c.println("Вебхуки не настроены (WEBHOOKS)")
+				return nil
+			}
+			c.webhooks.DeliverDue()
+		case "retry":
+			count, err := repo.RetryFailedWebhooks(time.Now())
+			if err != nil {
+				return err
+			}
+			c.println("🔁 Возвращено в очередь: %d", count)
+		default:
+			c.println("Использование: webhooks [deliver|retry]")
+			return ErrUsage
+		}
+	}
+
+	counts, err := repo.CountWebhooks()
+	if err != nil {
+		return err
+	}
+	c.println("Вебхуки: в очереди %d, доставлено %d, не доставлено %d",
+		counts[database.WebhookPending], counts[database.WebhookDelivered], counts[database.WebhookFailed])
+
+	failed, err := repo.GetWebhooksByStatus(database.WebhookFailed, 10)
+	if err != nil {
+		return err
+	}
+	for _, delivery := range failed {
+		c.println("  ❌ #%d %s → %s: %s (попыток: %d)", delivery.ID, delivery.Event, delivery.URL, delivery.LastError, delivery.Attempts)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"five-pillars/internal/database"
+	"five-pillars/internal/services"
+)
+
+const wellbeingUsage = "Использование: wellbeing [--all] | wellbeing add [--unit ед] [--min N] [--max N] [--aliases a,b] <ключ> scale|number|bool|text <эмодзи> <название> | " +
+	"wellbeing set <ключ> name|emoji|unit|aliases|min|max|position <значение> | wellbeing archive|restore <ключ>"
+
+func (c *CLI) wellbeingCmd(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return c.wellbeingList(args)
+	}
+
+	switch args[0] {
+	case "add":
+		return c.wellbeingAdd(args[1:])
+	case "set":
+		if len(args) < 4 {
+			c.println(wellbeingUsage)
+			return ErrUsage
+		}
+		patch, err := services.ParseWellbeingPatch(args[2], joinArgs(args[3:]))
+		if err != nil {
+			return err
+		}
+		metric, err := c.services.Wellbeing.Update(args[1], patch)
+		if err != nil {
+			return err
+		}
+		c.println("✅ Показатель изменен")
+		return c.printWellbeing([]database.WellbeingMetric{*metric})
+	case "archive", "restore":
+		if len(args) != 2 {
+			c.println(wellbeingUsage)
+			return ErrUsage
+		}
+		archived := args[0] == "archive"
+		metric, err := c.services.Wellbeing.Update(args[1], services.WellbeingPatch{Archived: &archived})
+		if err != nil {
+			return err
+		}
+		if archived {
+			c.println("📦 Показатель %s в архиве", metric.Title())
+		} else {
+			c.println("✅ Показатель %s снова спрашивается", metric.Title())
+		}
+		return nil
+	default:
+		c.println(wellbeingUsage)
+		return ErrUsage
+	}
+}
+
+func (c *CLI) wellbeingList(args []string) error {
+	fs := c.newFlagSet("wellbeing")
+	all := fs.Bool("all", false, "вместе с архивными")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+
+	return c.printWellbeing(c.services.Wellbeing.List(*all))
+}
+
+func (c *CLI) wellbeingAdd(args []string) error {
+	fs := c.newFlagSet("wellbeing add")
+	unit := fs.String("unit", "", "единица измерения")
+	minValue := fs.String("min", "", "минимум")
+	maxValue := fs.String("max", "", "максимум")
+	aliases := fs.String("aliases", "", "синонимы через запятую")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+
+	rest := fs.Args()
+	if len(rest) < 4 {
+		c.println(wellbeingUsage)
+		return ErrUsage
+	}
+
+	metric := database.WellbeingMetric{
+		Key:     rest[0],
+		Type:    database.WellbeingType(rest[1]),
+		Emoji:   rest[2],
+		Name:    joinArgs(rest[3:]),
+		Unit:    *unit,
+		Aliases: strings.Split(*aliases, ","),
+	}
+	// Границы разбираются так же, как в wellbeing set
+	for field, value := range map[string]string{"min": *minValue, "max": *maxValue} {
+		if value == "" {
+			continue
+		}
+		patch, err := services.ParseWellbeingPatch(field, value)
+		if err != nil {
+			return err
+		}
+		if patch.Min != nil {
+			metric.Min = *patch.Min
+		}
+		if patch.Max != nil {
+			metric.Max = *patch.Max
+		}
+	}
+
+	added, err := c.services.Wellbeing.Add(metric)
+	if err != nil {
+		return err
+	}
+	c.println("✅ Добавлен показатель")
+	return c.printWellbeing([]database.WellbeingMetric{*added})
+}
+
+func (c *CLI) printWellbeing(metrics []database.WellbeingMetric) error {
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "№\tКЛЮЧ\tПОКАЗАТЕЛЬ\tТИП\tЗНАЧЕНИЯ\tАРХИВ\tСИНОНИМЫ")
+	for _, metric := range metrics {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%v\t%s\n",
+			metric.Position, metric.Key, metric.Title(), metric.Type, services.FeelingsRange(metric),
+			metric.Archived, strings.Join(metric.Aliases, ", "))
+	}
+	return w.Flush()
+}
@@ -1,52 +1,169 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 )
 
 type Config struct {
 	Telegram struct {
-		Token  string `yaml:"token"`
-		ChatID int64  `yaml:"chat_id"`
+		Token       string `yaml:"token"`
+		ChatID      int64  `yaml:"chat_id"`
+		APIEndpoint string `yaml:"api_endpoint"`
 	} `yaml:"telegram"`
 	Server struct {
 		Port string `yaml:"port"`
+		// Token секрет HTTP API: Authorization: Bearer <token>. Без него API
+		// доступно только с локальной машины.
+		Token string `yaml:"token"`
 	} `yaml:"server"`
 	Database struct {
 		Path string `yaml:"path"`
 	} `yaml:"database"`
+	Backup struct {
+		Dir         string `yaml:"dir"`
+		KeepDaily   int    `yaml:"keep_daily"`
+		KeepWeekly  int    `yaml:"keep_weekly"`
+		KeepMonthly int    `yaml:"keep_monthly"`
+	} `yaml:"backup"`
+	Calendar struct {
+		Token      string `yaml:"token"`
+		PastDays   int    `yaml:"past_days"`
+		FutureDays int    `yaml:"future_days"`
+		// ImportRules ключевые слова для выбора столпа: "body=зал,бег;focus=курс"
+		ImportRules  string `yaml:"import_rules"`
+		ImportPillar string `yaml:"import_pillar"`
+		ImportDays   int    `yaml:"import_days"`
+	} `yaml:"calendar"`
+	Webhooks struct {
+		// Endpoints получатели: "url|события|секрет;url2", см. webhook.ParseEndpoints
+		Endpoints string `yaml:"endpoints"`
+		Secret    string `yaml:"secret"`
+	} `yaml:"webhooks"`
+	Notify struct {
+		// Routes каналы по типам уведомлений: "task_reminder=telegram;weekly_report=email"
+		Routes string `yaml:"routes"`
+		// QuietHours тихие часы по МСК: "23:00-08:00", пустая строка - без тишины
+		QuietHours string `yaml:"quiet_hours"`
+		SMTP       struct {
+			Addr     string `yaml:"addr"`
+			From     string `yaml:"from"`
+			To       string `yaml:"to"`
+			Username string `yaml:"username"`
+			Password string `yaml:"password"`
+		} `yaml:"smtp"`
+		Push struct {
+			URL   string `yaml:"url"`
+			Token string `yaml:"token"`
+			Style string `yaml:"style"`
+		} `yaml:"push"`
+	} `yaml:"notify"`
+	Rituals struct {
+		// Morning, Evening время брифинга и вечернего планирования по МСК, "off" - выключено
+		Morning string `yaml:"morning"`
+		Evening string `yaml:"evening"`
+		// Digest сводка по задачам каждые 2 часа
+		Digest bool `yaml:"digest"`
+	} `yaml:"rituals"`
+	Rollover struct {
+		// Time время переноса незакрытых задач по МСК, "off" - выключено
+		Time string `yaml:"time"`
+		// Policy правила по столпам: "тело=miss,фокус=ask", остальные - по умолчанию
+		Policy string `yaml:"policy"`
+	} `yaml:"rollover"`
 }
 
+// Load читает конфигурацию из окружения. Настройки Telegram здесь не
+// обязательны: консольные команды работают только с БД, а для запуска
+// бота их проверяет RequireTelegram.
 func Load() (*Config, error) {
+	cfg := &Config{}
+	cfg.Telegram.Token = getEnv("TG_TOKEN", "")
+	cfg.Telegram.APIEndpoint = getEnv("TG_API_ENDPOINT", "")
+	cfg.Server.Port = getEnv("PORT", "8080")
+	cfg.Server.Token = getEnv("API_TOKEN", "")
+	cfg.Database.Path = getEnv("DB_PATH", "/data/five-pillars.db")
 
-	token := getEnv("TG_TOKEN", "")
-	if token == "" {
-		log.Fatal("❌ TG_TOKEN не установлен. Установите переменную окружения или создайте .env файл")
+	// Копии по умолчанию лежат рядом с БД, на том же томе
+	cfg.Backup.Dir = getEnv("BACKUP_DIR", filepath.Join(filepath.Dir(cfg.Database.Path), "backups"))
+
+	var err error
+	if cfg.Backup.KeepDaily, err = getEnvInt("BACKUP_KEEP_DAILY", 7); err != nil {
+		return nil, err
+	}
+	if cfg.Backup.KeepWeekly, err = getEnvInt("BACKUP_KEEP_WEEKLY", 4); err != nil {
+		return nil, err
+	}
+	if cfg.Backup.KeepMonthly, err = getEnvInt("BACKUP_KEEP_MONTHLY", 12); err != nil {
+		return nil, err
 	}
 
-	chatIDStr := getEnv("TG_CHAT_ID", "")
-	if chatIDStr == "" {
-		log.Fatal("❌ TG_CHAT_ID не установлен")
+	// Без токена лента календаря не публикуется
+	cfg.Calendar.Token = getEnv("ICAL_TOKEN", "")
+	if cfg.Calendar.PastDays, err = getEnvInt("ICAL_PAST_DAYS", 14); err != nil {
+		return nil, err
+	}
+	if cfg.Calendar.FutureDays, err = getEnvInt("ICAL_FUTURE_DAYS", 14); err != nil {
+		return nil, err
 	}
 
-	chatID, err := strconv.ParseInt(chatIDStr, 10, 64)
-	if err != nil {
-		log.Fatalf("❌ Неверный TG_CHAT_ID: %v", err)
+	cfg.Calendar.ImportRules = getEnv("ICAL_IMPORT_RULES", "")
+	cfg.Calendar.ImportPillar = getEnv("ICAL_IMPORT_PILLAR", "")
+	if cfg.Calendar.ImportDays, err = getEnvInt("ICAL_IMPORT_DAYS", 90); err != nil {
+		return nil, err
 	}
 
-	cfg := &Config{}
-	cfg.Telegram.Token = token
-	cfg.Telegram.ChatID = chatID
-	cfg.Server.Port = getEnv("PORT", "8080")
-	cfg.Database.Path = getEnv("DB_PATH", getEnv("DB_PATH", "/data/five-pillars.db"))
+	cfg.Webhooks.Endpoints = getEnv("WEBHOOKS", "")
+	cfg.Webhooks.Secret = getEnv("WEBHOOK_SECRET", "")
+
+	cfg.Notify.Routes = getEnv("NOTIFY_ROUTES", "")
+	cfg.Notify.QuietHours = getEnv("QUIET_HOURS", "")
+
+	cfg.Rituals.Morning = getEnv("MORNING_BRIEFING", "08:00")
+	cfg.Rituals.Evening = getEnv("EVENING_PLANNING", "21:00")
+	if cfg.Rituals.Digest, err = getEnvBool("TASK_DIGEST", true); err != nil {
+		return nil, err
+	}
+	cfg.Rollover.Time = getEnv("ROLLOVER_TIME", "23:50")
+	cfg.Rollover.Policy = getEnv("ROLLOVER_POLICY", "")
+	cfg.Notify.SMTP.Addr = getEnv("SMTP_ADDR", "")
+	cfg.Notify.SMTP.From = getEnv("SMTP_FROM", "")
+	cfg.Notify.SMTP.To = getEnv("SMTP_TO", "")
+	cfg.Notify.SMTP.Username = getEnv("SMTP_USERNAME", "")
+	cfg.Notify.SMTP.Password = getEnv("SMTP_PASSWORD", "")
+	cfg.Notify.Push.URL = getEnv("PUSH_URL", "")
+	cfg.Notify.Push.Token = getEnv("PUSH_TOKEN", "")
+	cfg.Notify.Push.Style = getEnv("PUSH_STYLE", "ntfy")
+
+	if chatIDStr := getEnv("TG_CHAT_ID", ""); chatIDStr != "" {
+		chatID, err := strconv.ParseInt(chatIDStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("неверный TG_CHAT_ID: %v", err)
+		}
+		cfg.Telegram.ChatID = chatID
+	}
 
 	log.Printf("✅ Конфигурация загружена: порт=%s, БД=%s", cfg.Server.Port, cfg.Database.Path)
 
 	return cfg, nil
 }
 
+// RequireTelegram проверяет, что заданы настройки для запуска бота
+func (c *Config) RequireTelegram() error {
+	if c.Telegram.Token == "" {
+		return errors.New("TG_TOKEN не установлен. Установите переменную окружения или создайте .env файл")
+	}
+	if c.Telegram.ChatID == 0 {
+		return errors.New("TG_CHAT_ID не установлен")
+	}
+	return nil
+}
+
 func getEnv(key, defaultValue string) string {
 	value := os.Getenv(key)
 	if value == "" {
@@ -54,3 +171,29 @@ func getEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+func getEnvInt(key string, defaultValue int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("неверный %s: %q", key, value)
+	}
+	return n, nil
+}
+
+func getEnvBool(key string, defaultValue bool) (bool, error) {
+	switch strings.ToLower(os.Getenv(key)) {
+	case "":
+		return defaultValue, nil
+	case "1", "true", "on", "yes":
+		return true, nil
+	case "0", "false", "off", "no":
+		return false, nil
+	default:
+		return false, fmt.Errorf("неверный %s: %q, ожидается on или off", key, os.Getenv(key))
+	}
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+)
+
+// requiredTables таблицы, без которых файл не считается базой трекера
+var requiredTables = []string{"tasks", "feelings"}
+
+// BackupTo сохраняет согласованную копию БД в новый файл через VACUUM INTO.
+// Работает без остановки приложения, файл назначения не должен существовать.
+func (d *Database) BackupTo(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("файл копии уже существует: %s", path)
+	}
+
+	if _, err := d.db.Exec(`VACUUM INTO ?`, path); err != nil {
+		return fmt.Errorf("ошибка создания копии БД: %v", err)
+	}
+	return nil
+}
+
+// CheckIntegrity проверяет файл БД без изменения: PRAGMA integrity_check
+// и наличие основных таблиц трекера
+func CheckIntegrity(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("файл БД недоступен: %v", err)
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("ошибка открытия БД: %v", err)
+	}
+	defer db.Close()
+
+	var result string
+	if err := db.QueryRow(`PRAGMA integrity_check`).Scan(&result); err != nil {
+		return fmt.Errorf("ошибка проверки целостности: %v", err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("БД повреждена: %s", result)
+	}
+
+	for _, table := range requiredTables {
+		var count int
+		err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, table).Scan(&count)
+		if err != nil {
+			return fmt.Errorf("ошибка проверки схемы: %v", err)
+		}
+		if count == 0 {
+			return fmt.Errorf("в файле нет таблицы %s, это не база трекера", table)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,204 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// ChecklistItem пункт чек-листа задачи
+type ChecklistItem struct {
+	ID       int    `json:"id"`
+	TaskID   int    `json:"task_id"`
+	Position int    `json:"position"`
+	Text     string `json:"text"`
+	Done     bool   `json:"done"`
+}
+
+// ChecklistProgress сколько пунктов чек-листа отмечено и сколько всего
+func (t DailyTask) ChecklistProgress() (done, total int) {
+	for _, item := range t.Checklist {
+		if item.Done {
+			done++
+		}
+	}
+	return done, len(t.Checklist)
+}
+
+// ChecklistItems пункты чек-листа шаблона, по одному на строку
+func (t TaskTemplate) ChecklistItems() []string {
+	var items []string
+	for _, line := range strings.Split(t.Checklist, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			items = append(items, line)
+		}
+	}
+	return items
+}
+
+// migrateTemplateChecklist добавляет шаблонам чек-лист и заполняет его
+// для шаблонов по умолчанию, у которых шаги записаны в заметках
+func (d *Database) migrateTemplateChecklist() error {
+	columnExists, err := d.hasColumn("task_templates", "checklist")
+	if err != nil || columnExists {
+		return err
+	}
+
+	if err := d.migrateAddColumn("task_templates", "checklist", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+
+	var filled int64
+	for _, template := range DefaultTemplates {
+		if template.Checklist == "" {
+			continue
+		}
+		result, err := d.db.Exec(`
+			UPDATE task_templates SET checklist = ?
+			WHERE pillar = ? AND description = ? AND notes = ?
+		`, template.Checklist, template.Pillar, template.Description, template.Notes)
+		if err != nil {
+			return fmt.Errorf("ошибка заполнения чек-листов шаблонов: %v", err)
+		}
+		if updated, err := result.RowsAffected(); err == nil {
+			filled += updated
+		}
+	}
+
+	if filled > 0 {
+		log.Printf("✅ Чек-листы добавлены шаблонам по умолчанию: %d", filled)
+	}
+	return nil
+}
+
+// GetChecklist пункты чек-листа задачи по порядку
+func (r *Repository) GetChecklist(taskID int) ([]ChecklistItem, error) {
+	checklists, err := r.GetChecklists([]int{taskID})
+	if err != nil {
+		return nil, err
+	}
+	return checklists[taskID], nil
+}
+
+// GetChecklists чек-листы нескольких задач: task_id -> пункты
+func (r *Repository) GetChecklists(taskIDs []int) (map[int][]ChecklistItem, error) {
+	checklists := make(map[int][]ChecklistItem)
+	if len(taskIDs) == 0 {
+		return checklists, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(taskIDs)), ",")
+	args := make([]interface{}, len(taskIDs))
+	for i, id := range taskIDs {
+		args[i] = id
+	}
+
+	rows, err := r.Db.db.Query(`
+		SELECT id, task_id, position, text, done
+		FROM checklist_items
+		WHERE task_id IN (`+placeholders+`)
+		ORDER BY task_id, position, id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item ChecklistItem
+		if err := rows.Scan(&item.ID, &item.TaskID, &item.Position, &item.Text, &item.Done); err != nil {
+			return nil, err
+		}
+		checklists[item.TaskID] = append(checklists[item.TaskID], item)
+	}
+	return checklists, rows.Err()
+}
+
+// attachChecklists загружает чек-листы задач одним запросом
+func (r *Repository) attachChecklists(tasks []DailyTask) error {
+	ids := make([]int, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+
+	checklists, err := r.GetChecklists(ids)
+	if err != nil {
+		return err
+	}
+	for i := range tasks {
+		tasks[i].Checklist = checklists[tasks[i].ID]
+	}
+	return nil
+}
+
+// AddChecklistItems добавляет пункты в конец чек-листа задачи
+func (r *Repository) AddChecklistItems(taskID int, items []ChecklistItem) error {
+	tx, err := r.Db.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := addChecklistItems(tx, taskID, items); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func addChecklistItems(tx *sql.Tx, taskID int, items []ChecklistItem) error {
+	var position int
+	err := tx.QueryRow(`SELECT COALESCE(MAX(position), 0) FROM checklist_items WHERE task_id = ?`, taskID).Scan(&position)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		position++
+		_, err := tx.Exec(`
+			INSERT INTO checklist_items (task_id, position, text, done) VALUES (?, ?, ?, ?)
+		`, taskID, position, item.Text, item.Done)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetChecklistItem пункт чек-листа по ID, sql.ErrNoRows если его нет
+func (r *Repository) GetChecklistItem(itemID int) (*ChecklistItem, error) {
+	var item ChecklistItem
+	err := r.Db.db.QueryRow(`
+		SELECT id, task_id, position, text, done FROM checklist_items WHERE id = ?
+	`, itemID).Scan(&item.ID, &item.TaskID, &item.Position, &item.Text, &item.Done)
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// SetChecklistItemDone отмечает пункт чек-листа
+func (r *Repository) SetChecklistItemDone(itemID int, done bool) error {
+	_, err := r.Db.db.Exec(`UPDATE checklist_items SET done = ? WHERE id = ?`, done, itemID)
+	return err
+}
+
+// DeleteChecklistItem удаляет пункт чек-листа
+func (r *Repository) DeleteChecklistItem(itemID int) (bool, error) {
+	result, err := r.Db.db.Exec(`DELETE FROM checklist_items WHERE id = ?`, itemID)
+	if err != nil {
+		return false, err
+	}
+	deleted, err := result.RowsAffected()
+	return deleted > 0, err
+}
+
+// UpdateTemplateChecklist меняет чек-лист шаблона, пункты по одному на строку
+func (r *Repository) UpdateTemplateChecklist(templateID int, checklist string) (bool, error) {
+	result, err := r.Db.db.Exec(`UPDATE task_templates SET checklist = ? WHERE id = ?`, checklist, templateID)
+	if err != nil {
+		return false, err
+	}
+	updated, err := result.RowsAffected()
+	return updated > 0, err
+}
@@ -4,12 +4,17 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
 type Database struct {
 	db *sql.DB
+	// fts поиск идет по индексу FTS5, см. initSearch
+	fts bool
+	// registry справочники этой БД в памяти
+	registry *Registry
 }
 
 func New(path string) (*Database, error) {
@@ -22,7 +27,7 @@ func New(path string) (*Database, error) {
 		return nil, fmt.Errorf("ошибка подключения к БД: %v", err)
 	}
 
-	d := &Database{db: db}
+	d := &Database{db: db, registry: NewRegistry()}
 	if err := d.init(); err != nil {
 		return nil, err
 	}
@@ -54,6 +59,130 @@ func (d *Database) init() error {
 			notes TEXT,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
 		)`,
+
+		`CREATE TABLE IF NOT EXISTS task_templates (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			pillar TEXT NOT NULL,
+			description TEXT NOT NULL,
+			time_utc TEXT NOT NULL,
+			notes TEXT NOT NULL DEFAULT '',
+			weekdays TEXT NOT NULL DEFAULT '',
+			active BOOLEAN DEFAULT 1,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS webhook_deliveries (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			url TEXT NOT NULL,
+			event TEXT NOT NULL,
+			payload TEXT NOT NULL,
+			status TEXT NOT NULL DEFAULT 'pending',
+			attempts INTEGER NOT NULL DEFAULT 0,
+			next_attempt_at DATETIME NOT NULL,
+			last_error TEXT NOT NULL DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			delivered_at DATETIME
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS outbox (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			chat_id INTEGER NOT NULL,
+			method TEXT NOT NULL,
+			text TEXT NOT NULL DEFAULT '',
+			message_id INTEGER NOT NULL DEFAULT 0,
+			reply_markup TEXT NOT NULL DEFAULT '',
+			file_name TEXT NOT NULL DEFAULT '',
+			file_data BLOB,
+			file_path TEXT NOT NULL DEFAULT '',
+			status TEXT NOT NULL DEFAULT 'pending',
+			attempts INTEGER NOT NULL DEFAULT 0,
+			next_attempt_at DATETIME NOT NULL,
+			last_error TEXT NOT NULL DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			sent_at DATETIME
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS settings (
+			key TEXT PRIMARY KEY,
+			value TEXT NOT NULL,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS checklist_items (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			task_id INTEGER NOT NULL,
+			position INTEGER NOT NULL DEFAULT 0,
+			text TEXT NOT NULL,
+			done BOOLEAN NOT NULL DEFAULT 0
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS goals (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			pillar TEXT NOT NULL,
+			description TEXT NOT NULL,
+			target REAL NOT NULL DEFAULT 0,
+			unit TEXT NOT NULL DEFAULT '',
+			start_date TEXT NOT NULL,
+			deadline TEXT NOT NULL,
+			archived BOOLEAN NOT NULL DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS pillars (
+			key TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			emoji TEXT NOT NULL,
+			color TEXT NOT NULL DEFAULT '',
+			aliases TEXT NOT NULL DEFAULT '',
+			position INTEGER NOT NULL DEFAULT 0,
+			archived BOOLEAN NOT NULL DEFAULT 0
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS journal_entries (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			date TEXT NOT NULL,
+			text TEXT NOT NULL DEFAULT '',
+			photo_id TEXT NOT NULL DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS wellbeing_metrics (
+			key TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			emoji TEXT NOT NULL,
+			type TEXT NOT NULL,
+			min_value REAL,
+			max_value REAL,
+			unit TEXT NOT NULL DEFAULT '',
+			aliases TEXT NOT NULL DEFAULT '',
+			position INTEGER NOT NULL DEFAULT 0,
+			archived BOOLEAN NOT NULL DEFAULT 0
+		)`,
+
+		// Значения дополнительных показателей самочувствия, number - для средних
+		`CREATE TABLE IF NOT EXISTS feelings_values (
+			date TEXT NOT NULL,
+			metric TEXT NOT NULL,
+			value TEXT NOT NULL,
+			number REAL,
+			PRIMARY KEY (date, metric)
+		)`,
+
+		// Журнал сна: date - день пробуждения по UTC, как у задач и ощущений;
+		// время отбоя и подъема по Москве
+		`CREATE TABLE IF NOT EXISTS sleep_log (
+			date TEXT PRIMARY KEY,
+			bedtime TEXT NOT NULL,
+			wake_time TEXT NOT NULL,
+			hours REAL NOT NULL
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS deferred_notifications (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			kind TEXT NOT NULL,
+			task_id INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
 	}
 
 	for _, query := range queries {
@@ -64,12 +193,57 @@ func (d *Database) init() error {
 
 	d.migrateAddSkippedColumn()
 
+	// Время начала и напоминания заранее: "30,5" - за 30 и за 5 минут
+	columns := []struct{ table, column, definition string }{
+		{"tasks", "start_utc", "TEXT NOT NULL DEFAULT ''"},
+		{"tasks", "lead_minutes", "TEXT NOT NULL DEFAULT ''"},
+		{"task_templates", "start_utc", "TEXT NOT NULL DEFAULT ''"},
+		{"task_templates", "lead_minutes", "TEXT NOT NULL DEFAULT ''"},
+		// Перенос незакрытых задач: шаблон задачи и задача, с которой перенесли
+		{"tasks", "carried_from", "INTEGER NOT NULL DEFAULT 0"},
+		{"task_templates", "rollover", "TEXT NOT NULL DEFAULT ''"},
+		// Привязка задач и шаблонов к долгосрочным целям
+		{"tasks", "goal_id", "INTEGER NOT NULL DEFAULT 0"},
+		{"task_templates", "goal_id", "INTEGER NOT NULL DEFAULT 0"},
+		// Теги через запятую без #: "work,family"
+		{"tasks", "tags", "TEXT NOT NULL DEFAULT ''"},
+		{"task_templates", "tags", "TEXT NOT NULL DEFAULT ''"},
+		// Файл на диске вместо содержимого в очереди: копия БД не копирует саму себя
+		{"outbox", "file_path", "TEXT NOT NULL DEFAULT ''"},
+		// Правки уже отправленных сообщений идут через ту же очередь
+		{"outbox", "message_id", "INTEGER NOT NULL DEFAULT 0"},
+	}
+	for _, c := range columns {
+		if err := d.migrateAddColumn(c.table, c.column, c.definition); err != nil {
+			return err
+		}
+	}
+	if err := d.migrateTaskTemplateID(); err != nil {
+		return err
+	}
+	if err := d.migrateTaskStatus(); err != nil {
+		return err
+	}
+	if err := d.migrateTemplateChecklist(); err != nil {
+		return err
+	}
+	if err := d.migrateMetrics(); err != nil {
+		return err
+	}
+
 	indexQueries := []string{
 		`CREATE INDEX IF NOT EXISTS idx_tasks_date ON tasks(date)`,
 		`CREATE INDEX IF NOT EXISTS idx_tasks_pillar ON tasks(pillar)`,
 		`CREATE INDEX IF NOT EXISTS idx_tasks_completed ON tasks(completed)`,
 		`CREATE INDEX IF NOT EXISTS idx_tasks_skipped ON tasks(skipped)`,
+		`CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks(status)`,
 		`CREATE INDEX IF NOT EXISTS idx_tasks_time ON tasks(time_utc)`,
+		`CREATE INDEX IF NOT EXISTS idx_tasks_carried_from ON tasks(carried_from)`,
+		`CREATE INDEX IF NOT EXISTS idx_tasks_goal ON tasks(goal_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_checklist_items_task ON checklist_items(task_id, position)`,
+		`CREATE INDEX IF NOT EXISTS idx_journal_entries_date ON journal_entries(date, id)`,
+		`CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(status, next_attempt_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_outbox_status ON outbox(status, id)`,
 	}
 
 	for _, query := range indexQueries {
@@ -78,7 +252,23 @@ func (d *Database) init() error {
 		}
 	}
 
-	return nil
+	if err := d.initSearch(); err != nil {
+		return err
+	}
+
+	if err := d.seedPillars(); err != nil {
+		return err
+	}
+	if err := d.loadPillars(); err != nil {
+		return err
+	}
+	if err := d.seedWellbeingMetrics(); err != nil {
+		return err
+	}
+	if err := d.loadWellbeingMetrics(); err != nil {
+		return err
+	}
+	return d.seedTemplates()
 }
 
 // migrateAddSkippedColumn добавляет поле skipped если его нет
@@ -105,6 +295,119 @@ func (d *Database) migrateAddSkippedColumn() {
 	}
 }
 
+// hasColumn проверяет, есть ли поле в таблице
+func (d *Database) hasColumn(table, column string) (bool, error) {
+	var columnExists bool
+	err := d.db.QueryRow(`
+		SELECT COUNT(*) > 0
+		FROM pragma_table_info(?)
+		WHERE name = ?
+	`, table, column).Scan(&columnExists)
+	if err != nil {
+		return false, fmt.Errorf("ошибка проверки поля %s.%s: %v", table, column, err)
+	}
+	return columnExists, nil
+}
+
+// migrateAddColumn добавляет поле в таблицу, если его еще нет
+func (d *Database) migrateAddColumn(table, column, definition string) error {
+	columnExists, err := d.hasColumn(table, column)
+	if err != nil {
+		return err
+	}
+	if columnExists {
+		return nil
+	}
+
+	if _, err := d.db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, definition)); err != nil {
+		return fmt.Errorf("ошибка добавления поля %s.%s: %v", table, column, err)
+	}
+	log.Printf("✅ Поле '%s' добавлено в таблицу %s", column, table)
+	return nil
+}
+
+// migrateTaskTemplateID добавляет задачам ссылку на шаблон. Задачи,
+// созданные до миграции, связываются с шаблоном по столпу и описанию.
+func (d *Database) migrateTaskTemplateID() error {
+	columnExists, err := d.hasColumn("tasks", "template_id")
+	if err != nil || columnExists {
+		return err
+	}
+
+	if err := d.migrateAddColumn("tasks", "template_id", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+
+	result, err := d.db.Exec(`
+		UPDATE tasks SET template_id = (
+			SELECT MIN(t.id) FROM task_templates t
+			WHERE t.pillar = tasks.pillar AND t.description = tasks.description
+		)
+		WHERE EXISTS (
+			SELECT 1 FROM task_templates t
+			WHERE t.pillar = tasks.pillar AND t.description = tasks.description
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("ошибка связывания задач с шаблонами: %v", err)
+	}
+	if linked, err := result.RowsAffected(); err == nil && linked > 0 {
+		log.Printf("✅ Задачи связаны с шаблонами: %d", linked)
+	}
+	return nil
+}
+
+// migrateTaskStatus заменяет пару флагов completed/skipped статусом. Флаги
+// остаются в таблице и дальше заполняются по статусу. Поле и статусы
+// появляются в одной транзакции: иначе после сбоя заполнения следующий запуск
+// увидел бы поле и оставил всю историю в статусе planned.
+func (d *Database) migrateTaskStatus() error {
+	columnExists, err := d.hasColumn("tasks", "status")
+	if err != nil || columnExists {
+		return err
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`ALTER TABLE tasks ADD COLUMN status TEXT NOT NULL DEFAULT 'planned'`); err != nil {
+		return fmt.Errorf("ошибка добавления поля tasks.status: %v", err)
+	}
+
+	// Незакрытые задачи прошлых дней считаются несделанными, сегодняшние
+	// с наступившим временем - ждущими выполнения
+	now := time.Now().UTC()
+	_, err = tx.Exec(`
+		UPDATE tasks SET status = CASE
+			WHEN completed = 1 THEN 'done'
+			WHEN skipped = 1 AND notes LIKE 'Пропущено: missed |%' THEN 'missed'
+			WHEN skipped = 1 THEN 'skipped'
+			WHEN EXISTS(SELECT 1 FROM tasks c WHERE c.carried_from = tasks.id) THEN 'rescheduled'
+			WHEN date < ? THEN 'missed'
+			WHEN date || ' ' || time_utc <= ? THEN 'due'
+			ELSE 'planned'
+		END
+	`, now.Format("2006-01-02"), now.Format("2006-01-02 15:04"))
+	if err != nil {
+		return fmt.Errorf("ошибка заполнения статусов задач: %v", err)
+	}
+
+	_, err = tx.Exec(`UPDATE tasks SET completed = (status = 'done'), skipped = (status IN ('skipped', 'missed'))`)
+	if err != nil {
+		return fmt.Errorf("ошибка заполнения статусов задач: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("ошибка заполнения статусов задач: %v", err)
+	}
+
+	log.Println("✅ Поле 'status' добавлено в таблицу tasks")
+	log.Println("✅ Статусы задач заполнены по полям completed и skipped")
+	return nil
+}
+
 func (d *Database) Close() error {
 	return d.db.Close()
 }
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrateTaskStatus(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.db")
+	db, err := New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// База до статусов: только флаги completed и skipped
+	for _, query := range []string{
+		`DROP INDEX idx_tasks_status`,
+		`ALTER TABLE tasks DROP COLUMN status`,
+		`INSERT INTO tasks (pillar, description, completed, skipped, time_utc, date, notes) VALUES
+			('body', 'сделано', 1, 0, '10:00', '2020-01-01', ''),
+			('body', 'пропущено', 0, 1, '10:00', '2020-01-01', 'Пропущено: нет сил'),
+			('body', 'забыто', 0, 0, '10:00', '2020-01-01', ''),
+			('body', 'потом', 0, 0, '10:00', '2999-01-01', '')`,
+	} {
+		if _, err := db.db.Exec(query); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+	}
+	db.Close()
+
+	db, err = New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	want := map[string]string{"сделано": "done", "пропущено": "skipped", "забыто": "missed", "потом": "planned"}
+	rows, err := db.db.Query(`SELECT description, status FROM tasks`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var description, status string
+		if err := rows.Scan(&description, &status); err != nil {
+			t.Fatal(err)
+		}
+		if want[description] != status {
+			t.Errorf("%s: статус %s, ожидался %s", description, status, want[description])
+		}
+		delete(want, description)
+	}
+	if len(want) > 0 {
+		t.Errorf("не найдены задачи: %v", want)
+	}
+}
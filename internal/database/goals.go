@@ -0,0 +1,184 @@
+package database
+
+import "time"
+
+// Goal долгосрочная цель столпа. Прогресс считается по привязанным задачам:
+// с единицей - сумма их результатов в этой единице, без единицы - число
+// выполненных задач.
+type Goal struct {
+	ID          int     `json:"id"`
+	Pillar      Pillar  `json:"pillar"`
+	Description string  `json:"description"`
+	Target      float64 `json:"target"`
+	Unit        string  `json:"unit,omitempty"`
+	// StartDate и Deadline период цели, задачи вне его не учитываются
+	StartDate string    `json:"start_date"`
+	Deadline  string    `json:"deadline"`
+	Archived  bool      `json:"archived"`
+	CreatedAt time.Time `json:"created_at"`
+	// Progress считается при чтении цели, в таблице не хранится
+	Progress GoalProgress `json:"progress"`
+	// Templates шаблоны, задачи которых привязываются к цели
+	Templates []int `json:"templates,omitempty"`
+}
+
+// GoalProgress прогресс цели по привязанным задачам
+type GoalProgress struct {
+	// Tasks задач за период цели, без отмененных и перенесенных
+	Tasks int `json:"tasks"`
+	Done  int `json:"done"`
+	// Value достигнуто: сумма результатов или число выполненных задач
+	Value float64 `json:"value"`
+}
+
+// Percent процент достижения цели, 0 если цель не задана
+func (g Goal) Percent() float64 {
+	if g.Target <= 0 {
+		return 0
+	}
+	return g.Progress.Value / g.Target * 100
+}
+
+// AddGoal добавляет цель и возвращает её ID
+func (r *Repository) AddGoal(goal Goal) (int, error) {
+	result, err := r.Db.db.Exec(`
+		INSERT INTO goals (pillar, description, target, unit, start_date, deadline)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, goal.Pillar, goal.Description, goal.Target, goal.Unit, goal.StartDate, goal.Deadline)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	return int(id), err
+}
+
+// GetGoals цели с прогрессом, archived - вместе с архивными
+func (r *Repository) GetGoals(archived bool) ([]Goal, error) {
+	rows, err := r.Db.db.Query(`
+		SELECT id, pillar, description, target, unit, start_date, deadline, archived, created_at
+		FROM goals
+		WHERE (? = 1 OR archived = 0)
+		ORDER BY archived, deadline, id
+	`, archived)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var goals []Goal
+	for rows.Next() {
+		var goal Goal
+		err := rows.Scan(&goal.ID, &goal.Pillar, &goal.Description, &goal.Target, &goal.Unit,
+			&goal.StartDate, &goal.Deadline, &goal.Archived, &goal.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		goals = append(goals, goal)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range goals {
+		if err := r.fillGoal(&goals[i]); err != nil {
+			return nil, err
+		}
+	}
+	return goals, nil
+}
+
+// GetGoal цель с прогрессом по ID, sql.ErrNoRows если её нет
+func (r *Repository) GetGoal(goalID int) (*Goal, error) {
+	var goal Goal
+	err := r.Db.db.QueryRow(`
+		SELECT id, pillar, description, target, unit, start_date, deadline, archived, created_at
+		FROM goals WHERE id = ?
+	`, goalID).Scan(&goal.ID, &goal.Pillar, &goal.Description, &goal.Target, &goal.Unit,
+		&goal.StartDate, &goal.Deadline, &goal.Archived, &goal.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.fillGoal(&goal); err != nil {
+		return nil, err
+	}
+	return &goal, nil
+}
+
+// fillGoal считает прогресс цели и находит привязанные шаблоны
+func (r *Repository) fillGoal(goal *Goal) error {
+	var done, value float64
+	err := r.Db.db.QueryRow(`
+		SELECT
+			COUNT(*),
+			COALESCE(SUM(CASE WHEN status = 'done' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN metric_unit = ? THEN value END), 0)
+		FROM tasks
+		WHERE goal_id = ? AND date BETWEEN ? AND ? AND `+isCounted+`
+	`, goal.Unit, goal.ID, goal.StartDate, goal.Deadline).Scan(&goal.Progress.Tasks, &done, &value)
+	if err != nil {
+		return err
+	}
+
+	goal.Progress.Done = int(done)
+	goal.Progress.Value = done
+	if goal.Unit != "" {
+		goal.Progress.Value = value
+	}
+
+	rows, err := r.Db.db.Query(`SELECT id FROM task_templates WHERE goal_id = ? ORDER BY id`, goal.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	goal.Templates = nil
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		goal.Templates = append(goal.Templates, id)
+	}
+	return rows.Err()
+}
+
+// ArchiveGoal убирает цель в архив или возвращает из него
+func (r *Repository) ArchiveGoal(goalID int, archived bool) (bool, error) {
+	result, err := r.Db.db.Exec(`UPDATE goals SET archived = ? WHERE id = ?`, archived, goalID)
+	if err != nil {
+		return false, err
+	}
+	updated, err := result.RowsAffected()
+	return updated > 0, err
+}
+
+// SetTaskGoal привязывает задачу к цели, 0 - отвязывает
+func (r *Repository) SetTaskGoal(taskID, goalID int) error {
+	_, err := r.Db.db.Exec(`UPDATE tasks SET goal_id = ? WHERE id = ?`, goalID, taskID)
+	return err
+}
+
+// SetTemplateGoal привязывает шаблон к цели (0 - отвязывает) вместе с уже
+// созданными по нему задачами начиная с даты from
+func (r *Repository) SetTemplateGoal(templateID, goalID int, from string) (bool, error) {
+	tx, err := r.Db.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE task_templates SET goal_id = ? WHERE id = ?`, goalID, templateID)
+	if err != nil {
+		return false, err
+	}
+	if updated, err := result.RowsAffected(); err != nil || updated == 0 {
+		return false, err
+	}
+
+	if _, err := tx.Exec(`UPDATE tasks SET goal_id = ? WHERE template_id = ? AND date >= ?`, goalID, templateID, from); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
@@ -0,0 +1,29 @@
+package database
+
+// ImportRows записывает загруженные задачи, ощущения и шаблоны одной
+// транзакцией: при ошибке в любой строке не сохраняется ничего, и повторная
+// загрузка того же файла не создает дубликатов
+func (r *Repository) ImportRows(tasks []DailyTask, feelings []DailyFeelings, templates []TaskTemplate) error {
+	tx, err := r.Db.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, task := range tasks {
+		if _, err := addTask(tx, task); err != nil {
+			return err
+		}
+	}
+	for _, day := range feelings {
+		if err := saveFeelings(tx, r.Db.registry, day); err != nil {
+			return err
+		}
+	}
+	for _, template := range templates {
+		if _, err := addTemplate(tx, template); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,43 @@
+package database
+
+import "testing"
+
+func TestImportRowsRollsBackOnError(t *testing.T) {
+	r := newTestRepository(t)
+
+	tasks := []DailyTask{
+		{Pillar: "body", Description: "Зарядка", TimeUTC: "07:00", Date: "2030-01-10"},
+		{Pillar: "body", Description: "Прогулка", TimeUTC: "18:00", Date: "2030-01-10",
+			Checklist: []ChecklistItem{{Text: "парк"}}},
+	}
+	// Энергия вне CHECK(1..10): строка падает уже после записи задач
+	feelings := []DailyFeelings{{Date: "2030-01-10", EnergyLevel: 11}}
+	templates := []TaskTemplate{{Pillar: "body", Description: "Зарядка", TimeUTC: "07:00", Active: true}}
+
+	if err := r.ImportRows(tasks, feelings, templates); err == nil {
+		t.Fatal("ImportRows с неверной строкой без ошибки")
+	}
+
+	saved, err := r.GetTasksByDate("2030-01-10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 0 {
+		t.Errorf("после ошибки остались задачи: %+v", saved)
+	}
+
+	// Исправленная загрузка записывает все строки один раз
+	feelings[0].EnergyLevel = 7
+	if err := r.ImportRows(tasks, feelings, templates); err != nil {
+		t.Fatal(err)
+	}
+	if saved, err = r.GetTasksByDate("2030-01-10"); err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != len(tasks) {
+		t.Errorf("после загрузки задач: %d, ожидалось %d", len(saved), len(tasks))
+	}
+	if exists, err := r.FeelingsExist("2030-01-10"); err != nil || !exists {
+		t.Errorf("ощущения не записаны: %v", err)
+	}
+}
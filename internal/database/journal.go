@@ -0,0 +1,109 @@
+package database
+
+import (
+	"fmt"
+	"time"
+)
+
+// JournalEntry запись дневника за день: текст или фото с подписью
+type JournalEntry struct {
+	ID   int    `json:"id"`
+	Date string `json:"date"`
+	Text string `json:"text,omitempty"`
+	// PhotoID file_id фото в Telegram, пусто - текстовая запись
+	PhotoID   string    `json:"photo_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// journalNotes текст дневника за день (date - первый параметр запроса):
+// записи по порядку через пустую строку. Он же хранится в заметках ощущений,
+// чтобы дневник попадал в поиск и выгрузки ощущений.
+const journalNotes = `(
+	SELECT COALESCE(GROUP_CONCAT(text, char(10) || char(10)), '')
+	FROM (SELECT text FROM journal_entries WHERE date = ? AND text != '' ORDER BY id)
+)`
+
+// AddJournalEntry добавляет запись дневника и обновляет заметки ощущений за день
+func (r *Repository) AddJournalEntry(entry JournalEntry) (int, error) {
+	tx, err := r.Db.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`INSERT INTO journal_entries (date, text, photo_id) VALUES (?, ?, ?)`,
+		entry.Date, entry.Text, entry.PhotoID)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка добавления записи дневника: %v", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(`UPDATE feelings SET notes = `+journalNotes+` WHERE date = ?`, entry.Date, entry.Date); err != nil {
+		return 0, fmt.Errorf("ошибка обновления заметок ощущений: %v", err)
+	}
+	return int(id), tx.Commit()
+}
+
+// DeleteJournalEntry удаляет запись дневника. Возвращает дату записи,
+// пустая дата - записи не было.
+func (r *Repository) DeleteJournalEntry(id int) (string, error) {
+	entry, err := r.GetJournalEntry(id)
+	if err != nil || entry == nil {
+		return "", err
+	}
+
+	tx, err := r.Db.db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM journal_entries WHERE id = ?`, id); err != nil {
+		return "", fmt.Errorf("ошибка удаления записи дневника: %v", err)
+	}
+	if _, err := tx.Exec(`UPDATE feelings SET notes = `+journalNotes+` WHERE date = ?`, entry.Date, entry.Date); err != nil {
+		return "", fmt.Errorf("ошибка обновления заметок ощущений: %v", err)
+	}
+	return entry.Date, tx.Commit()
+}
+
+// GetJournalEntry запись дневника по ID, nil - записи нет
+func (r *Repository) GetJournalEntry(id int) (*JournalEntry, error) {
+	entries, err := r.queryJournal(`WHERE id = ?`, id)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	return &entries[0], nil
+}
+
+// GetJournalBetween записи дневника за период включительно по порядку,
+// пустые границы не ограничивают выборку
+func (r *Repository) GetJournalBetween(from, to string) ([]JournalEntry, error) {
+	return r.queryJournal(`WHERE (? = '' OR date >= ?) AND (? = '' OR date <= ?)`, from, from, to, to)
+}
+
+func (r *Repository) queryJournal(where string, args ...interface{}) ([]JournalEntry, error) {
+	rows, err := r.Db.db.Query(`
+		SELECT id, date, text, photo_id, created_at
+		FROM journal_entries
+		`+where+`
+		ORDER BY date, id
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения дневника: %v", err)
+	}
+	defer rows.Close()
+
+	var entries []JournalEntry
+	for rows.Next() {
+		var entry JournalEntry
+		if err := rows.Scan(&entry.ID, &entry.Date, &entry.Text, &entry.PhotoID, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка получения дневника: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,168 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// Способы свести результаты измеримой задачи за период
+const (
+	// MetricSum сумма: км за неделю, уроки за месяц
+	MetricSum = "sum"
+	// MetricAvg среднее: часы сна
+	MetricAvg = "avg"
+	// MetricMax лучший результат
+	MetricMax = "max"
+)
+
+// MetricStat прогресс измеримой задачи за период
+type MetricStat struct {
+	Pillar      Pillar `json:"pillar"`
+	Description string `json:"description"`
+	Unit        string `json:"unit"`
+	Aggregation string `json:"aggregation"`
+	// Tasks задач за период, Entries - из них с внесенным результатом
+	Tasks   int `json:"tasks"`
+	Entries int `json:"entries"`
+	// Value итог по Aggregation, Target - цель за период: для суммы цели
+	// задач складываются, для среднего и лучшего - средняя цель задачи
+	Value  float64 `json:"value"`
+	Target float64 `json:"target"`
+}
+
+// Progress процент достижения цели, 0 если цель не задана
+func (ms MetricStat) Progress() float64 {
+	if ms.Target <= 0 {
+		return 0
+	}
+	return ms.Value / ms.Target * 100
+}
+
+func nullFloat(value sql.NullFloat64) *float64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Float64
+}
+
+// migrateMetrics добавляет задачам и шаблонам измеримый результат и
+// заполняет его для шаблонов по умолчанию и созданных по ним задач
+func (d *Database) migrateMetrics() error {
+	columnExists, err := d.hasColumn("task_templates", "metric_unit")
+	if err != nil || columnExists {
+		return err
+	}
+
+	columns := []struct{ table, column, definition string }{
+		{"task_templates", "metric_unit", "TEXT NOT NULL DEFAULT ''"},
+		{"task_templates", "metric_target", "REAL NOT NULL DEFAULT 0"},
+		{"task_templates", "metric_agg", "TEXT NOT NULL DEFAULT ''"},
+		{"tasks", "metric_unit", "TEXT NOT NULL DEFAULT ''"},
+		{"tasks", "metric_target", "REAL NOT NULL DEFAULT 0"},
+		{"tasks", "metric_agg", "TEXT NOT NULL DEFAULT ''"},
+		{"tasks", "value", "REAL"},
+	}
+	for _, c := range columns {
+		if err := d.migrateAddColumn(c.table, c.column, c.definition); err != nil {
+			return err
+		}
+	}
+
+	for _, template := range DefaultTemplates {
+		if template.MetricUnit == "" {
+			continue
+		}
+		_, err := d.db.Exec(`
+			UPDATE task_templates SET metric_unit = ?, metric_target = ?, metric_agg = ?
+			WHERE pillar = ? AND description = ? AND notes = ?
+		`, template.MetricUnit, template.MetricTarget, template.MetricAgg, template.Pillar, template.Description, template.Notes)
+		if err != nil {
+			return fmt.Errorf("ошибка заполнения метрик шаблонов: %v", err)
+		}
+	}
+
+	result, err := d.db.Exec(`
+		UPDATE tasks SET
+			metric_unit = t.metric_unit,
+			metric_target = t.metric_target,
+			metric_agg = t.metric_agg
+		FROM task_templates t
+		WHERE t.id = tasks.template_id AND t.metric_unit <> ''
+	`)
+	if err != nil {
+		return fmt.Errorf("ошибка заполнения метрик задач: %v", err)
+	}
+	if filled, err := result.RowsAffected(); err == nil && filled > 0 {
+		log.Printf("✅ Метрики добавлены задачам по шаблонам: %d", filled)
+	}
+	return nil
+}
+
+// UpdateTaskValue сохраняет результат задачи, nil - стереть
+func (r *Repository) UpdateTaskValue(taskID int, value *float64) error {
+	_, err := r.Db.db.Exec(`UPDATE tasks SET value = ? WHERE id = ?`, value, taskID)
+	return err
+}
+
+// UpdateTaskMetric меняет метрику задачи
+func (r *Repository) UpdateTaskMetric(taskID int, unit string, target float64, aggregation string) error {
+	_, err := r.Db.db.Exec(`
+		UPDATE tasks SET metric_unit = ?, metric_target = ?, metric_agg = ? WHERE id = ?
+	`, unit, target, aggregation, taskID)
+	return err
+}
+
+// UpdateTemplateMetric меняет метрику шаблона
+func (r *Repository) UpdateTemplateMetric(templateID int, unit string, target float64, aggregation string) (bool, error) {
+	result, err := r.Db.db.Exec(`
+		UPDATE task_templates SET metric_unit = ?, metric_target = ?, metric_agg = ? WHERE id = ?
+	`, unit, target, aggregation, templateID)
+	if err != nil {
+		return false, err
+	}
+	updated, err := result.RowsAffected()
+	return updated > 0, err
+}
+
+// GetMetricStats прогресс измеримых задач за период включительно
+func (r *Repository) GetMetricStats(from, to string) ([]MetricStat, error) {
+	rows, err := r.Db.db.Query(`
+		SELECT
+			pillar, description, metric_unit, metric_agg,
+			COUNT(*) AS tasks,
+			COUNT(value) AS entries,
+			COALESCE(SUM(value), 0), COALESCE(AVG(value), 0), COALESCE(MAX(value), 0),
+			SUM(metric_target), AVG(metric_target)
+		FROM tasks
+		WHERE metric_unit <> '' AND date BETWEEN ? AND ? AND `+isCounted+`
+		GROUP BY pillar, description, metric_unit, metric_agg
+		ORDER BY pillar, description
+	`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []MetricStat
+	for rows.Next() {
+		var stat MetricStat
+		var sum, avg, best, targetSum, targetAvg float64
+		err := rows.Scan(&stat.Pillar, &stat.Description, &stat.Unit, &stat.Aggregation,
+			&stat.Tasks, &stat.Entries, &sum, &avg, &best, &targetSum, &targetAvg)
+		if err != nil {
+			return nil, err
+		}
+
+		switch stat.Aggregation {
+		case MetricAvg:
+			stat.Value, stat.Target = avg, targetAvg
+		case MetricMax:
+			stat.Value, stat.Target = best, targetAvg
+		default:
+			stat.Value, stat.Target = sum, targetSum
+		}
+		stats = append(stats, stat)
+	}
+	return stats, rows.Err()
+}
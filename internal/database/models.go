@@ -1,9 +1,15 @@
 package database
 
-import "time"
+import (
+	"strconv"
+	"strings"
+	"time"
+)
 
+// Pillar ключ столпа из справочника pillars
 type Pillar string
 
+// Столпы по умолчанию, см. DefaultPillars
 const (
 	Energy  Pillar = "energy"
 	Body    Pillar = "body"
@@ -12,61 +18,248 @@ const (
 	Balance Pillar = "balance"
 )
 
-var PillarNames = map[Pillar]string{
-	Energy:  "⚖️ Энергия",
-	Body:    "🏃 Тело",
-	Focus:   "🧠 Фокус",
-	Life:    "🏠 Быт",
-	Balance: "🔄 Баланс",
+// TaskStatus состояние задачи. Переходы между статусами проверяет слой сервисов.
+type TaskStatus string
+
+const (
+	// StatusPlanned запланирована, время еще не наступило
+	StatusPlanned TaskStatus = "planned"
+	// StatusDue время наступило, задача ждет выполнения
+	StatusDue TaskStatus = "due"
+	// StatusDone выполнена
+	StatusDone TaskStatus = "done"
+	// StatusSkipped пропущена по причине
+	StatusSkipped TaskStatus = "skipped"
+	// StatusMissed день закончился, а задача не выполнена
+	StatusMissed TaskStatus = "missed"
+	// StatusCancelled отменена, в аналитике не учитывается
+	StatusCancelled TaskStatus = "cancelled"
+	// StatusRescheduled перенесена на другой день копией, учитывается там
+	StatusRescheduled TaskStatus = "rescheduled"
+)
+
+// TaskStatuses все статусы задачи
+var TaskStatuses = []TaskStatus{
+	StatusPlanned, StatusDue, StatusDone, StatusSkipped, StatusMissed, StatusCancelled, StatusRescheduled,
 }
 
-var PillarEmojis = map[Pillar]string{
-	Energy:  "⚖️",
-	Body:    "🏃",
-	Focus:   "🧠",
-	Life:    "🏠",
-	Balance: "🔄",
+// Open задача еще не закрыта: запланирована или ждет выполнения
+func (s TaskStatus) Open() bool {
+	return s == StatusPlanned || s == StatusDue
+}
+
+// Counted задача учитывается в аналитике своего дня
+func (s TaskStatus) Counted() bool {
+	return s != StatusCancelled && s != StatusRescheduled
 }
 
 type DailyTask struct {
-	ID          int       `json:"id"`
-	Pillar      Pillar    `json:"pillar"`
-	Description string    `json:"description"`
-	Completed   bool      `json:"completed"`
-	TimeUTC     string    `json:"time_utc"`
-	Date        string    `json:"date"`
-	Notes       string    `json:"notes,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
-	Skipped     bool      `json:"skipped"`
+	ID          int        `json:"id"`
+	Pillar      Pillar     `json:"pillar"`
+	Description string     `json:"description"`
+	Status      TaskStatus `json:"status"`
+	TimeUTC     string     `json:"time_utc"`
+	Date        string     `json:"date"`
+	Notes       string     `json:"notes,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	// Completed, Skipped выводятся из Status для старых клиентов API и
+	// выгрузок; пропуском здесь считается и missed
+	Completed bool `json:"completed"`
+	Skipped   bool `json:"skipped"`
+	// StartUTC реальное время начала, если оно отличается от TimeUTC
+	StartUTC string `json:"start_utc,omitempty"`
+	// LeadMinutes за сколько минут до начала напомнить: "30,5"
+	LeadMinutes string `json:"lead_minutes,omitempty"`
+	// TemplateID шаблон, по которому создана задача, 0 - разовая задача
+	TemplateID int `json:"template_id,omitempty"`
+	// CarriedFrom задача, незакрытой перенесенная на этот день
+	CarriedFrom int `json:"carried_from,omitempty"`
+	// GoalID цель, к которой привязана задача, 0 - без цели
+	GoalID int `json:"goal_id,omitempty"`
+	// Checklist пункты чек-листа по порядку
+	Checklist []ChecklistItem `json:"checklist,omitempty"`
+	// MetricUnit единица измеримого результата: "км", "мин"; пусто - без метрики
+	MetricUnit string `json:"metric_unit,omitempty"`
+	// MetricTarget цель на одну задачу, 0 - без цели
+	MetricTarget float64 `json:"metric_target,omitempty"`
+	// MetricAgg как сводить результаты за период: sum, avg или max
+	MetricAgg string `json:"metric_agg,omitempty"`
+	// Value внесенный результат, nil - еще не внесен
+	Value *float64 `json:"value,omitempty"`
+	// Tags теги через запятую без #: "work,family"
+	Tags string `json:"tags,omitempty"`
+}
+
+// StatusFromFlags статус по флагам старого формата
+func StatusFromFlags(completed, skipped bool) TaskStatus {
+	switch {
+	case completed:
+		return StatusDone
+	case skipped:
+		return StatusSkipped
+	default:
+		return StatusPlanned
+	}
+}
+
+// syncFlags заполняет Completed и Skipped по статусу
+func (t *DailyTask) syncFlags() {
+	t.Completed = t.Status == StatusDone
+	t.Skipped = t.Status == StatusSkipped || t.Status == StatusMissed
+}
+
+// Start время начала задачи: StartUTC или, если не задано, TimeUTC
+func (t DailyTask) Start() string {
+	if t.StartUTC != "" {
+		return t.StartUTC
+	}
+	return t.TimeUTC
+}
+
+// Leads напоминания заранее в минутах, неразборчивые значения пропускаются
+func (t DailyTask) Leads() []int {
+	return parseMinutesList(t.LeadMinutes)
+}
+
+// parseMinutesList разбирает список минут через запятую
+func parseMinutesList(value string) []int {
+	var minutes []int
+	for _, part := range strings.Split(value, ",") {
+		if m, err := strconv.Atoi(strings.TrimSpace(part)); err == nil && m > 0 {
+			minutes = append(minutes, m)
+		}
+	}
+	return minutes
 }
 
 type DailyFeelings struct {
-	ID           int       `json:"id"`
-	Date         string    `json:"date"`
-	EnergyLevel  int       `json:"energy_level"`  // 1-10
-	ControlLevel int       `json:"control_level"` // 1-10
-	SleepHours   float64   `json:"sleep_hours,omitempty"`
-	Mood         string    `json:"mood,omitempty"`
-	Notes        string    `json:"notes,omitempty"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID           int     `json:"id"`
+	Date         string  `json:"date"`
+	EnergyLevel  int     `json:"energy_level"`  // 1-10
+	ControlLevel int     `json:"control_level"` // 1-10
+	SleepHours   float64 `json:"sleep_hours,omitempty"`
+	Mood         string  `json:"mood,omitempty"`
+	Notes        string  `json:"notes,omitempty"`
+	// Values значения дополнительных показателей по ключу справочника
+	// wellbeing_metrics, встроенные показатели хранятся в полях выше
+	Values map[string]string `json:"values,omitempty"`
+	// Sleep ночь из журнала сна, по ней посчитан SleepHours
+	Sleep     *SleepEntry `json:"sleep,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// Snapshot выгрузка данных трекера за период
+type Snapshot struct {
+	ExportedAt  time.Time        `json:"exported_at"`
+	From        string           `json:"from,omitempty"`
+	To          string           `json:"to,omitempty"`
+	Tasks       []DailyTask      `json:"tasks"`
+	Feelings    []DailyFeelings  `json:"feelings"`
+	Journal     []JournalEntry   `json:"journal,omitempty"`
+	Sleep       []SleepEntry     `json:"sleep,omitempty"`
+	Templates   []TaskTemplate   `json:"templates,omitempty"`
+	SkipReasons []SkipReasonStat `json:"skip_reasons,omitempty"`
+	Analytics   *WeeklyAnalytics `json:"analytics,omitempty"`
+}
+
+// SkipReasonStat сколько раз задачи пропускались по причине
+type SkipReasonStat struct {
+	Code  string `json:"code"`
+	Text  string `json:"text"`
+	Count int    `json:"count"`
+}
+
+// DailySummary итоги дня. Total - задачи дня без отмененных и перенесенных.
+type DailySummary struct {
+	Date      string `json:"date"`
+	Total     int    `json:"total"`
+	Completed int    `json:"completed"`
+	Skipped   int    `json:"skipped"`
+	Missed    int    `json:"missed"`
+	// Partial пропущенные и несделанные задачи с частично выполненным чек-листом
+	Partial     int            `json:"partial"`
+	Percentage  float64        `json:"percentage"`
+	PillarStats map[string]int `json:"pillar_stats"`
+	Feelings    *DailyFeelings `json:"feelings,omitempty"`
 }
 
 type WeeklyAnalytics struct {
-	WeekNumber   int                   `json:"week_number"`
-	StartDate    string                `json:"start_date"`
-	EndDate      string                `json:"end_date"`
-	TotalDone    int                   `json:"total_done"`
-	TotalTasks   int                   `json:"total_tasks"`
-	TotalSkipped int                   `json:"total_skipped"`
-	PillarStats  map[string]PillarStat `json:"pillar_stats"`
-	AvgFeelings  map[string]float64    `json:"avg_feelings"`
-	Insights     string                `json:"insights"`
+	WeekNumber   int    `json:"week_number"`
+	StartDate    string `json:"start_date"`
+	EndDate      string `json:"end_date"`
+	TotalDone    int    `json:"total_done"`
+	TotalTasks   int    `json:"total_tasks"`
+	TotalSkipped int    `json:"total_skipped"`
+	TotalMissed  int    `json:"total_missed"`
+	// TotalOpen задачи, которые еще впереди или ждут выполнения
+	TotalOpen int `json:"total_open"`
+	// TotalPartial закрытые невыполненными задачи с частично выполненным чек-листом
+	TotalPartial int `json:"total_partial"`
+	// TotalProgress выполненные задачи плюс доли выполненных чек-листов частичных
+	TotalProgress float64               `json:"total_progress"`
+	PillarStats   map[string]PillarStat `json:"pillar_stats"`
+	// Metrics прогресс измеримых задач относительно целей
+	Metrics []MetricStat `json:"metrics,omitempty"`
+	// AvgFeelings средние числовых показателей самочувствия по ключу
+	// справочника wellbeing_metrics, для bool - доля дней с ответом «да»
+	AvgFeelings map[string]float64 `json:"avg_feelings"`
+	// Sleep режим сна за период, nil - сон не записывали
+	Sleep    *SleepStats `json:"sleep,omitempty"`
+	Insights string      `json:"insights"`
+}
+
+// Resolved задачи недели, которые уже закрыты: выполнены, пропущены или не сделаны
+func (wa *WeeklyAnalytics) Resolved() int {
+	return wa.TotalTasks - wa.TotalOpen
+}
+
+// CompletionRate процент выполненных среди закрытых задач недели
+func (wa *WeeklyAnalytics) CompletionRate() float64 {
+	if wa.Resolved() == 0 {
+		return 0
+	}
+	return float64(wa.TotalDone) / float64(wa.Resolved()) * 100
 }
 
+// ProgressRate процент выполнения недели с учетом частично выполненных чек-листов
+func (wa *WeeklyAnalytics) ProgressRate() float64 {
+	if wa.Resolved() == 0 {
+		return 0
+	}
+	return wa.TotalProgress / float64(wa.Resolved()) * 100
+}
+
+// PillarStat задачи столпа без отмененных и перенесенных
 type PillarStat struct {
 	Completed int `json:"completed"`
 	Skipped   int `json:"skipped"`
+	Missed    int `json:"missed"`
+	Open      int `json:"open"`
 	Total     int `json:"total"`
+	// Partial, Progress частично выполненные чек-листы, см. WeeklyAnalytics
+	Partial  int     `json:"partial"`
+	Progress float64 `json:"progress"`
+}
+
+// Resolved закрытые задачи столпа
+func (ps PillarStat) Resolved() int {
+	return ps.Total - ps.Open
+}
+
+// CompletionRate процент выполненных среди закрытых задач столпа
+func (ps PillarStat) CompletionRate() float64 {
+	if ps.Resolved() == 0 {
+		return 0
+	}
+	return float64(ps.Completed) / float64(ps.Resolved()) * 100
+}
+
+// ProgressRate процент выполнения столпа с учетом частично выполненных чек-листов
+func (ps PillarStat) ProgressRate() float64 {
+	if ps.Resolved() == 0 {
+		return 0
+	}
+	return ps.Progress / float64(ps.Resolved()) * 100
 }
 
 type TaskNotification struct {
@@ -76,4 +269,10 @@ type TaskNotification struct {
 	TimeUTC     string `json:"time_utc"`
 	Notes       string `json:"notes"`
 	Date        string `json:"date"`
+	StartUTC    string `json:"start_utc,omitempty"`
+	// Checklist пункты чек-листа, кнопки для них показывает Telegram
+	Checklist []ChecklistItem `json:"checklist,omitempty"`
+	// MetricUnit и MetricTarget измеримый результат задачи
+	MetricUnit   string  `json:"metric_unit,omitempty"`
+	MetricTarget float64 `json:"metric_target,omitempty"`
 }
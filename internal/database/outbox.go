@@ -0,0 +1,191 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Статусы сообщения в исходящей очереди бота
+const (
+	OutboxPending = "pending"
+	OutboxSent    = "sent"
+	// OutboxDead сообщение не удалось доставить, повторов не будет
+	OutboxDead = "dead"
+)
+
+// OutboxMessage сообщение, ожидающее отправки в Telegram
+type OutboxMessage struct {
+	ID     int
+	ChatID int64
+	// Method sendMessage, sendDocument, sendPhoto или editMessageText
+	Method string
+	Text   string
+	// MessageID сообщение в чате, которое меняет editMessageText
+	MessageID int
+	// ReplyMarkup клавиатура в JSON, пустая - без клавиатуры
+	ReplyMarkup string
+	// FileName имя файла sendDocument или file_id фото sendPhoto
+	FileName string
+	FileData []byte
+	// FilePath файл на диске для sendDocument вместо FileData. Так отправляются
+	// резервные копии, чтобы копия БД не попадала в очередь этой же БД.
+	FilePath      string
+	Status        string
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	CreatedAt     time.Time
+	SentAt        *time.Time
+}
+
+// EnqueueOutbox ставит сообщение в очередь и возвращает его ID
+func (r *Repository) EnqueueOutbox(msg OutboxMessage) (int, error) {
+	result, err := r.Db.db.Exec(`
+		INSERT INTO outbox (chat_id, method, text, message_id, reply_markup, file_name, file_data, file_path, status, next_attempt_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, msg.ChatID, msg.Method, msg.Text, msg.MessageID, msg.ReplyMarkup, msg.FileName, msg.FileData, msg.FilePath,
+		OutboxPending, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+// GetPendingOutbox неотправленные сообщения в порядке постановки в очередь
+func (r *Repository) GetPendingOutbox(limit int) ([]OutboxMessage, error) {
+	return r.queryOutbox(`
+		SELECT id, chat_id, method, text, message_id, reply_markup, file_name, file_data, file_path, status,
+		       attempts, next_attempt_at, last_error, created_at, sent_at
+		FROM outbox
+		WHERE status = ?
+		ORDER BY id
+		LIMIT ?
+	`, OutboxPending, limit)
+}
+
+// GetOutboxByStatus последние сообщения с указанным статусом (без файлов)
+func (r *Repository) GetOutboxByStatus(status string, limit int) ([]OutboxMessage, error) {
+	return r.queryOutbox(`
+		SELECT id, chat_id, method, text, message_id, reply_markup, file_name, NULL, file_path, status,
+		       attempts, next_attempt_at, last_error, created_at, sent_at
+		FROM outbox
+		WHERE status = ?
+		ORDER BY id DESC
+		LIMIT ?
+	`, status, limit)
+}
+
+// MarkOutboxSent отмечает сообщение отправленным. Файл больше не нужен и удаляется.
+func (r *Repository) MarkOutboxSent(id int, attempts int, at time.Time) error {
+	_, err := r.Db.db.Exec(`
+		UPDATE outbox
+		SET status = ?, attempts = ?, last_error = '', sent_at = ?, file_data = NULL
+		WHERE id = ?
+	`, OutboxSent, attempts, at.UTC(), id)
+	return err
+}
+
+// RescheduleOutbox записывает неудачную попытку отправки. У недоставленного
+// сообщения файл удаляется, как и у отправленного.
+func (r *Repository) RescheduleOutbox(id int, status string, attempts int, next time.Time, lastError string) error {
+	_, err := r.Db.db.Exec(`
+		UPDATE outbox
+		SET status = ?, attempts = ?, next_attempt_at = ?, last_error = ?,
+		    file_data = CASE WHEN ? = ? THEN NULL ELSE file_data END
+		WHERE id = ?
+	`, status, attempts, next.UTC(), lastError, status, OutboxDead, id)
+	return err
+}
+
+// RetryDeadOutbox возвращает недоставленные сообщения в очередь. Файлы, чье
+// содержимое уже удалено, повторить нельзя - они остаются недоставленными.
+func (r *Repository) RetryDeadOutbox(now time.Time) (int, error) {
+	result, err := r.Db.db.Exec(`
+		UPDATE outbox
+		SET status = ?, attempts = 0, next_attempt_at = ?
+		WHERE status = ? AND NOT (method = 'sendDocument' AND file_data IS NULL AND file_path = '')
+	`, OutboxPending, now.UTC(), OutboxDead)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := result.RowsAffected()
+	return int(count), err
+}
+
+// DeleteSentOutbox удаляет отправленные сообщения старше before
+func (r *Repository) DeleteSentOutbox(before time.Time) (int, error) {
+	result, err := r.Db.db.Exec(`DELETE FROM outbox WHERE status = ? AND sent_at < ?`, OutboxSent, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := result.RowsAffected()
+	return int(count), err
+}
+
+// CountOutbox число сообщений по статусам
+func (r *Repository) CountOutbox() (map[string]int, error) {
+	rows, err := r.Db.db.Query(`SELECT status, COUNT(*) FROM outbox GROUP BY status`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		counts[status] = count
+	}
+	return counts, rows.Err()
+}
+
+func (r *Repository) queryOutbox(query string, args ...interface{}) ([]OutboxMessage, error) {
+	rows, err := r.Db.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []OutboxMessage
+	for rows.Next() {
+		var msg OutboxMessage
+		var sentAt sql.NullTime
+		err := rows.Scan(
+			&msg.ID,
+			&msg.ChatID,
+			&msg.Method,
+			&msg.Text,
+			&msg.MessageID,
+			&msg.ReplyMarkup,
+			&msg.FileName,
+			&msg.FileData,
+			&msg.FilePath,
+			&msg.Status,
+			&msg.Attempts,
+			&msg.NextAttemptAt,
+			&msg.LastError,
+			&msg.CreatedAt,
+			&sentAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if sentAt.Valid {
+			msg.SentAt = &sentAt.Time
+		}
+		messages = append(messages, msg)
+	}
+
+	return messages, rows.Err()
+}
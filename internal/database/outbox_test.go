@@ -0,0 +1,65 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestRepository(t *testing.T) *Repository {
+	t.Helper()
+	db, err := New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewRepository(db)
+}
+
+func TestOutboxDropsFilesOnceDone(t *testing.T) {
+	r := newTestRepository(t)
+
+	sent, _ := r.EnqueueOutbox(OutboxMessage{Method: "sendDocument", FileName: "a.csv", FileData: []byte("a")})
+	dead, _ := r.EnqueueOutbox(OutboxMessage{Method: "sendDocument", FileName: "b.csv", FileData: []byte("b")})
+	backup, _ := r.EnqueueOutbox(OutboxMessage{Method: "sendDocument", FilePath: "/data/backups/c.db"})
+	text, _ := r.EnqueueOutbox(OutboxMessage{Method: "sendMessage", Text: "привет"})
+
+	now := time.Now()
+	if err := r.MarkOutboxSent(sent, 1, now); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []int{dead, backup, text} {
+		if err := r.RescheduleOutbox(id, OutboxDead, 5, now, "ошибка"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var stored int
+	if err := r.Db.db.QueryRow(`SELECT COUNT(*) FROM outbox WHERE file_data IS NOT NULL`).Scan(&stored); err != nil {
+		t.Fatal(err)
+	}
+	if stored != 0 {
+		t.Errorf("после отправки и отказа в очереди осталось файлов: %d", stored)
+	}
+
+	// Файл без содержимого повторить нельзя, копия с диска и текст - можно
+	retried, err := r.RetryDeadOutbox(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if retried != 2 {
+		t.Errorf("в очередь вернулось %d сообщений, ожидалось 2", retried)
+	}
+	pending, err := r.GetPendingOutbox(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, msg := range pending {
+		if msg.ID == dead {
+			t.Errorf("файл #%d без содержимого вернулся в очередь", dead)
+		}
+		if msg.ID == backup && msg.FilePath != "/data/backups/c.db" {
+			t.Errorf("путь копии %q потерян", msg.FilePath)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// PillarInfo столп из справочника pillars. Задачи, шаблоны и цели ссылаются
+// на столп по ключу, названия и эмодзи для показа берутся из справочника.
+type PillarInfo struct {
+	Key   Pillar `json:"key"`
+	Name  string `json:"name"`
+	Emoji string `json:"emoji"`
+	// Color цвет столпа для клиентов, #RRGGBB или пусто
+	Color string `json:"color"`
+	// Aliases дополнительные названия, по которым столп распознается в командах
+	Aliases  []string `json:"aliases"`
+	Position int      `json:"position"`
+	// Archived столп скрыт из списков и команд, старые задачи показываются как раньше
+	Archived bool `json:"archived"`
+}
+
+// Title эмодзи и название: "🏃 Тело"
+func (p PillarInfo) Title() string {
+	return p.Emoji + " " + p.Name
+}
+
+// Names ключ, название и синонимы в нижнем регистре
+func (p PillarInfo) Names() []string {
+	names := []string{string(p.Key), strings.ToLower(p.Name)}
+	for _, alias := range p.Aliases {
+		names = append(names, strings.ToLower(alias))
+	}
+	return names
+}
+
+// DefaultPillars столпы, которыми заполняется пустой справочник
+var DefaultPillars = []PillarInfo{
+	{Key: Energy, Name: "Энергия", Emoji: "⚖️", Color: "#F5A623"},
+	{Key: Body, Name: "Тело", Emoji: "🏃", Color: "#7ED321"},
+	{Key: Focus, Name: "Фокус", Emoji: "🧠", Color: "#4A90E2"},
+	{Key: Life, Name: "Быт", Emoji: "🏠", Color: "#8B572A"},
+	{Key: Balance, Name: "Баланс", Emoji: "🔄", Color: "#9013FE"},
+}
+
+// Pillars все столпы по порядку, archived - вместе с архивными
+func (r *Registry) Pillars(archived bool) []PillarInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var pillars []PillarInfo
+	for _, pillar := range r.pillars {
+		if archived || !pillar.Archived {
+			pillars = append(pillars, pillar)
+		}
+	}
+	return pillars
+}
+
+// GetPillar столп по ключу, включая архивные
+func (r *Registry) GetPillar(key Pillar) (PillarInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, pillar := range r.pillars {
+		if pillar.Key == key {
+			return pillar, true
+		}
+	}
+	return PillarInfo{}, false
+}
+
+// LookupPillar ищет действующий столп по ключу, названию или синониму
+func (r *Registry) LookupPillar(value string) (PillarInfo, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	for _, pillar := range r.Pillars(false) {
+		for _, name := range pillar.Names() {
+			if name == value {
+				return pillar, true
+			}
+		}
+	}
+	return PillarInfo{}, false
+}
+
+// PillarName эмодзи и название столпа, для неизвестного - сам ключ
+func (r *Registry) PillarName(key Pillar) string {
+	if pillar, ok := r.GetPillar(key); ok {
+		return pillar.Title()
+	}
+	return string(key)
+}
+
+// PillarEmoji эмодзи столпа, для неизвестного - 📌
+func (r *Registry) PillarEmoji(key Pillar) string {
+	if pillar, ok := r.GetPillar(key); ok {
+		return pillar.Emoji
+	}
+	return "📌"
+}
+
+// UsePillars заменяет столпы в справочнике. Нужен клиентам без своей БД,
+// которые получают столпы по HTTP API.
+func (r *Registry) UsePillars(pillars []PillarInfo) {
+	r.mu.Lock()
+	r.pillars = pillars
+	r.mu.Unlock()
+}
+
+// SortedPillars ключи карты по столпам в порядке справочника, неизвестные
+// столпы - в конце по алфавиту
+func SortedPillars[K ~string, V any](registry *Registry, byPillar map[K]V) []K {
+	position := make(map[K]int)
+	for i, pillar := range registry.Pillars(true) {
+		position[K(pillar.Key)] = i
+	}
+
+	keys := make([]K, 0, len(byPillar))
+	for key := range byPillar {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		pi, iKnown := position[keys[i]]
+		pj, jKnown := position[keys[j]]
+		switch {
+		case iKnown && jKnown:
+			return pi < pj
+		case iKnown != jKnown:
+			return iKnown
+		default:
+			return keys[i] < keys[j]
+		}
+	})
+	return keys
+}
+
+// seedPillars заполняет пустой справочник столпами по умолчанию
+func (d *Database) seedPillars() error {
+	var count int
+	if err := d.db.QueryRow(`SELECT COUNT(*) FROM pillars`).Scan(&count); err != nil {
+		return fmt.Errorf("ошибка проверки столпов: %v", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	repo := NewRepository(d)
+	for i, pillar := range DefaultPillars {
+		pillar.Position = i + 1
+		if err := repo.AddPillar(pillar); err != nil {
+			return fmt.Errorf("ошибка добавления столпа: %v", err)
+		}
+	}
+
+	log.Printf("✅ Добавлены столпы по умолчанию: %d", len(DefaultPillars))
+	return nil
+}
+
+// loadPillars перечитывает справочник столпов в память
+func (d *Database) loadPillars() error {
+	rows, err := d.db.Query(`
+		SELECT key, name, emoji, color, aliases, position, archived
+		FROM pillars
+		ORDER BY position, key
+	`)
+	if err != nil {
+		return fmt.Errorf("ошибка загрузки столпов: %v", err)
+	}
+	defer rows.Close()
+
+	var pillars []PillarInfo
+	for rows.Next() {
+		var pillar PillarInfo
+		var aliases string
+		err := rows.Scan(&pillar.Key, &pillar.Name, &pillar.Emoji, &pillar.Color, &aliases, &pillar.Position, &pillar.Archived)
+		if err != nil {
+			return fmt.Errorf("ошибка загрузки столпов: %v", err)
+		}
+		pillar.Aliases = splitAliases(aliases)
+		pillars = append(pillars, pillar)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("ошибка загрузки столпов: %v", err)
+	}
+
+	d.registry.UsePillars(pillars)
+	return nil
+}
+
+func splitAliases(value string) []string {
+	aliases := []string{}
+	for _, alias := range strings.Split(value, ",") {
+		if alias = strings.TrimSpace(alias); alias != "" {
+			aliases = append(aliases, alias)
+		}
+	}
+	return aliases
+}
+
+// AddPillar добавляет столп в справочник. Position 0 - в конец списка.
+func (r *Repository) AddPillar(pillar PillarInfo) error {
+	_, err := r.Db.db.Exec(`
+		INSERT INTO pillars (key, name, emoji, color, aliases, position, archived)
+		VALUES (?, ?, ?, ?, ?, CASE WHEN ? > 0 THEN ? ELSE (SELECT COALESCE(MAX(position), 0) + 1 FROM pillars) END, ?)
+	`, pillar.Key, pillar.Name, pillar.Emoji, pillar.Color, strings.Join(pillar.Aliases, ","),
+		pillar.Position, pillar.Position, pillar.Archived)
+	if err != nil {
+		return err
+	}
+	return r.Db.loadPillars()
+}
+
+// UpdatePillar сохраняет название, эмодзи, цвет, синонимы, порядок и архив
+// столпа. При смене позиции остальные столпы сдвигаются, номера идут подряд.
+func (r *Repository) UpdatePillar(pillar PillarInfo) (bool, error) {
+	tx, err := r.Db.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var position int
+	if err := tx.QueryRow(`SELECT position FROM pillars WHERE key = ?`, pillar.Key).Scan(&position); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	// Столпы между старой и новой позицией сдвигаются на освободившееся место
+	if err := movePosition(tx, "pillars", position, pillar.Position); err != nil {
+		return false, err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE pillars SET name = ?, emoji = ?, color = ?, aliases = ?, position = ?, archived = ?
+		WHERE key = ?
+	`, pillar.Name, pillar.Emoji, pillar.Color, strings.Join(pillar.Aliases, ","), pillar.Position, pillar.Archived, pillar.Key)
+	if err != nil {
+		return false, err
+	}
+	if err := renumberPositions(tx, "pillars"); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return true, r.Db.loadPillars()
+}
+
+// movePosition освобождает место для записи справочника, переезжающей с
+// позиции from на позицию to: записи между ними сдвигаются на одну
+func movePosition(tx *sql.Tx, table string, from, to int) error {
+	var err error
+	switch {
+	case to < from:
+		_, err = tx.Exec(`UPDATE `+table+` SET position = position + 1 WHERE position >= ? AND position < ?`, to, from)
+	case to > from:
+		_, err = tx.Exec(`UPDATE `+table+` SET position = position - 1 WHERE position > ? AND position <= ?`, from, to)
+	}
+	return err
+}
+
+// renumberPositions нумерует записи справочника подряд с 1
+func renumberPositions(tx *sql.Tx, table string) error {
+	_, err := tx.Exec(`
+		UPDATE ` + table + ` SET position = (
+			SELECT n FROM (SELECT key, ROW_NUMBER() OVER (ORDER BY position, key) AS n FROM ` + table + `) ordered
+			WHERE ordered.key = ` + table + `.key
+		)
+	`)
+	return err
+}
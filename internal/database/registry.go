@@ -0,0 +1,28 @@
+package database
+
+import "sync"
+
+// Registry справочники столпов и показателей самочувствия в памяти. У каждой
+// БД свой: загружается при открытии и после каждого изменения справочника,
+// форматтеры читают его без запросов к базе. Клиенты без своей БД заполняют
+// его через UsePillars и UseWellbeingMetrics.
+type Registry struct {
+	mu        sync.RWMutex
+	pillars   []PillarInfo
+	wellbeing []WellbeingMetric
+}
+
+// NewRegistry пустой справочник
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Registry справочники БД
+func (d *Database) Registry() *Registry {
+	return d.registry
+}
+
+// Registry справочники БД репозитория
+func (r *Repository) Registry() *Registry {
+	return r.Db.registry
+}
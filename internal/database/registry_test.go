@@ -0,0 +1,30 @@
+package database
+
+import "testing"
+
+// Справочники у каждой БД свои: открытие второй базы не подменяет первую
+func TestRegistryPerDatabase(t *testing.T) {
+	first := newTestRepository(t)
+	second := newTestRepository(t)
+
+	if err := first.AddPillar(PillarInfo{Key: "finance", Name: "Финансы", Emoji: "💰"}); err != nil {
+		t.Fatal(err)
+	}
+	metric := WellbeingMetric{Key: "water", Name: "Вода", Emoji: "💧", Type: WellbeingNumber}
+	if err := second.AddWellbeingMetric(metric); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := first.Registry().LookupPillar("финансы"); !ok {
+		t.Error("столп не появился в справочнике своей БД")
+	}
+	if _, ok := second.Registry().GetPillar("finance"); ok {
+		t.Error("столп другой БД попал в справочник")
+	}
+	if _, ok := second.Registry().LookupWellbeingMetric("вода"); !ok {
+		t.Error("показатель не появился в справочнике своей БД")
+	}
+	if _, ok := first.Registry().GetWellbeingMetric("water"); ok {
+		t.Error("показатель другой БД попал в справочник")
+	}
+}
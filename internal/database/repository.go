@@ -45,10 +45,17 @@ func (r *Repository) UpdateTaskDate(taskID int, newDate string) error {
 	return nil
 }
 
+// UpdateTaskStart меняет время начала и напоминания заранее
+func (r *Repository) UpdateTaskStart(taskID int, startUTC, leadMinutes string) error {
+	_, err := r.Db.db.Exec(`UPDATE tasks SET start_utc = ?, lead_minutes = ? WHERE id = ?`, startUTC, leadMinutes, taskID)
+	return err
+}
+
 // GetTasksByDate поиск списка задач по указанной дате
 func (r *Repository) GetTasksByDate(date string) ([]DailyTask, error) {
 	rows, err := r.Db.db.Query(`
-		SELECT id, pillar, description, completed, time_utc, date, notes, created_at, skipped
+		SELECT id, pillar, description, status, time_utc, date, notes, created_at, start_utc, lead_minutes,
+			template_id, carried_from, metric_unit, metric_target, metric_agg, value, goal_id, tags
 		FROM tasks 
 		WHERE date = ?
 		ORDER BY time_utc
@@ -66,52 +73,218 @@ func (r *Repository) GetTasksByDate(date string) ([]DailyTask, error) {
 	var tasks []DailyTask
 	for rows.Next() {
 		var task DailyTask
+		var value sql.NullFloat64
 		err := rows.Scan(
 			&task.ID,
 			&task.Pillar,
 			&task.Description,
-			&task.Completed,
+			&task.Status,
 			&task.TimeUTC,
 			&task.Date,
 			&task.Notes,
 			&task.CreatedAt,
-			&task.Skipped,
+			&task.StartUTC,
+			&task.LeadMinutes,
+			&task.TemplateID,
+			&task.CarriedFrom,
+			&task.MetricUnit,
+			&task.MetricTarget,
+			&task.MetricAgg,
+			&value,
+			&task.GoalID,
+			&task.Tags,
 		)
 		if err != nil {
 			return nil, err
 		}
+		task.Value = nullFloat(value)
+		task.syncFlags()
 		tasks = append(tasks, task)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
-	return tasks, nil
+	return tasks, r.attachChecklists(tasks)
 }
 
-func (r *Repository) AddTask(task DailyTask) error {
-	_, err := r.Db.db.Exec(`
-		INSERT INTO tasks (pillar, description, completed, time_utc, date, notes)
-		VALUES (?, ?, ?, ?, ?, ?)
-	`, task.Pillar, task.Description, task.Completed, task.TimeUTC, task.Date, task.Notes)
-	return err
+// GetTasksBetween поиск задач за период включительно, пустые границы не ограничивают выборку
+func (r *Repository) GetTasksBetween(from, to string) ([]DailyTask, error) {
+	rows, err := r.Db.db.Query(`
+		SELECT id, pillar, description, status, time_utc, date, notes, created_at, start_utc, lead_minutes,
+			template_id, carried_from, metric_unit, metric_target, metric_agg, value, goal_id, tags
+		FROM tasks 
+		WHERE (? = '' OR date >= ?) AND (? = '' OR date <= ?)
+		ORDER BY date, time_utc
+	`, from, from, to, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []DailyTask
+	for rows.Next() {
+		var task DailyTask
+		var value sql.NullFloat64
+		err := rows.Scan(
+			&task.ID,
+			&task.Pillar,
+			&task.Description,
+			&task.Status,
+			&task.TimeUTC,
+			&task.Date,
+			&task.Notes,
+			&task.CreatedAt,
+			&task.StartUTC,
+			&task.LeadMinutes,
+			&task.TemplateID,
+			&task.CarriedFrom,
+			&task.MetricUnit,
+			&task.MetricTarget,
+			&task.MetricAgg,
+			&value,
+			&task.GoalID,
+			&task.Tags,
+		)
+		if err != nil {
+			return nil, err
+		}
+		task.Value = nullFloat(value)
+		task.syncFlags()
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tasks, r.attachChecklists(tasks)
 }
 
-func (r *Repository) UpdateTaskCompletion(taskID int, completed bool) error {
-	_, err := r.Db.db.Exec("UPDATE tasks SET completed = ? WHERE id = ?", completed, taskID)
-	return err
+// AddTask добавляет задачу и возвращает её ID
+func (r *Repository) AddTask(task DailyTask) (int, error) {
+	tx, err := r.Db.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	id, err := addTask(tx, task)
+	if err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+func addTask(tx *sql.Tx, task DailyTask) (int, error) {
+	if task.Status == "" {
+		task.Status = StatusPlanned
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO tasks (pillar, description, status, completed, skipped, time_utc, date, notes, start_utc, lead_minutes,
+			template_id, carried_from, metric_unit, metric_target, metric_agg, value, goal_id, tags)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, task.Pillar, task.Description, task.Status, task.Status == StatusDone, task.Status == StatusSkipped || task.Status == StatusMissed,
+		task.TimeUTC, task.Date, task.Notes, task.StartUTC, task.LeadMinutes, task.TemplateID, task.CarriedFrom,
+		task.MetricUnit, task.MetricTarget, task.MetricAgg, task.Value, task.GoalID, task.Tags)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	// Чек-лист сохраняется вместе с задачей
+	if err := addChecklistItems(tx, int(id), task.Checklist); err != nil {
+		return 0, err
+	}
+	return int(id), nil
+}
+
+// GetTaskByID поиск задачи по ID, sql.ErrNoRows если задачи нет
+func (r *Repository) GetTaskByID(taskID int) (*DailyTask, error) {
+	var task DailyTask
+	var value sql.NullFloat64
+	err := r.Db.db.QueryRow(`
+		SELECT id, pillar, description, status, time_utc, date, notes, created_at, start_utc, lead_minutes,
+			template_id, carried_from, metric_unit, metric_target, metric_agg, value, goal_id, tags
+		FROM tasks 
+		WHERE id = ?
+	`, taskID).Scan(
+		&task.ID,
+		&task.Pillar,
+		&task.Description,
+		&task.Status,
+		&task.TimeUTC,
+		&task.Date,
+		&task.Notes,
+		&task.CreatedAt,
+		&task.StartUTC,
+		&task.LeadMinutes,
+		&task.TemplateID,
+		&task.CarriedFrom,
+		&task.MetricUnit,
+		&task.MetricTarget,
+		&task.MetricAgg,
+		&value,
+		&task.GoalID,
+		&task.Tags,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	task.Value = nullFloat(value)
+	task.syncFlags()
+	if task.Checklist, err = r.GetChecklist(task.ID); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// TaskExists проверяет наличие задачи с той же датой, столпом и описанием
+func (r *Repository) TaskExists(date string, pillar Pillar, description string) (bool, error) {
+	var exists bool
+	err := r.Db.db.QueryRow(`
+		SELECT COUNT(*) > 0 FROM tasks
+		WHERE date = ? AND pillar = ? AND description = ?
+	`, date, pillar, description).Scan(&exists)
+	return exists, err
+}
+
+// UpdateTaskStatus меняет статус задачи, если он все еще from. false -
+// статус успели изменить. Флаги completed и skipped обновляются вместе с ним.
+func (r *Repository) UpdateTaskStatus(taskID int, from, to TaskStatus) (bool, error) {
+	result, err := r.Db.db.Exec(`
+		UPDATE tasks
+		SET status = ?, completed = ?, skipped = ?
+		WHERE id = ? AND status = ?
+	`, to, to == StatusDone, to == StatusSkipped || to == StatusMissed, taskID, from)
+	if err != nil {
+		return false, err
+	}
+	updated, err := result.RowsAffected()
+	return updated > 0, err
 }
 
 func (r *Repository) DeleteTask(taskID int) error {
+	if _, err := r.Db.db.Exec("DELETE FROM checklist_items WHERE task_id = ?", taskID); err != nil {
+		return err
+	}
 	_, err := r.Db.db.Exec("DELETE FROM tasks WHERE id = ?", taskID)
 	return err
 }
 
 func (r *Repository) GetTasksForNotification(currentTime, today string) ([]TaskNotification, error) {
 	rows, err := r.Db.db.Query(`
-		SELECT id, pillar, description, time_utc, notes, date
+		SELECT id, pillar, description, time_utc, notes, date, start_utc, metric_unit, metric_target
 		FROM tasks 
 		WHERE date = ? 
 		AND time_utc <= ? 
-		AND completed = 0 
-		AND skipped = 0 
+		AND status IN ('planned', 'due')
 	`, today, currentTime)
 
 	if err != nil {
@@ -134,41 +307,152 @@ func (r *Repository) GetTasksForNotification(currentTime, today string) ([]TaskN
 			&task.TimeUTC,
 			&task.Notes,
 			&task.Date,
+			&task.StartUTC,
+			&task.MetricUnit,
+			&task.MetricTarget,
 		)
 		if err != nil {
 			return nil, err
 		}
 		tasks = append(tasks, task)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	checklists, err := r.GetChecklists(ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tasks {
+		tasks[i].Checklist = checklists[tasks[i].ID]
+	}
 
 	return tasks, nil
 }
 
-// MarkTaskAsSkipped отмечает задачу как пропущенную (ДОБАВЛЯЕМ НОВЫЙ МЕТОД)
-func (r *Repository) MarkTaskAsSkipped(taskID int, reasonCode, reasonText string) error {
-	_, err := r.Db.db.Exec(`
+// GetUnfinishedTasksDue незакрытые и не перенесенные задачи, срок которых
+// ("YYYY-MM-DD HH:MM" UTC) попадает в полуинтервал (from, to]
+func (r *Repository) GetUnfinishedTasksDue(from, to string) ([]DailyTask, error) {
+	rows, err := r.Db.db.Query(`
+		SELECT id, pillar, description, status, time_utc, date, notes, created_at, start_utc, lead_minutes,
+			template_id, carried_from, metric_unit, metric_target, metric_agg, value, goal_id, tags
+		FROM tasks
+		WHERE status IN ('planned', 'due')
+		AND date || ' ' || time_utc > ? AND date || ' ' || time_utc <= ?
+		ORDER BY date, time_utc
+	`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []DailyTask
+	for rows.Next() {
+		var task DailyTask
+		var value sql.NullFloat64
+		err := rows.Scan(
+			&task.ID,
+			&task.Pillar,
+			&task.Description,
+			&task.Status,
+			&task.TimeUTC,
+			&task.Date,
+			&task.Notes,
+			&task.CreatedAt,
+			&task.StartUTC,
+			&task.LeadMinutes,
+			&task.TemplateID,
+			&task.CarriedFrom,
+			&task.MetricUnit,
+			&task.MetricTarget,
+			&task.MetricAgg,
+			&value,
+			&task.GoalID,
+			&task.Tags,
+		)
+		if err != nil {
+			return nil, err
+		}
+		task.Value = nullFloat(value)
+		task.syncFlags()
+		tasks = append(tasks, task)
+	}
+
+	return tasks, rows.Err()
+}
+
+// MarkTaskAsSkipped переводит задачу из from в пропущенные с причиной
+func (r *Repository) MarkTaskAsSkipped(taskID int, from TaskStatus, reasonCode, reasonText string) (bool, error) {
+	result, err := r.Db.db.Exec(`
 		UPDATE tasks 
-		SET skipped = 1, 
+		SET status = ?,
+		    completed = 0,
+		    skipped = 1, 
 		    notes = ? 
-		WHERE id = ?
-	`, fmt.Sprintf("Пропущено: %s | %s", reasonCode, reasonText), taskID)
-	return err
+		WHERE id = ? AND status = ?
+	`, StatusSkipped, fmt.Sprintf("Пропущено: %s | %s", reasonCode, reasonText), taskID, from)
+	if err != nil {
+		return false, err
+	}
+	updated, err := result.RowsAffected()
+	return updated > 0, err
 }
 
-// SaveFeelings добавляет запись об ощущениях
+// SaveFeelings добавляет запись об ощущениях. Если за день есть дневник,
+// заметками становится его текст.
 func (r *Repository) SaveFeelings(feelings DailyFeelings) error {
-	_, err := r.Db.db.Exec(`
+	tx, err := r.Db.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := saveFeelings(tx, r.Db.registry, feelings); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func saveFeelings(tx *sql.Tx, registry *Registry, feelings DailyFeelings) error {
+	// Без сна в ощущениях берется ночь из журнала сна, если ее уже записали.
+	// Не оцененные энергия и контроль хранятся как NULL.
+	_, err := tx.Exec(`
 		INSERT OR REPLACE INTO feelings 
 		(date, energy_level, control_level, sleep_hours, mood, notes)
-		VALUES (?, ?, ?, ?, ?, ?)
-	`, feelings.Date, feelings.EnergyLevel, feelings.ControlLevel, feelings.SleepHours, feelings.Mood, feelings.Notes)
-	return err
+		VALUES (?, NULLIF(?, 0), NULLIF(?, 0),
+			CASE WHEN ? > 0 THEN ? ELSE COALESCE((SELECT hours FROM sleep_log WHERE date = ?), 0) END,
+			?, CASE WHEN EXISTS(SELECT 1 FROM journal_entries WHERE date = ?) THEN `+journalNotes+` ELSE ? END)
+	`, feelings.Date, feelings.EnergyLevel, feelings.ControlLevel,
+		feelings.SleepHours, feelings.SleepHours, feelings.Date,
+		feelings.Mood, feelings.Date, feelings.Date, feelings.Notes)
+	if err != nil {
+		return err
+	}
+	if err := saveFeelingsValues(tx, registry, feelings.Date, feelings.Values); err != nil {
+		return err
+	}
+	if feelings.Sleep != nil {
+		return saveSleep(tx, *feelings.Sleep)
+	}
+	return nil
+}
+
+// FeelingsExist проверяет, есть ли запись ощущений за дату
+func (r *Repository) FeelingsExist(date string) (bool, error) {
+	var exists bool
+	err := r.Db.db.QueryRow(`SELECT COUNT(*) > 0 FROM feelings WHERE date = ?`, date).Scan(&exists)
+	return exists, err
 }
 
 func (r *Repository) GetFeelings(date string) (*DailyFeelings, error) {
 	var feelings DailyFeelings
 	err := r.Db.db.QueryRow(`
-		SELECT id, date, energy_level, control_level, sleep_hours, mood, notes, created_at
+		SELECT id, date, COALESCE(energy_level, 0), COALESCE(control_level, 0), sleep_hours, mood, notes, created_at
 		FROM feelings 
 		WHERE date = ?
 	`, date).Scan(
@@ -186,41 +470,106 @@ func (r *Repository) GetFeelings(date string) (*DailyFeelings, error) {
 		return nil, err
 	}
 
-	return &feelings, nil
+	result := []DailyFeelings{feelings}
+	if err := r.attachFeelingsValues(result, date, date); err != nil {
+		return nil, err
+	}
+	if err := r.attachSleep(result, date, date); err != nil {
+		return nil, err
+	}
+	return &result[0], nil
+}
+
+// GetFeelingsBetween поиск ощущений за период включительно, пустые границы не ограничивают выборку
+func (r *Repository) GetFeelingsBetween(from, to string) ([]DailyFeelings, error) {
+	rows, err := r.Db.db.Query(`
+		SELECT id, date, COALESCE(energy_level, 0), COALESCE(control_level, 0), sleep_hours, mood, notes, created_at
+		FROM feelings 
+		WHERE (? = '' OR date >= ?) AND (? = '' OR date <= ?)
+		ORDER BY date
+	`, from, from, to, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []DailyFeelings
+	for rows.Next() {
+		var feelings DailyFeelings
+		err := rows.Scan(
+			&feelings.ID,
+			&feelings.Date,
+			&feelings.EnergyLevel,
+			&feelings.ControlLevel,
+			&feelings.SleepHours,
+			&feelings.Mood,
+			&feelings.Notes,
+			&feelings.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, feelings)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.attachFeelingsValues(result, from, to); err != nil {
+		return nil, err
+	}
+	return result, r.attachSleep(result, from, to)
 }
 
+// isCounted задача учитывается в аналитике: отмененные не считаются,
+// перенесенные считаются один раз - в день, куда их перенесли
+const isCounted = `status NOT IN ('cancelled', 'rescheduled')`
+
+// withChecklists присоединяет к задачам число пунктов чек-листа (items) и
+// отмеченных пунктов (items_done)
+const withChecklists = `LEFT JOIN (
+			SELECT task_id, COUNT(*) AS items, SUM(done) AS items_done
+			FROM checklist_items GROUP BY task_id
+		) c ON c.task_id = tasks.id`
+
+// isPartial пропущенная или несделанная задача, часть чек-листа которой выполнена
+const isPartial = `status IN ('skipped', 'missed') AND c.items_done > 0`
+
 // GetDailySummary сбор данных по дневной аналитике
-func (r *Repository) GetDailySummary(date string) (map[string]interface{}, error) {
-	summary := make(map[string]interface{})
+func (r *Repository) GetDailySummary(date string) (*DailySummary, error) {
+	summary := &DailySummary{
+		Date:        date,
+		PillarStats: make(map[string]int),
+	}
 
-	var total, completed int
+	var completed, skipped, missed, partial sql.NullInt64
 	err := r.Db.db.QueryRow(`
 		SELECT 
 			COUNT(*) as total,
-			SUM(CASE WHEN completed = 1 THEN 1 ELSE 0 END) as completed
-		FROM tasks 
-		WHERE date = ?
-	`, date).Scan(&total, &completed)
+			SUM(CASE WHEN status = 'done' THEN 1 ELSE 0 END) as completed,
+			SUM(CASE WHEN status = 'skipped' THEN 1 ELSE 0 END) as skipped,
+			SUM(CASE WHEN status = 'missed' THEN 1 ELSE 0 END) as missed,
+			SUM(CASE WHEN `+isPartial+` THEN 1 ELSE 0 END) as partial
+		FROM tasks `+withChecklists+`
+		WHERE date = ? AND `+isCounted+`
+	`, date).Scan(&summary.Total, &completed, &skipped, &missed, &partial)
 
 	if err != nil {
 		return nil, err
 	}
 
-	summary["date"] = date
-	summary["total"] = total
-	summary["completed"] = completed
-
-	percentage := 0.0
-	if total > 0 {
-		percentage = float64(completed) / float64(total) * 100
+	summary.Completed = int(completed.Int64)
+	summary.Skipped = int(skipped.Int64)
+	summary.Missed = int(missed.Int64)
+	summary.Partial = int(partial.Int64)
+	if summary.Total > 0 {
+		summary.Percentage = float64(summary.Completed) / float64(summary.Total) * 100
 	}
-	summary["percentage"] = percentage
 
-	pillarStats := make(map[string]int)
 	rows, err := r.Db.db.Query(`
 		SELECT pillar, COUNT(*) as count
 		FROM tasks 
-		WHERE date = ? AND completed = 1
+		WHERE date = ? AND status = 'done'
 		GROUP BY pillar
 	`, date)
 
@@ -230,10 +579,9 @@ func (r *Repository) GetDailySummary(date string) (map[string]interface{}, error
 			var pillar string
 			var count int
 			rows.Scan(&pillar, &count)
-			pillarStats[pillar] = count
+			summary.PillarStats[pillar] = count
 		}
 	}
-	summary["pillar_stats"] = pillarStats
 
 	return summary, nil
 }
@@ -243,17 +591,24 @@ func (r *Repository) GetWeeklyAnalytics(startDate, endDate string) (*WeeklyAnaly
 		StartDate:   startDate,
 		EndDate:     endDate,
 		PillarStats: make(map[string]PillarStat),
-		AvgFeelings: make(map[string]float64),
 	}
 
 	rows, err := r.Db.db.Query(`
 		SELECT 
 			pillar,
 			COUNT(*) as total,
-			SUM(CASE WHEN completed = 1 THEN 1 ELSE 0 END) as completed,
-			SUM(CASE WHEN skipped = 1 THEN 1 ELSE 0 END) as skipped
-		FROM tasks 
-		WHERE date BETWEEN ? AND ?
+			SUM(CASE WHEN status = 'done' THEN 1 ELSE 0 END) as completed,
+			SUM(CASE WHEN status = 'skipped' THEN 1 ELSE 0 END) as skipped,
+			SUM(CASE WHEN status = 'missed' THEN 1 ELSE 0 END) as missed,
+			SUM(CASE WHEN status IN ('planned', 'due') THEN 1 ELSE 0 END) as open,
+			SUM(CASE WHEN `+isPartial+` THEN 1 ELSE 0 END) as partial,
+			SUM(CASE
+				WHEN status = 'done' THEN 1.0
+				WHEN `+isPartial+` THEN 1.0 * c.items_done / c.items
+				ELSE 0
+			END) as progress
+		FROM tasks `+withChecklists+`
+		WHERE date BETWEEN ? AND ? AND `+isCounted+`
 		GROUP BY pillar
 	`, startDate, endDate)
 
@@ -265,7 +620,8 @@ func (r *Repository) GetWeeklyAnalytics(startDate, endDate string) (*WeeklyAnaly
 	for rows.Next() {
 		var pillar string
 		var stats PillarStat
-		err := rows.Scan(&pillar, &stats.Total, &stats.Completed, &stats.Skipped)
+		err := rows.Scan(&pillar, &stats.Total, &stats.Completed, &stats.Skipped, &stats.Missed, &stats.Open,
+			&stats.Partial, &stats.Progress)
 		if err != nil {
 			return nil, err
 		}
@@ -273,37 +629,18 @@ func (r *Repository) GetWeeklyAnalytics(startDate, endDate string) (*WeeklyAnaly
 		analytics.TotalTasks += stats.Total
 		analytics.TotalDone += stats.Completed
 		analytics.TotalSkipped += stats.Skipped
+		analytics.TotalMissed += stats.Missed
+		analytics.TotalOpen += stats.Open
+		analytics.TotalPartial += stats.Partial
+		analytics.TotalProgress += stats.Progress
 	}
 
-	metricRows, err := r.Db.db.Query(`
-		SELECT 
-			AVG(energy_level) as avg_energy,
-			AVG(control_level) as avg_control
-		FROM feelings 
-		WHERE date BETWEEN ? AND ?
-	`, startDate, endDate)
+	if analytics.Metrics, err = r.GetMetricStats(startDate, endDate); err != nil {
+		return nil, err
+	}
 
-	if err == nil {
-		defer func(metricRows *sql.Rows) {
-			err := metricRows.Close()
-			if err != nil {
-				panic(err)
-			}
-		}(metricRows)
-		if metricRows.Next() {
-			var avgEnergy, avgControl sql.NullFloat64
-			err := metricRows.Scan(&avgEnergy, &avgControl)
-			if err != nil {
-				return nil, err
-			}
-
-			if avgEnergy.Valid {
-				analytics.AvgFeelings["energy"] = avgEnergy.Float64
-			}
-			if avgControl.Valid {
-				analytics.AvgFeelings["control"] = avgControl.Float64
-			}
-		}
+	if analytics.AvgFeelings, err = r.wellbeingAverages(startDate, endDate); err != nil {
+		return nil, err
 	}
 
 	return analytics, nil
@@ -0,0 +1,271 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// SettingSearchStale индекс поиска отстал: базу открывали без FTS5
+const SettingSearchStale = "search_stale"
+
+// SearchQuery условия поиска, пустые поля выборку не ограничивают
+type SearchQuery struct {
+	// Words слова, каждое должно встретиться в описании, заметках или тегах
+	Words []string
+	Tags  []string
+	// From, To период по дате задачи включительно
+	From     string
+	To       string
+	Statuses []TaskStatus
+	Limit    int
+}
+
+// searchTriggers поддерживают индексы tasks_fts и feelings_fts. Ощущения
+// сохраняются через INSERT OR REPLACE, поэтому старая запись за дату
+// удаляется из индекса при вставке новой.
+var searchTriggers = []string{
+	`CREATE TRIGGER IF NOT EXISTS tasks_fts_insert AFTER INSERT ON tasks BEGIN
+		INSERT INTO tasks_fts (rowid, description, notes, tags) VALUES (new.id, new.description, COALESCE(new.notes, ''), new.tags);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS tasks_fts_update AFTER UPDATE OF description, notes, tags ON tasks BEGIN
+		DELETE FROM tasks_fts WHERE rowid = old.id;
+		INSERT INTO tasks_fts (rowid, description, notes, tags) VALUES (new.id, new.description, COALESCE(new.notes, ''), new.tags);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS tasks_fts_delete AFTER DELETE ON tasks BEGIN
+		DELETE FROM tasks_fts WHERE rowid = old.id;
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS feelings_fts_insert AFTER INSERT ON feelings BEGIN
+		DELETE FROM feelings_fts WHERE date = new.date;
+		INSERT INTO feelings_fts (date, mood, notes) VALUES (new.date, COALESCE(new.mood, ''), COALESCE(new.notes, ''));
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS feelings_fts_update AFTER UPDATE ON feelings BEGIN
+		DELETE FROM feelings_fts WHERE date = old.date;
+		INSERT INTO feelings_fts (date, mood, notes) VALUES (new.date, COALESCE(new.mood, ''), COALESCE(new.notes, ''));
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS feelings_fts_delete AFTER DELETE ON feelings BEGIN
+		DELETE FROM feelings_fts WHERE date = old.date;
+	END`,
+}
+
+// initSearch создает полнотекстовые индексы, если SQLite собран с FTS5
+// (go build -tags sqlite_fts5). Без FTS5 триггеры индекса удаляются, иначе
+// запись в tasks будет падать, а поиск перебирает задачи сам.
+func (d *Database) initSearch() error {
+	if _, err := d.db.Exec(`CREATE VIRTUAL TABLE IF NOT EXISTS temp.fts5_probe USING fts5(text)`); err != nil {
+		return d.disableSearchIndex()
+	}
+	if _, err := d.db.Exec(`DROP TABLE temp.fts5_probe`); err != nil {
+		return fmt.Errorf("ошибка проверки FTS5: %v", err)
+	}
+
+	var exists bool
+	if err := d.db.QueryRow(`SELECT COUNT(*) > 0 FROM sqlite_master WHERE name = 'tasks_fts'`).Scan(&exists); err != nil {
+		return fmt.Errorf("ошибка проверки индекса поиска: %v", err)
+	}
+	var stale int
+	if err := d.db.QueryRow(`SELECT COUNT(*) FROM settings WHERE key = ?`, SettingSearchStale).Scan(&stale); err != nil {
+		return fmt.Errorf("ошибка проверки индекса поиска: %v", err)
+	}
+
+	queries := []string{
+		`CREATE VIRTUAL TABLE IF NOT EXISTS tasks_fts USING fts5(description, notes, tags, tokenize = 'unicode61 remove_diacritics 2')`,
+		`CREATE VIRTUAL TABLE IF NOT EXISTS feelings_fts USING fts5(date UNINDEXED, mood, notes, tokenize = 'unicode61 remove_diacritics 2')`,
+	}
+	if !exists || stale > 0 {
+		queries = append(queries,
+			`DELETE FROM tasks_fts`,
+			`INSERT INTO tasks_fts (rowid, description, notes, tags) SELECT id, description, COALESCE(notes, ''), tags FROM tasks`,
+			`DELETE FROM feelings_fts`,
+			`INSERT INTO feelings_fts (date, mood, notes) SELECT date, COALESCE(mood, ''), COALESCE(notes, '') FROM feelings`,
+			`DELETE FROM settings WHERE key = '`+SettingSearchStale+`'`,
+		)
+	}
+	for _, query := range append(queries, searchTriggers...) {
+		if _, err := d.db.Exec(query); err != nil {
+			return fmt.Errorf("ошибка создания индекса поиска: %v", err)
+		}
+	}
+
+	if !exists || stale > 0 {
+		log.Println("✅ Индекс полнотекстового поиска построен")
+	}
+	d.fts = true
+	return nil
+}
+
+// disableSearchIndex убирает триггеры индекса, созданные сборкой с FTS5, и
+// помечает индекс устаревшим - он перестроится при следующем запуске с FTS5
+func (d *Database) disableSearchIndex() error {
+	var triggers int
+	err := d.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name LIKE '%_fts_%'`).Scan(&triggers)
+	if err != nil {
+		return fmt.Errorf("ошибка проверки индекса поиска: %v", err)
+	}
+	if triggers == 0 {
+		return nil
+	}
+
+	for _, name := range []string{"tasks_fts_insert", "tasks_fts_update", "tasks_fts_delete",
+		"feelings_fts_insert", "feelings_fts_update", "feelings_fts_delete"} {
+		if _, err := d.db.Exec(`DROP TRIGGER IF EXISTS ` + name); err != nil {
+			return fmt.Errorf("ошибка отключения индекса поиска: %v", err)
+		}
+	}
+	if _, err := d.db.Exec(`INSERT OR REPLACE INTO settings (key, value) VALUES (?, '1')`, SettingSearchStale); err != nil {
+		return fmt.Errorf("ошибка отключения индекса поиска: %v", err)
+	}
+	log.Println("⚠️ SQLite собран без FTS5: поиск работает перебором, индекс перестроится при запуске с FTS5")
+	return nil
+}
+
+// FullTextSearch true, если поиск идет по индексу FTS5
+func (d *Database) FullTextSearch() bool {
+	return d.fts
+}
+
+// matchExpression запрос FTS5: все слова, каждое как префикс
+func matchExpression(words []string) string {
+	terms := make([]string, len(words))
+	for i, word := range words {
+		terms[i] = `"` + strings.ReplaceAll(word, `"`, `""`) + `"*`
+	}
+	return strings.Join(terms, " ")
+}
+
+// containsWords перебор без индекса: все слова встречаются в тексте без
+// учета регистра. LIKE в SQLite не сравнивает кириллицу без учета регистра.
+func containsWords(text string, words []string) bool {
+	text = strings.ToLower(text)
+	for _, word := range words {
+		if !strings.Contains(text, strings.ToLower(word)) {
+			return false
+		}
+	}
+	return true
+}
+
+// SearchTasks задачи по запросу, сначала самые поздние
+func (r *Repository) SearchTasks(query SearchQuery) ([]DailyTask, error) {
+	conditions := []string{`(? = '' OR date >= ?)`, `(? = '' OR date <= ?)`}
+	args := []interface{}{query.From, query.From, query.To, query.To}
+
+	for _, tag := range query.Tags {
+		conditions = append(conditions, `(',' || tags || ',') LIKE ?`)
+		args = append(args, "%,"+tag+",%")
+	}
+	if len(query.Statuses) > 0 {
+		placeholders := make([]string, len(query.Statuses))
+		for i, status := range query.Statuses {
+			placeholders[i] = "?"
+			args = append(args, status)
+		}
+		conditions = append(conditions, `status IN (`+strings.Join(placeholders, ", ")+`)`)
+	}
+	if len(query.Words) > 0 && r.Db.fts {
+		conditions = append(conditions, `id IN (SELECT rowid FROM tasks_fts WHERE tasks_fts MATCH ?)`)
+		args = append(args, matchExpression(query.Words))
+	}
+
+	rows, err := r.Db.db.Query(`
+		SELECT id, pillar, description, status, time_utc, date, notes, created_at, start_utc, lead_minutes,
+			template_id, carried_from, metric_unit, metric_target, metric_agg, value, goal_id, tags
+		FROM tasks
+		WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY date DESC, time_utc DESC, id DESC
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка поиска задач: %v", err)
+	}
+	defer rows.Close()
+
+	var tasks []DailyTask
+	for rows.Next() {
+		if query.Limit > 0 && len(tasks) >= query.Limit {
+			break
+		}
+
+		var task DailyTask
+		var notes sql.NullString
+		var value sql.NullFloat64
+		err := rows.Scan(&task.ID, &task.Pillar, &task.Description, &task.Status, &task.TimeUTC, &task.Date, &notes,
+			&task.CreatedAt, &task.StartUTC, &task.LeadMinutes, &task.TemplateID, &task.CarriedFrom,
+			&task.MetricUnit, &task.MetricTarget, &task.MetricAgg, &value, &task.GoalID, &task.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка поиска задач: %v", err)
+		}
+		task.Notes = notes.String
+		if !r.Db.fts && !containsWords(task.Description+" "+task.Notes+" "+task.Tags, query.Words) {
+			continue
+		}
+		task.Value = nullFloat(value)
+		task.syncFlags()
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка поиска задач: %v", err)
+	}
+
+	return tasks, r.attachChecklists(tasks)
+}
+
+// SearchFeelings ощущения, в настроении или заметках которых есть все слова,
+// сначала самые поздние
+func (r *Repository) SearchFeelings(query SearchQuery) ([]DailyFeelings, error) {
+	if len(query.Words) == 0 {
+		return nil, nil
+	}
+
+	filter := `1 = 1`
+	args := []interface{}{query.From, query.From, query.To, query.To}
+	if r.Db.fts {
+		filter = `date IN (SELECT date FROM feelings_fts WHERE feelings_fts MATCH ?)`
+		args = append(args, matchExpression(query.Words))
+	}
+
+	rows, err := r.Db.db.Query(`
+		SELECT id, date, COALESCE(energy_level, 0), COALESCE(control_level, 0), COALESCE(sleep_hours, 0), COALESCE(mood, ''), COALESCE(notes, ''), created_at
+		FROM feelings
+		WHERE (? = '' OR date >= ?) AND (? = '' OR date <= ?) AND `+filter+`
+		ORDER BY date DESC
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка поиска ощущений: %v", err)
+	}
+	defer rows.Close()
+
+	var feelings []DailyFeelings
+	for rows.Next() {
+		if query.Limit > 0 && len(feelings) >= query.Limit {
+			break
+		}
+
+		var f DailyFeelings
+		err := rows.Scan(&f.ID, &f.Date, &f.EnergyLevel, &f.ControlLevel, &f.SleepHours, &f.Mood, &f.Notes, &f.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка поиска ощущений: %v", err)
+		}
+		if !r.Db.fts && !containsWords(f.Mood+" "+f.Notes, query.Words) {
+			continue
+		}
+		feelings = append(feelings, f)
+	}
+	return feelings, rows.Err()
+}
+
+// UpdateTaskTags заменяет теги задачи
+func (r *Repository) UpdateTaskTags(taskID int, tags string) error {
+	_, err := r.Db.db.Exec(`UPDATE tasks SET tags = ? WHERE id = ?`, tags, taskID)
+	return err
+}
+
+// UpdateTemplateTags заменяет теги шаблона, новые задачи по нему получат эти теги
+func (r *Repository) UpdateTemplateTags(templateID int, tags string) (bool, error) {
+	result, err := r.Db.db.Exec(`UPDATE task_templates SET tags = ? WHERE id = ?`, tags, templateID)
+	if err != nil {
+		return false, err
+	}
+	updated, err := result.RowsAffected()
+	return updated > 0, err
+}
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Ключи таблицы settings
+const (
+	// SettingDNDUntil режим "не беспокоить" до указанного момента (RFC 3339, UTC)
+	SettingDNDUntil = "dnd_until"
+	// SettingTasksPrepared последняя дата, на которую созданы задачи по шаблонам
+	SettingTasksPrepared = "tasks_prepared"
+	// SettingRolloverAt когда последний раз переносили незакрытые задачи (RFC 3339, UTC)
+	SettingRolloverAt = "rollover_at"
+)
+
+// GetSetting значение настройки, false если она не задана
+func (r *Repository) GetSetting(key string) (string, bool, error) {
+	var value string
+	err := r.Db.db.QueryRow(`SELECT value FROM settings WHERE key = ?`, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+// SetSetting сохраняет настройку
+func (r *Repository) SetSetting(key, value string) error {
+	_, err := r.Db.db.Exec(`
+		INSERT INTO settings (key, value, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at
+	`, key, value, time.Now().UTC())
+	return err
+}
+
+// DeleteSetting удаляет настройку
+func (r *Repository) DeleteSetting(key string) error {
+	_, err := r.Db.db.Exec(`DELETE FROM settings WHERE key = ?`, key)
+	return err
+}
+
+// DeferredNotification уведомление, отложенное на время тихих часов
+type DeferredNotification struct {
+	ID   int
+	Kind string
+	// TaskID задача напоминания, 0 для сводки
+	TaskID    int
+	CreatedAt time.Time
+}
+
+// DeferNotification откладывает уведомление до конца тихих часов
+func (r *Repository) DeferNotification(kind string, taskID int) error {
+	_, err := r.Db.db.Exec(`
+		INSERT INTO deferred_notifications (kind, task_id, created_at) VALUES (?, ?, ?)
+	`, kind, taskID, time.Now().UTC())
+	return err
+}
+
+// GetDeferredNotifications отложенные уведомления по порядку
+func (r *Repository) GetDeferredNotifications() ([]DeferredNotification, error) {
+	rows, err := r.Db.db.Query(`
+		SELECT id, kind, task_id, created_at FROM deferred_notifications ORDER BY id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []DeferredNotification
+	for rows.Next() {
+		var notification DeferredNotification
+		if err := rows.Scan(&notification.ID, &notification.Kind, &notification.TaskID, &notification.CreatedAt); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, notification)
+	}
+	return notifications, rows.Err()
+}
+
+// DeleteDeferredNotifications удаляет отложенные уведомления с ID не больше lastID
+func (r *Repository) DeleteDeferredNotifications(lastID int) error {
+	_, err := r.Db.db.Exec(`DELETE FROM deferred_notifications WHERE id <= ?`, lastID)
+	return err
+}
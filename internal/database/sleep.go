@@ -0,0 +1,123 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// SleepEntry ночь сна. Date - день пробуждения по UTC, к нему же относятся
+// ощущения и сон в feelings.sleep_hours.
+type SleepEntry struct {
+	Date string `json:"date"`
+	// Bedtime и WakeTime отбой и подъем по Москве, как их вводит пользователь: "23:40"
+	Bedtime  string  `json:"bedtime"`
+	WakeTime string  `json:"wake_time"`
+	Hours    float64 `json:"hours"`
+}
+
+// SleepStats режим сна за период
+type SleepStats struct {
+	Nights   int     `json:"nights"`
+	AvgHours float64 `json:"avg_hours"`
+	// AvgBedtime и AvgWake среднее время отбоя и подъема, пусто - нет журнала сна
+	AvgBedtime string `json:"avg_bedtime,omitempty"`
+	AvgWake    string `json:"avg_wake,omitempty"`
+	// MidpointSpread разброс середины сна в минутах (стандартное отклонение):
+	// чем меньше, тем ровнее режим
+	MidpointSpread float64 `json:"midpoint_spread"`
+	// SocialJetLag насколько середина сна в выходные сдвинута относительно
+	// будней, в минутах, nil - не хватает ночей
+	SocialJetLag *float64 `json:"social_jet_lag,omitempty"`
+	// EnergyCorrelation корреляция длительности сна и энергии того же дня,
+	// nil - меньше 5 дней с обоими значениями
+	EnergyCorrelation *float64 `json:"energy_correlation,omitempty"`
+	// EnergyShort и EnergyLong средняя энергия после ночей короче 7 часов и
+	// от 7 часов, nil - таких ночей не было
+	EnergyShort *float64 `json:"energy_short,omitempty"`
+	EnergyLong  *float64 `json:"energy_long,omitempty"`
+}
+
+// saveSleep записывает ночь и переносит длительность в ощущения того же дня
+func saveSleep(tx *sql.Tx, entry SleepEntry) error {
+	_, err := tx.Exec(`
+		INSERT OR REPLACE INTO sleep_log (date, bedtime, wake_time, hours) VALUES (?, ?, ?, ?)
+	`, entry.Date, entry.Bedtime, entry.WakeTime, entry.Hours)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения сна: %v", err)
+	}
+	if _, err := tx.Exec(`UPDATE feelings SET sleep_hours = ? WHERE date = ?`, entry.Hours, entry.Date); err != nil {
+		return fmt.Errorf("ошибка обновления сна в ощущениях: %v", err)
+	}
+	return nil
+}
+
+// SaveSleep записывает ночь, запись за тот же день заменяется
+func (r *Repository) SaveSleep(entry SleepEntry) error {
+	tx, err := r.Db.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := saveSleep(tx, entry); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteSleep удаляет ночь из журнала, сон в ощущениях остается. false - записи не было.
+func (r *Repository) DeleteSleep(date string) (bool, error) {
+	result, err := r.Db.db.Exec(`DELETE FROM sleep_log WHERE date = ?`, date)
+	if err != nil {
+		return false, fmt.Errorf("ошибка удаления сна: %v", err)
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// GetSleepBetween ночи за период включительно по порядку, пустые границы не
+// ограничивают выборку
+func (r *Repository) GetSleepBetween(from, to string) ([]SleepEntry, error) {
+	rows, err := r.Db.db.Query(`
+		SELECT date, bedtime, wake_time, hours
+		FROM sleep_log
+		WHERE (? = '' OR date >= ?) AND (? = '' OR date <= ?)
+		ORDER BY date
+	`, from, from, to, to)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения сна: %v", err)
+	}
+	defer rows.Close()
+
+	var entries []SleepEntry
+	for rows.Next() {
+		var entry SleepEntry
+		if err := rows.Scan(&entry.Date, &entry.Bedtime, &entry.WakeTime, &entry.Hours); err != nil {
+			return nil, fmt.Errorf("ошибка получения сна: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// attachSleep дополняет ощущения ночью из журнала сна
+func (r *Repository) attachSleep(feelings []DailyFeelings, from, to string) error {
+	if len(feelings) == 0 {
+		return nil
+	}
+
+	entries, err := r.GetSleepBetween(from, to)
+	if err != nil {
+		return err
+	}
+	byDate := make(map[string]SleepEntry, len(entries))
+	for _, entry := range entries {
+		byDate[entry.Date] = entry
+	}
+	for i := range feelings {
+		if entry, ok := byDate[feelings[i].Date]; ok {
+			feelings[i].Sleep = &entry
+		}
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TaskTemplate шаблон задачи, по которому каждый день создаются DailyTask
+type TaskTemplate struct {
+	ID          int       `json:"id"`
+	Pillar      Pillar    `json:"pillar"`
+	Description string    `json:"description"`
+	TimeUTC     string    `json:"time_utc"`
+	Notes       string    `json:"notes,omitempty"`
+	Weekdays    string    `json:"weekdays,omitempty"` // ISO дни недели через запятую (1 - пн, 7 - вс), пусто - каждый день
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	StartUTC    string    `json:"start_utc,omitempty"`
+	LeadMinutes string    `json:"lead_minutes,omitempty"`
+	// Rollover что делать с незакрытой задачей в конце дня: carry, miss, ask,
+	// пусто - по настройкам столпа
+	Rollover string `json:"rollover,omitempty"`
+	// Checklist пункты чек-листа задачи, по одному на строку
+	Checklist string `json:"checklist,omitempty"`
+	// MetricUnit, MetricTarget, MetricAgg измеримый результат задачи, см. DailyTask
+	MetricUnit   string  `json:"metric_unit,omitempty"`
+	MetricTarget float64 `json:"metric_target,omitempty"`
+	MetricAgg    string  `json:"metric_agg,omitempty"`
+	// GoalID цель, к которой привязываются задачи шаблона, 0 - без цели
+	GoalID int `json:"goal_id,omitempty"`
+	// Tags теги задач шаблона, см. DailyTask
+	Tags string `json:"tags,omitempty"`
+}
+
+// AppliesTo проверяет, что шаблон действует в указанный день недели
+func (t TaskTemplate) AppliesTo(weekday time.Weekday) bool {
+	if strings.TrimSpace(t.Weekdays) == "" {
+		return true
+	}
+
+	iso := int(weekday)
+	if weekday == time.Sunday {
+		iso = 7
+	}
+
+	for _, part := range strings.Split(t.Weekdays, ",") {
+		if day, err := strconv.Atoi(strings.TrimSpace(part)); err == nil && day == iso {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultTemplates шаблоны, которыми заполняется пустая таблица
+var DefaultTemplates = []TaskTemplate{
+	{Pillar: Energy, Description: "День без алкоголя", TimeUTC: "18:00", Notes: "Вечерний ритуал: кроссовки → активность → контрастный душ",
+		Checklist: "Кроссовки\nАктивность\nКонтрастный душ"},
+	{Pillar: Focus, Description: "Уроки Duolingo", TimeUTC: "06:00", Notes: "Уроки Duolingo",
+		MetricUnit: "уроков", MetricTarget: 3, MetricAgg: "sum"},
+	{Pillar: Body, Description: "Беговая тренировка в 18:30", TimeUTC: "18:30", LeadMinutes: "30,5", Notes: "Ритм 2+1 - инвестиция в энергию", Weekdays: "1,5",
+		MetricUnit: "км", MetricTarget: 5, MetricAgg: "sum"},
+	{Pillar: Body, Description: "Силовая тренировка 18:30", TimeUTC: "18:30", LeadMinutes: "30,5", Notes: "Ритм 2+1 - инвестиция в энергию", Weekdays: "3"},
+	{Pillar: Focus, Description: "Утренний блок 90 мин", TimeUTC: "09:00", Notes: "Самая сложная задача дня", Weekdays: "1,2,3,4,5",
+		MetricUnit: "мин", MetricTarget: 90, MetricAgg: "sum"},
+	{Pillar: Focus, Description: "Вечерний урок", TimeUTC: "18:00", Notes: "вечерний урок 15 мин", Weekdays: "1,2,3,4,5"},
+	{Pillar: Life, Description: "Проверяй смету по кваритре, ищи деньги, подбивай таймлайн конца проекта (2 часа)", TimeUTC: "08:00", Notes: "Одно конкретное действие: замер, выбор, упаковка", Weekdays: "6"},
+	{Pillar: Focus, Description: "Провести чекап ситуации с финансами", TimeUTC: "12:00", Notes: "Всегда имей план на будущее по твоим инвестициям - так спокойнее", Weekdays: "6"},
+	{Pillar: Balance, Description: "Ревью недели + план", TimeUTC: "11:00", Notes: "30 мин: анализ + корректировка плана", Weekdays: "7",
+		Checklist: "Анализ недели\nКорректировка плана"},
+}
+
+// seedTemplates заполняет таблицу шаблонов значениями по умолчанию, если она пуста
+func (d *Database) seedTemplates() error {
+	var count int
+	if err := d.db.QueryRow(`SELECT COUNT(*) FROM task_templates`).Scan(&count); err != nil {
+		return fmt.Errorf("ошибка проверки шаблонов: %v", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	repo := NewRepository(d)
+	for _, template := range DefaultTemplates {
+		template.Active = true
+		if _, err := repo.AddTemplate(template); err != nil {
+			return fmt.Errorf("ошибка добавления шаблона: %v", err)
+		}
+	}
+
+	log.Printf("✅ Добавлены шаблоны задач по умолчанию: %d", len(DefaultTemplates))
+	return nil
+}
+
+// GetTemplates список шаблонов, activeOnly - только действующие
+func (r *Repository) GetTemplates(activeOnly bool) ([]TaskTemplate, error) {
+	rows, err := r.Db.db.Query(`
+		SELECT id, pillar, description, time_utc, notes, weekdays, active, created_at, start_utc, lead_minutes, rollover, checklist,
+			metric_unit, metric_target, metric_agg, goal_id, tags
+		FROM task_templates
+		WHERE (? = 0 OR active = 1)
+		ORDER BY time_utc, id
+	`, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			panic(err)
+		}
+	}(rows)
+
+	var templates []TaskTemplate
+	for rows.Next() {
+		var template TaskTemplate
+		err := rows.Scan(
+			&template.ID,
+			&template.Pillar,
+			&template.Description,
+			&template.TimeUTC,
+			&template.Notes,
+			&template.Weekdays,
+			&template.Active,
+			&template.CreatedAt,
+			&template.StartUTC,
+			&template.LeadMinutes,
+			&template.Rollover,
+			&template.Checklist,
+			&template.MetricUnit,
+			&template.MetricTarget,
+			&template.MetricAgg,
+			&template.GoalID,
+			&template.Tags,
+		)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+
+	return templates, rows.Err()
+}
+
+// AddTemplate добавляет шаблон и возвращает его ID
+func (r *Repository) AddTemplate(template TaskTemplate) (int, error) {
+	tx, err := r.Db.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	id, err := addTemplate(tx, template)
+	if err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+func addTemplate(tx *sql.Tx, template TaskTemplate) (int, error) {
+	result, err := tx.Exec(`
+		INSERT INTO task_templates (pillar, description, time_utc, notes, weekdays, active, start_utc, lead_minutes, rollover,
+			checklist, metric_unit, metric_target, metric_agg, tags)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, template.Pillar, template.Description, template.TimeUTC, template.Notes, template.Weekdays, template.Active,
+		template.StartUTC, template.LeadMinutes, template.Rollover, template.Checklist,
+		template.MetricUnit, template.MetricTarget, template.MetricAgg, template.Tags)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+// TemplateExists проверяет наличие шаблона с тем же столпом, описанием и временем
+func (r *Repository) TemplateExists(pillar Pillar, description, timeUTC string) (bool, error) {
+	var exists bool
+	err := r.Db.db.QueryRow(`
+		SELECT COUNT(*) > 0 FROM task_templates
+		WHERE pillar = ? AND description = ? AND time_utc = ?
+	`, pillar, description, timeUTC).Scan(&exists)
+	return exists, err
+}
+
+// UpdateTemplateRollover меняет правило переноса незакрытых задач шаблона
+func (r *Repository) UpdateTemplateRollover(templateID int, rollover string) (bool, error) {
+	result, err := r.Db.db.Exec(`UPDATE task_templates SET rollover = ? WHERE id = ?`, rollover, templateID)
+	if err != nil {
+		return false, err
+	}
+	updated, err := result.RowsAffected()
+	return updated > 0, err
+}
@@ -0,0 +1,158 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Статусы доставки вебхука
+const (
+	WebhookPending   = "pending"
+	WebhookDelivered = "delivered"
+	WebhookFailed    = "failed"
+)
+
+// WebhookDelivery событие в очереди на отправку во внешний сервис
+type WebhookDelivery struct {
+	ID            int        `json:"id"`
+	URL           string     `json:"url"`
+	Event         string     `json:"event"`
+	Payload       string     `json:"payload"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	LastError     string     `json:"last_error,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+}
+
+// EnqueueWebhook ставит доставку в очередь и возвращает её ID
+func (r *Repository) EnqueueWebhook(url, event, payload string, at time.Time) (int, error) {
+	result, err := r.Db.db.Exec(`
+		INSERT INTO webhook_deliveries (url, event, payload, status, next_attempt_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, url, event, payload, WebhookPending, at.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+// GetDueWebhooks доставки, время попытки которых наступило
+func (r *Repository) GetDueWebhooks(now time.Time, limit int) ([]WebhookDelivery, error) {
+	return r.queryWebhooks(`
+		SELECT id, url, event, payload, status, attempts, next_attempt_at, last_error, created_at, delivered_at
+		FROM webhook_deliveries
+		WHERE status = ? AND next_attempt_at <= ?
+		ORDER BY next_attempt_at, id
+		LIMIT ?
+	`, WebhookPending, now.UTC(), limit)
+}
+
+// GetWebhooksByStatus последние доставки с указанным статусом
+func (r *Repository) GetWebhooksByStatus(status string, limit int) ([]WebhookDelivery, error) {
+	return r.queryWebhooks(`
+		SELECT id, url, event, payload, status, attempts, next_attempt_at, last_error, created_at, delivered_at
+		FROM webhook_deliveries
+		WHERE status = ?
+		ORDER BY id DESC
+		LIMIT ?
+	`, status, limit)
+}
+
+// MarkWebhookDelivered отмечает доставку успешной
+func (r *Repository) MarkWebhookDelivered(id int, attempts int, at time.Time) error {
+	_, err := r.Db.db.Exec(`
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, last_error = '', delivered_at = ?
+		WHERE id = ?
+	`, WebhookDelivered, attempts, at.UTC(), id)
+	return err
+}
+
+// RescheduleWebhook записывает неудачную попытку. Со статусом WebhookFailed
+// доставка больше не повторяется.
+func (r *Repository) RescheduleWebhook(id int, status string, attempts int, next time.Time, lastError string) error {
+	_, err := r.Db.db.Exec(`
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, next_attempt_at = ?, last_error = ?
+		WHERE id = ?
+	`, status, attempts, next.UTC(), lastError, id)
+	return err
+}
+
+// RetryFailedWebhooks возвращает неотправленные доставки в очередь
+func (r *Repository) RetryFailedWebhooks(now time.Time) (int, error) {
+	result, err := r.Db.db.Exec(`
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = 0, next_attempt_at = ?
+		WHERE status = ?
+	`, WebhookPending, now.UTC(), WebhookFailed)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := result.RowsAffected()
+	return int(count), err
+}
+
+// CountWebhooks число доставок по статусам
+func (r *Repository) CountWebhooks() (map[string]int, error) {
+	rows, err := r.Db.db.Query(`SELECT status, COUNT(*) FROM webhook_deliveries GROUP BY status`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		counts[status] = count
+	}
+	return counts, rows.Err()
+}
+
+func (r *Repository) queryWebhooks(query string, args ...interface{}) ([]WebhookDelivery, error) {
+	rows, err := r.Db.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []WebhookDelivery
+	for rows.Next() {
+		var delivery WebhookDelivery
+		var deliveredAt sql.NullTime
+		err := rows.Scan(
+			&delivery.ID,
+			&delivery.URL,
+			&delivery.Event,
+			&delivery.Payload,
+			&delivery.Status,
+			&delivery.Attempts,
+			&delivery.NextAttemptAt,
+			&delivery.LastError,
+			&delivery.CreatedAt,
+			&deliveredAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if deliveredAt.Valid {
+			delivery.DeliveredAt = &deliveredAt.Time
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
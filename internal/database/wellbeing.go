@@ -0,0 +1,393 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// WellbeingType тип показателя самочувствия
+type WellbeingType string
+
+const (
+	// WellbeingScale оценка целым числом в диапазоне, например 1-10
+	WellbeingScale WellbeingType = "scale"
+	// WellbeingNumber число: часы, шаги, килограммы
+	WellbeingNumber WellbeingType = "number"
+	// WellbeingBool да или нет
+	WellbeingBool WellbeingType = "bool"
+	// WellbeingText свободный текст
+	WellbeingText WellbeingType = "text"
+)
+
+// Встроенные показатели хранятся в колонках таблицы feelings, остальные -
+// в feelings_values
+const (
+	WellbeingEnergy  = "energy"
+	WellbeingControl = "control"
+	WellbeingSleep   = "sleep"
+	WellbeingMood    = "mood"
+)
+
+// WellbeingMetric показатель самочувствия из справочника wellbeing_metrics.
+// По нему разбирается /feelings, строится форма ввода и средние в аналитике.
+type WellbeingMetric struct {
+	Key   string        `json:"key"`
+	Name  string        `json:"name"`
+	Emoji string        `json:"emoji"`
+	Type  WellbeingType `json:"type"`
+	// Min и Max допустимые значения scale и number, nil - без ограничения
+	Min  *float64 `json:"min,omitempty"`
+	Max  *float64 `json:"max,omitempty"`
+	Unit string   `json:"unit"`
+	// Aliases дополнительные названия, по которым показатель распознается в /feelings
+	Aliases  []string `json:"aliases"`
+	Position int      `json:"position"`
+	// Archived показатель не спрашивается и не разбирается, старые значения остаются
+	Archived bool `json:"archived"`
+}
+
+// Title эмодзи и название: "😴 Сон"
+func (m WellbeingMetric) Title() string {
+	return m.Emoji + " " + m.Name
+}
+
+// Names ключ, название и синонимы в нижнем регистре
+func (m WellbeingMetric) Names() []string {
+	names := []string{m.Key, strings.ToLower(m.Name)}
+	for _, alias := range m.Aliases {
+		names = append(names, strings.ToLower(alias))
+	}
+	return names
+}
+
+// Builtin показатель хранится в колонке feelings, его тип и диапазон не меняются
+func (m WellbeingMetric) Builtin() bool {
+	switch m.Key {
+	case WellbeingEnergy, WellbeingControl, WellbeingSleep, WellbeingMood:
+		return true
+	}
+	return false
+}
+
+// Numeric значения показателя усредняются в аналитике
+func (m WellbeingMetric) Numeric() bool {
+	return m.Type != WellbeingText
+}
+
+// FormatValue значение для показа: "8/10", "7.5 ч", "да"
+func (m WellbeingMetric) FormatValue(value string) string {
+	switch m.Type {
+	case WellbeingBool:
+		if value == "1" {
+			return "да"
+		}
+		return "нет"
+	case WellbeingScale:
+		if m.Max != nil {
+			return value + "/" + FormatNumber(*m.Max)
+		}
+	}
+	if m.Unit != "" {
+		return value + " " + m.Unit
+	}
+	return value
+}
+
+// FormatNumber число без лишних нулей: 7.5, 10
+func FormatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func limit(value float64) *float64 {
+	return &value
+}
+
+// DefaultWellbeingMetrics показатели, которыми заполняется пустой справочник.
+// Дополнительные показатели добавляются в архиве: их включают, когда нужны.
+var DefaultWellbeingMetrics = []WellbeingMetric{
+	{Key: WellbeingEnergy, Name: "Энергия", Emoji: "⚡", Type: WellbeingScale, Min: limit(1), Max: limit(10)},
+	{Key: WellbeingControl, Name: "Контроль", Emoji: "🎯", Type: WellbeingScale, Min: limit(1), Max: limit(10)},
+	{Key: WellbeingSleep, Name: "Сон", Emoji: "😴", Type: WellbeingNumber, Min: limit(0), Max: limit(24), Unit: "ч"},
+	{Key: WellbeingMood, Name: "Настроение", Emoji: "😊", Type: WellbeingText},
+	{Key: "stress", Name: "Стресс", Emoji: "😣", Type: WellbeingScale, Min: limit(1), Max: limit(10), Archived: true},
+	{Key: "focus", Name: "Фокус", Emoji: "🧠", Type: WellbeingScale, Min: limit(1), Max: limit(10), Archived: true},
+	{Key: "alcohol", Name: "Алкоголь", Emoji: "🍷", Type: WellbeingNumber, Min: limit(0), Max: limit(50), Unit: "ед.", Archived: true},
+	{Key: "steps", Name: "Шаги", Emoji: "👣", Type: WellbeingNumber, Min: limit(0), Max: limit(100000), Archived: true},
+	{Key: "weight", Name: "Вес", Emoji: "⚖️", Type: WellbeingNumber, Min: limit(20), Max: limit(300), Unit: "кг", Archived: true},
+	{Key: "screen", Name: "Экранное время", Emoji: "📱", Type: WellbeingNumber, Min: limit(0), Max: limit(24), Unit: "ч",
+		Aliases: []string{"экран"}, Archived: true},
+}
+
+// WellbeingMetrics все показатели по порядку, archived - вместе с архивными
+func (r *Registry) WellbeingMetrics(archived bool) []WellbeingMetric {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var metrics []WellbeingMetric
+	for _, metric := range r.wellbeing {
+		if archived || !metric.Archived {
+			metrics = append(metrics, metric)
+		}
+	}
+	return metrics
+}
+
+// GetWellbeingMetric показатель по ключу, включая архивные
+func (r *Registry) GetWellbeingMetric(key string) (WellbeingMetric, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, metric := range r.wellbeing {
+		if metric.Key == key {
+			return metric, true
+		}
+	}
+	return WellbeingMetric{}, false
+}
+
+// LookupWellbeingMetric ищет действующий показатель по ключу, названию или синониму
+func (r *Registry) LookupWellbeingMetric(value string) (WellbeingMetric, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	for _, metric := range r.WellbeingMetrics(false) {
+		for _, name := range metric.Names() {
+			if name == value {
+				return metric, true
+			}
+		}
+	}
+	return WellbeingMetric{}, false
+}
+
+// UseWellbeingMetrics заменяет показатели в справочнике, как UsePillars
+func (r *Registry) UseWellbeingMetrics(metrics []WellbeingMetric) {
+	r.mu.Lock()
+	r.wellbeing = metrics
+	r.mu.Unlock()
+}
+
+// Value значение показателя за день строкой, пусто - не записано
+func (f DailyFeelings) Value(key string) string {
+	switch key {
+	case WellbeingEnergy:
+		if f.EnergyLevel > 0 {
+			return strconv.Itoa(f.EnergyLevel)
+		}
+		return ""
+	case WellbeingControl:
+		if f.ControlLevel > 0 {
+			return strconv.Itoa(f.ControlLevel)
+		}
+		return ""
+	case WellbeingSleep:
+		if f.SleepHours > 0 {
+			return FormatNumber(f.SleepHours)
+		}
+		return ""
+	case WellbeingMood:
+		return f.Mood
+	}
+	return f.Values[key]
+}
+
+// seedWellbeingMetrics заполняет пустой справочник показателями по умолчанию
+func (d *Database) seedWellbeingMetrics() error {
+	var count int
+	if err := d.db.QueryRow(`SELECT COUNT(*) FROM wellbeing_metrics`).Scan(&count); err != nil {
+		return fmt.Errorf("ошибка проверки показателей самочувствия: %v", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	repo := NewRepository(d)
+	for i, metric := range DefaultWellbeingMetrics {
+		metric.Position = i + 1
+		if err := repo.AddWellbeingMetric(metric); err != nil {
+			return fmt.Errorf("ошибка добавления показателя самочувствия: %v", err)
+		}
+	}
+
+	log.Printf("✅ Добавлены показатели самочувствия по умолчанию: %d", len(DefaultWellbeingMetrics))
+	return nil
+}
+
+// loadWellbeingMetrics перечитывает справочник показателей в память
+func (d *Database) loadWellbeingMetrics() error {
+	rows, err := d.db.Query(`
+		SELECT key, name, emoji, type, min_value, max_value, unit, aliases, position, archived
+		FROM wellbeing_metrics
+		ORDER BY position, key
+	`)
+	if err != nil {
+		return fmt.Errorf("ошибка загрузки показателей самочувствия: %v", err)
+	}
+	defer rows.Close()
+
+	var metrics []WellbeingMetric
+	for rows.Next() {
+		var metric WellbeingMetric
+		var minValue, maxValue sql.NullFloat64
+		var aliases string
+		err := rows.Scan(&metric.Key, &metric.Name, &metric.Emoji, &metric.Type, &minValue, &maxValue,
+			&metric.Unit, &aliases, &metric.Position, &metric.Archived)
+		if err != nil {
+			return fmt.Errorf("ошибка загрузки показателей самочувствия: %v", err)
+		}
+		metric.Min, metric.Max = nullFloat(minValue), nullFloat(maxValue)
+		metric.Aliases = splitAliases(aliases)
+		metrics = append(metrics, metric)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("ошибка загрузки показателей самочувствия: %v", err)
+	}
+
+	d.registry.UseWellbeingMetrics(metrics)
+	return nil
+}
+
+// AddWellbeingMetric добавляет показатель в справочник. Position 0 - в конец списка.
+func (r *Repository) AddWellbeingMetric(metric WellbeingMetric) error {
+	_, err := r.Db.db.Exec(`
+		INSERT INTO wellbeing_metrics (key, name, emoji, type, min_value, max_value, unit, aliases, position, archived)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, CASE WHEN ? > 0 THEN ? ELSE (SELECT COALESCE(MAX(position), 0) + 1 FROM wellbeing_metrics) END, ?)
+	`, metric.Key, metric.Name, metric.Emoji, metric.Type, metric.Min, metric.Max, metric.Unit,
+		strings.Join(metric.Aliases, ","), metric.Position, metric.Position, metric.Archived)
+	if err != nil {
+		return err
+	}
+	return r.Db.loadWellbeingMetrics()
+}
+
+// UpdateWellbeingMetric сохраняет все поля показателя, кроме ключа. При смене
+// позиции остальные показатели сдвигаются, как в UpdatePillar.
+func (r *Repository) UpdateWellbeingMetric(metric WellbeingMetric) (bool, error) {
+	tx, err := r.Db.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var position int
+	if err := tx.QueryRow(`SELECT position FROM wellbeing_metrics WHERE key = ?`, metric.Key).Scan(&position); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	if err := movePosition(tx, "wellbeing_metrics", position, metric.Position); err != nil {
+		return false, err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE wellbeing_metrics
+		SET name = ?, emoji = ?, type = ?, min_value = ?, max_value = ?, unit = ?, aliases = ?, position = ?, archived = ?
+		WHERE key = ?
+	`, metric.Name, metric.Emoji, metric.Type, metric.Min, metric.Max, metric.Unit,
+		strings.Join(metric.Aliases, ","), metric.Position, metric.Archived, metric.Key)
+	if err != nil {
+		return false, err
+	}
+	if err := renumberPositions(tx, "wellbeing_metrics"); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return true, r.Db.loadWellbeingMetrics()
+}
+
+// saveFeelingsValues заменяет значения дополнительных показателей за день.
+// Для числовых показателей число хранится отдельно, по нему считаются средние.
+func saveFeelingsValues(tx *sql.Tx, registry *Registry, date string, values map[string]string) error {
+	if _, err := tx.Exec(`DELETE FROM feelings_values WHERE date = ?`, date); err != nil {
+		return fmt.Errorf("ошибка сохранения показателей самочувствия: %v", err)
+	}
+	for key, value := range values {
+		var number *float64
+		if metric, ok := registry.GetWellbeingMetric(key); !ok || metric.Numeric() {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				number = &parsed
+			}
+		}
+		_, err := tx.Exec(`INSERT INTO feelings_values (date, metric, value, number) VALUES (?, ?, ?, ?)`,
+			date, key, value, number)
+		if err != nil {
+			return fmt.Errorf("ошибка сохранения показателей самочувствия: %v", err)
+		}
+	}
+	return nil
+}
+
+// attachFeelingsValues дополняет ощущения значениями из feelings_values
+func (r *Repository) attachFeelingsValues(feelings []DailyFeelings, from, to string) error {
+	if len(feelings) == 0 {
+		return nil
+	}
+
+	index := make(map[string]*DailyFeelings, len(feelings))
+	for i := range feelings {
+		index[feelings[i].Date] = &feelings[i]
+	}
+
+	rows, err := r.Db.db.Query(`
+		SELECT date, metric, value FROM feelings_values
+		WHERE (? = '' OR date >= ?) AND (? = '' OR date <= ?)
+	`, from, from, to, to)
+	if err != nil {
+		return fmt.Errorf("ошибка получения показателей самочувствия: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var date, key, value string
+		if err := rows.Scan(&date, &key, &value); err != nil {
+			return fmt.Errorf("ошибка получения показателей самочувствия: %v", err)
+		}
+		if f, ok := index[date]; ok {
+			if f.Values == nil {
+				f.Values = make(map[string]string)
+			}
+			f.Values[key] = value
+		}
+	}
+	return rows.Err()
+}
+
+// wellbeingAverages средние числовых показателей за период: встроенные из
+// колонок feelings, дополнительные из feelings_values. Для bool среднее -
+// доля дней с ответом «да».
+func (r *Repository) wellbeingAverages(from, to string) (map[string]float64, error) {
+	rows, err := r.Db.db.Query(`
+		SELECT 'energy', AVG(energy_level) FROM feelings WHERE date BETWEEN ? AND ?
+		UNION ALL
+		SELECT 'control', AVG(control_level) FROM feelings WHERE date BETWEEN ? AND ?
+		UNION ALL
+		SELECT 'sleep', AVG(sleep_hours) FROM feelings WHERE date BETWEEN ? AND ? AND sleep_hours > 0
+		UNION ALL
+		SELECT metric, AVG(number) FROM feelings_values
+		WHERE date BETWEEN ? AND ? AND number IS NOT NULL
+		GROUP BY metric
+	`, from, to, from, to, from, to, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка расчета средних показателей: %v", err)
+	}
+	defer rows.Close()
+
+	averages := make(map[string]float64)
+	for rows.Next() {
+		var key string
+		var avg sql.NullFloat64
+		if err := rows.Scan(&key, &avg); err != nil {
+			return nil, fmt.Errorf("ошибка расчета средних показателей: %v", err)
+		}
+		if avg.Valid {
+			averages[key] = avg.Float64
+		}
+	}
+	return averages, rows.Err()
+}
@@ -0,0 +1,383 @@
+// Package export - выгрузка истории трекера в CSV, JSON и Markdown.
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"five-pillars/internal/database"
+	"five-pillars/internal/services"
+)
+
+type Format string
+
+const (
+	CSV      Format = "csv"
+	JSON     Format = "json"
+	Markdown Format = "md"
+)
+
+// Наборы данных для CSV: каждый выгружается отдельной таблицей
+const (
+	DatasetTasks       = "tasks"
+	DatasetFeelings    = "feelings"
+	DatasetSkipReasons = "skip_reasons"
+	DatasetAnalytics   = "analytics"
+	DatasetJournal     = "journal"
+	DatasetSleep       = "sleep"
+)
+
+// Datasets наборы данных CSV в порядке выгрузки
+var Datasets = []string{DatasetTasks, DatasetFeelings, DatasetJournal, DatasetSleep, DatasetSkipReasons, DatasetAnalytics}
+
+// File готовый файл выгрузки
+type File struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// ParseFormat распознает формат выгрузки
+func ParseFormat(value string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "csv":
+		return CSV, nil
+	case "json", "":
+		return JSON, nil
+	case "md", "markdown":
+		return Markdown, nil
+	default:
+		return "", services.NewValidationError("Неизвестный формат выгрузки. Используйте: csv, json, md")
+	}
+}
+
+// Render формирует файл выгрузки. dataset учитывается только для CSV,
+// JSON и Markdown всегда содержат все данные.
+func Render(registry *database.Registry, snapshot *database.Snapshot, format Format, dataset string) (*File, error) {
+	switch format {
+	case JSON:
+		data, err := json.MarshalIndent(snapshot, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return &File{Name: fileName(snapshot, "", "json"), ContentType: "application/json", Data: data}, nil
+	case Markdown:
+		return &File{Name: fileName(snapshot, "journal", "md"), ContentType: "text/markdown; charset=utf-8", Data: renderMarkdown(registry, snapshot)}, nil
+	case CSV:
+		if dataset == "" {
+			dataset = DatasetTasks
+		}
+		data, err := renderCSV(registry, snapshot, dataset)
+		if err != nil {
+			return nil, err
+		}
+		return &File{Name: fileName(snapshot, dataset, "csv"), ContentType: "text/csv; charset=utf-8", Data: data}, nil
+	default:
+		return nil, fmt.Errorf("неизвестный формат выгрузки: %s", format)
+	}
+}
+
+// RenderAll формирует все файлы формата: для CSV - по файлу на набор данных
+func RenderAll(registry *database.Registry, snapshot *database.Snapshot, format Format) ([]*File, error) {
+	if format != CSV {
+		file, err := Render(registry, snapshot, format, "")
+		if err != nil {
+			return nil, err
+		}
+		return []*File{file}, nil
+	}
+
+	var files []*File
+	for _, dataset := range Datasets {
+		file, err := Render(registry, snapshot, format, dataset)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+func fileName(snapshot *database.Snapshot, dataset, ext string) string {
+	name := "five-pillars"
+	if dataset != "" {
+		name += "-" + dataset
+	}
+	if snapshot.From != "" || snapshot.To != "" {
+		name += "_" + periodName(snapshot.From, "start") + "_" + periodName(snapshot.To, "now")
+	}
+	return name + "." + ext
+}
+
+func periodName(date, fallback string) string {
+	if date == "" {
+		return fallback
+	}
+	return date
+}
+
+func renderCSV(registry *database.Registry, snapshot *database.Snapshot, dataset string) ([]byte, error) {
+	var rows [][]string
+
+	switch dataset {
+	case DatasetTasks:
+		rows = append(rows, []string{"id", "date", "time_utc", "pillar", "description", "status", "completed", "skipped", "skip_reason", "notes", "start_utc", "lead_minutes", "value", "metric_unit", "metric_target", "metric_agg", "tags"})
+		for _, task := range snapshot.Tasks {
+			rows = append(rows, []string{
+				strconv.Itoa(task.ID),
+				task.Date,
+				task.TimeUTC,
+				string(task.Pillar),
+				task.Description,
+				string(task.Status),
+				strconv.FormatBool(task.Completed),
+				strconv.FormatBool(task.Skipped),
+				services.SkipReasonCode(task),
+				task.Notes,
+				task.StartUTC,
+				task.LeadMinutes,
+				formatValue(task.Value),
+				task.MetricUnit,
+				strconv.FormatFloat(task.MetricTarget, 'f', -1, 64),
+				task.MetricAgg,
+				task.Tags,
+			})
+		}
+	case DatasetFeelings:
+		// Дополнительные показатели самочувствия - по колонке на ключ справочника
+		header := []string{"date", "energy_level", "control_level", "sleep_hours", "mood", "notes"}
+		var extra []string
+		for _, metric := range registry.WellbeingMetrics(true) {
+			if !metric.Builtin() {
+				extra = append(extra, metric.Key)
+			}
+		}
+		rows = append(rows, append(header, extra...))
+		for _, feelings := range snapshot.Feelings {
+			row := []string{
+				feelings.Date,
+				strconv.Itoa(feelings.EnergyLevel),
+				strconv.Itoa(feelings.ControlLevel),
+				strconv.FormatFloat(feelings.SleepHours, 'f', -1, 64),
+				feelings.Mood,
+				feelings.Notes,
+			}
+			for _, key := range extra {
+				row = append(row, feelings.Values[key])
+			}
+			rows = append(rows, row)
+		}
+	case DatasetJournal:
+		rows = append(rows, []string{"id", "date", "created_at", "text", "photo_id"})
+		for _, entry := range snapshot.Journal {
+			rows = append(rows, []string{
+				strconv.Itoa(entry.ID),
+				entry.Date,
+				entry.CreatedAt.UTC().Format(time.RFC3339),
+				entry.Text,
+				entry.PhotoID,
+			})
+		}
+	case DatasetSleep:
+		rows = append(rows, []string{"date", "bedtime", "wake_time", "hours"})
+		for _, entry := range snapshot.Sleep {
+			rows = append(rows, []string{
+				entry.Date,
+				entry.Bedtime,
+				entry.WakeTime,
+				strconv.FormatFloat(entry.Hours, 'f', -1, 64),
+			})
+		}
+	case DatasetSkipReasons:
+		rows = append(rows, []string{"code", "text", "count"})
+		for _, reason := range snapshot.SkipReasons {
+			rows = append(rows, []string{reason.Code, reason.Text, strconv.Itoa(reason.Count)})
+		}
+	case DatasetAnalytics:
+		rows = append(rows, []string{"pillar", "total", "completed", "skipped", "missed", "open", "partial", "completion_rate", "progress_rate"})
+		if analytics := snapshot.Analytics; analytics != nil {
+			for _, pillar := range database.SortedPillars(registry, analytics.PillarStats) {
+				stats := analytics.PillarStats[pillar]
+				rows = append(rows, []string{
+					pillar,
+					strconv.Itoa(stats.Total),
+					strconv.Itoa(stats.Completed),
+					strconv.Itoa(stats.Skipped),
+					strconv.Itoa(stats.Missed),
+					strconv.Itoa(stats.Open),
+					strconv.Itoa(stats.Partial),
+					strconv.FormatFloat(stats.CompletionRate(), 'f', 1, 64),
+					strconv.FormatFloat(stats.ProgressRate(), 'f', 1, 64),
+				})
+			}
+			rows = append(rows, []string{
+				"total",
+				strconv.Itoa(analytics.TotalTasks),
+				strconv.Itoa(analytics.TotalDone),
+				strconv.Itoa(analytics.TotalSkipped),
+				strconv.Itoa(analytics.TotalMissed),
+				strconv.Itoa(analytics.TotalOpen),
+				strconv.Itoa(analytics.TotalPartial),
+				strconv.FormatFloat(analytics.CompletionRate(), 'f', 1, 64),
+				strconv.FormatFloat(analytics.ProgressRate(), 'f', 1, 64),
+			})
+		}
+	default:
+		return nil, services.NewValidationError("Неизвестный набор данных. Используйте: " + strings.Join(Datasets, ", "))
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// renderMarkdown выгрузка в стиле дневника: по дню на раздел
+func renderMarkdown(registry *database.Registry, snapshot *database.Snapshot) []byte {
+	var b strings.Builder
+
+	b.WriteString("# 🎯 5 Столпов - дневник")
+	if snapshot.From != "" || snapshot.To != "" {
+		b.WriteString(fmt.Sprintf(": %s — %s", periodName(snapshot.From, "начало"), periodName(snapshot.To, "сегодня")))
+	}
+	b.WriteString("\n\n")
+
+	tasksByDate := make(map[string][]database.DailyTask)
+	feelingsByDate := make(map[string]database.DailyFeelings)
+	journalByDate := make(map[string][]database.JournalEntry)
+	dateSet := make(map[string]bool)
+	for _, task := range snapshot.Tasks {
+		tasksByDate[task.Date] = append(tasksByDate[task.Date], task)
+		dateSet[task.Date] = true
+	}
+	for _, feelings := range snapshot.Feelings {
+		feelingsByDate[feelings.Date] = feelings
+		dateSet[feelings.Date] = true
+	}
+	for _, entry := range snapshot.Journal {
+		journalByDate[entry.Date] = append(journalByDate[entry.Date], entry)
+		dateSet[entry.Date] = true
+	}
+
+	dates := make([]string, 0, len(dateSet))
+	for date := range dateSet {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	for _, date := range dates {
+		b.WriteString(fmt.Sprintf("## %s\n\n", date))
+
+		if tasks := tasksByDate[date]; len(tasks) > 0 {
+			b.WriteString("### Задачи\n\n")
+			for _, task := range tasks {
+				mark := " "
+				switch task.Status {
+				case database.StatusDone:
+					mark = "x"
+				case database.StatusSkipped, database.StatusMissed, database.StatusCancelled:
+					mark = "-"
+				case database.StatusRescheduled:
+					mark = ">"
+				}
+				b.WriteString(fmt.Sprintf("- [%s] %s — %s (%s UTC)", mark, registry.PillarName(task.Pillar), task.Description, task.TimeUTC))
+				if value := services.FormatTaskValue(task); value != "" {
+					b.WriteString(" — " + value)
+				}
+				if reason := services.SkipReasonText(task); reason != "" {
+					b.WriteString(fmt.Sprintf(" — пропущено: %s", reason))
+				}
+				if tags := services.FormatTags(task.Tags); tags != "" {
+					b.WriteString(" " + tags)
+				}
+				b.WriteString("\n")
+				for _, item := range task.Checklist {
+					itemMark := " "
+					if item.Done {
+						itemMark = "x"
+					}
+					b.WriteString(fmt.Sprintf("  - [%s] %s\n", itemMark, item.Text))
+				}
+			}
+			b.WriteString("\n")
+		}
+
+		if feelings, ok := feelingsByDate[date]; ok {
+			b.WriteString("### Ощущения\n\n")
+			for _, line := range services.FeelingsLines(registry, feelings) {
+				b.WriteString("- " + line + "\n")
+			}
+			// Заметки дня с дневником - это его текст, он выводится ниже
+			if feelings.Notes != "" && len(journalByDate[date]) == 0 {
+				b.WriteString(fmt.Sprintf("\n%s\n", feelings.Notes))
+			}
+			b.WriteString("\n")
+		}
+
+		if entries := journalByDate[date]; len(entries) > 0 {
+			b.WriteString("### Дневник\n\n")
+			for _, entry := range entries {
+				if entry.PhotoID != "" {
+					b.WriteString("📷 Фото")
+					if entry.Text != "" {
+						b.WriteString(": ")
+					}
+				}
+				b.WriteString(entry.Text + "\n\n")
+			}
+		}
+	}
+
+	if analytics := snapshot.Analytics; analytics != nil && analytics.TotalTasks > 0 {
+		b.WriteString("## 📈 Итоги периода\n\n")
+		b.WriteString(fmt.Sprintf("✅ Выполнено: %d/%d (%.0f%%), пропущено: %d, не сделано: %d\n\n",
+			analytics.TotalDone, analytics.Resolved(), analytics.CompletionRate(), analytics.TotalSkipped, analytics.TotalMissed))
+		for _, pillar := range database.SortedPillars(registry, analytics.PillarStats) {
+			stats := analytics.PillarStats[pillar]
+			b.WriteString(fmt.Sprintf("- %s: %d/%d (%.0f%%), пропущено: %d\n",
+				registry.PillarName(database.Pillar(pillar)), stats.Completed, stats.Resolved(), stats.CompletionRate(), stats.Skipped))
+		}
+		b.WriteString("\n")
+		if len(analytics.Metrics) > 0 {
+			b.WriteString("### 📏 Измеримые цели\n\n")
+			for _, line := range strings.Split(strings.TrimRight(services.FormatMetrics(registry, analytics.Metrics), "\n"), "\n") {
+				b.WriteString("- " + line + "\n")
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if analytics := snapshot.Analytics; analytics != nil && analytics.Sleep != nil {
+		b.WriteString("## 😴 Сон\n\n")
+		for _, line := range strings.Split(strings.TrimRight(services.FormatSleepStats(analytics.Sleep), "\n"), "\n") {
+			b.WriteString("- " + line + "\n")
+		}
+		b.WriteString("\n")
+	}
+
+	if len(snapshot.SkipReasons) > 0 {
+		b.WriteString("## 📝 Причины пропусков\n\n")
+		for _, reason := range snapshot.SkipReasons {
+			b.WriteString(fmt.Sprintf("- %s: %d\n", reason.Text, reason.Count))
+		}
+		b.WriteString("\n")
+	}
+
+	return []byte(b.String())
+}
+
+// formatValue результат задачи для CSV, пустая строка если его нет
+func formatValue(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
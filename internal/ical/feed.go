@@ -0,0 +1,143 @@
+// Package ical - календарь задач в формате iCalendar (RFC 5545).
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"five-pillars/internal/database"
+	"five-pillars/internal/services"
+)
+
+const (
+	// EventDuration длительность события задачи в календаре
+	EventDuration = 30 * time.Minute
+
+	prodID    = "-//five-pillars//tasks//RU"
+	stampTime = "20060102T150405Z"
+)
+
+// Window период ленты: дни до и после текущей даты
+type Window struct {
+	PastDays   int
+	FutureDays int
+}
+
+// Range границы окна относительно now в формате дат задач
+func (w Window) Range(now time.Time) (string, string) {
+	now = now.UTC()
+	from := now.AddDate(0, 0, -w.PastDays).Format("2006-01-02")
+	to := now.AddDate(0, 0, w.FutureDays).Format("2006-01-02")
+	return from, to
+}
+
+// Render календарь с событием на каждую задачу
+func Render(registry *database.Registry, tasks []database.DailyTask, name string, now time.Time) []byte {
+	var buf bytes.Buffer
+	w := &writer{buf: &buf}
+
+	w.line("BEGIN:VCALENDAR")
+	w.line("VERSION:2.0")
+	w.line("PRODID:" + prodID)
+	w.line("CALSCALE:GREGORIAN")
+	w.line("METHOD:PUBLISH")
+	w.line("X-WR-CALNAME:" + escape(name))
+	w.line("X-WR-TIMEZONE:UTC")
+	w.line("REFRESH-INTERVAL;VALUE=DURATION:PT1H")
+	w.line("X-PUBLISHED-TTL:PT1H")
+
+	stamp := now.UTC().Format(stampTime)
+	for _, task := range tasks {
+		start, err := time.Parse("2006-01-02 15:04", task.Date+" "+task.TimeUTC)
+		if err != nil {
+			continue
+		}
+
+		w.line("BEGIN:VEVENT")
+		w.line(fmt.Sprintf("UID:task-%d@five-pillars", task.ID))
+		w.line("DTSTAMP:" + stamp)
+		w.line("DTSTART:" + start.Format(stampTime))
+		w.line("DTEND:" + start.Add(EventDuration).Format(stampTime))
+		w.line("SUMMARY:" + escape(summary(registry, task)))
+		w.line("DESCRIPTION:" + escape(description(registry, task)))
+		w.line("CATEGORIES:" + escape(string(task.Pillar)))
+		if task.Status == database.StatusSkipped || task.Status == database.StatusMissed || task.Status == database.StatusCancelled {
+			w.line("STATUS:CANCELLED")
+		} else {
+			w.line("STATUS:CONFIRMED")
+		}
+		w.line("TRANSP:TRANSPARENT")
+		w.line("END:VEVENT")
+	}
+
+	w.line("END:VCALENDAR")
+	return buf.Bytes()
+}
+
+// summary заголовок события: статус, эмодзи столпа и описание
+func summary(registry *database.Registry, task database.DailyTask) string {
+	emoji := registry.PillarEmoji(task.Pillar)
+	switch task.Status {
+	case database.StatusDone:
+		return "✅ " + emoji + " " + task.Description
+	case database.StatusSkipped, database.StatusMissed:
+		return "⏭ " + emoji + " " + task.Description
+	case database.StatusCancelled, database.StatusRescheduled:
+		return "✖️ " + emoji + " " + task.Description
+	default:
+		return emoji + " " + task.Description
+	}
+}
+
+func description(registry *database.Registry, task database.DailyTask) string {
+	lines := []string{
+		"Столп: " + registry.PillarName(task.Pillar),
+		"Статус: " + status(task),
+	}
+	if task.Notes != "" && task.Status != database.StatusSkipped {
+		lines = append(lines, "", task.Notes)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func status(task database.DailyTask) string {
+	if reason := services.SkipReasonText(task); reason != "" {
+		return services.StatusNames[task.Status] + " (" + reason + ")"
+	}
+	return services.StatusNames[task.Status]
+}
+
+// escape экранирует TEXT-значение по RFC 5545
+func escape(value string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(value)
+}
+
+// writer пишет строки содержимого с CRLF и переносом длинных строк
+type writer struct {
+	buf *bytes.Buffer
+}
+
+// line переносит строку по 75 байт, не разрывая символы UTF-8
+func (w *writer) line(content string) {
+	const limit = 75
+
+	width := 0
+	for _, r := range content {
+		size := len(string(r))
+		if width+size > limit {
+			w.buf.WriteString("\r\n ")
+			width = 1
+		}
+		w.buf.WriteRune(r)
+		width += size
+	}
+	w.buf.WriteString("\r\n")
+}
@@ -0,0 +1,213 @@
+package ical
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"five-pillars/internal/database"
+	"five-pillars/internal/services"
+)
+
+const (
+	// DefaultRulesSpec ключевые слова по умолчанию для выбора столпа
+	DefaultRulesSpec = "body=зал,бег,тренировк,йога,бассейн,спорт;" +
+		"focus=курс,лекци,урок,учеб,чтени;" +
+		"energy=сон,медитац,отдых;" +
+		"life=уборк,покупк,ремонт;" +
+		"balance=прогулк,друз,семь"
+
+	// AllDayTimeUTC время задачи для событий на весь день (09:00 МСК)
+	AllDayTimeUTC = "06:00"
+
+	calendarNote = "📅 Из календаря"
+)
+
+// KeywordRule столп для событий, в названии или категориях которых есть слово
+type KeywordRule struct {
+	Pillar   database.Pillar
+	Keywords []string
+}
+
+// Rules выбор столпа для события: сначала категории, совпадающие со столпом,
+// затем ключевые слова по порядку, затем Default (если задан)
+type Rules struct {
+	Keywords []KeywordRule
+	Default  database.Pillar
+	// registry справочник, по которому категории событий узнаются как столпы
+	registry *database.Registry
+}
+
+// ParseRules разбирает правила вида "body=зал,бег;focus=курс", пустая строка -
+// DefaultRulesSpec. Столп можно указать любым названием, которое понимает /add.
+func ParseRules(registry *database.Registry, spec, defaultPillar string) (Rules, error) {
+	if strings.TrimSpace(spec) == "" {
+		spec = DefaultRulesSpec
+	}
+
+	rules := Rules{registry: registry}
+
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, words, ok := strings.Cut(part, "=")
+		if !ok {
+			return Rules{}, fmt.Errorf("неверное правило календаря %q, ожидается столп=слово,слово", part)
+		}
+		pillar, err := services.ParsePillar(registry, strings.TrimSpace(name))
+		if err != nil {
+			return Rules{}, fmt.Errorf("неверное правило календаря %q: %v", part, err)
+		}
+
+		rule := KeywordRule{Pillar: pillar}
+		for _, word := range strings.Split(words, ",") {
+			if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+				rule.Keywords = append(rule.Keywords, word)
+			}
+		}
+		rules.Keywords = append(rules.Keywords, rule)
+	}
+
+	if defaultPillar != "" {
+		pillar, err := services.ParsePillar(registry, defaultPillar)
+		if err != nil {
+			return Rules{}, fmt.Errorf("неверный столп по умолчанию для календаря: %v", err)
+		}
+		rules.Default = pillar
+	}
+
+	return rules, nil
+}
+
+// Pillar выбирает столп для события
+func (r Rules) Pillar(event Event) (database.Pillar, bool) {
+	for _, category := range event.Categories {
+		if pillar, err := services.ParsePillar(r.registry, category); err == nil {
+			return pillar, true
+		}
+	}
+
+	text := strings.ToLower(event.Summary + " " + strings.Join(event.Categories, " "))
+	for _, rule := range r.Keywords {
+		for _, word := range rule.Keywords {
+			if strings.Contains(text, word) {
+				return rule.Pillar, true
+			}
+		}
+	}
+
+	if r.Default != "" {
+		return r.Default, true
+	}
+	return "", false
+}
+
+// ImportOptions настройки превращения событий в задачи
+type ImportOptions struct {
+	Rules Rules
+	// From, To даты YYYY-MM-DD: повторения разворачиваются только в этом периоде
+	From string
+	To   string
+	// Location пояс для времени без указания пояса и событий на весь день
+	Location *time.Location
+}
+
+// Batch превращает события календаря в задачи для TransferService.Import.
+// Номер строки в ошибках - строка BEGIN:VEVENT события.
+func Batch(data []byte, opts ImportOptions) (*services.ImportBatch, error) {
+	location := opts.Location
+	if location == nil {
+		location = time.UTC
+	}
+
+	from, err := time.ParseInLocation("2006-01-02", opts.From, location)
+	if err != nil {
+		return nil, services.ErrInvalidDate
+	}
+	to, err := time.ParseInLocation("2006-01-02", opts.To, location)
+	if err != nil {
+		return nil, services.ErrInvalidDate
+	}
+	// Конец периода включительно
+	to = to.AddDate(0, 0, 1).Add(-time.Second)
+
+	events, err := Parse(data, location)
+	if err != nil {
+		return nil, services.NewValidationError(fmt.Sprintf("Ошибка разбора календаря: %v", err))
+	}
+
+	// Измененные повторения описаны отдельными событиями с RECURRENCE-ID,
+	// в основном событии эти даты нужно исключить
+	overrides := make(map[string][]time.Time)
+	for _, event := range events {
+		if event.Err == nil && event.RecurrenceID != nil {
+			overrides[event.UID] = append(overrides[event.UID], *event.RecurrenceID)
+		}
+	}
+
+	batch := &services.ImportBatch{}
+	for _, event := range events {
+		issue := func(message string) {
+			batch.Issues = append(batch.Issues, services.ImportIssue{
+				Dataset: services.DatasetTasks,
+				Row:     event.Line,
+				Message: message,
+			})
+		}
+
+		if event.Err != nil {
+			issue(fmt.Sprintf("Событие %q: %v", event.Summary, event.Err))
+			continue
+		}
+		if event.Cancelled {
+			continue
+		}
+		if strings.TrimSpace(event.Summary) == "" {
+			issue("Событие без названия")
+			continue
+		}
+
+		pillar, ok := opts.Rules.Pillar(event)
+		if !ok {
+			issue(fmt.Sprintf("Не удалось определить столп для %q: добавьте категорию или ключевое слово в правила", event.Summary))
+			continue
+		}
+
+		if event.RecurrenceID == nil {
+			event.ExDates = append(event.ExDates, overrides[event.UID]...)
+		}
+
+		for _, start := range event.Occurrences(from, to) {
+			batch.Tasks = append(batch.Tasks, services.ImportTaskRow{
+				Row:   event.Line,
+				Value: eventTask(event, pillar, start),
+			})
+		}
+	}
+
+	return batch, nil
+}
+
+func eventTask(event Event, pillar database.Pillar, start time.Time) database.DailyTask {
+	task := database.DailyTask{
+		Pillar:      pillar,
+		Description: strings.TrimSpace(event.Summary),
+		Notes:       calendarNote,
+	}
+
+	if event.AllDay {
+		task.Date = start.Format("2006-01-02")
+		task.TimeUTC = AllDayTimeUTC
+	} else {
+		task.Date = start.UTC().Format("2006-01-02")
+		task.TimeUTC = start.UTC().Format("15:04")
+	}
+
+	if description := strings.TrimSpace(event.Description); description != "" {
+		task.Notes = calendarNote + ": " + description
+	}
+	return task
+}
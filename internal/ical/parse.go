@@ -0,0 +1,252 @@
+package ical
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Event событие календаря, нужное для создания задач
+type Event struct {
+	// Line строка BEGIN:VEVENT в файле, для сообщений об ошибках
+	Line        int
+	UID         string
+	Summary     string
+	Description string
+	Categories  []string
+	// Start начало в часовом поясе события, для повторений важен именно он
+	Start     time.Time
+	AllDay    bool
+	Cancelled bool
+	RRule     *RRule
+	ExDates   []time.Time
+	// RecurrenceID заменяемое повторение основного события с тем же UID
+	RecurrenceID *time.Time
+	// Err событие не удалось разобрать, остальные поля могут быть неполными
+	Err error
+}
+
+// property строка содержимого: ИМЯ;ПАРАМЕТРЫ:ЗНАЧЕНИЕ
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Parse читает события из файла iCalendar. Ошибки отдельных событий
+// возвращаются в Event.Err, ошибка функции - только если это не календарь.
+func Parse(data []byte, defaultLocation *time.Location) ([]Event, error) {
+	lines := unfold(data)
+	if len(lines) == 0 || !strings.EqualFold(strings.TrimSpace(lines[0].text), "BEGIN:VCALENDAR") {
+		return nil, fmt.Errorf("файл не похож на календарь iCalendar")
+	}
+
+	var events []Event
+	var current *Event
+	depth := 0 // вложенные блоки внутри VEVENT (VALARM)
+
+	for _, line := range lines {
+		prop, ok := parseProperty(line.text)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case prop.name == "BEGIN" && strings.EqualFold(prop.value, "VEVENT"):
+			current = &Event{Line: line.number}
+			depth = 0
+			continue
+		case current == nil:
+			continue
+		case prop.name == "BEGIN":
+			depth++
+			continue
+		case prop.name == "END" && strings.EqualFold(prop.value, "VEVENT"):
+			if current.Err == nil && current.Start.IsZero() {
+				current.Err = fmt.Errorf("нет даты начала (DTSTART)")
+			}
+			events = append(events, *current)
+			current = nil
+			continue
+		case prop.name == "END":
+			depth--
+			continue
+		case depth > 0:
+			continue
+		}
+
+		if current.Err != nil {
+			continue
+		}
+		current.Err = current.apply(prop, defaultLocation)
+	}
+
+	return events, nil
+}
+
+func (e *Event) apply(prop property, defaultLocation *time.Location) error {
+	switch prop.name {
+	case "UID":
+		e.UID = prop.value
+	case "SUMMARY":
+		e.Summary = unescape(prop.value)
+	case "DESCRIPTION":
+		e.Description = unescape(prop.value)
+	case "CATEGORIES":
+		for _, category := range splitList(prop.value) {
+			if category = strings.TrimSpace(unescape(category)); category != "" {
+				e.Categories = append(e.Categories, category)
+			}
+		}
+	case "STATUS":
+		e.Cancelled = strings.EqualFold(prop.value, "CANCELLED")
+	case "DTSTART":
+		start, allDay, err := parseDateTime(prop, defaultLocation)
+		if err != nil {
+			return fmt.Errorf("неверный DTSTART: %s", prop.value)
+		}
+		e.Start, e.AllDay = start, allDay
+	case "RRULE":
+		rule, err := ParseRRule(prop.value, defaultLocation)
+		if err != nil {
+			return err
+		}
+		e.RRule = rule
+	case "EXDATE":
+		for _, value := range strings.Split(prop.value, ",") {
+			exdate, _, err := parseDateTime(property{params: prop.params, value: value}, defaultLocation)
+			if err != nil {
+				return fmt.Errorf("неверный EXDATE: %s", value)
+			}
+			e.ExDates = append(e.ExDates, exdate)
+		}
+	case "RECURRENCE-ID":
+		id, _, err := parseDateTime(prop, defaultLocation)
+		if err != nil {
+			return fmt.Errorf("неверный RECURRENCE-ID: %s", prop.value)
+		}
+		e.RecurrenceID = &id
+	}
+	return nil
+}
+
+type contentLine struct {
+	number int
+	text   string
+}
+
+// unfold склеивает перенесенные строки (продолжение начинается с пробела или табуляции)
+func unfold(data []byte) []contentLine {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	var lines []contentLine
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	number := 0
+	for scanner.Scan() {
+		number++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1].text += text[1:]
+			continue
+		}
+		if text != "" {
+			lines = append(lines, contentLine{number: number, text: text})
+		}
+	}
+	return lines
+}
+
+// parseProperty разбирает строку содержимого, учитывая кавычки в параметрах
+func parseProperty(line string) (property, bool) {
+	inQuotes := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			inQuotes = !inQuotes
+		}
+		if r == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+	if colon <= 0 {
+		return property{}, false
+	}
+
+	head := strings.Split(line[:colon], ";")
+	prop := property{
+		name:   strings.ToUpper(head[0]),
+		params: make(map[string]string),
+		value:  line[colon+1:],
+	}
+	for _, param := range head[1:] {
+		if key, value, ok := strings.Cut(param, "="); ok {
+			prop.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+		}
+	}
+	return prop, true
+}
+
+// parseDateTime понимает UTC (…Z), TZID=…, плавающее время и даты VALUE=DATE.
+// Плавающее время и неизвестные пояса считаются в defaultLocation.
+func parseDateTime(prop property, defaultLocation *time.Location) (time.Time, bool, error) {
+	value := strings.TrimSpace(prop.value)
+
+	if prop.params["VALUE"] == "DATE" || len(value) == 8 {
+		date, err := time.ParseInLocation("20060102", value, defaultLocation)
+		return date, true, err
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+
+	location := defaultLocation
+	if tzid := prop.params["TZID"]; tzid != "" {
+		if loaded, err := time.LoadLocation(tzid); err == nil {
+			location = loaded
+		}
+	}
+
+	t, err := time.ParseInLocation("20060102T150405", value, location)
+	return t, false, err
+}
+
+// splitList делит значение по запятым, не трогая экранированные \,
+func splitList(value string) []string {
+	var parts []string
+	var current strings.Builder
+	escaped := false
+	for _, r := range value {
+		switch {
+		case escaped:
+			current.WriteRune('\\')
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ',':
+			parts = append(parts, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	return append(parts, current.String())
+}
+
+// unescape обратное к escape
+func unescape(value string) string {
+	return strings.NewReplacer(
+		`\\`, `\`,
+		`\;`, ";",
+		`\,`, ",",
+		`\n`, "\n",
+		`\N`, "\n",
+	).Replace(value)
+}
@@ -0,0 +1,197 @@
+package ical
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxIterations защита от бесконечных правил при развороте
+const maxIterations = 100000
+
+var rruleWeekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// RRule поддерживаемое подмножество правил повторения: FREQ DAILY, WEEKLY,
+// MONTHLY, YEARLY с INTERVAL, COUNT, UNTIL и BYDAY для WEEKLY
+type RRule struct {
+	Freq     string
+	Interval int
+	Count    int
+	Until    time.Time
+	ByDay    []time.Weekday
+}
+
+// ParseRRule разбирает значение RRULE
+func ParseRRule(value string, defaultLocation *time.Location) (*RRule, error) {
+	rule := &RRule{Interval: 1}
+
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			rule.Freq = strings.ToUpper(val)
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("неверный INTERVAL: %s", val)
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("неверный COUNT: %s", val)
+			}
+			rule.Count = n
+		case "UNTIL":
+			until, allDay, err := parseDateTime(property{value: val}, defaultLocation)
+			if err != nil {
+				return nil, fmt.Errorf("неверный UNTIL: %s", val)
+			}
+			if allDay {
+				// Дата без времени включает весь день
+				until = until.AddDate(0, 0, 1).Add(-time.Second)
+			}
+			rule.Until = until
+		case "BYDAY":
+			for _, day := range strings.Split(val, ",") {
+				weekday, ok := rruleWeekdays[strings.ToUpper(strings.TrimSpace(day))]
+				if !ok {
+					return nil, fmt.Errorf("BYDAY=%s не поддерживается", val)
+				}
+				rule.ByDay = append(rule.ByDay, weekday)
+			}
+		case "BYMONTH", "BYMONTHDAY", "BYSETPOS", "BYYEARDAY", "BYWEEKNO", "BYHOUR", "BYMINUTE":
+			return nil, fmt.Errorf("%s в RRULE не поддерживается", strings.ToUpper(key))
+		}
+	}
+
+	switch rule.Freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+	case "":
+		return nil, fmt.Errorf("в RRULE нет FREQ")
+	default:
+		return nil, fmt.Errorf("FREQ=%s не поддерживается", rule.Freq)
+	}
+	if len(rule.ByDay) > 0 && rule.Freq != "WEEKLY" {
+		return nil, fmt.Errorf("BYDAY поддерживается только для FREQ=WEEKLY")
+	}
+
+	return rule, nil
+}
+
+// Occurrences начала события в пределах [from, to]. Без RRULE - только Start.
+// COUNT отсчитывается от первого повторения, а не от from.
+func (e *Event) Occurrences(from, to time.Time) []time.Time {
+	excluded := make(map[int64]bool)
+	for _, exdate := range e.ExDates {
+		excluded[exdate.Unix()] = true
+	}
+
+	var result []time.Time
+	add := func(t time.Time) {
+		if !t.Before(from) && !t.After(to) && !excluded[t.Unix()] {
+			result = append(result, t)
+		}
+	}
+
+	if e.RRule == nil {
+		add(e.Start)
+		return result
+	}
+
+	e.RRule.expand(e.Start, to, func(t time.Time) {
+		add(t)
+	})
+	return result
+}
+
+// expand перебирает повторения от start до to (или до UNTIL/COUNT)
+func (r *RRule) expand(start, to time.Time, emit func(time.Time)) {
+	count, iterations := 0, 0
+	next := func(t time.Time) bool {
+		iterations++
+		if iterations > maxIterations {
+			return false
+		}
+		if t.Before(start) {
+			return true
+		}
+		if !r.Until.IsZero() && t.After(r.Until) {
+			return false
+		}
+		if t.After(to) {
+			return false
+		}
+		count++
+		if r.Count > 0 && count > r.Count {
+			return false
+		}
+		emit(t)
+		return true
+	}
+
+	switch r.Freq {
+	case "DAILY":
+		for k := 0; ; k++ {
+			if !next(start.AddDate(0, 0, k*r.Interval)) {
+				return
+			}
+		}
+
+	case "WEEKLY":
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []time.Weekday{start.Weekday()}
+		}
+		offsets := make([]int, 0, len(days))
+		for _, day := range days {
+			offsets = append(offsets, (int(day)+6)%7) // понедельник = 0
+		}
+		sort.Ints(offsets)
+
+		weekStart := start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+		for k := 0; ; k++ {
+			week := weekStart.AddDate(0, 0, 7*k*r.Interval)
+			for _, offset := range offsets {
+				if !next(week.AddDate(0, 0, offset)) {
+					return
+				}
+			}
+		}
+
+	case "MONTHLY", "YEARLY":
+		for k := 0; ; k++ {
+			var t time.Time
+			if r.Freq == "MONTHLY" {
+				t = start.AddDate(0, k*r.Interval, 0)
+			} else {
+				t = start.AddDate(k*r.Interval, 0, 0)
+			}
+			// 31-е число или 29 февраля есть не в каждом месяце - пропускаем
+			if t.Day() != start.Day() {
+				if t.After(to) || iterations > maxIterations {
+					return
+				}
+				iterations++
+				continue
+			}
+			if !next(t) {
+				return
+			}
+		}
+	}
+}
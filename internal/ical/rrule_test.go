@@ -0,0 +1,105 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRRuleErrors(t *testing.T) {
+	tests := []struct {
+		value string
+		err   string
+	}{
+		{"INTERVAL=2", "нет FREQ"},
+		{"FREQ=HOURLY", "FREQ=HOURLY"},
+		{"FREQ=DAILY;INTERVAL=0", "INTERVAL"},
+		{"FREQ=DAILY;COUNT=x", "COUNT"},
+		{"FREQ=DAILY;UNTIL=завтра", "UNTIL"},
+		{"FREQ=WEEKLY;BYDAY=1MO", "BYDAY"},
+		{"FREQ=DAILY;BYDAY=MO", "только для FREQ=WEEKLY"},
+		{"FREQ=YEARLY;BYMONTH=3", "BYMONTH"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			_, err := ParseRRule(tt.value, time.UTC)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("ParseRRule(%q) = %v, ожидалась ошибка про %s", tt.value, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestOccurrences(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	from := time.Date(2030, 1, 1, 0, 0, 0, 0, msk)
+	to := time.Date(2030, 3, 31, 23, 59, 59, 0, msk)
+
+	tests := []struct {
+		name  string
+		event string
+		want  []string
+	}{
+		{
+			name:  "без повторения",
+			event: "DTSTART;TZID=Europe/Moscow:20300105T090000",
+			want:  []string{"2030-01-05 09:00"},
+		},
+		{
+			name:  "ежедневно с COUNT и EXDATE",
+			event: "DTSTART:20300105T090000\nRRULE:FREQ=DAILY;COUNT=4\nEXDATE:20300106T090000",
+			want:  []string{"2030-01-05 09:00", "2030-01-07 09:00", "2030-01-08 09:00"},
+		},
+		{
+			name:  "через день до UNTIL",
+			event: "DTSTART:20300105T090000\nRRULE:FREQ=DAILY;INTERVAL=2;UNTIL=20300109",
+			want:  []string{"2030-01-05 09:00", "2030-01-07 09:00", "2030-01-09 09:00"},
+		},
+		{
+			name: "по будням недели",
+			// 2030-01-02 - среда
+			event: "DTSTART:20300102T180000\nRRULE:FREQ=WEEKLY;BYDAY=MO,WE,FR;COUNT=5\nEXDATE:20300104T180000,20300107T180000",
+			want:  []string{"2030-01-02 18:00", "2030-01-09 18:00", "2030-01-11 18:00"},
+		},
+		{
+			name:  "раз в две недели",
+			event: "DTSTART:20300101T100000\nRRULE:FREQ=WEEKLY;INTERVAL=2;COUNT=3",
+			want:  []string{"2030-01-01 10:00", "2030-01-15 10:00", "2030-01-29 10:00"},
+		},
+		{
+			name:  "31-е число есть не в каждом месяце",
+			event: "DTSTART:20300131T080000\nRRULE:FREQ=MONTHLY",
+			want:  []string{"2030-01-31 08:00", "2030-03-31 08:00"},
+		},
+		{
+			name:  "начало до окна",
+			event: "DTSTART:20291230T070000\nRRULE:FREQ=DAILY;COUNT=4",
+			want:  []string{"2030-01-01 07:00", "2030-01-02 07:00"},
+		},
+		{
+			name:  "весь день",
+			event: "DTSTART;VALUE=DATE:20300301\nRRULE:FREQ=YEARLY",
+			want:  []string{"2030-03-01 00:00"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := "BEGIN:VCALENDAR\nBEGIN:VEVENT\nUID:1\nSUMMARY:Тест\n" + tt.event + "\nEND:VEVENT\nEND:VCALENDAR\n"
+			events, err := Parse([]byte(strings.ReplaceAll(data, "\n", "\r\n")), msk)
+			if err != nil || len(events) != 1 {
+				t.Fatalf("Parse: %v, событий %d", err, len(events))
+			}
+			if events[0].Err != nil {
+				t.Fatalf("событие: %v", events[0].Err)
+			}
+
+			var got []string
+			for _, at := range events[0].Occurrences(from, to) {
+				got = append(got, at.In(msk).Format("2006-01-02 15:04"))
+			}
+			if strings.Join(got, ", ") != strings.Join(tt.want, ", ") {
+				t.Errorf("Occurrences() = %v, ожидалось %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,338 @@
+// Package importer - разбор CSV и JSON файлов для загрузки в трекер.
+//
+// Разбор только превращает файл в services.ImportBatch и отмечает строки,
+// которые не удалось прочитать. Проверка столпов, дат и дубликатов
+// выполняется в services.TransferService.Import.
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"five-pillars/internal/database"
+	"five-pillars/internal/services"
+)
+
+// headerAliases русские и альтернативные названия колонок
+var headerAliases = map[string]string{
+	"дата":       "date",
+	"время":      "time_utc",
+	"time":       "time_utc",
+	"столп":      "pillar",
+	"описание":   "description",
+	"задача":     "description",
+	"выполнено":  "completed",
+	"done":       "completed",
+	"пропущено":  "skipped",
+	"статус":     "status",
+	"причина":    "skip_reason",
+	"заметки":    "notes",
+	"энергия":    "energy_level",
+	"energy":     "energy_level",
+	"контроль":   "control_level",
+	"control":    "control_level",
+	"сон":        "sleep_hours",
+	"sleep":      "sleep_hours",
+	"настроение": "mood",
+	"дни":        "weekdays",
+	"начало":     "start_utc",
+	"заранее":    "lead_minutes",
+	"результат":  "value",
+	"единица":    "metric_unit",
+	"цель":       "metric_target",
+	"теги":       "tags",
+	"дни_недели": "weekdays",
+	"активен":    "active",
+	"активный":   "active",
+}
+
+// Parse разбирает файл по расширению имени: .json или .csv. Колонки
+// дополнительных показателей в CSV узнаются по справочнику registry.
+func Parse(registry *database.Registry, name string, data []byte) (*services.ImportBatch, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return ParseJSON(data)
+	case ".csv":
+		return ParseCSV(registry, data)
+	default:
+		return nil, services.NewValidationError("Поддерживаются только файлы .csv и .json")
+	}
+}
+
+// ParseJSON разбирает выгрузку в формате JSON (как у export)
+func ParseJSON(data []byte) (*services.ImportBatch, error) {
+	var payload struct {
+		Tasks     []database.DailyTask     `json:"tasks"`
+		Feelings  []database.DailyFeelings `json:"feelings"`
+		Templates []struct {
+			database.TaskTemplate
+			Active *bool `json:"active"`
+		} `json:"templates"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, services.NewValidationError(fmt.Sprintf("Ошибка разбора JSON: %v", err))
+	}
+
+	snapshot := &database.Snapshot{Tasks: payload.Tasks, Feelings: payload.Feelings}
+	for _, raw := range payload.Templates {
+		template := raw.TaskTemplate
+		template.Active = raw.Active == nil || *raw.Active
+		snapshot.Templates = append(snapshot.Templates, template)
+	}
+
+	return services.BatchFromSnapshot(snapshot), nil
+}
+
+// ParseCSV разбирает таблицу задач, ощущений или шаблонов.
+// Набор данных определяется по заголовку.
+func ParseCSV(registry *database.Registry, data []byte) (*services.ImportBatch, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if bytes.Count(data[:firstLineEnd(data)], []byte(";")) > bytes.Count(data[:firstLineEnd(data)], []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, services.NewValidationError(fmt.Sprintf("Ошибка разбора CSV: %v", err))
+	}
+	if len(records) == 0 {
+		return nil, services.NewValidationError("CSV файл пустой")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if alias, ok := headerAliases[key]; ok {
+			key = alias
+		}
+		columns[key] = i
+	}
+
+	dataset, err := detectDataset(columns)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := &services.ImportBatch{}
+	for i, record := range records[1:] {
+		row := &csvRow{columns: columns, record: record, line: i + 2}
+		if row.empty() {
+			continue
+		}
+
+		switch dataset {
+		case services.DatasetTasks:
+			task := row.task()
+			if row.err != nil {
+				batch.Issues = append(batch.Issues, row.issue(dataset))
+				continue
+			}
+			batch.Tasks = append(batch.Tasks, services.ImportTaskRow{Row: row.line, Value: task})
+		case services.DatasetFeelings:
+			feelings := row.feelings(registry)
+			if row.err != nil {
+				batch.Issues = append(batch.Issues, row.issue(dataset))
+				continue
+			}
+			batch.Feelings = append(batch.Feelings, services.ImportFeelingsRow{Row: row.line, Value: feelings})
+		case services.DatasetTemplates:
+			template := row.template()
+			if row.err != nil {
+				batch.Issues = append(batch.Issues, row.issue(dataset))
+				continue
+			}
+			batch.Templates = append(batch.Templates, services.ImportTemplateRow{Row: row.line, Value: template})
+		}
+	}
+
+	return batch, nil
+}
+
+func firstLineEnd(data []byte) int {
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return i
+	}
+	return len(data)
+}
+
+func detectDataset(columns map[string]int) (string, error) {
+	has := func(name string) bool {
+		_, ok := columns[name]
+		return ok
+	}
+
+	switch {
+	case has("energy_level") && has("date"):
+		return services.DatasetFeelings, nil
+	case has("description") && has("date") && has("pillar"):
+		return services.DatasetTasks, nil
+	case has("description") && has("pillar") && has("time_utc"):
+		return services.DatasetTemplates, nil
+	default:
+		return "", services.NewValidationError(
+			"Не удалось определить содержимое CSV. Нужны колонки date,time_utc,pillar,description (задачи), " +
+				"date,energy_level,control_level (ощущения) или pillar,description,time_utc,weekdays (шаблоны)")
+	}
+}
+
+// csvRow строка CSV с доступом к значениям по названию колонки.
+// Первая ошибка преобразования сохраняется в err.
+type csvRow struct {
+	columns map[string]int
+	record  []string
+	line    int
+	err     error
+}
+
+func (r *csvRow) empty() bool {
+	for _, value := range r.record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *csvRow) issue(dataset string) services.ImportIssue {
+	return services.ImportIssue{Dataset: dataset, Row: r.line, Message: r.err.Error()}
+}
+
+func (r *csvRow) get(name string) string {
+	i, ok := r.columns[name]
+	if !ok || i >= len(r.record) {
+		return ""
+	}
+	return strings.TrimSpace(r.record[i])
+}
+
+func (r *csvRow) int(name string) int {
+	value := r.get(name)
+	if value == "" || r.err != nil {
+		return 0
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		r.err = fmt.Errorf("%s: ожидалось целое число, получено %q", name, value)
+	}
+	return n
+}
+
+func (r *csvRow) float(name string) float64 {
+	value := strings.ReplaceAll(r.get(name), ",", ".")
+	if value == "" || r.err != nil {
+		return 0
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		r.err = fmt.Errorf("%s: ожидалось число, получено %q", name, value)
+	}
+	return f
+}
+
+func (r *csvRow) bool(name string, defaultValue bool) bool {
+	value := strings.ToLower(r.get(name))
+	switch value {
+	case "":
+		return defaultValue
+	case "1", "true", "yes", "да", "x", "+", "✅":
+		return true
+	case "0", "false", "no", "нет", "-":
+		return false
+	default:
+		if r.err == nil {
+			r.err = fmt.Errorf("%s: ожидалось да/нет, получено %q", name, value)
+		}
+		return defaultValue
+	}
+}
+
+func (r *csvRow) task() database.DailyTask {
+	task := database.DailyTask{
+		Pillar:      database.Pillar(r.get("pillar")),
+		Description: r.get("description"),
+		TimeUTC:     r.get("time_utc"),
+		Date:        r.get("date"),
+		Notes:       r.get("notes"),
+		Status:      database.TaskStatus(r.get("status")),
+		Completed:   r.bool("completed", false),
+		Skipped:     r.bool("skipped", false),
+		StartUTC:    r.get("start_utc"),
+		LeadMinutes: r.get("lead_minutes"),
+
+		MetricUnit:   r.get("metric_unit"),
+		MetricTarget: r.float("metric_target"),
+		MetricAgg:    r.get("metric_agg"),
+		Tags:         r.get("tags"),
+	}
+	if r.get("value") != "" {
+		value := r.float("value")
+		task.Value = &value
+	}
+
+	if code := r.get("skip_reason"); code != "" && !strings.Contains(task.Notes, "Пропущено:") {
+		task.Skipped = true
+		if task.Status == "" {
+			task.Status = database.StatusSkipped
+		}
+		text := code
+		if reason, ok := services.FindSkipReason(code); ok {
+			text = reason.Text
+		}
+		task.Notes = fmt.Sprintf("Пропущено: %s | %s", code, text)
+	}
+
+	return task
+}
+
+func (r *csvRow) feelings(registry *database.Registry) database.DailyFeelings {
+	feelings := database.DailyFeelings{
+		Date:         r.get("date"),
+		EnergyLevel:  r.int("energy_level"),
+		ControlLevel: r.int("control_level"),
+		SleepHours:   r.float("sleep_hours"),
+		Mood:         r.get("mood"),
+		Notes:        r.get("notes"),
+	}
+
+	// Дополнительные показатели - колонки с ключом, названием или синонимом
+	// показателя, значения проверяет сервис при загрузке
+	for _, metric := range registry.WellbeingMetrics(true) {
+		if metric.Builtin() {
+			continue
+		}
+		for _, name := range metric.Names() {
+			if value := r.get(name); value != "" {
+				services.SetFeelingsValue(&feelings, metric.Key, value)
+				break
+			}
+		}
+	}
+	return feelings
+}
+
+func (r *csvRow) template() database.TaskTemplate {
+	return database.TaskTemplate{
+		Pillar:      database.Pillar(r.get("pillar")),
+		Description: r.get("description"),
+		TimeUTC:     r.get("time_utc"),
+		Notes:       r.get("notes"),
+		Weekdays:    r.get("weekdays"),
+		Active:      r.bool("active", true),
+		StartUTC:    r.get("start_utc"),
+		LeadMinutes: r.get("lead_minutes"),
+
+		MetricUnit:   r.get("metric_unit"),
+		MetricTarget: r.float("metric_target"),
+		MetricAgg:    r.get("metric_agg"),
+		Tags:         r.get("tags"),
+	}
+}
@@ -0,0 +1,59 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+
+	"five-pillars/internal/services"
+)
+
+var datasetNames = map[string]string{
+	services.DatasetTasks:     "Задачи",
+	services.DatasetFeelings:  "Ощущения",
+	services.DatasetTemplates: "Шаблоны",
+}
+
+// Summary текстовый отчет о загрузке. maxIssues ограничивает число
+// показанных проблемных строк, 0 - показать все.
+func Summary(report *services.ImportReport, maxIssues int) string {
+	var b strings.Builder
+
+	if report.DryRun {
+		b.WriteString("🔍 Проверка без записи (dry-run)\n\n")
+	} else {
+		b.WriteString("📥 Загрузка завершена\n\n")
+	}
+
+	for _, item := range []struct {
+		dataset string
+		counts  services.ImportCounts
+	}{
+		{services.DatasetTasks, report.Tasks},
+		{services.DatasetFeelings, report.Feelings},
+		{services.DatasetTemplates, report.Templates},
+	} {
+		c := item.counts
+		if c.Added+c.Duplicates+c.Invalid == 0 {
+			continue
+		}
+		verb := "добавлено"
+		if report.DryRun {
+			verb = "будет добавлено"
+		}
+		b.WriteString(fmt.Sprintf("%s: %s %d, дубликатов %d, с ошибками %d\n",
+			datasetNames[item.dataset], verb, c.Added, c.Duplicates, c.Invalid))
+	}
+
+	if len(report.Issues) > 0 {
+		b.WriteString("\nОшибки:\n")
+		for i, issue := range report.Issues {
+			if maxIssues > 0 && i >= maxIssues {
+				b.WriteString(fmt.Sprintf("... и еще %d\n", len(report.Issues)-maxIssues))
+				break
+			}
+			b.WriteString(fmt.Sprintf("• %s, строка %d: %s\n", datasetNames[issue.Dataset], issue.Row, issue.Message))
+		}
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,53 @@
+package notify
+
+import (
+	"io"
+	"strings"
+
+	"five-pillars/internal/config"
+	"five-pillars/internal/database"
+	"five-pillars/internal/services"
+)
+
+// Channels создает каналы, для которых есть настройки: почта (SMTP_ADDR),
+// push (PUSH_URL) и консоль out. Telegram добавляет вызывающий код.
+func Channels(cfg *config.Config, registry *database.Registry, out io.Writer) (map[string]services.NotificationSender, error) {
+	channels := map[string]services.NotificationSender{
+		ChannelStdout: NewStdoutSender(out, registry),
+	}
+
+	if cfg.Notify.SMTP.Addr != "" {
+		var recipients []string
+		for _, to := range strings.Split(cfg.Notify.SMTP.To, ",") {
+			if to = strings.TrimSpace(to); to != "" {
+				recipients = append(recipients, to)
+			}
+		}
+
+		email, err := NewEmailSender(SMTPConfig{
+			Addr:     cfg.Notify.SMTP.Addr,
+			From:     cfg.Notify.SMTP.From,
+			To:       recipients,
+			Username: cfg.Notify.SMTP.Username,
+			Password: cfg.Notify.SMTP.Password,
+		}, registry)
+		if err != nil {
+			return nil, err
+		}
+		channels[ChannelEmail] = email
+	}
+
+	if cfg.Notify.Push.URL != "" {
+		push, err := NewPushSender(PushConfig{
+			URL:   cfg.Notify.Push.URL,
+			Token: cfg.Notify.Push.Token,
+			Style: cfg.Notify.Push.Style,
+		}, registry)
+		if err != nil {
+			return nil, err
+		}
+		channels[ChannelPush] = push
+	}
+
+	return channels, nil
+}
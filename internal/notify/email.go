@@ -0,0 +1,84 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"five-pillars/internal/database"
+	"five-pillars/internal/services"
+)
+
+// SMTPConfig параметры почтового сервера
+type SMTPConfig struct {
+	// Addr host:port
+	Addr     string
+	From     string
+	To       []string
+	Username string
+	Password string
+}
+
+// EmailSender отправляет уведомления письмами через SMTP.
+// Без Username письмо отправляется без авторизации (локальный релей).
+type EmailSender struct {
+	config   SMTPConfig
+	registry *database.Registry
+}
+
+func NewEmailSender(config SMTPConfig, registry *database.Registry) (*EmailSender, error) {
+	if config.Addr == "" || config.From == "" || len(config.To) == 0 {
+		return nil, fmt.Errorf("для почты нужны SMTP_ADDR, SMTP_FROM и SMTP_TO")
+	}
+	if _, _, err := net.SplitHostPort(config.Addr); err != nil {
+		return nil, fmt.Errorf("неверный SMTP_ADDR %q: %v", config.Addr, err)
+	}
+	return &EmailSender{config: config, registry: registry}, nil
+}
+
+func (s *EmailSender) SendMessage(text string) error {
+	return s.send(Title(text), PlainText(text))
+}
+
+func (s *EmailSender) SendTaskNotification(task database.TaskNotification) error {
+	text := services.FormatTaskNotification(s.registry, task)
+	return s.send(Title(text), PlainText(text))
+}
+
+func (s *EmailSender) send(subject, body string) error {
+	var auth smtp.Auth
+	if s.config.Username != "" {
+		host, _, _ := net.SplitHostPort(s.config.Addr)
+		auth = smtp.PlainAuth("", s.config.Username, s.config.Password, host)
+	}
+
+	if err := smtp.SendMail(s.config.Addr, auth, s.config.From, s.config.To, s.message(subject, body)); err != nil {
+		return fmt.Errorf("ошибка отправки письма: %v", err)
+	}
+	return nil
+}
+
+func (s *EmailSender) message(subject, body string) []byte {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", s.config.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(s.config.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(body))
+	for len(encoded) > 76 {
+		msg.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	msg.WriteString(encoded + "\r\n")
+
+	return msg.Bytes()
+}
@@ -0,0 +1,155 @@
+package notify
+
+import (
+	"bufio"
+	"encoding/base64"
+	"mime"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"five-pillars/internal/database"
+)
+
+// smtpMail письмо, принятое локальным SMTP-сервером
+type smtpMail struct {
+	auth string
+	from string
+	to   []string
+	data string
+}
+
+// startSMTP поднимает на 127.0.0.1 минимальный SMTP-сервер на одно письмо
+func startSMTP(t *testing.T) (string, <-chan smtpMail) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	mails := make(chan smtpMail, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		text := textproto.NewConn(conn)
+		var mail smtpMail
+		text.PrintfLine("220 localhost ESMTP")
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(strings.Fields(line + " ")[0])
+			switch command {
+			case "EHLO", "HELO":
+				text.PrintfLine("250-localhost")
+				text.PrintfLine("250 AUTH PLAIN")
+			case "AUTH":
+				mail.auth = line
+				text.PrintfLine("235 OK")
+			case "MAIL":
+				mail.from = line
+				text.PrintfLine("250 OK")
+			case "RCPT":
+				mail.to = append(mail.to, line)
+				text.PrintfLine("250 OK")
+			case "DATA":
+				text.PrintfLine("354 Go ahead")
+				data, err := text.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				mail.data = string(data)
+				text.PrintfLine("250 OK")
+			case "QUIT":
+				text.PrintfLine("221 Bye")
+				mails <- mail
+				return
+			default:
+				text.PrintfLine("502 Not implemented")
+			}
+		}
+	}()
+	return listener.Addr().String(), mails
+}
+
+func TestEmailSender(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		wantAuth bool
+	}{
+		{"без авторизации", "", false},
+		{"с авторизацией", "user", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, mails := startSMTP(t)
+			sender, err := NewEmailSender(SMTPConfig{
+				Addr:     addr,
+				From:     "bot@example.com",
+				To:       []string{"me@example.com", "wife@example.com"},
+				Username: tt.username,
+				Password: "secret",
+			}, database.NewRegistry())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := sender.SendMessage("📊 <b>Итоги дня</b>\n\nQ&amp;A: 3/5"); err != nil {
+				t.Fatal(err)
+			}
+			mail := <-mails
+
+			if (mail.auth != "") != tt.wantAuth {
+				t.Errorf("авторизация %q, ожидалась: %v", mail.auth, tt.wantAuth)
+			}
+			if !strings.Contains(mail.from, "<bot@example.com>") || len(mail.to) != 2 {
+				t.Errorf("отправитель %q, получатели %v", mail.from, mail.to)
+			}
+
+			headers, body, _ := strings.Cut(mail.data, "\n\n")
+			reader := textproto.NewReader(bufio.NewReader(strings.NewReader(headers + "\n\n")))
+			header, err := reader.ReadMIMEHeader()
+			if err != nil {
+				t.Fatal(err)
+			}
+			subject, err := new(mime.WordDecoder).DecodeHeader(header.Get("Subject"))
+			if err != nil || subject != "📊 Итоги дня" {
+				t.Errorf("тема %q: %v", subject, err)
+			}
+			decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(body, "\n", ""))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(decoded); got != "📊 Итоги дня\n\nQ&A: 3/5" {
+				t.Errorf("текст письма %q", got)
+			}
+		})
+	}
+}
+
+func TestNewEmailSenderValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		config SMTPConfig
+	}{
+		{"без адреса", SMTPConfig{From: "a@b", To: []string{"c@d"}}},
+		{"без отправителя", SMTPConfig{Addr: "localhost:25", To: []string{"c@d"}}},
+		{"без получателей", SMTPConfig{Addr: "localhost:25", From: "a@b"}},
+		{"без порта", SMTPConfig{Addr: "localhost", From: "a@b", To: []string{"c@d"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewEmailSender(tt.config, database.NewRegistry()); err == nil {
+				t.Error("ожидалась ошибка настройки")
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"five-pillars/internal/database"
+	"five-pillars/internal/services"
+)
+
+// Форматы HTTP push
+const (
+	// PushNtfy тело - текст, заголовок в Title, токен в Authorization: Bearer
+	PushNtfy = "ntfy"
+	// PushGotify JSON {title, message, priority}, токен в X-Gotify-Key
+	PushGotify = "gotify"
+)
+
+// PushConfig параметры HTTP push
+type PushConfig struct {
+	// URL для ntfy - адрес темы (https://ntfy.sh/my-topic), для gotify - https://host/message
+	URL   string
+	Token string
+	Style string
+}
+
+// PushSender отправляет уведомления HTTP-запросом в сервис push-уведомлений
+type PushSender struct {
+	config   PushConfig
+	client   *http.Client
+	registry *database.Registry
+}
+
+func NewPushSender(config PushConfig, registry *database.Registry) (*PushSender, error) {
+	if config.Style == "" {
+		config.Style = PushNtfy
+	}
+	if config.Style != PushNtfy && config.Style != PushGotify {
+		return nil, fmt.Errorf("неизвестный PUSH_STYLE %q, доступны: %s, %s", config.Style, PushNtfy, PushGotify)
+	}
+	if !strings.HasPrefix(config.URL, "http://") && !strings.HasPrefix(config.URL, "https://") {
+		return nil, fmt.Errorf("неверный PUSH_URL %q", config.URL)
+	}
+
+	return &PushSender{
+		config:   config,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		registry: registry,
+	}, nil
+}
+
+func (s *PushSender) SendMessage(text string) error {
+	return s.send(Title(text), PlainText(text), 5)
+}
+
+func (s *PushSender) SendTaskNotification(task database.TaskNotification) error {
+	text := services.FormatTaskNotification(s.registry, task)
+	return s.send(Title(text), PlainText(text), 8)
+}
+
+func (s *PushSender) send(title, message string, priority int) error {
+	var req *http.Request
+	var err error
+
+	switch s.config.Style {
+	case PushGotify:
+		body, _ := json.Marshal(map[string]interface{}{
+			"title":    title,
+			"message":  message,
+			"priority": priority,
+		})
+		req, err = http.NewRequest(http.MethodPost, s.config.URL, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if s.config.Token != "" {
+			req.Header.Set("X-Gotify-Key", s.config.Token)
+		}
+	default:
+		req, err = http.NewRequest(http.MethodPost, s.config.URL, strings.NewReader(message))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+		req.Header.Set("Title", mime.BEncoding.Encode("utf-8", title))
+		if priority > 5 {
+			req.Header.Set("Priority", "high")
+		}
+		if s.config.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+s.config.Token)
+		}
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("ошибка отправки push: %v", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("ошибка отправки push: HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
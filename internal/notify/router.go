@@ -0,0 +1,175 @@
+// Package notify - каналы уведомлений помимо Telegram (почта, HTTP push,
+// консоль) и маршрутизация сообщений по каналам в зависимости от типа.
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"five-pillars/internal/database"
+	"five-pillars/internal/services"
+)
+
+// Названия каналов в настройках маршрутов
+const (
+	ChannelTelegram = "telegram"
+	ChannelEmail    = "email"
+	ChannelPush     = "push"
+	ChannelStdout   = "stdout"
+)
+
+// Router реализует services.NotificationSender, services.KindSender, services.RitualSender
+// и services.RolloverSender: отправляет каждое сообщение во все каналы, назначенные его типу
+type Router struct {
+	channels map[string]services.NotificationSender
+	routes   map[services.MessageKind][]string
+	fallback []string
+	// registry справочники для текстовых версий брифингов и итогов переноса
+	registry *database.Registry
+}
+
+// NewRouter создает маршрутизатор. fallback - каналы для типов без
+// отдельного маршрута.
+func NewRouter(registry *database.Registry, fallback ...string) *Router {
+	return &Router{
+		channels: make(map[string]services.NotificationSender),
+		routes:   make(map[services.MessageKind][]string),
+		fallback: fallback,
+		registry: registry,
+	}
+}
+
+// AddChannel регистрирует канал под именем
+func (r *Router) AddChannel(name string, sender services.NotificationSender) {
+	r.channels[name] = sender
+}
+
+// SetRoutes разбирает маршруты вида "task_reminder=telegram;weekly_report=email,telegram;*=telegram".
+// "*" задает каналы по умолчанию. Все упомянутые каналы должны быть добавлены.
+func (r *Router) SetRoutes(spec string) error {
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		kindName, channelList, ok := strings.Cut(entry, "=")
+		if !ok {
+			return fmt.Errorf("неверный маршрут уведомлений %q, ожидается тип=канал,канал", entry)
+		}
+
+		var channels []string
+		for _, name := range strings.Split(channelList, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if _, ok := r.channels[name]; !ok {
+				return fmt.Errorf("канал уведомлений %q не настроен, доступны: %s", name, strings.Join(r.Channels(), ", "))
+			}
+			channels = append(channels, name)
+		}
+
+		kindName = strings.TrimSpace(kindName)
+		if kindName == "*" {
+			r.fallback = channels
+			continue
+		}
+		if !knownKind(kindName) {
+			return fmt.Errorf("неизвестный тип уведомлений %q", kindName)
+		}
+		r.routes[services.MessageKind(kindName)] = channels
+	}
+	return nil
+}
+
+// Channels имена зарегистрированных каналов
+func (r *Router) Channels() []string {
+	names := make([]string, 0, len(r.channels))
+	for name := range r.channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SendKind отправляет сообщение во все каналы типа. Ошибка одного канала
+// не мешает отправке в остальные.
+func (r *Router) SendKind(kind services.MessageKind, text string) error {
+	return r.each(kind, func(sender services.NotificationSender) error {
+		return sender.SendMessage(text)
+	})
+}
+
+// SendMessage сообщение без типа уходит как системное
+func (r *Router) SendMessage(text string) error {
+	return r.SendKind(services.KindSystem, text)
+}
+
+// SendTaskNotification напоминание о задаче в каналы KindTaskReminder
+func (r *Router) SendTaskNotification(task database.TaskNotification) error {
+	return r.each(services.KindTaskReminder, func(sender services.NotificationSender) error {
+		return sender.SendTaskNotification(task)
+	})
+}
+
+// SendMorningBriefing брифинг в каналы KindMorningBriefing: с кнопками
+// там, где они есть, остальным текстом
+func (r *Router) SendMorningBriefing(briefing *services.MorningBriefing) error {
+	return r.each(services.KindMorningBriefing, func(sender services.NotificationSender) error {
+		if ritual, ok := sender.(services.RitualSender); ok {
+			return ritual.SendMorningBriefing(briefing)
+		}
+		return sender.SendMessage(services.FormatMorningBriefing(r.registry, briefing))
+	})
+}
+
+// SendEveningPlan план на завтра в каналы KindEveningPlan
+func (r *Router) SendEveningPlan(plan *services.EveningPlan) error {
+	return r.each(services.KindEveningPlan, func(sender services.NotificationSender) error {
+		if ritual, ok := sender.(services.RitualSender); ok {
+			return ritual.SendEveningPlan(plan)
+		}
+		return sender.SendMessage(services.FormatEveningPlan(r.registry, plan))
+	})
+}
+
+// SendRollover итоги переноса незакрытых задач в каналы KindRollover
+func (r *Router) SendRollover(result *services.RolloverResult) error {
+	return r.each(services.KindRollover, func(sender services.NotificationSender) error {
+		if rollover, ok := sender.(services.RolloverSender); ok {
+			return rollover.SendRollover(result)
+		}
+		return sender.SendMessage(services.FormatRollover(r.registry, result))
+	})
+}
+
+func (r *Router) each(kind services.MessageKind, send func(services.NotificationSender) error) error {
+	channels, ok := r.routes[kind]
+	if !ok {
+		channels = r.fallback
+	}
+
+	var errs []error
+	for _, name := range channels {
+		sender, ok := r.channels[name]
+		if !ok {
+			continue
+		}
+		if err := send(sender); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func knownKind(name string) bool {
+	for _, kind := range services.MessageKinds {
+		if string(kind) == name {
+			return true
+		}
+	}
+	return false
+}
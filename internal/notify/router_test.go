@@ -0,0 +1,120 @@
+package notify
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"five-pillars/internal/database"
+	"five-pillars/internal/services"
+)
+
+// recorder канал, который запоминает отправленные сообщения
+type recorder struct {
+	messages []string
+}
+
+func (r *recorder) SendMessage(text string) error {
+	r.messages = append(r.messages, text)
+	return nil
+}
+
+func (r *recorder) SendTaskNotification(task database.TaskNotification) error {
+	r.messages = append(r.messages, task.Description)
+	return nil
+}
+
+func TestRouterSetRoutes(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr bool
+		// want каналы, в которые уходит сообщение каждого типа
+		want map[services.MessageKind][]string
+	}{
+		{
+			name: "пусто - все по умолчанию",
+			spec: "",
+			want: map[services.MessageKind][]string{
+				services.KindTaskReminder: {ChannelTelegram},
+				services.KindWeeklyReport: {ChannelTelegram},
+			},
+		},
+		{
+			name: "отдельный маршрут и несколько каналов",
+			spec: "weekly_report=email,telegram; task_reminder = push",
+			want: map[services.MessageKind][]string{
+				services.KindWeeklyReport: {ChannelEmail, ChannelTelegram},
+				services.KindTaskReminder: {ChannelPush},
+				services.KindDailySummary: {ChannelTelegram},
+			},
+		},
+		{
+			name: "звездочка меняет каналы по умолчанию",
+			spec: "*=email;task_reminder=telegram",
+			want: map[services.MessageKind][]string{
+				services.KindTaskReminder: {ChannelTelegram},
+				services.KindDailySummary: {ChannelEmail},
+			},
+		},
+		{
+			name: "пустой список каналов выключает тип",
+			spec: "task_digest=",
+			want: map[services.MessageKind][]string{
+				services.KindTaskDigest:   nil,
+				services.KindTaskReminder: {ChannelTelegram},
+			},
+		},
+		{name: "без знака равно", spec: "weekly_report", wantErr: true},
+		{name: "неизвестный тип", spec: "monthly=email", wantErr: true},
+		{name: "канал не настроен", spec: "weekly_report=sms", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			channels := map[string]*recorder{
+				ChannelTelegram: {}, ChannelEmail: {}, ChannelPush: {},
+			}
+			router := NewRouter(database.NewRegistry(), ChannelTelegram)
+			for name, channel := range channels {
+				router.AddChannel(name, channel)
+			}
+
+			err := router.SetRoutes(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetRoutes(%q): %v", tt.spec, err)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			for kind, want := range tt.want {
+				for _, channel := range channels {
+					channel.messages = nil
+				}
+				if err := router.SendKind(kind, string(kind)); err != nil {
+					t.Fatal(err)
+				}
+
+				var got []string
+				for _, name := range router.Channels() {
+					if len(channels[name].messages) > 0 {
+						got = append(got, name)
+					}
+				}
+				if !reflect.DeepEqual(sorted(got), sorted(want)) {
+					t.Errorf("%s уходит в %v, ожидалось %v", kind, got, want)
+				}
+			}
+		})
+	}
+}
+
+func sorted(names []string) []string {
+	if len(names) == 0 {
+		return nil
+	}
+	names = append([]string(nil), names...)
+	sort.Strings(names)
+	return names
+}
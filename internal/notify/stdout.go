@@ -0,0 +1,33 @@
+package notify
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"five-pillars/internal/database"
+	"five-pillars/internal/services"
+)
+
+// StdoutSender печатает уведомления в консоль (для CLI и отладки)
+type StdoutSender struct {
+	mu       sync.Mutex
+	out      io.Writer
+	registry *database.Registry
+}
+
+func NewStdoutSender(out io.Writer, registry *database.Registry) *StdoutSender {
+	return &StdoutSender{out: out, registry: registry}
+}
+
+func (s *StdoutSender) SendMessage(text string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := fmt.Fprintf(s.out, "%s\n\n", PlainText(text))
+	return err
+}
+
+func (s *StdoutSender) SendTaskNotification(task database.TaskNotification) error {
+	return s.SendMessage(services.FormatTaskNotification(s.registry, task))
+}
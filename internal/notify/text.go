@@ -0,0 +1,24 @@
+package notify
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var tagPattern = regexp.MustCompile(`<[^>]+>`)
+
+// PlainText убирает HTML-разметку Telegram для каналов без форматирования
+func PlainText(text string) string {
+	return strings.TrimSpace(html.UnescapeString(tagPattern.ReplaceAllString(text, "")))
+}
+
+// Title первая непустая строка сообщения - тема письма или заголовок push
+func Title(text string) string {
+	for _, line := range strings.Split(PlainText(text), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return "5 Столпов"
+}
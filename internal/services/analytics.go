@@ -18,8 +18,30 @@ func NewAnalyticsService(repo *database.Repository) *AnalyticsService {
 	}
 }
 
+// GetDailySummary итоги дня вместе с ощущениями, если они заполнены
+func (as *AnalyticsService) GetDailySummary(date string) (*database.DailySummary, error) {
+	if err := ValidateDate(date); err != nil {
+		return nil, err
+	}
+
+	summary, err := as.repository.GetDailySummary(date)
+	if err != nil {
+		return nil, err
+	}
+
+	if feelings, err := as.repository.GetFeelings(date); err == nil {
+		summary.Feelings = feelings
+	}
+
+	return summary, nil
+}
+
 func (as *AnalyticsService) GetWeeklyAnalytics() (*database.WeeklyAnalytics, error) {
-	now := time.Now()
+	return as.GetWeeklyAnalyticsAt(time.Now())
+}
+
+// GetWeeklyAnalyticsAt аналитика за неделю, в которую попадает now
+func (as *AnalyticsService) GetWeeklyAnalyticsAt(now time.Time) (*database.WeeklyAnalytics, error) {
 	year, week := now.ISOWeek()
 	startDate := as.firstDayOfISOWeek(year, week)
 	endDate := startDate.AddDate(0, 0, 7)
@@ -33,6 +55,9 @@ func (as *AnalyticsService) GetWeeklyAnalytics() (*database.WeeklyAnalytics, err
 	}
 
 	analytics.WeekNumber = week
+	if analytics.Sleep, err = sleepStats(as.repository, analytics.StartDate, analytics.EndDate); err != nil {
+		return nil, err
+	}
 	analytics.Insights = as.generateInsights(analytics)
 
 	return analytics, nil
@@ -41,7 +66,12 @@ func (as *AnalyticsService) GetWeeklyAnalytics() (*database.WeeklyAnalytics, err
 func (as *AnalyticsService) generateInsights(analytics *database.WeeklyAnalytics) string {
 	var insights []string
 
-	completionRate := float64(analytics.TotalDone) / float64(analytics.TotalTasks) * 100
+	// Процент выполнения считается по закрытым задачам: пока все открыты, судить рано
+	if analytics.Resolved() == 0 {
+		return "📊 Данных для анализа недостаточно. Продолжайте заполнять трекер!"
+	}
+
+	completionRate := analytics.CompletionRate()
 
 	if completionRate < 50 {
 		insights = append(insights, "💪 Нужно больше фокуса на выполнении задач")
@@ -51,14 +81,18 @@ func (as *AnalyticsService) generateInsights(analytics *database.WeeklyAnalytics
 		insights = append(insights, "📈 Хороший прогресс, есть куда расти")
 	}
 
-	for pillar, stats := range analytics.PillarStats {
-		rate := float64(stats.Completed) / float64(stats.Total) * 100
+	for _, pillar := range database.SortedPillars(as.repository.Registry(), analytics.PillarStats) {
+		stat := analytics.PillarStats[pillar]
+		if stat.Resolved() == 0 {
+			continue
+		}
+		rate := stat.CompletionRate()
 		p := database.Pillar(pillar)
 
 		if rate < 40 {
 			insights = append(insights, fmt.Sprintf(
 				"⚠️ %s требует внимания: %.0f%% выполнено",
-				database.PillarNames[p], rate,
+				as.repository.Registry().PillarName(p), rate,
 			))
 		}
 	}
@@ -71,6 +105,8 @@ func (as *AnalyticsService) generateInsights(analytics *database.WeeklyAnalytics
 		}
 	}
 
+	insights = append(insights, sleepInsights(analytics.Sleep)...)
+
 	if len(insights) == 0 {
 		return "📊 Данных для анализа недостаточно. Продолжайте заполнять трекер!"
 	}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"five-pillars/internal/database"
+)
+
+func TestGenerateInsightsOpenTasks(t *testing.T) {
+	as := newTestServices(t).Analytics
+
+	// Все задачи недели еще открыты: оценивать выполнение рано
+	open := &database.WeeklyAnalytics{
+		TotalTasks:  3,
+		TotalOpen:   3,
+		PillarStats: map[string]database.PillarStat{"body": {Total: 3, Open: 3}},
+	}
+	if got := as.generateInsights(open); strings.Contains(got, "фокуса") || strings.Contains(got, "требует внимания") {
+		t.Errorf("неделя без закрытых задач: %q", got)
+	}
+
+	// Столп без закрытых задач не получает предупреждения о 0%
+	mixed := &database.WeeklyAnalytics{
+		TotalTasks: 4,
+		TotalDone:  2,
+		TotalOpen:  2,
+		PillarStats: map[string]database.PillarStat{
+			"body":  {Total: 2, Completed: 2},
+			"focus": {Total: 2, Open: 2},
+		},
+	}
+	if got := as.generateInsights(mixed); strings.Contains(got, "требует внимания") {
+		t.Errorf("предупреждение для столпа без закрытых задач: %q", got)
+	}
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"five-pillars/internal/database"
+)
+
+// MaxChecklistItems сколько пунктов может быть в чек-листе задачи
+const MaxChecklistItems = 20
+
+var ErrChecklistTooLong = NewValidationError(fmt.Sprintf("В чек-листе может быть не больше %d пунктов", MaxChecklistItems))
+
+// ParseChecklist разбирает пункты чек-листа: по строкам, через ";" или
+// через "→", как шаги записывались в заметках
+func ParseChecklist(text string) []string {
+	text = strings.NewReplacer(";", "\n", "→", "\n").Replace(text)
+
+	var items []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			items = append(items, line)
+		}
+	}
+	return items
+}
+
+// checklistItems пункты для сохранения вместе с задачей
+func checklistItems(texts []string) []database.ChecklistItem {
+	items := make([]database.ChecklistItem, len(texts))
+	for i, text := range texts {
+		items[i] = database.ChecklistItem{Text: text}
+	}
+	return items
+}
+
+// checklistEditable чек-лист выполненной, отмененной или перенесенной
+// задачи уже не меняется
+func checklistEditable(task *database.DailyTask) error {
+	switch task.Status {
+	case database.StatusDone, database.StatusCancelled, database.StatusRescheduled:
+		return NewValidationError(fmt.Sprintf("Задача #%d %s, чек-лист уже не изменить", task.ID, StatusNames[task.Status]))
+	}
+	return nil
+}
+
+// AddChecklist добавляет пункты в конец чек-листа задачи
+func (ts *TaskService) AddChecklist(taskID int, text string) (*database.DailyTask, error) {
+	task, err := ts.GetTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checklistEditable(task); err != nil {
+		return nil, err
+	}
+
+	texts := ParseChecklist(text)
+	if len(texts) == 0 {
+		return nil, NewValidationError("Укажите пункты чек-листа через «;»")
+	}
+	if len(task.Checklist)+len(texts) > MaxChecklistItems {
+		return nil, ErrChecklistTooLong
+	}
+
+	if err := ts.repository.AddChecklistItems(taskID, checklistItems(texts)); err != nil {
+		return nil, err
+	}
+	return ts.GetTask(taskID)
+}
+
+// ToggleChecklistItem отмечает пункт чек-листа или снимает отметку. Когда
+// отмечены все пункты, задача выполняется.
+func (ts *TaskService) ToggleChecklistItem(itemID int) (*database.DailyTask, error) {
+	item, task, err := ts.getChecklistItem(itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ts.repository.SetChecklistItemDone(item.ID, !item.Done); err != nil {
+		return nil, err
+	}
+
+	task, err = ts.GetTask(task.ID)
+	if err != nil {
+		return nil, err
+	}
+	done, total := task.ChecklistProgress()
+	if done == total && CanTransition(task.Status, database.StatusDone) {
+		return ts.CompleteTask(task.ID)
+	}
+	return task, nil
+}
+
+// RemoveChecklistItem удаляет пункт чек-листа
+func (ts *TaskService) RemoveChecklistItem(itemID int) (*database.DailyTask, error) {
+	item, task, err := ts.getChecklistItem(itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := ts.repository.DeleteChecklistItem(item.ID); err != nil {
+		return nil, err
+	}
+	return ts.GetTask(task.ID)
+}
+
+// SetTemplateChecklist задает чек-лист шаблона, пустой текст убирает его.
+// Чек-лист получат задачи, созданные по шаблону после изменения.
+func (ts *TaskService) SetTemplateChecklist(templateID int, text string) error {
+	texts := ParseChecklist(text)
+	if len(texts) > MaxChecklistItems {
+		return ErrChecklistTooLong
+	}
+
+	updated, err := ts.repository.UpdateTemplateChecklist(templateID, strings.Join(texts, "\n"))
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return NewValidationError(fmt.Sprintf("Шаблон #%d не найден", templateID))
+	}
+	return nil
+}
+
+func (ts *TaskService) getChecklistItem(itemID int) (*database.ChecklistItem, *database.DailyTask, error) {
+	item, err := ts.repository.GetChecklistItem(itemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, NewValidationError(fmt.Sprintf("Пункт чек-листа #%d не найден", itemID))
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	task, err := ts.GetTask(item.TaskID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checklistEditable(task); err != nil {
+		return nil, nil, err
+	}
+	return item, task, nil
+}
+
+// ChecklistMark значок пункта чек-листа
+func ChecklistMark(item database.ChecklistItem) string {
+	if item.Done {
+		return "☑️"
+	}
+	return "⬜"
+}
+
+// FormatChecklistProgress "📋 Чек-лист: 1/3", пустая строка если чек-листа нет
+func FormatChecklistProgress(task database.DailyTask) string {
+	done, total := task.ChecklistProgress()
+	if total == 0 {
+		return ""
+	}
+	return fmt.Sprintf("📋 Чек-лист: %d/%d", done, total)
+}
+
+// FormatChecklist пункты чек-листа по строкам со значками
+func FormatChecklist(items []database.ChecklistItem) string {
+	var b strings.Builder
+	for _, item := range items {
+		b.WriteString(ChecklistMark(item) + " " + item.Text + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,33 @@
+package services
+
+import "errors"
+
+// ValidationError ошибка во входных данных пользователя.
+// Текст ошибки предназначен для показа пользователю как есть.
+type ValidationError struct {
+	message string
+}
+
+func NewValidationError(message string) *ValidationError {
+	return &ValidationError{message: message}
+}
+
+func (e *ValidationError) Error() string {
+	return e.message
+}
+
+var (
+	ErrInvalidTime       = NewValidationError("Время должно быть в формате HH:mm (UTC)")
+	ErrInvalidDate       = NewValidationError("Дата должна быть в формате YYYY-MM-DD")
+	ErrEmptyDescription  = NewValidationError("Описание задачи не может быть пустым")
+	ErrUnknownSkipReason = NewValidationError("Неизвестная причина пропуска")
+
+	ErrTaskNotFound     = errors.New("задача не найдена")
+	ErrFeelingsNotFound = errors.New("ощущения за день не найдены")
+)
+
+// IsValidationError проверяет, что ошибка вызвана некорректным вводом
+func IsValidationError(err error) bool {
+	var validationErr *ValidationError
+	return errors.As(err, &validationErr)
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"five-pillars/internal/database"
+)
+
+// EventType тип события трекера для внешних подписчиков (вебхуки и т.п.)
+type EventType string
+
+const (
+	EventTaskCreated   EventType = "task.created"
+	EventTaskCompleted EventType = "task.completed"
+	EventTaskSkipped   EventType = "task.skipped"
+	EventTaskSnoozed   EventType = "task.snoozed"
+	EventTaskMissed    EventType = "task.missed"
+	EventTaskCancelled EventType = "task.cancelled"
+	EventFeelingsSaved EventType = "feelings.saved"
+)
+
+// EventTypes все типы событий
+var EventTypes = []EventType{
+	EventTaskCreated,
+	EventTaskCompleted,
+	EventTaskSkipped,
+	EventTaskSnoozed,
+	EventTaskMissed,
+	EventTaskCancelled,
+	EventFeelingsSaved,
+}
+
+// Event что произошло и с какой задачей или ощущениями
+type Event struct {
+	Type          EventType               `json:"event"`
+	OccurredAt    time.Time               `json:"occurred_at"`
+	Task          *database.DailyTask     `json:"task,omitempty"`
+	Feelings      *database.DailyFeelings `json:"feelings,omitempty"`
+	SkipReason    *SkipReason             `json:"skip_reason,omitempty"`
+	SnoozeMinutes int                     `json:"snooze_minutes,omitempty"`
+}
+
+// EventListener получает события после успешной записи в БД.
+// Вызывается синхронно, долгую работу слушатель должен делать сам в фоне.
+type EventListener interface {
+	HandleEvent(event Event)
+}
+
+// eventBus раздает события подписчикам
+type eventBus struct {
+	mu        sync.RWMutex
+	listeners []EventListener
+}
+
+func (eb *eventBus) subscribe(listener EventListener) {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	eb.listeners = append(eb.listeners, listener)
+}
+
+func (eb *eventBus) publish(event Event) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now().UTC()
+	}
+
+	eb.mu.RLock()
+	defer eb.mu.RUnlock()
+	for _, listener := range eb.listeners {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("⚠️ Ошибка обработчика события %s: %v", event.Type, r)
+				}
+			}()
+			listener.HandleEvent(event)
+		}()
+	}
+}
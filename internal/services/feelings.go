@@ -0,0 +1,153 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"five-pillars/internal/database"
+)
+
+type FeelingsService struct {
+	repository *database.Repository
+	events     *eventBus
+}
+
+func NewFeelingsService(repo *database.Repository, events *eventBus) *FeelingsService {
+	return &FeelingsService{
+		repository: repo,
+		events:     events,
+	}
+}
+
+// ParseFeelings разбирает строку вида "энергия=8 контроль=7 сон=7.5 настроение=Сосредоточен"
+// по справочнику показателей. Слова без "=" продолжают предыдущее текстовое
+// значение: "настроение=спокойно, но устал".
+func ParseFeelings(registry *database.Registry, text string) (database.DailyFeelings, error) {
+	var feelings database.DailyFeelings
+	values := make(map[string]string)
+	var order []database.WellbeingMetric
+	var last *database.WellbeingMetric
+
+	for _, word := range strings.Fields(text) {
+		name, value, found := strings.Cut(word, "=")
+		if !found {
+			if last == nil || last.Type != database.WellbeingText {
+				return feelings, NewValidationError(fmt.Sprintf("Не понял «%s». Формат: %s", word, FeelingsFormat(registry)))
+			}
+			values[last.Key] += " " + word
+			continue
+		}
+
+		metric, ok := registry.LookupWellbeingMetric(name)
+		if !ok {
+			return feelings, unknownWellbeingError(registry, name)
+		}
+		if _, seen := values[metric.Key]; !seen {
+			order = append(order, metric)
+		}
+		values[metric.Key] = value
+		last = &metric
+	}
+
+	for _, metric := range order {
+		// Сон можно записать как отбой-подъем, часы посчитаются по журналу сна
+		if metric.Key == database.WellbeingSleep && IsSleepRange(values[metric.Key]) {
+			entry, err := ParseSleepRange("", values[metric.Key])
+			if err != nil {
+				return feelings, err
+			}
+			feelings.Sleep = &entry
+			continue
+		}
+
+		value, err := ParseWellbeingValue(metric, values[metric.Key])
+		if err != nil {
+			return feelings, err
+		}
+		SetFeelingsValue(&feelings, metric.Key, value)
+	}
+
+	return feelings, validateFeelings(registry, &feelings)
+}
+
+// unknownWellbeingError перечисляет действующие показатели, чтобы было из чего выбрать
+func unknownWellbeingError(registry *database.Registry, name string) error {
+	var names []string
+	for _, metric := range registry.WellbeingMetrics(false) {
+		names = append(names, inputName(metric))
+	}
+	return NewValidationError(fmt.Sprintf("Неизвестный показатель «%s». Используйте: %s", name, strings.Join(names, ", ")))
+}
+
+// validateFeelings проверяет ощущения по справочнику: значения в допустимых
+// границах, незаполненные показатели пропускаются. Значения приводятся к
+// виду, в котором хранятся.
+func validateFeelings(registry *database.Registry, feelings *database.DailyFeelings) error {
+	if sleep := feelings.Sleep; sleep != nil {
+		entry, err := NewSleepEntry(feelings.Date, sleep.Bedtime, sleep.WakeTime)
+		if err != nil {
+			return err
+		}
+		feelings.Sleep = &entry
+		feelings.SleepHours = entry.Hours
+	}
+
+	for key := range feelings.Values {
+		if _, ok := registry.GetWellbeingMetric(key); !ok {
+			return unknownWellbeingError(registry, key)
+		}
+	}
+
+	for _, metric := range registry.WellbeingMetrics(true) {
+		value := feelings.Value(metric.Key)
+		if value == "" {
+			continue
+		}
+		value, err := ParseWellbeingValue(metric, value)
+		if err != nil {
+			return err
+		}
+		SetFeelingsValue(feelings, metric.Key, value)
+	}
+	return nil
+}
+
+// Save сохраняет ощущения. Пустая дата означает сегодня.
+func (fs *FeelingsService) Save(feelings database.DailyFeelings) (*database.DailyFeelings, error) {
+	if feelings.Date == "" {
+		feelings.Date = Today()
+	}
+	if err := ValidateDate(feelings.Date); err != nil {
+		return nil, err
+	}
+	if err := validateFeelings(fs.repository.Registry(), &feelings); err != nil {
+		return nil, err
+	}
+
+	if err := fs.repository.SaveFeelings(feelings); err != nil {
+		return nil, err
+	}
+
+	saved, err := fs.Get(feelings.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	fs.events.publish(Event{Type: EventFeelingsSaved, Feelings: saved})
+	return saved, nil
+}
+
+// Get возвращает ощущения за дату
+func (fs *FeelingsService) Get(date string) (*database.DailyFeelings, error) {
+	if err := ValidateDate(date); err != nil {
+		return nil, err
+	}
+
+	feelings, err := fs.repository.GetFeelings(date)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrFeelingsNotFound
+	}
+	return feelings, err
+}
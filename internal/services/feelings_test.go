@@ -0,0 +1,72 @@
+package services
+
+import (
+	"testing"
+)
+
+func TestParseFeelings(t *testing.T) {
+	sm := newTestServices(t)
+
+	tests := []struct {
+		text    string
+		energy  int
+		control int
+		sleep   float64
+		mood    string
+		wantErr bool
+	}{
+		{text: "энергия=8 контроль=7 сон=7.5 настроение=Сосредоточен", energy: 8, control: 7, sleep: 7.5, mood: "Сосредоточен"},
+		{text: "сон=7", sleep: 7},
+		{text: "сон=7,5", sleep: 7.5},
+		{text: "энергия=6", energy: 6},
+		{text: "настроение=спокойно, но устал", mood: "спокойно, но устал"},
+		{text: "сон=23:30-07:00", sleep: 7.5},
+		{text: ""},
+		{text: "сон=0", wantErr: true},
+		{text: "сон=-1", wantErr: true},
+		{text: "сон=25", wantErr: true},
+		{text: "энергия=0", wantErr: true},
+		{text: "энергия=11", wantErr: true},
+		{text: "энергия=7.5", wantErr: true},
+		{text: "контроль=много", wantErr: true},
+		{text: "пульс=60", wantErr: true},
+		{text: "энергия", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			feelings, err := ParseFeelings(sm.Registry(), tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFeelings(%q): %v", tt.text, err)
+			}
+			if err != nil {
+				if !IsValidationError(err) {
+					t.Errorf("ошибка не для пользователя: %v", err)
+				}
+				return
+			}
+			if feelings.EnergyLevel != tt.energy || feelings.ControlLevel != tt.control ||
+				feelings.SleepHours != tt.sleep || feelings.Mood != tt.mood {
+				t.Errorf("ParseFeelings(%q) = энергия %d, контроль %d, сон %v, настроение %q",
+					tt.text, feelings.EnergyLevel, feelings.ControlLevel, feelings.SleepHours, feelings.Mood)
+			}
+		})
+	}
+}
+
+// Энергия и контроль необязательны: запись только со сном сохраняется
+func TestSaveFeelingsWithoutScales(t *testing.T) {
+	sm := newTestServices(t)
+
+	feelings, err := ParseFeelings(sm.Registry(), "сон=7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	feelings.Date = "2030-01-10"
+	saved, err := sm.Feelings.Save(feelings)
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if saved.EnergyLevel != 0 || saved.ControlLevel != 0 || saved.SleepHours != 7 {
+		t.Errorf("сохранено: энергия %d, контроль %d, сон %v", saved.EnergyLevel, saved.ControlLevel, saved.SleepHours)
+	}
+}
@@ -0,0 +1,291 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+
+	"five-pillars/internal/database"
+)
+
+var (
+	ErrInvalidGoalTarget = NewValidationError("Цель - число, можно с единицей: 500км, 12 или 1200мин")
+	ErrInvalidDeadline   = NewValidationError("Срок цели должен быть не раньше её начала")
+	ErrGoalArchived      = NewValidationError("Цель в архиве, к ней нельзя привязывать задачи")
+)
+
+// ParseGoalTarget разбирает цель: "500км" -> 500 и "км", "12" -> 12 без
+// единицы (считаются выполненные задачи)
+func ParseGoalTarget(value string) (float64, string, error) {
+	value = strings.TrimSpace(value)
+	split := strings.IndexFunc(value, func(r rune) bool {
+		return !unicode.IsDigit(r) && r != '.' && r != ','
+	})
+	if split < 0 {
+		split = len(value)
+	}
+
+	target, err := ParseValue(value[:split])
+	if err != nil || target <= 0 {
+		return 0, "", ErrInvalidGoalTarget
+	}
+	unit := strings.TrimSpace(value[split:])
+	if len([]rune(unit)) > maxMetricUnit {
+		return 0, "", ErrMetricTooLong
+	}
+	return target, unit, nil
+}
+
+// NewGoalInput данные для создания цели. Пустое начало - сегодня.
+type NewGoalInput struct {
+	Pillar      string  `json:"pillar"`
+	Description string  `json:"description"`
+	Target      float64 `json:"target"`
+	Unit        string  `json:"unit"`
+	StartDate   string  `json:"start_date"`
+	Deadline    string  `json:"deadline"`
+}
+
+type GoalService struct {
+	repository *database.Repository
+}
+
+func NewGoalService(repo *database.Repository) *GoalService {
+	return &GoalService{repository: repo}
+}
+
+// AddGoal создает цель
+func (gs *GoalService) AddGoal(input NewGoalInput) (*database.Goal, error) {
+	pillar, err := ParsePillar(gs.repository.Registry(), input.Pillar)
+	if err != nil {
+		return nil, err
+	}
+
+	description := strings.TrimSpace(input.Description)
+	if description == "" {
+		return nil, ErrEmptyDescription
+	}
+	if input.Target <= 0 {
+		return nil, ErrInvalidGoalTarget
+	}
+	unit := strings.TrimSpace(input.Unit)
+	if len([]rune(unit)) > maxMetricUnit {
+		return nil, ErrMetricTooLong
+	}
+
+	start := input.StartDate
+	if start == "" {
+		start = Today()
+	}
+	if err := ValidateDate(start); err != nil {
+		return nil, err
+	}
+	if err := ValidateDate(input.Deadline); err != nil {
+		return nil, err
+	}
+	if input.Deadline < start {
+		return nil, ErrInvalidDeadline
+	}
+
+	id, err := gs.repository.AddGoal(database.Goal{
+		Pillar:      pillar,
+		Description: description,
+		Target:      input.Target,
+		Unit:        unit,
+		StartDate:   start,
+		Deadline:    input.Deadline,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return gs.GetGoal(id)
+}
+
+// GetGoals цели с прогрессом, archived - вместе с архивными
+func (gs *GoalService) GetGoals(archived bool) ([]database.Goal, error) {
+	return gs.repository.GetGoals(archived)
+}
+
+// GetGoal цель с прогрессом
+func (gs *GoalService) GetGoal(goalID int) (*database.Goal, error) {
+	goal, err := gs.repository.GetGoal(goalID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, NewValidationError(fmt.Sprintf("Цель #%d не найдена", goalID))
+	}
+	return goal, err
+}
+
+// Archive убирает цель в архив или возвращает из него
+func (gs *GoalService) Archive(goalID int, archived bool) error {
+	updated, err := gs.repository.ArchiveGoal(goalID, archived)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return NewValidationError(fmt.Sprintf("Цель #%d не найдена", goalID))
+	}
+	return nil
+}
+
+// LinkTemplate привязывает шаблон к цели. Задачи шаблона, уже созданные за
+// период цели, учитываются сразу, новые - по мере создания.
+func (gs *GoalService) LinkTemplate(goalID, templateID int) (*database.Goal, error) {
+	goal, err := gs.linkableGoal(goalID)
+	if err != nil {
+		return nil, err
+	}
+
+	templates, err := gs.repository.GetTemplates(false)
+	if err != nil {
+		return nil, err
+	}
+	for _, template := range templates {
+		if template.ID != templateID {
+			continue
+		}
+		if err := checkGoalUnit(goal, template.MetricUnit, fmt.Sprintf("metric template %d", template.ID)); err != nil {
+			return nil, err
+		}
+		if _, err := gs.repository.SetTemplateGoal(templateID, goal.ID, goal.StartDate); err != nil {
+			return nil, err
+		}
+		return gs.GetGoal(goal.ID)
+	}
+	return nil, NewValidationError(fmt.Sprintf("Шаблон #%d не найден", templateID))
+}
+
+// UnlinkTemplate отвязывает шаблон от цели. Задачи шаблона по сегодняшний
+// день остаются в прогрессе цели, начиная с завтрашних - нет.
+func (gs *GoalService) UnlinkTemplate(templateID int) error {
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+	updated, err := gs.repository.SetTemplateGoal(templateID, 0, tomorrow)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return NewValidationError(fmt.Sprintf("Шаблон #%d не найден", templateID))
+	}
+	return nil
+}
+
+// LinkTask привязывает задачу к цели
+func (gs *GoalService) LinkTask(goalID, taskID int) (*database.Goal, error) {
+	goal, err := gs.linkableGoal(goalID)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := gs.repository.GetTaskByID(taskID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := checkGoalUnit(goal, task.MetricUnit, fmt.Sprintf("/metric %d", task.ID)); err != nil {
+		return nil, err
+	}
+
+	if err := gs.repository.SetTaskGoal(task.ID, goal.ID); err != nil {
+		return nil, err
+	}
+	return gs.GetGoal(goal.ID)
+}
+
+// UnlinkTask отвязывает задачу от цели
+func (gs *GoalService) UnlinkTask(taskID int) error {
+	if _, err := gs.repository.GetTaskByID(taskID); errors.Is(err, sql.ErrNoRows) {
+		return ErrTaskNotFound
+	} else if err != nil {
+		return err
+	}
+	return gs.repository.SetTaskGoal(taskID, 0)
+}
+
+func (gs *GoalService) linkableGoal(goalID int) (*database.Goal, error) {
+	goal, err := gs.GetGoal(goalID)
+	if err != nil {
+		return nil, err
+	}
+	if goal.Archived {
+		return nil, ErrGoalArchived
+	}
+	return goal, nil
+}
+
+// checkGoalUnit измеримую цель продвигают только задачи с метрикой в той же
+// единице, hint - команда, которой метрику можно задать
+func checkGoalUnit(goal *database.Goal, unit, hint string) error {
+	if goal.Unit == "" || goal.Unit == unit {
+		return nil
+	}
+	return NewValidationError(fmt.Sprintf("Цель #%d считается в «%s», а задачи - в «%s». Задайте метрику: %s %s",
+		goal.ID, goal.Unit, unit, hint, goal.Unit))
+}
+
+// GoalPace какой процент цели должен быть достигнут к дате now, если
+// двигаться равномерно от начала до срока
+func GoalPace(goal database.Goal, now time.Time) float64 {
+	start, err := time.Parse("2006-01-02", goal.StartDate)
+	if err != nil {
+		return 0
+	}
+	deadline, err := time.Parse("2006-01-02", goal.Deadline)
+	if err != nil {
+		return 0
+	}
+
+	total := deadline.Sub(start).Hours()/24 + 1
+	elapsed := now.UTC().Sub(start).Hours()/24 + 1
+	return math.Max(0, math.Min(100, math.Floor(elapsed)/total*100))
+}
+
+// progressBar полоска из десяти делений
+func progressBar(percent float64) string {
+	filled := int(math.Min(10, math.Max(0, percent/10)))
+	return strings.Repeat("▓", filled) + strings.Repeat("░", 10-filled)
+}
+
+// FormatGoal цель с прогрессом и темпом
+func FormatGoal(registry *database.Registry, goal database.Goal, now time.Time) string {
+	unit := goal.Unit
+	if unit == "" {
+		unit = "задач"
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%s #%d %s\n", registry.PillarEmoji(goal.Pillar), goal.ID, goal.Description))
+	b.WriteString(fmt.Sprintf("%s %.0f%% · %s/%s %s\n",
+		progressBar(goal.Percent()), goal.Percent(), FormatAmount(goal.Progress.Value), FormatAmount(goal.Target), unit))
+
+	today := now.UTC().Format("2006-01-02")
+	switch {
+	case goal.Archived:
+		b.WriteString("📦 В архиве\n")
+	case goal.Percent() >= 100:
+		b.WriteString("🏆 Цель достигнута!\n")
+	case today > goal.Deadline:
+		b.WriteString(fmt.Sprintf("⌛ Срок %s прошел\n", goal.Deadline))
+	default:
+		pace := GoalPace(goal, now)
+		status := "✅ по графику"
+		if goal.Percent() < pace {
+			status = "⚠️ отстаете"
+		}
+		b.WriteString(fmt.Sprintf("📅 до %s, по графику %.0f%% - %s\n", goal.Deadline, pace, status))
+	}
+	return b.String()
+}
+
+// FormatGoals цели по порядку, пустая строка если целей нет
+func FormatGoals(registry *database.Registry, goals []database.Goal, now time.Time) string {
+	parts := make([]string, len(goals))
+	for i, goal := range goals {
+		parts[i] = FormatGoal(registry, goal, now)
+	}
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"five-pillars/internal/database"
+	"five-pillars/internal/utils"
+)
+
+// MaxJournalText длина одной записи дневника, чуть меньше лимита сообщения Telegram
+const MaxJournalText = 4000
+
+var (
+	ErrEmptyJournal         = NewValidationError("Запись дневника пустая: напишите текст или пришлите фото")
+	ErrJournalTooLong       = NewValidationError(fmt.Sprintf("Запись дневника длиннее %d символов, разбейте ее на несколько сообщений", MaxJournalText))
+	ErrJournalEntryNotFound = NewValidationError("Запись дневника не найдена")
+)
+
+// JournalService дневник: свободные записи и фото за день. Текст дневника
+// дублируется в заметки ощущений того же дня.
+type JournalService struct {
+	repository *database.Repository
+}
+
+func NewJournalService(repo *database.Repository) *JournalService {
+	return &JournalService{repository: repo}
+}
+
+// Add добавляет запись: текст, фото с подписью или фото без нее. Пустая
+// дата означает сегодня.
+func (js *JournalService) Add(date, text, photoID string) (*database.JournalEntry, error) {
+	if date == "" {
+		date = Today()
+	}
+	if err := ValidateDate(date); err != nil {
+		return nil, err
+	}
+
+	text = strings.TrimSpace(text)
+	if text == "" && photoID == "" {
+		return nil, ErrEmptyJournal
+	}
+	if utf8.RuneCountInString(text) > MaxJournalText {
+		return nil, ErrJournalTooLong
+	}
+
+	id, err := js.repository.AddJournalEntry(database.JournalEntry{Date: date, Text: text, PhotoID: photoID})
+	if err != nil {
+		return nil, err
+	}
+	return js.repository.GetJournalEntry(id)
+}
+
+// Get записи дневника за день по порядку
+func (js *JournalService) Get(date string) ([]database.JournalEntry, error) {
+	if err := ValidateDate(date); err != nil {
+		return nil, err
+	}
+	return js.repository.GetJournalBetween(date, date)
+}
+
+// Delete удаляет запись, заметки ощущений дня пересобираются без нее
+func (js *JournalService) Delete(id int) error {
+	date, err := js.repository.DeleteJournalEntry(id)
+	if err != nil {
+		return err
+	}
+	if date == "" {
+		return ErrJournalEntryNotFound
+	}
+	return nil
+}
+
+// FormatJournal записи дня по строке: "#12 21:05 текст", фото - "#13 21:06 📷 подпись"
+func FormatJournal(entries []database.JournalEntry) string {
+	var b strings.Builder
+	for _, entry := range entries {
+		b.WriteString(fmt.Sprintf("#%d %s ", entry.ID, entry.CreatedAt.In(utils.MoscowLocation()).Format("15:04")))
+		text := entry.Text
+		if entry.PhotoID != "" {
+			text = strings.TrimSpace("📷 " + text)
+		}
+		b.WriteString(text + "\n")
+	}
+	return b.String()
+}
@@ -8,20 +8,55 @@ type ServiceManager struct {
 	Notification *NotificationService
 	Analytics    *AnalyticsService
 	Task         *TaskService
+	Feelings     *FeelingsService
+	Transfer     *TransferService
+	Outbox       *OutboxService
+	Quiet        *QuietService
+	Rollover     *RolloverService
+	Goals        *GoalService
+	Pillars      *PillarService
+	Search       *SearchService
+	Journal      *JournalService
+	Wellbeing    *WellbeingService
+	Sleep        *SleepService
 	repository   *database.Repository
+	events       *eventBus
 }
 
 func NewServiceManager(db *database.Database) *ServiceManager {
 	repo := database.NewRepository(db)
+	events := &eventBus{}
 
 	return &ServiceManager{
 		Notification: nil,
 		Analytics:    NewAnalyticsService(repo),
-		Task:         NewTaskService(repo),
+		Task:         NewTaskService(repo, events),
+		Feelings:     NewFeelingsService(repo, events),
+		Transfer:     NewTransferService(repo),
+		Outbox:       NewOutboxService(repo),
+		Quiet:        NewQuietService(repo),
+		Rollover:     NewRolloverService(repo, events),
+		Goals:        NewGoalService(repo),
+		Pillars:      NewPillarService(repo),
+		Search:       NewSearchService(repo),
+		Journal:      NewJournalService(repo),
+		Wellbeing:    NewWellbeingService(repo),
+		Sleep:        NewSleepService(repo),
 		repository:   repo,
+		events:       events,
 	}
 }
 
+// Subscribe подписывает на события задач и ощущений
+func (sm *ServiceManager) Subscribe(listener EventListener) {
+	sm.events.subscribe(listener)
+}
+
 func (sm *ServiceManager) SetNotificationSender(sender NotificationSender) {
-	sm.Notification = NewNotificationService(sender, sm.repository)
+	sm.Notification = NewNotificationService(sender, sm.repository, sm.Analytics, sm.Quiet)
+}
+
+// Registry справочники столпов и показателей самочувствия открытой БД
+func (sm *ServiceManager) Registry() *database.Registry {
+	return sm.repository.Registry()
 }
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"five-pillars/internal/database"
+)
+
+// maxMetricUnit длина единицы измерения в символах
+const maxMetricUnit = 16
+
+var (
+	ErrInvalidValue  = NewValidationError("Результат должен быть неотрицательным числом, например 5 или 2,5")
+	ErrInvalidMetric = NewValidationError("Метрика: единица, цель и способ подсчета, например: км 5 sum. Способы: sum, avg, max")
+	ErrUnknownMetric = NewValidationError("Неизвестный способ подсчета. Используйте: sum (сумма), avg (среднее) или max (лучший)")
+	ErrUnknownPeriod = NewValidationError("Неизвестный период. Используйте: week или month")
+	ErrMetricTooLong = NewValidationError(fmt.Sprintf("Единица измерения должна быть не длиннее %d символов", maxMetricUnit))
+	ErrInvalidTarget = NewValidationError("Цель должна быть неотрицательным числом")
+)
+
+// ParseValue разбирает число, десятичная часть через точку или запятую
+func ParseValue(text string) (float64, error) {
+	value, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(text), ",", "."), 64)
+	if err != nil || value < 0 {
+		return 0, ErrInvalidValue
+	}
+	return value, nil
+}
+
+// ParseMetricAggregation способ подсчета за период, пусто - сумма
+func ParseMetricAggregation(value string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "sum", "сумма":
+		return database.MetricSum, nil
+	case "avg", "среднее":
+		return database.MetricAvg, nil
+	case "max", "лучший", "максимум":
+		return database.MetricMax, nil
+	default:
+		return "", ErrUnknownMetric
+	}
+}
+
+// Metric измеримый результат задачи или шаблона
+type Metric struct {
+	Unit        string
+	Target      float64
+	Aggregation string
+}
+
+// ParseMetricArgs разбирает метрику из аргументов команды:
+// "км 5 sum", "мин 90", "уроков", "off" - убрать метрику
+func ParseMetricArgs(args []string) (Metric, error) {
+	if len(args) == 0 || len(args) > 3 {
+		return Metric{}, ErrInvalidMetric
+	}
+	if len(args) == 1 && strings.EqualFold(args[0], "off") {
+		return Metric{}, nil
+	}
+
+	metric := Metric{Unit: args[0]}
+	if len(args) > 1 {
+		target, err := ParseValue(args[1])
+		if err != nil {
+			return Metric{}, ErrInvalidTarget
+		}
+		metric.Target = target
+	}
+	if len(args) > 2 {
+		metric.Aggregation = args[2]
+	}
+	return validateMetric(metric)
+}
+
+// validateMetric проверяет метрику. Без единицы метрики нет, цель и
+// способ подсчета сбрасываются.
+func validateMetric(metric Metric) (Metric, error) {
+	metric.Unit = strings.TrimSpace(metric.Unit)
+	if metric.Unit == "" {
+		return Metric{}, nil
+	}
+	if len([]rune(metric.Unit)) > maxMetricUnit {
+		return Metric{}, ErrMetricTooLong
+	}
+	if metric.Target < 0 {
+		return Metric{}, ErrInvalidTarget
+	}
+
+	aggregation, err := ParseMetricAggregation(metric.Aggregation)
+	if err != nil {
+		return Metric{}, err
+	}
+	metric.Aggregation = aggregation
+	return metric, nil
+}
+
+// RecordValue сохраняет результат задачи. Незакрытая задача с внесенным
+// результатом считается выполненной.
+func (ts *TaskService) RecordValue(taskID int, value float64) (*database.DailyTask, error) {
+	task, err := ts.GetTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.MetricUnit == "" {
+		return nil, NewValidationError(fmt.Sprintf("У задачи #%d нет метрики. Задайте её: /metric %d км 5", task.ID, task.ID))
+	}
+	if value < 0 {
+		return nil, ErrInvalidValue
+	}
+	if !task.Status.Counted() {
+		return nil, NewValidationError(fmt.Sprintf("Задача #%d %s, результат не записать", task.ID, StatusNames[task.Status]))
+	}
+
+	if err := ts.repository.UpdateTaskValue(task.ID, &value); err != nil {
+		return nil, err
+	}
+	if task.Status.Open() {
+		return ts.CompleteTask(task.ID)
+	}
+	return ts.GetTask(task.ID)
+}
+
+// SetMetric задает метрику задачи, пустая единица убирает её
+func (ts *TaskService) SetMetric(taskID int, metric Metric) (*database.DailyTask, error) {
+	metric, err := validateMetric(metric)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ts.GetTask(taskID); err != nil {
+		return nil, err
+	}
+
+	if err := ts.repository.UpdateTaskMetric(taskID, metric.Unit, metric.Target, metric.Aggregation); err != nil {
+		return nil, err
+	}
+	return ts.GetTask(taskID)
+}
+
+// SetTemplateMetric задает метрику шаблона. Её получат задачи, созданные
+// по шаблону после изменения.
+func (ts *TaskService) SetTemplateMetric(templateID int, metric Metric) error {
+	metric, err := validateMetric(metric)
+	if err != nil {
+		return err
+	}
+
+	updated, err := ts.repository.UpdateTemplateMetric(templateID, metric.Unit, metric.Target, metric.Aggregation)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return NewValidationError(fmt.Sprintf("Шаблон #%d не найден", templateID))
+	}
+	return nil
+}
+
+// MetricsReport прогресс измеримых задач за неделю или месяц
+type MetricsReport struct {
+	Period  string                `json:"period"`
+	From    string                `json:"from"`
+	To      string                `json:"to"`
+	Metrics []database.MetricStat `json:"metrics"`
+}
+
+// GetMetrics прогресс измеримых задач за текущую неделю (week) или месяц (month)
+func (as *AnalyticsService) GetMetrics(period string, now time.Time) (*MetricsReport, error) {
+	now = now.UTC()
+	var from, to time.Time
+	switch period {
+	case "week":
+		year, week := now.ISOWeek()
+		from = as.firstDayOfISOWeek(year, week)
+		to = from.AddDate(0, 0, 6)
+	case "month":
+		from = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		to = from.AddDate(0, 1, -1)
+	default:
+		return nil, ErrUnknownPeriod
+	}
+
+	report := &MetricsReport{
+		Period: period,
+		From:   from.Format("2006-01-02"),
+		To:     to.Format("2006-01-02"),
+	}
+	var err error
+	if report.Metrics, err = as.repository.GetMetricStats(report.From, report.To); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// FormatAmount число до сотых без лишних нулей: 5, 2.5, 0.33
+func FormatAmount(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+}
+
+// FormatTaskValue "📏 3/5 км", "📏 —/5 км" пока результат не внесен,
+// пустая строка для задачи без метрики
+func FormatTaskValue(task database.DailyTask) string {
+	if task.MetricUnit == "" {
+		return ""
+	}
+
+	value := "—"
+	if task.Value != nil {
+		value = FormatAmount(*task.Value)
+	}
+	if task.MetricTarget > 0 {
+		return fmt.Sprintf("📏 %s/%s %s", value, FormatAmount(task.MetricTarget), task.MetricUnit)
+	}
+	return fmt.Sprintf("📏 %s %s", value, task.MetricUnit)
+}
+
+// FormatMetrics строки прогресса измеримых задач
+func FormatMetrics(registry *database.Registry, stats []database.MetricStat) string {
+	var b strings.Builder
+	for _, stat := range stats {
+		b.WriteString(fmt.Sprintf("%s %s: %s", registry.PillarEmoji(stat.Pillar), stat.Description, FormatAmount(stat.Value)))
+		if stat.Target > 0 {
+			b.WriteString(fmt.Sprintf("/%s %s (%.0f%%)", FormatAmount(stat.Target), stat.Unit, stat.Progress()))
+		} else {
+			b.WriteString(" " + stat.Unit)
+		}
+		switch stat.Aggregation {
+		case database.MetricAvg:
+			b.WriteString(", в среднем")
+		case database.MetricMax:
+			b.WriteString(", лучший")
+		}
+		b.WriteString(fmt.Sprintf(", записей %d/%d\n", stat.Entries, stat.Tasks))
+	}
+	return b.String()
+}
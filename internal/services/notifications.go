@@ -3,6 +3,7 @@ package services
 import (
 	"five-pillars/internal/utils"
 	"fmt"
+	"html"
 	"log"
 	"strings"
 	"time"
@@ -16,16 +17,174 @@ type NotificationSender interface {
 	SendTaskNotification(task database.TaskNotification) error
 }
 
+// MessageKind тип уведомления, по нему сообщения распределяются по каналам
+type MessageKind string
+
+const (
+	KindTaskReminder     MessageKind = "task_reminder"
+	KindTaskLead         MessageKind = "task_lead"
+	KindTaskDigest       MessageKind = "task_digest"
+	KindDailySummary     MessageKind = "daily_summary"
+	KindWeeklyReport     MessageKind = "weekly_report"
+	KindGoalsReport      MessageKind = "goals_report"
+	KindFeelingsReminder MessageKind = "feelings_reminder"
+	KindMorningBriefing  MessageKind = "morning_briefing"
+	KindEveningPlan      MessageKind = "evening_plan"
+	KindRollover         MessageKind = "rollover"
+	KindSystem           MessageKind = "system"
+)
+
+// MessageKinds все типы уведомлений
+var MessageKinds = []MessageKind{
+	KindTaskReminder,
+	KindTaskLead,
+	KindTaskDigest,
+	KindDailySummary,
+	KindWeeklyReport,
+	KindGoalsReport,
+	KindFeelingsReminder,
+	KindMorningBriefing,
+	KindEveningPlan,
+	KindRollover,
+	KindSystem,
+}
+
+// KindSender отправитель, которому важен тип уведомления (например,
+// маршрутизатор по каналам). Остальным отправителям тип не передается.
+type KindSender interface {
+	SendKind(kind MessageKind, text string) error
+}
+
 type NotificationService struct {
 	sender     NotificationSender
 	repository *database.Repository
+	analytics  *AnalyticsService
+	quiet      *QuietService
 }
 
-func NewNotificationService(sender NotificationSender, repo *database.Repository) *NotificationService {
+func NewNotificationService(sender NotificationSender, repo *database.Repository, analytics *AnalyticsService, quiet *QuietService) *NotificationService {
 	return &NotificationService{
 		sender:     sender,
 		repository: repo,
+		analytics:  analytics,
+		quiet:      quiet,
+	}
+}
+
+// Notify отправляет сообщение с указанным типом
+func (ns *NotificationService) Notify(kind MessageKind, text string) error {
+	if kindSender, ok := ns.sender.(KindSender); ok {
+		return kindSender.SendKind(kind, text)
+	}
+	return ns.sender.SendMessage(text)
+}
+
+// FormatTaskNotification текст напоминания о задаче. Сообщения уходят в
+// разметке HTML, поэтому все, что ввел пользователь, экранируется.
+func FormatTaskNotification(registry *database.Registry, task database.TaskNotification) string {
+	start := ""
+	if task.StartUTC != "" && task.StartUTC != task.TimeUTC {
+		start = fmt.Sprintf("🏁 Начало: %s\n", utils.FormatTimeForDisplay(task.StartUTC))
+	}
+
+	message := fmt.Sprintf(
+		"🔔 <b>%s %s</b>\n\n"+
+			"<i>%s</i>\n\n"+
+			"⏰ Время: %s\n"+
+			"%s"+
+			"📝 %s",
+		registry.PillarEmoji(database.Pillar(task.Pillar)), html.EscapeString(registry.PillarName(database.Pillar(task.Pillar))),
+		html.EscapeString(task.Description),
+		utils.FormatTimeForDisplay(task.TimeUTC),
+		start,
+		html.EscapeString(task.Notes),
+	)
+	if task.MetricUnit != "" && task.MetricTarget > 0 {
+		message += fmt.Sprintf("\n🎯 Цель: %s %s", FormatAmount(task.MetricTarget), html.EscapeString(task.MetricUnit))
 	}
+	if len(task.Checklist) > 0 {
+		message += "\n\n📋 <b>Чек-лист:</b>\n" + html.EscapeString(strings.TrimRight(FormatChecklist(task.Checklist), "\n"))
+	}
+	return message
+}
+
+// FormatLeadReminder текст напоминания за lead минут до начала задачи
+func FormatLeadReminder(registry *database.Registry, task database.DailyTask, lead int) string {
+	return fmt.Sprintf(
+		"⏳ <b>Через %s: %s</b>\n\n"+
+			"<i>%s</i>\n\n"+
+			"🏁 Начало: %s",
+		formatMinutes(lead), html.EscapeString(registry.PillarName(task.Pillar)),
+		html.EscapeString(task.Description),
+		utils.FormatTimeForDisplay(task.Start()),
+	)
+}
+
+// formatMinutes "5 мин", "2 ч", "1 ч 30 мин"
+func formatMinutes(minutes int) string {
+	hours, rest := minutes/60, minutes%60
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("%d мин", rest)
+	case rest == 0:
+		return fmt.Sprintf("%d ч", hours)
+	default:
+		return fmt.Sprintf("%d ч %d мин", hours, rest)
+	}
+}
+
+// FormatWeeklyReport текст аналитики за неделю
+func FormatWeeklyReport(registry *database.Registry, analytics *database.WeeklyAnalytics) string {
+	message := fmt.Sprintf(
+		"📈 <b>Аналитика за неделю %d</b>\n\n"+
+			"📅 %s - %s\n\n"+
+			"✅ Выполнено: %d/%d (%.0f%%)\n"+
+			"⌛ Не сделано: %d, впереди: %d\n",
+		analytics.WeekNumber,
+		analytics.StartDate,
+		analytics.EndDate,
+		analytics.TotalDone,
+		analytics.Resolved(),
+		analytics.CompletionRate(),
+		analytics.TotalMissed,
+		analytics.TotalOpen,
+	)
+	if analytics.TotalPartial > 0 {
+		message += fmt.Sprintf("📋 Чек-листы выполнены частично: %d, с их учетом %.0f%%\n",
+			analytics.TotalPartial, analytics.ProgressRate())
+	}
+	message += "\n<b>Эффективность по столпам:</b>\n"
+
+	for _, pillar := range database.SortedPillars(registry, analytics.PillarStats) {
+		stats := analytics.PillarStats[pillar]
+		message += fmt.Sprintf(
+			"%s: %d/%d (%.0f%%), пропущено задач: %d\n",
+			html.EscapeString(registry.PillarName(database.Pillar(pillar))),
+			stats.Completed,
+			stats.Resolved(),
+			stats.CompletionRate(),
+			stats.Skipped,
+		)
+	}
+
+	if len(analytics.Metrics) > 0 {
+		message += "\n<b>📏 Измеримые цели:</b>\n" + html.EscapeString(FormatMetrics(registry, analytics.Metrics))
+	}
+
+	if len(analytics.AvgFeelings) > 0 {
+		message += "\n<b>Средние ощущения:</b>\n"
+		message += html.EscapeString(strings.Join(WellbeingAverageLines(registry, analytics.AvgFeelings), "\n")) + "\n"
+	}
+
+	if analytics.Sleep != nil {
+		message += "\n<b>Сон:</b>\n" + html.EscapeString(FormatSleepStats(analytics.Sleep))
+	}
+
+	if analytics.Insights != "" {
+		message += fmt.Sprintf("\n<b>💡 Инсайты:</b>\n%s", html.EscapeString(analytics.Insights))
+	}
+
+	return message
 }
 
 func (ns *NotificationService) CheckAndSendNotifications() {
@@ -44,6 +203,33 @@ func (ns *NotificationService) CheckAndSendNotifications() {
 
 	log.Printf("📋 Найдено задач для текущего времени: %d", len(tasks))
 
+	// Время задач наступило: они ждут выполнения, даже если напоминание
+	// отложено до конца тишины
+	for _, task := range tasks {
+		if _, err := ns.repository.UpdateTaskStatus(task.ID, database.StatusPlanned, database.StatusDue); err != nil {
+			log.Printf("⚠️ Ошибка обновления статуса задачи #%d: %v", task.ID, err)
+		}
+	}
+
+	leads := ns.leadReminders(now)
+	if ns.isQuiet(now) {
+		for _, task := range tasks {
+			ns.deferNotification(KindTaskReminder, task.ID)
+		}
+		for _, reminder := range leads {
+			ns.deferNotification(KindTaskLead, reminder.task.ID)
+		}
+		return
+	}
+	ns.SendDeferred()
+
+	for _, reminder := range leads {
+		log.Printf("⏳ Напоминание за %d мин: %s - %s", reminder.lead, reminder.task.Pillar, reminder.task.Description)
+		if err := ns.Notify(KindTaskLead, FormatLeadReminder(ns.repository.Registry(), reminder.task, reminder.lead)); err != nil {
+			log.Printf("❌ Ошибка отправки напоминания заранее: %v", err)
+		}
+	}
+
 	for _, task := range tasks {
 		log.Printf("📨 Отправляю уведомление: %s - %s", task.Pillar, task.Description)
 
@@ -55,44 +241,172 @@ func (ns *NotificationService) CheckAndSendNotifications() {
 	}
 }
 
+// leadReminder напоминание о задаче за lead минут до начала
+type leadReminder struct {
+	task database.DailyTask
+	lead int
+}
+
+// leadReminders задачи, до начала которых осталось одно из заданных
+// в LeadMinutes значений. Начало может быть уже завтра.
+func (ns *NotificationService) leadReminders(now time.Time) []leadReminder {
+	minute := now.UTC().Truncate(time.Minute)
+	tasks, err := ns.repository.GetTasksBetween(minute.Format("2006-01-02"), minute.AddDate(0, 0, 1).Format("2006-01-02"))
+	if err != nil {
+		log.Printf("⚠️ Ошибка получения задач для напоминаний заранее: %v", err)
+		return nil
+	}
+
+	var reminders []leadReminder
+
+	for _, task := range tasks {
+		if !task.Status.Open() || task.LeadMinutes == "" {
+			continue
+		}
+
+		start, err := time.Parse("2006-01-02 15:04", task.Date+" "+task.Start())
+		if err != nil {
+			continue
+		}
+
+		for _, lead := range task.Leads() {
+			if start.Add(-time.Duration(lead) * time.Minute).Equal(minute) {
+				reminders = append(reminders, leadReminder{task: task, lead: lead})
+			}
+		}
+	}
+	return reminders
+}
+
+// deferIfQuiet откладывает рассылку kind, если сейчас тишина
+func (ns *NotificationService) deferIfQuiet(kind MessageKind) bool {
+	if !ns.isQuiet(time.Now()) {
+		return false
+	}
+	ns.deferNotification(kind, 0)
+	return true
+}
+
 // SendDailySummary отправляет итоги дня
 func (ns *NotificationService) SendDailySummary() {
-	today := time.Now().UTC().Format("2006-01-02")
+	if ns.deferIfQuiet(KindDailySummary) {
+		return
+	}
+	ns.sendDailySummary(Today())
+}
+
+func (ns *NotificationService) sendDailySummary(today string) {
 	summary, err := ns.repository.GetDailySummary(today)
 	if err != nil {
 		log.Printf("⚠️ Ошибка получения сводки дня: %v", err)
 		return
 	}
 
-	completed := summary["completed"].(int)
-	total := summary["total"].(int)
-	percentage := summary["percentage"].(float64)
-
 	message := fmt.Sprintf(
 		"📊 <b>Итоги дня %s</b>\n\n"+
-			"✅ Выполнено: %d/%d (%.0f%%)\n\n"+
-			"Завтра будет новый день! 🌅",
+			"✅ Выполнено: %d/%d (%.0f%%)\n"+
+			"➖ Пропущено: %d, ⌛ не сделано: %d\n",
 		today,
-		completed,
-		total,
-		percentage,
+		summary.Completed,
+		summary.Total,
+		summary.Percentage,
+		summary.Skipped,
+		summary.Missed,
 	)
+	if summary.Partial > 0 {
+		message += fmt.Sprintf("📋 Из них чек-лист начат: %d\n", summary.Partial)
+	}
+	message += "\nЗавтра будет новый день! 🌅"
+
+	if err := ns.Notify(KindDailySummary, message); err != nil {
+		log.Printf("❌ Ошибка отправки итогов дня: %v", err)
+	}
+}
 
-	ns.sender.SendMessage(message)
+// SendWeeklyReport отправляет аналитику за текущую неделю
+func (ns *NotificationService) SendWeeklyReport() {
+	if ns.deferIfQuiet(KindWeeklyReport) {
+		return
+	}
+	ns.sendWeeklyReport(time.Now())
+}
+
+func (ns *NotificationService) sendWeeklyReport(now time.Time) {
+	analytics, err := ns.analytics.GetWeeklyAnalyticsAt(now)
+	if err != nil {
+		log.Printf("⚠️ Ошибка получения аналитики за неделю: %v", err)
+		return
+	}
+
+	if err := ns.Notify(KindWeeklyReport, FormatWeeklyReport(ns.repository.Registry(), analytics)); err != nil {
+		log.Printf("❌ Ошибка отправки отчета за неделю: %v", err)
+	}
+}
+
+// SendGoalsReport отправляет прогресс по долгосрочным целям
+func (ns *NotificationService) SendGoalsReport() {
+	if ns.deferIfQuiet(KindGoalsReport) {
+		return
+	}
+
+	goals, err := ns.repository.GetGoals(false)
+	if err != nil {
+		log.Printf("⚠️ Ошибка получения целей: %v", err)
+		return
+	}
+	if len(goals) == 0 {
+		return
+	}
+
+	message := "🎯 <b>Прогресс по целям</b>\n\n" + html.EscapeString(FormatGoals(ns.repository.Registry(), goals, time.Now()))
+	if err := ns.Notify(KindGoalsReport, message); err != nil {
+		log.Printf("❌ Ошибка отправки прогресса по целям: %v", err)
+	}
+}
+
+// SendFeelingsReminder напоминает оценить ощущения за день
+func (ns *NotificationService) SendFeelingsReminder() {
+	if ns.deferIfQuiet(KindFeelingsReminder) {
+		return
+	}
+
+	message := "📝 Не забудьте оценить свои ощущения за день!\n" +
+		"Используйте команду: /feelings " + FeelingsFormat(ns.repository.Registry()) + "\n\n" +
+		"📓 Как прошел день? /journal - запишите мысли одним или несколькими сообщениями, можно с фото"
+
+	if err := ns.Notify(KindFeelingsReminder, message); err != nil {
+		log.Printf("❌ Ошибка отправки напоминания: %v", err)
+	}
 }
 
 // SendAllTodayTaskNotification отправляет текущий статус по задачам
 func (ns *NotificationService) SendAllTodayTaskNotification() {
-	today := time.Now().UTC().Format("2006-01-02")
-	tasks, err := ns.repository.GetTasksByDate(today)
+	if ns.deferIfQuiet(KindTaskDigest) {
+		return
+	}
+
+	message, err := ns.todayDigest()
 	if err != nil {
 		log.Printf("⚠️ Ошибка получения сводки дня: %v", err)
 		return
 	}
 
+	err = ns.Notify(KindTaskDigest, message)
+	if err != nil {
+		log.Printf("❌ Ошибка отправки уведомления: %v", err)
+	}
+}
+
+// todayDigest текст сводки по задачам на сегодня
+func (ns *NotificationService) todayDigest() (string, error) {
+	today := time.Now().UTC().Format("2006-01-02")
+	tasks, err := ns.repository.GetTasksByDate(today)
+	if err != nil {
+		return "", err
+	}
+
 	if len(tasks) == 0 {
-		ns.sender.SendMessage("📭 На сегодня задач нет")
-		return
+		return "📭 На сегодня задач нет", nil
 	}
 
 	var message strings.Builder
@@ -100,44 +414,153 @@ func (ns *NotificationService) SendAllTodayTaskNotification() {
 	message.WriteString(utils.GetTimezoneInfo() + "\n\n")
 
 	for _, task := range tasks {
-		pillarName := utils.GetPillarName(string(task.Pillar))
+		pillarName := ns.repository.Registry().PillarName(task.Pillar)
 		displayTime := utils.FormatTimeForDisplay(task.TimeUTC)
 
-		var status string
-		if task.Completed {
-			status = "✅"
-		} else if task.Skipped {
-			status = "➖"
-		} else {
-			taskTime, _ := time.Parse("15:04", task.TimeUTC)
-			currentUTC := time.Now().UTC()
-			taskUTC := time.Date(currentUTC.Year(), currentUTC.Month(), currentUTC.Day(),
-				taskTime.Hour(), taskTime.Minute(), 0, 0, time.UTC)
-
-			status = "⬜"
-			if currentUTC.After(taskUTC) {
-				status = "⏰"
-			}
-		}
+		status := StatusMark(task, time.Now())
 
 		message.WriteString(fmt.Sprintf(
 			"%s <b>%s</b>\n"+
 				"%s\n"+
 				"<i>%s</i>\n\n",
-			status, pillarName,
-			displayTime, task.Description,
+			status, html.EscapeString(pillarName),
+			displayTime, html.EscapeString(task.Description),
 		))
 
-		if task.Skipped && task.Notes != "" && strings.Contains(task.Notes, "Пропущено:") {
-			parts := strings.SplitN(task.Notes, "|", 2)
-			if len(parts) > 1 {
-				message.WriteString(fmt.Sprintf("📝 <i>%s</i>\n\n", strings.TrimSpace(parts[1])))
-			}
+		if reason := SkipReasonText(task); reason != "" {
+			message.WriteString(fmt.Sprintf("📝 <i>%s</i>\n\n", html.EscapeString(reason)))
 		}
 	}
 
-	err = ns.sender.SendMessage(message.String())
+	return message.String(), nil
+}
+
+// isQuiet действуют ли тихие часы или "не беспокоить". При ошибке чтения
+// настроек уведомления лучше отправить, чем потерять.
+func (ns *NotificationService) isQuiet(now time.Time) bool {
+	if ns.quiet == nil {
+		return false
+	}
+
+	state, err := ns.quiet.State(now)
 	if err != nil {
-		log.Printf("❌ Ошибка отправки уведомления: %v", err)
+		log.Printf("⚠️ %v", err)
+		return false
+	}
+	return state.Active
+}
+
+func (ns *NotificationService) deferNotification(kind MessageKind, taskID int) {
+	if err := ns.repository.DeferNotification(string(kind), taskID); err != nil {
+		log.Printf("⚠️ Ошибка откладывания уведомления: %v", err)
+		return
+	}
+	log.Printf("🔕 Тихий режим, уведомление отложено: %s %d", kind, taskID)
+}
+
+// SendDeferred отправляет одним сообщением уведомления, отложенные
+// на время тишины: напоминания о еще открытых задачах и свежую сводку.
+// Отложенные отчеты и ритуалы следом уходят отдельными сообщениями.
+func (ns *NotificationService) SendDeferred() {
+	if ns.isQuiet(time.Now()) {
+		return
+	}
+
+	deferred, err := ns.repository.GetDeferredNotifications()
+	if err != nil {
+		log.Printf("⚠️ Ошибка получения отложенных уведомлений: %v", err)
+		return
+	}
+	if len(deferred) == 0 {
+		return
+	}
+
+	var reminders strings.Builder
+	digest := false
+	seen := make(map[int]bool)
+	var reports []database.DeferredNotification
+	replayed := make(map[MessageKind]bool)
+
+	for _, notification := range deferred {
+		kind := MessageKind(notification.Kind)
+		switch kind {
+		case KindTaskDigest:
+			digest = true
+			continue
+		case KindTaskReminder, KindTaskLead:
+		default:
+			if !replayed[kind] {
+				replayed[kind] = true
+				reports = append(reports, notification)
+			}
+			continue
+		}
+		if seen[notification.TaskID] {
+			continue
+		}
+		seen[notification.TaskID] = true
+
+		task, err := ns.repository.GetTaskByID(notification.TaskID)
+		if err != nil || !task.Status.Open() {
+			// Задачу удалили или уже закрыли - напоминать не о чем
+			continue
+		}
+		reminders.WriteString(fmt.Sprintf(
+			"⏰ <b>%s</b> — %s\n<i>%s</i>\n\n",
+			html.EscapeString(ns.repository.Registry().PillarName(task.Pillar)),
+			utils.FormatTimeForDisplay(task.TimeUTC), html.EscapeString(task.Description),
+		))
+	}
+
+	var message strings.Builder
+	if reminders.Len() > 0 {
+		message.WriteString("🔔 <b>Пока действовала тишина</b>\n\n")
+		message.WriteString(reminders.String())
+		message.WriteString("Отметить выполнение: /today\n\n")
+	}
+	if digest {
+		text, err := ns.todayDigest()
+		if err != nil {
+			log.Printf("⚠️ Ошибка получения сводки дня: %v", err)
+			return
+		}
+		message.WriteString(text)
+	}
+
+	if message.Len() > 0 {
+		if err := ns.Notify(KindTaskDigest, message.String()); err != nil {
+			log.Printf("❌ Ошибка отправки отложенных уведомлений: %v", err)
+			return
+		}
+	}
+
+	if err := ns.repository.DeleteDeferredNotifications(deferred[len(deferred)-1].ID); err != nil {
+		log.Printf("⚠️ Ошибка очистки отложенных уведомлений: %v", err)
+	}
+	for _, report := range reports {
+		ns.replayDeferred(MessageKind(report.Kind), report.CreatedAt)
+	}
+	log.Printf("🔔 Тишина закончилась, отправлены отложенные уведомления: %d", len(deferred))
+}
+
+// replayDeferred отправляет отложенный отчет или ритуал. Отчеты собираются
+// на тот день, когда их откладывали: тишина могла перейти через полночь.
+func (ns *NotificationService) replayDeferred(kind MessageKind, deferredAt time.Time) {
+	day := deferredAt.UTC()
+	switch kind {
+	case KindDailySummary:
+		ns.sendDailySummary(day.Format("2006-01-02"))
+	case KindWeeklyReport:
+		ns.sendWeeklyReport(day)
+	case KindGoalsReport:
+		ns.SendGoalsReport()
+	case KindFeelingsReminder:
+		ns.SendFeelingsReminder()
+	case KindMorningBriefing:
+		ns.sendMorningBriefing(day.Format("2006-01-02"))
+	case KindEveningPlan:
+		ns.sendEveningPlan(day.AddDate(0, 0, 1).Format("2006-01-02"))
+	default:
+		log.Printf("⚠️ Неизвестный тип отложенного уведомления: %s", kind)
 	}
 }
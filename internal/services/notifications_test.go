@@ -0,0 +1,47 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"five-pillars/internal/database"
+)
+
+func TestFormattersEscapeUserText(t *testing.T) {
+	const raw = "Q&A <встреча>"
+	const escaped = "Q&amp;A &lt;встреча&gt;"
+	registry := database.NewRegistry()
+
+	task := database.DailyTask{
+		ID: 1, Pillar: "focus", Description: raw, TimeUTC: "10:00", Date: "2030-01-10",
+		Status: database.StatusPlanned,
+	}
+
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"напоминание", FormatTaskNotification(registry, database.TaskNotification{
+			Pillar: "focus", Description: raw, TimeUTC: "10:00", Notes: raw, MetricUnit: raw, MetricTarget: 1,
+			Checklist: []database.ChecklistItem{{Text: raw}},
+		})},
+		{"напоминание заранее", FormatLeadReminder(registry, task, 30)},
+		{"утренний брифинг", FormatMorningBriefing(registry, &MorningBriefing{
+			Date: "2030-01-10", Tasks: []database.DailyTask{task}, Unfinished: []database.DailyTask{task},
+		})},
+		{"вечерний план", FormatEveningPlan(registry, &EveningPlan{Date: "2030-01-10", Tasks: []database.DailyTask{task}})},
+		{"итоги переноса", FormatRollover(registry, &RolloverResult{
+			Carried: []database.DailyTask{task}, Missed: []database.DailyTask{task}, Ask: []database.DailyTask{task},
+		})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if strings.Contains(tt.text, raw) {
+				t.Errorf("текст не экранирован:\n%s", tt.text)
+			}
+			if !strings.Contains(tt.text, escaped) {
+				t.Errorf("нет экранированного текста:\n%s", tt.text)
+			}
+		})
+	}
+}
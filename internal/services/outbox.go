@@ -0,0 +1,64 @@
+package services
+
+import (
+	"time"
+
+	"five-pillars/internal/database"
+)
+
+// OutboxService исходящая очередь сообщений бота: сообщения сначала
+// записываются в БД, а отправляет их фоновый обработчик транспорта
+type OutboxService struct {
+	repository *database.Repository
+}
+
+func NewOutboxService(repo *database.Repository) *OutboxService {
+	return &OutboxService{
+		repository: repo,
+	}
+}
+
+// Enqueue ставит сообщение в очередь
+func (ob *OutboxService) Enqueue(msg database.OutboxMessage) (int, error) {
+	return ob.repository.EnqueueOutbox(msg)
+}
+
+// Pending неотправленные сообщения по порядку
+func (ob *OutboxService) Pending(limit int) ([]database.OutboxMessage, error) {
+	return ob.repository.GetPendingOutbox(limit)
+}
+
+// MarkSent сообщение доставлено
+func (ob *OutboxService) MarkSent(msg database.OutboxMessage, attempts int) error {
+	return ob.repository.MarkOutboxSent(msg.ID, attempts, time.Now())
+}
+
+// Retry переносит следующую попытку на next
+func (ob *OutboxService) Retry(msg database.OutboxMessage, attempts int, next time.Time, lastError string) error {
+	return ob.repository.RescheduleOutbox(msg.ID, database.OutboxPending, attempts, next, lastError)
+}
+
+// Bury переносит сообщение в недоставленные
+func (ob *OutboxService) Bury(msg database.OutboxMessage, attempts int, lastError string) error {
+	return ob.repository.RescheduleOutbox(msg.ID, database.OutboxDead, attempts, time.Now(), lastError)
+}
+
+// Dead последние недоставленные сообщения
+func (ob *OutboxService) Dead(limit int) ([]database.OutboxMessage, error) {
+	return ob.repository.GetOutboxByStatus(database.OutboxDead, limit)
+}
+
+// RetryDead возвращает недоставленные сообщения в очередь
+func (ob *OutboxService) RetryDead() (int, error) {
+	return ob.repository.RetryDeadOutbox(time.Now())
+}
+
+// Cleanup удаляет отправленные сообщения старше keep
+func (ob *OutboxService) Cleanup(keep time.Duration) (int, error) {
+	return ob.repository.DeleteSentOutbox(time.Now().Add(-keep))
+}
+
+// Counts число сообщений по статусам
+func (ob *OutboxService) Counts() (map[string]int, error) {
+	return ob.repository.CountOutbox()
+}
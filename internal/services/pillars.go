@@ -0,0 +1,238 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"five-pillars/internal/database"
+)
+
+// Ограничения справочника столпов в символах
+const (
+	maxPillarName  = 30
+	maxPillarEmoji = 8
+)
+
+var (
+	pillarKeyPattern   = regexp.MustCompile(`^[a-z][a-z0-9_]{1,19}$`)
+	pillarColorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+	ErrInvalidPillarKey   = NewValidationError("Ключ столпа - латиница, цифры и _, от 2 до 20 символов, например finance")
+	ErrInvalidPillarName  = NewValidationError(fmt.Sprintf("Название столпа не может быть пустым и длиннее %d символов", maxPillarName))
+	ErrInvalidPillarEmoji = NewValidationError(fmt.Sprintf("Эмодзи столпа не может быть пустым и длиннее %d символов", maxPillarEmoji))
+	ErrInvalidPillarColor = NewValidationError("Цвет столпа в формате #RRGGBB")
+	ErrPillarMarkup       = NewValidationError("Название и эмодзи столпа не могут содержать символы <, > и &")
+	ErrLastPillar         = NewValidationError("Нельзя убрать в архив последний действующий столп")
+)
+
+// ParsePillar распознает действующий столп по ключу, названию или синониму
+func ParsePillar(registry *database.Registry, value string) (database.Pillar, error) {
+	pillar, ok := registry.LookupPillar(value)
+	if !ok {
+		return "", unknownPillarError(registry)
+	}
+	return pillar.Key, nil
+}
+
+// unknownPillarError перечисляет действующие столпы, чтобы было из чего выбрать
+func unknownPillarError(registry *database.Registry) error {
+	var names []string
+	for _, pillar := range registry.Pillars(false) {
+		names = append(names, strings.ToLower(pillar.Name))
+	}
+	return NewValidationError("Неизвестный столп. Используйте: " + strings.Join(names, ", "))
+}
+
+// PillarPatch изменения столпа, nil - поле не меняется
+type PillarPatch struct {
+	Name     *string   `json:"name"`
+	Emoji    *string   `json:"emoji"`
+	Color    *string   `json:"color"`
+	Aliases  *[]string `json:"aliases"`
+	Position *int      `json:"position"`
+	Archived *bool     `json:"archived"`
+}
+
+// ParsePillarPatch изменение одного поля из команды: name|emoji|color|aliases|position.
+// Синонимы перечисляются через запятую, "-" очищает цвет и синонимы.
+func ParsePillarPatch(field, value string) (PillarPatch, error) {
+	value = strings.TrimSpace(value)
+	if value == "-" {
+		value = ""
+	}
+
+	var patch PillarPatch
+	switch strings.ToLower(field) {
+	case "name":
+		patch.Name = &value
+	case "emoji":
+		patch.Emoji = &value
+	case "color":
+		patch.Color = &value
+	case "aliases":
+		aliases := strings.Split(value, ",")
+		patch.Aliases = &aliases
+	case "position":
+		position, err := strconv.Atoi(value)
+		if err != nil || position < 1 {
+			return PillarPatch{}, NewValidationError("Позиция столпа - число от 1")
+		}
+		patch.Position = &position
+	default:
+		return PillarPatch{}, NewValidationError("Неизвестное поле столпа. Доступны: name, emoji, color, aliases, position")
+	}
+	return patch, nil
+}
+
+type PillarService struct {
+	repository *database.Repository
+}
+
+func NewPillarService(repo *database.Repository) *PillarService {
+	return &PillarService{repository: repo}
+}
+
+// List столпы по порядку, archived - вместе с архивными
+func (ps *PillarService) List(archived bool) []database.PillarInfo {
+	return ps.repository.Registry().Pillars(archived)
+}
+
+// Get столп по ключу, включая архивные
+func (ps *PillarService) Get(key string) (*database.PillarInfo, error) {
+	pillar, ok := ps.repository.Registry().GetPillar(database.Pillar(strings.ToLower(strings.TrimSpace(key))))
+	if !ok {
+		return nil, NewValidationError(fmt.Sprintf("Столп «%s» не найден", key))
+	}
+	return &pillar, nil
+}
+
+// Add добавляет столп в справочник, Position 0 - в конец списка
+func (ps *PillarService) Add(pillar database.PillarInfo) (*database.PillarInfo, error) {
+	pillar.Key = database.Pillar(strings.ToLower(strings.TrimSpace(string(pillar.Key))))
+	if !pillarKeyPattern.MatchString(string(pillar.Key)) {
+		return nil, ErrInvalidPillarKey
+	}
+	if _, exists := ps.repository.Registry().GetPillar(pillar.Key); exists {
+		return nil, NewValidationError(fmt.Sprintf("Столп «%s» уже есть", pillar.Key))
+	}
+	if pillar.Position < 0 {
+		pillar.Position = 0
+	}
+	pillar.Archived = false
+
+	if err := validatePillar(ps.repository.Registry(), &pillar); err != nil {
+		return nil, err
+	}
+	if err := ps.repository.AddPillar(pillar); err != nil {
+		return nil, err
+	}
+	return ps.Get(string(pillar.Key))
+}
+
+// Update меняет поля столпа из patch
+func (ps *PillarService) Update(key string, patch PillarPatch) (*database.PillarInfo, error) {
+	current, err := ps.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	pillar := *current
+
+	if patch.Name != nil {
+		pillar.Name = *patch.Name
+	}
+	if patch.Emoji != nil {
+		pillar.Emoji = *patch.Emoji
+	}
+	if patch.Color != nil {
+		pillar.Color = *patch.Color
+	}
+	if patch.Aliases != nil {
+		pillar.Aliases = *patch.Aliases
+	}
+	if patch.Position != nil {
+		pillar.Position = *patch.Position
+	}
+	if patch.Archived != nil {
+		if *patch.Archived && !pillar.Archived && len(ps.repository.Registry().Pillars(false)) == 1 {
+			return nil, ErrLastPillar
+		}
+		pillar.Archived = *patch.Archived
+	}
+
+	if err := validatePillar(ps.repository.Registry(), &pillar); err != nil {
+		return nil, err
+	}
+	if _, err := ps.repository.UpdatePillar(pillar); err != nil {
+		return nil, err
+	}
+	return ps.Get(string(pillar.Key))
+}
+
+// validatePillar нормализует поля столпа и проверяет, что его названия не
+// совпадают с названиями других столпов
+func validatePillar(registry *database.Registry, pillar *database.PillarInfo) error {
+	pillar.Name = strings.TrimSpace(pillar.Name)
+	if pillar.Name == "" || len([]rune(pillar.Name)) > maxPillarName {
+		return ErrInvalidPillarName
+	}
+	pillar.Emoji = strings.TrimSpace(pillar.Emoji)
+	if pillar.Emoji == "" || len([]rune(pillar.Emoji)) > maxPillarEmoji {
+		return ErrInvalidPillarEmoji
+	}
+	// Названия столпов попадают в сообщения с HTML-разметкой без экранирования
+	if strings.ContainsAny(pillar.Name+pillar.Emoji, "<>&") {
+		return ErrPillarMarkup
+	}
+	pillar.Color = strings.TrimSpace(pillar.Color)
+	if pillar.Color != "" && !pillarColorPattern.MatchString(pillar.Color) {
+		return ErrInvalidPillarColor
+	}
+
+	var aliases []string
+	seen := make(map[string]bool)
+	for _, alias := range pillar.Aliases {
+		alias = strings.ToLower(strings.TrimSpace(alias))
+		if alias == "" || seen[alias] || alias == string(pillar.Key) || alias == strings.ToLower(pillar.Name) {
+			continue
+		}
+		if strings.ContainsAny(alias, " <>&") || len([]rune(alias)) > maxPillarName {
+			return NewValidationError(fmt.Sprintf("Синоним «%s» должен быть одним словом без <, > и & не длиннее %d символов", alias, maxPillarName))
+		}
+		seen[alias] = true
+		aliases = append(aliases, alias)
+	}
+	pillar.Aliases = aliases
+
+	for _, other := range registry.Pillars(true) {
+		if other.Key == pillar.Key {
+			continue
+		}
+		for _, name := range pillar.Names() {
+			for _, taken := range other.Names() {
+				if name == taken {
+					return NewValidationError(fmt.Sprintf("Название «%s» уже занято столпом %s", name, other.Title()))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// FormatPillars справочник столпов: название и все имена, по которым
+// столп понимают команды
+func FormatPillars(pillars []database.PillarInfo) string {
+	var b strings.Builder
+	for _, pillar := range pillars {
+		b.WriteString(fmt.Sprintf("%s - %s", pillar.Title(), strings.Join(pillar.Names(), ", ")))
+		if pillar.Color != "" {
+			b.WriteString(" · " + pillar.Color)
+		}
+		if pillar.Archived {
+			b.WriteString(" · 📦 в архиве")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"five-pillars/internal/database"
+	"five-pillars/internal/utils"
+)
+
+// maxDND на сколько максимум можно включить "не беспокоить"
+const maxDND = 7 * 24 * time.Hour
+
+// QuietHours ежедневные тихие часы по московскому времени. Start и End -
+// минуты от полуночи, окно может переходить через полночь (23:00-08:00).
+// Нулевое значение - тихие часы выключены.
+type QuietHours struct {
+	Start int
+	End   int
+}
+
+// ParseQuietHours разбирает окно вида "23:00-08:00" (МСК), пустая строка -
+// тихие часы выключены
+func ParseQuietHours(spec string) (QuietHours, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return QuietHours{}, nil
+	}
+
+	from, to, ok := strings.Cut(spec, "-")
+	if !ok {
+		return QuietHours{}, fmt.Errorf("неверные тихие часы %q, ожидается ЧЧ:ММ-ЧЧ:ММ", spec)
+	}
+
+	start, err := parseClock(from)
+	if err != nil {
+		return QuietHours{}, fmt.Errorf("неверные тихие часы %q: %v", spec, err)
+	}
+	end, err := parseClock(to)
+	if err != nil {
+		return QuietHours{}, fmt.Errorf("неверные тихие часы %q: %v", spec, err)
+	}
+	if start == end {
+		return QuietHours{}, fmt.Errorf("неверные тихие часы %q: начало совпадает с концом", spec)
+	}
+
+	return QuietHours{Start: start, End: end}, nil
+}
+
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("время должно быть в формате ЧЧ:ММ")
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func (q QuietHours) Enabled() bool {
+	return q.Start != q.End
+}
+
+// Contains попадает ли момент в тихие часы
+func (q QuietHours) Contains(t time.Time) bool {
+	if !q.Enabled() {
+		return false
+	}
+
+	msk := t.In(utils.MoscowLocation())
+	minute := msk.Hour()*60 + msk.Minute()
+	if q.Start < q.End {
+		return minute >= q.Start && minute < q.End
+	}
+	return minute >= q.Start || minute < q.End
+}
+
+// EndAfter ближайший конец тихих часов после t
+func (q QuietHours) EndAfter(t time.Time) time.Time {
+	msk := t.In(utils.MoscowLocation())
+	end := time.Date(msk.Year(), msk.Month(), msk.Day(), q.End/60, q.End%60, 0, 0, msk.Location())
+	if !end.After(t) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end
+}
+
+func (q QuietHours) String() string {
+	if !q.Enabled() {
+		return "выключены"
+	}
+	return fmt.Sprintf("%02d:%02d-%02d:%02d МСК", q.Start/60, q.Start%60, q.End/60, q.End%60)
+}
+
+// QuietState действует ли сейчас тишина и до какого момента
+type QuietState struct {
+	Active bool
+	Until  time.Time
+	// DNDUntil включенный вручную режим "не беспокоить", нулевое - не включен
+	DNDUntil time.Time
+}
+
+// QuietService тихие часы и режим "не беспокоить": в это время напоминания
+// о задачах и сводки откладываются и приходят одним сообщением после
+type QuietService struct {
+	repository *database.Repository
+	hours      QuietHours
+}
+
+func NewQuietService(repo *database.Repository) *QuietService {
+	return &QuietService{
+		repository: repo,
+	}
+}
+
+// SetHours задает ежедневные тихие часы
+func (qs *QuietService) SetHours(hours QuietHours) {
+	qs.hours = hours
+}
+
+func (qs *QuietService) Hours() QuietHours {
+	return qs.hours
+}
+
+// State состояние тишины на момент now
+func (qs *QuietService) State(now time.Time) (QuietState, error) {
+	var state QuietState
+
+	value, ok, err := qs.repository.GetSetting(database.SettingDNDUntil)
+	if err != nil {
+		return state, fmt.Errorf("ошибка чтения режима \"не беспокоить\": %v", err)
+	}
+	if ok {
+		if until, err := time.Parse(time.RFC3339, value); err == nil && until.After(now) {
+			state.DNDUntil = until
+		}
+	}
+
+	// Тихие часы и "не беспокоить" могут идти подряд: тишина длится,
+	// пока действует хотя бы одно из них
+	until := now
+	for i := 0; i < 2; i++ {
+		if qs.hours.Contains(until) {
+			until = qs.hours.EndAfter(until)
+		}
+		if state.DNDUntil.After(until) {
+			until = state.DNDUntil
+		}
+	}
+
+	if until.After(now) {
+		state.Active = true
+		state.Until = until
+	}
+	return state, nil
+}
+
+// SetDND включает "не беспокоить" до until
+func (qs *QuietService) SetDND(until time.Time) error {
+	if err := qs.repository.SetSetting(database.SettingDNDUntil, until.UTC().Format(time.RFC3339)); err != nil {
+		return fmt.Errorf("ошибка сохранения режима \"не беспокоить\": %v", err)
+	}
+	return nil
+}
+
+// ClearDND выключает "не беспокоить"
+func (qs *QuietService) ClearDND() error {
+	if err := qs.repository.DeleteSetting(database.SettingDNDUntil); err != nil {
+		return fmt.Errorf("ошибка выключения режима \"не беспокоить\": %v", err)
+	}
+	return nil
+}
+
+// ParseDND разбирает аргумент /dnd: длительность ("2h", "30m", "1ч30м")
+// или "until 14:00" / "до 14:00" по московскому времени
+func ParseDND(arg string, now time.Time) (time.Time, error) {
+	arg = strings.ToLower(strings.TrimSpace(arg))
+
+	for _, prefix := range []string{"until ", "до "} {
+		if clock, ok := strings.CutPrefix(arg, prefix); ok {
+			minute, err := parseClock(clock)
+			if err != nil {
+				return time.Time{}, NewValidationError("Время должно быть в формате ЧЧ:ММ (МСК), например: /dnd until 14:00")
+			}
+
+			msk := now.In(utils.MoscowLocation())
+			until := time.Date(msk.Year(), msk.Month(), msk.Day(), minute/60, minute%60, 0, 0, msk.Location())
+			if !until.After(now) {
+				until = until.AddDate(0, 0, 1)
+			}
+			return until, nil
+		}
+	}
+
+	duration, err := time.ParseDuration(strings.NewReplacer("ч", "h", "м", "m").Replace(strings.ReplaceAll(arg, " ", "")))
+	if err != nil || duration <= 0 {
+		return time.Time{}, NewValidationError("Укажите длительность (2h, 30m, 1ч30м) или время: /dnd until 14:00")
+	}
+	if duration > maxDND {
+		return time.Time{}, NewValidationError("Режим \"не беспокоить\" можно включить максимум на 7 дней")
+	}
+	return now.Add(duration), nil
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"five-pillars/internal/database"
+	"five-pillars/internal/utils"
+)
+
+func TestParseQuietHours(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    QuietHours
+		wantErr bool
+	}{
+		{spec: "", want: QuietHours{}},
+		{spec: "  ", want: QuietHours{}},
+		{spec: "23:00-08:00", want: QuietHours{Start: 23 * 60, End: 8 * 60}},
+		{spec: "13:30 - 14:15", want: QuietHours{Start: 13*60 + 30, End: 14*60 + 15}},
+		{spec: "23:00", wantErr: true},
+		{spec: "25:00-08:00", wantErr: true},
+		{spec: "23:00-8", wantErr: true},
+		{spec: "08:00-08:00", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseQuietHours(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseQuietHours(%q): %v", tt.spec, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseQuietHours(%q) = %+v, ожидалось %+v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuietHoursContains(t *testing.T) {
+	msk := utils.MoscowLocation()
+	night := QuietHours{Start: 23 * 60, End: 8 * 60}
+	lunch := QuietHours{Start: 13 * 60, End: 14 * 60}
+
+	tests := []struct {
+		name  string
+		hours QuietHours
+		clock string
+		want  bool
+	}{
+		{"ночь, до начала", night, "22:59", false},
+		{"ночь, начало", night, "23:00", true},
+		{"ночь, после полуночи", night, "03:00", true},
+		{"ночь, конец не входит", night, "08:00", false},
+		{"день, внутри", lunch, "13:30", true},
+		{"день, после", lunch, "14:00", false},
+		{"выключены", QuietHours{}, "03:00", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock, _ := time.Parse("15:04", tt.clock)
+			at := time.Date(2030, 1, 10, clock.Hour(), clock.Minute(), 0, 0, msk)
+			if got := tt.hours.Contains(at); got != tt.want {
+				t.Errorf("Contains(%s) = %v, ожидалось %v", tt.clock, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDND(t *testing.T) {
+	msk := utils.MoscowLocation()
+	now := time.Date(2030, 1, 10, 12, 0, 0, 0, msk)
+
+	tests := []struct {
+		arg     string
+		want    time.Time
+		wantErr bool
+	}{
+		{arg: "2h", want: now.Add(2 * time.Hour)},
+		{arg: "30m", want: now.Add(30 * time.Minute)},
+		{arg: "1ч30м", want: now.Add(90 * time.Minute)},
+		{arg: "1ч 30м", want: now.Add(90 * time.Minute)},
+		{arg: "until 14:00", want: time.Date(2030, 1, 10, 14, 0, 0, 0, msk)},
+		{arg: "до 09:00", want: time.Date(2030, 1, 11, 9, 0, 0, 0, msk)},
+		{arg: "until 12:00", want: time.Date(2030, 1, 11, 12, 0, 0, 0, msk)},
+		{arg: "until 25:00", wantErr: true},
+		{arg: "0m", wantErr: true},
+		{arg: "-1h", wantErr: true},
+		{arg: "8d", wantErr: true},
+		{arg: "169h", wantErr: true},
+		{arg: "завтра", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			got, err := ParseDND(tt.arg, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDND(%q): %v", tt.arg, err)
+			}
+			if err != nil {
+				if !IsValidationError(err) {
+					t.Errorf("ParseDND(%q): ошибка не для пользователя: %v", tt.arg, err)
+				}
+				return
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseDND(%q) = %s, ожидалось %s", tt.arg, got, tt.want)
+			}
+		})
+	}
+}
+
+// kindRecorder отправитель, который запоминает типы и тексты сообщений
+type kindRecorder struct {
+	kinds    []MessageKind
+	messages []string
+}
+
+func (r *kindRecorder) SendMessage(text string) error {
+	return r.SendKind(KindSystem, text)
+}
+
+func (r *kindRecorder) SendTaskNotification(task database.TaskNotification) error {
+	return r.SendKind(KindTaskReminder, task.Description)
+}
+
+func (r *kindRecorder) SendKind(kind MessageKind, text string) error {
+	r.kinds = append(r.kinds, kind)
+	r.messages = append(r.messages, text)
+	return nil
+}
+
+func TestQuietDefersScheduledSends(t *testing.T) {
+	sm := newTestServices(t)
+	recorder := &kindRecorder{}
+	sm.SetNotificationSender(recorder)
+	ns := sm.Notification
+
+	task := addTestTask(t, sm, "10:00")
+	if err := sm.Quiet.SetDND(time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	ns.SendDailySummary()
+	ns.SendFeelingsReminder()
+	ns.SendMorningBriefing()
+	ns.SendEveningPlan()
+	ns.SendWeeklyReport()
+	ns.SendAllTodayTaskNotification()
+	ns.deferNotification(KindTaskLead, task.ID)
+	ns.SendDeferred()
+
+	if len(recorder.kinds) > 0 {
+		t.Fatalf("во время тишины отправлено: %v", recorder.kinds)
+	}
+
+	if err := sm.Quiet.ClearDND(); err != nil {
+		t.Fatal(err)
+	}
+	ns.SendDeferred()
+
+	want := []MessageKind{
+		KindTaskDigest,
+		KindDailySummary,
+		KindFeelingsReminder,
+		KindMorningBriefing,
+		KindEveningPlan,
+		KindWeeklyReport,
+	}
+	if !reflect.DeepEqual(recorder.kinds, want) {
+		t.Fatalf("после тишины отправлено %v, ожидалось %v", recorder.kinds, want)
+	}
+	if !strings.Contains(recorder.messages[0], task.Description) {
+		t.Errorf("в сводке после тишины нет отложенного напоминания заранее:\n%s", recorder.messages[0])
+	}
+
+	// Отложенное отправляется один раз
+	ns.SendDeferred()
+	if len(recorder.kinds) != len(want) {
+		t.Errorf("отложенные уведомления отправлены повторно: %v", recorder.kinds[len(want):])
+	}
+}
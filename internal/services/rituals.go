@@ -0,0 +1,285 @@
+package services
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"time"
+
+	"five-pillars/internal/database"
+	"five-pillars/internal/utils"
+)
+
+// streakLookbackDays насколько далеко назад считаются серии
+const streakLookbackDays = 365
+
+// PillarStreak сколько дней подряд по столпу выполнялась хотя бы одна задача
+type PillarStreak struct {
+	Pillar database.Pillar
+	Days   int
+}
+
+// MorningBriefing утренний брифинг: план на сегодня, хвосты вчерашнего дня,
+// серии по столпам и сон
+type MorningBriefing struct {
+	Date string
+	// Tasks задачи на сегодня
+	Tasks []database.DailyTask
+	// Unfinished вчерашние задачи, которые остались открытыми
+	Unfinished []database.DailyTask
+	Streaks    []PillarStreak
+	// SleepHours сон прошлой ночи, 0 - не записан
+	SleepHours float64
+	// Sleep прошлая ночь из журнала сна, nil - записаны только часы или ничего
+	Sleep *database.SleepEntry
+}
+
+// EveningPlan вечернее планирование: открытые задачи на завтра, которые еще можно поправить
+type EveningPlan struct {
+	Date  string
+	Tasks []database.DailyTask
+}
+
+// RitualSender отправитель, который умеет показывать кнопки брифинга и
+// планирования (Telegram). Остальным уходит текст.
+type RitualSender interface {
+	SendMorningBriefing(briefing *MorningBriefing) error
+	SendEveningPlan(plan *EveningPlan) error
+}
+
+// GetStreaks серии по столпам на дату date. День без задач столпа серию
+// не прерывает, день с задачами, но без выполненных - прерывает. Сегодняшний
+// день засчитывается, только если по столпу уже что-то выполнено.
+func (as *AnalyticsService) GetStreaks(date string) ([]PillarStreak, error) {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil, ErrInvalidDate
+	}
+
+	tasks, err := as.repository.GetTasksBetween(day.AddDate(0, 0, -streakLookbackDays).Format("2006-01-02"), date)
+	if err != nil {
+		return nil, err
+	}
+
+	// done[столп][дата]: true - что-то выполнено, false - были только невыполненные
+	done := make(map[database.Pillar]map[string]bool)
+	for _, task := range tasks {
+		// Отмененные не считаются, перенесенная засчитывается в день, куда её перенесли
+		if !task.Status.Counted() {
+			continue
+		}
+		if done[task.Pillar] == nil {
+			done[task.Pillar] = make(map[string]bool)
+		}
+		done[task.Pillar][task.Date] = done[task.Pillar][task.Date] || task.Status == database.StatusDone
+	}
+
+	var streaks []PillarStreak
+	for _, info := range as.repository.Registry().Pillars(false) {
+		pillar := info.Key
+		days := 0
+		for i := 0; i <= streakLookbackDays; i++ {
+			d := day.AddDate(0, 0, -i).Format("2006-01-02")
+			completed, hasTasks := done[pillar][d]
+			if !hasTasks || (i == 0 && !completed) {
+				continue
+			}
+			if !completed {
+				break
+			}
+			days++
+		}
+		streaks = append(streaks, PillarStreak{Pillar: pillar, Days: days})
+	}
+	return streaks, nil
+}
+
+// MorningBriefing собирает брифинг на дату date
+func (ns *NotificationService) MorningBriefing(date string) (*MorningBriefing, error) {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil, ErrInvalidDate
+	}
+	yesterday := day.AddDate(0, 0, -1).Format("2006-01-02")
+
+	briefing := &MorningBriefing{Date: date}
+
+	if briefing.Tasks, err = ns.repository.GetTasksByDate(date); err != nil {
+		return nil, err
+	}
+
+	previous, err := ns.repository.GetTasksByDate(yesterday)
+	if err != nil {
+		return nil, err
+	}
+	for _, task := range previous {
+		if task.Status.Open() {
+			briefing.Unfinished = append(briefing.Unfinished, task)
+		}
+	}
+
+	if briefing.Streaks, err = ns.analytics.GetStreaks(date); err != nil {
+		return nil, err
+	}
+
+	// Утром сон записывают в журнал сна, иначе его видно в ощущениях:
+	// их обычно заполняют вечером того же дня, но могли и утром
+	entries, err := ns.repository.GetSleepBetween(date, date)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) > 0 {
+		briefing.Sleep = &entries[0]
+		briefing.SleepHours = entries[0].Hours
+		return briefing, nil
+	}
+	for _, d := range []string{date, yesterday} {
+		if feelings, err := ns.repository.GetFeelings(d); err == nil && feelings.SleepHours > 0 {
+			briefing.SleepHours = feelings.SleepHours
+			break
+		}
+	}
+
+	return briefing, nil
+}
+
+// EveningPlan собирает план на дату date
+func (ns *NotificationService) EveningPlan(date string) (*EveningPlan, error) {
+	if err := ValidateDate(date); err != nil {
+		return nil, err
+	}
+
+	tasks, err := ns.repository.GetTasksByDate(date)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &EveningPlan{Date: date}
+	for _, task := range tasks {
+		if task.Status.Open() {
+			plan.Tasks = append(plan.Tasks, task)
+		}
+	}
+	return plan, nil
+}
+
+// SendMorningBriefing отправляет утренний брифинг на сегодня
+func (ns *NotificationService) SendMorningBriefing() {
+	if ns.deferIfQuiet(KindMorningBriefing) {
+		return
+	}
+	ns.sendMorningBriefing(Today())
+}
+
+func (ns *NotificationService) sendMorningBriefing(date string) {
+	briefing, err := ns.MorningBriefing(date)
+	if err != nil {
+		log.Printf("⚠️ Ошибка подготовки утреннего брифинга: %v", err)
+		return
+	}
+
+	if sender, ok := ns.sender.(RitualSender); ok {
+		err = sender.SendMorningBriefing(briefing)
+	} else {
+		err = ns.Notify(KindMorningBriefing, FormatMorningBriefing(ns.repository.Registry(), briefing))
+	}
+	if err != nil {
+		log.Printf("❌ Ошибка отправки утреннего брифинга: %v", err)
+	}
+}
+
+// SendEveningPlan отправляет план на завтра. Задачи на завтра к этому
+// моменту должны быть созданы (TaskService.PrepareTasks).
+func (ns *NotificationService) SendEveningPlan() {
+	if ns.deferIfQuiet(KindEveningPlan) {
+		return
+	}
+	ns.sendEveningPlan(time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02"))
+}
+
+func (ns *NotificationService) sendEveningPlan(date string) {
+	plan, err := ns.EveningPlan(date)
+	if err != nil {
+		log.Printf("⚠️ Ошибка подготовки плана на завтра: %v", err)
+		return
+	}
+
+	if sender, ok := ns.sender.(RitualSender); ok {
+		err = sender.SendEveningPlan(plan)
+	} else {
+		err = ns.Notify(KindEveningPlan, FormatEveningPlan(ns.repository.Registry(), plan))
+	}
+	if err != nil {
+		log.Printf("❌ Ошибка отправки плана на завтра: %v", err)
+	}
+}
+
+// FormatMorningBriefing текст утреннего брифинга
+func FormatMorningBriefing(registry *database.Registry, briefing *MorningBriefing) string {
+	var message strings.Builder
+	message.WriteString(fmt.Sprintf("☀️ <b>Доброе утро! План на %s</b>\n\n", briefing.Date))
+
+	if len(briefing.Tasks) == 0 {
+		message.WriteString("📭 На сегодня задач нет\n")
+	}
+	byPillar := make(map[database.Pillar][]string)
+	for _, task := range briefing.Tasks {
+		byPillar[task.Pillar] = append(byPillar[task.Pillar], fmt.Sprintf("%s %s — %s",
+			taskMark(task), utils.FormatTimeForDisplay(task.TimeUTC), html.EscapeString(task.Description)))
+	}
+	for _, pillar := range database.SortedPillars(registry, byPillar) {
+		message.WriteString(fmt.Sprintf("<b>%s</b>\n%s\n\n", html.EscapeString(registry.PillarName(pillar)), strings.Join(byPillar[pillar], "\n")))
+	}
+
+	if len(briefing.Unfinished) > 0 {
+		message.WriteString("<b>↩️ Не закончено вчера:</b>\n")
+		for _, task := range briefing.Unfinished {
+			message.WriteString(fmt.Sprintf("#%d %s %s\n", task.ID, registry.PillarEmoji(task.Pillar), html.EscapeString(task.Description)))
+		}
+		message.WriteString("\n")
+	}
+
+	var streaks []string
+	for _, streak := range briefing.Streaks {
+		if streak.Days > 0 {
+			streaks = append(streaks, fmt.Sprintf("%s %d", registry.PillarEmoji(streak.Pillar), streak.Days))
+		}
+	}
+	if len(streaks) > 0 {
+		message.WriteString("🔥 Серии, дней подряд: " + strings.Join(streaks, " · ") + "\n")
+	}
+
+	switch {
+	case briefing.Sleep != nil:
+		message.WriteString(fmt.Sprintf("😴 Сон: %.1f ч, %s-%s\n", briefing.SleepHours, briefing.Sleep.Bedtime, briefing.Sleep.WakeTime))
+	case briefing.SleepHours > 0:
+		message.WriteString(fmt.Sprintf("😴 Сон: %.1f ч\n", briefing.SleepHours))
+	default:
+		message.WriteString("😴 Сон не записан: /sleep 23:40-07:10\n")
+	}
+
+	return strings.TrimRight(message.String(), "\n")
+}
+
+// FormatEveningPlan текст вечернего планирования
+func FormatEveningPlan(registry *database.Registry, plan *EveningPlan) string {
+	var message strings.Builder
+	message.WriteString(fmt.Sprintf("🌙 <b>План на завтра, %s</b>\n\n", plan.Date))
+
+	if len(plan.Tasks) == 0 {
+		message.WriteString("📭 Задач на завтра нет. Добавить: /add [столп] [описание] HH:mm\n")
+		return message.String()
+	}
+
+	for _, task := range plan.Tasks {
+		message.WriteString(fmt.Sprintf("#%d %s %s\n<i>%s</i>\n\n",
+			task.ID, registry.PillarEmoji(task.Pillar), utils.FormatTimeForDisplay(task.TimeUTC), html.EscapeString(task.Description)))
+	}
+	message.WriteString("Поправить: /time [id] HH:mm, /date [id] YYYY-MM-DD")
+	return message.String()
+}
+
+func taskMark(task database.DailyTask) string {
+	return StatusMarks[task.Status]
+}
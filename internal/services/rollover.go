@@ -0,0 +1,367 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"time"
+
+	"five-pillars/internal/database"
+)
+
+// RolloverPolicy что делать с задачей, не закрытой к концу дня
+type RolloverPolicy string
+
+const (
+	// RolloverAuto по настройкам столпа, иначе разовые переносятся,
+	// повторяющиеся отмечаются несделанными
+	RolloverAuto RolloverPolicy = ""
+	// RolloverCarry перенести на завтра
+	RolloverCarry RolloverPolicy = "carry"
+	// RolloverMiss отметить несделанной
+	RolloverMiss RolloverPolicy = "miss"
+	// RolloverAsk спросить кнопками
+	RolloverAsk RolloverPolicy = "ask"
+)
+
+var ErrUnknownRollover = NewValidationError("Неизвестное правило переноса. Используйте: carry, miss, ask или auto")
+
+// ParseRolloverPolicy разбирает правило переноса, "auto" и пустая строка -
+// по настройкам столпа
+func ParseRolloverPolicy(value string) (RolloverPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "auto":
+		return RolloverAuto, nil
+	case "carry", "перенести":
+		return RolloverCarry, nil
+	case "miss", "не сделано":
+		return RolloverMiss, nil
+	case "ask", "спросить":
+		return RolloverAsk, nil
+	default:
+		return "", ErrUnknownRollover
+	}
+}
+
+// ParseRolloverPolicies разбирает правила по столпам: "тело=miss, фокус=ask"
+func ParseRolloverPolicies(registry *database.Registry, spec string) (map[database.Pillar]RolloverPolicy, error) {
+	policies := make(map[database.Pillar]RolloverPolicy)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("неверное правило переноса %q, ожидается столп=carry|miss|ask", part)
+		}
+		pillar, err := ParsePillar(registry, name)
+		if err != nil {
+			return nil, fmt.Errorf("неверное правило переноса %q: %v", part, err)
+		}
+		policy, err := ParseRolloverPolicy(value)
+		if err != nil {
+			return nil, fmt.Errorf("неверное правило переноса %q: %v", part, err)
+		}
+		policies[pillar] = policy
+	}
+	return policies, nil
+}
+
+// RolloverResult что сделано с незакрытыми задачами
+type RolloverResult struct {
+	// Carried задачи-копии на новый день, CarriedFrom ведет на исходную
+	Carried []database.DailyTask
+	Missed  []database.DailyTask
+	// Ask задачи, по которым решение примет пользователь
+	Ask []database.DailyTask
+}
+
+// Empty ничего не перенесено и спрашивать не о чем
+func (r *RolloverResult) Empty() bool {
+	return len(r.Carried) == 0 && len(r.Missed) == 0 && len(r.Ask) == 0
+}
+
+// RolloverSender отправитель, который умеет показывать кнопки выбора для
+// задач RolloverAsk (Telegram). Остальным уходит текст.
+type RolloverSender interface {
+	SendRollover(result *RolloverResult) error
+}
+
+// RolloverService переносит незакрытые задачи в конце дня: на завтра,
+// в несделанные или спрашивает пользователя
+type RolloverService struct {
+	repository *database.Repository
+	events     *eventBus
+	policies   map[database.Pillar]RolloverPolicy
+}
+
+func NewRolloverService(repo *database.Repository, events *eventBus) *RolloverService {
+	return &RolloverService{
+		repository: repo,
+		events:     events,
+		policies:   make(map[database.Pillar]RolloverPolicy),
+	}
+}
+
+// SetPolicies задает правила по столпам
+func (rs *RolloverService) SetPolicies(policies map[database.Pillar]RolloverPolicy) {
+	rs.policies = policies
+}
+
+// Policy правило для задачи: шаблона, столпа или по умолчанию
+func (rs *RolloverService) Policy(task database.DailyTask, template *database.TaskTemplate) RolloverPolicy {
+	if template != nil && template.Rollover != "" {
+		return RolloverPolicy(template.Rollover)
+	}
+	if policy := rs.policies[task.Pillar]; policy != RolloverAuto {
+		return policy
+	}
+	if task.TemplateID != 0 {
+		return RolloverMiss
+	}
+	return RolloverCarry
+}
+
+// Run обрабатывает задачи, срок которых наступил после прошлого запуска и
+// не позже now. При первом запуске - только задачи текущего дня.
+func (rs *RolloverService) Run(now time.Time) (*RolloverResult, error) {
+	now = now.UTC()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	last, ok, err := rs.repository.GetSetting(database.SettingRolloverAt)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		if at, err := time.Parse(time.RFC3339, last); err == nil {
+			from = at.UTC()
+		}
+	}
+
+	tasks, err := rs.repository.GetUnfinishedTasksDue(from.Format("2006-01-02 15:04"), now.Format("2006-01-02 15:04"))
+	if err != nil {
+		return nil, err
+	}
+
+	templates := make(map[int]*database.TaskTemplate)
+	if len(tasks) > 0 {
+		all, err := rs.repository.GetTemplates(false)
+		if err != nil {
+			return nil, err
+		}
+		for i := range all {
+			templates[all[i].ID] = &all[i]
+		}
+	}
+
+	tomorrow := now.AddDate(0, 0, 1).Format("2006-01-02")
+	result := &RolloverResult{}
+	for _, task := range tasks {
+		switch rs.Policy(task, templates[task.TemplateID]) {
+		case RolloverAsk:
+			result.Ask = append(result.Ask, task)
+		case RolloverCarry:
+			carried, err := rs.Carry(task.ID, tomorrow)
+			if err != nil {
+				log.Printf("⚠️ Ошибка переноса задачи #%d: %v", task.ID, err)
+				continue
+			}
+			if carried.ID == task.ID {
+				// На завтра такая задача уже есть, копию не создаем
+				result.Missed = append(result.Missed, *carried)
+				continue
+			}
+			result.Carried = append(result.Carried, *carried)
+		default:
+			missed, err := rs.Miss(task.ID)
+			if err != nil {
+				log.Printf("⚠️ Ошибка отметки задачи #%d: %v", task.ID, err)
+				continue
+			}
+			result.Missed = append(result.Missed, *missed)
+		}
+	}
+
+	if err := rs.repository.SetSetting(database.SettingRolloverAt, now.Format(time.RFC3339)); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Carry переносит задачу на date: создает копию со ссылкой на исходную,
+// исходная становится перенесенной. Пустая дата - на следующий день после
+// задачи, но не раньше сегодняшнего. Если такая задача на эту дату уже
+// есть, исходная отмечается несделанной и возвращается она.
+func (rs *RolloverService) Carry(taskID int, date string) (*database.DailyTask, error) {
+	task, err := rs.getTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if !CanTransition(task.Status, database.StatusRescheduled) {
+		return nil, transitionError(task, database.StatusRescheduled)
+	}
+
+	if date == "" {
+		date = nextDate(task.Date)
+		if today := Today(); date < today {
+			date = today
+		}
+	}
+	if err := ValidateDate(date); err != nil {
+		return nil, err
+	}
+
+	exists, err := rs.repository.TaskExists(date, task.Pillar, task.Description)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		if task.Status == database.StatusMissed {
+			return task, nil
+		}
+		return rs.Miss(taskID)
+	}
+
+	// Отмеченные пункты чек-листа переносятся вместе с задачей
+	checklist := make([]database.ChecklistItem, len(task.Checklist))
+	for i, item := range task.Checklist {
+		checklist[i] = database.ChecklistItem{Text: item.Text, Done: item.Done}
+	}
+
+	carried := database.DailyTask{
+		Pillar:      task.Pillar,
+		Description: task.Description,
+		TimeUTC:     task.TimeUTC,
+		Date:        date,
+		Notes:       task.Notes,
+		StartUTC:    task.StartUTC,
+		LeadMinutes: task.LeadMinutes,
+		TemplateID:  task.TemplateID,
+		CarriedFrom: task.ID,
+		Checklist:   checklist,
+
+		// Результат вносится заново в день, куда перенесена задача
+		MetricUnit:   task.MetricUnit,
+		MetricTarget: task.MetricTarget,
+		MetricAgg:    task.MetricAgg,
+		GoalID:       task.GoalID,
+		Tags:         task.Tags,
+	}
+	id, err := rs.repository.AddTask(carried)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setStatus(rs.repository, task, database.StatusRescheduled); err != nil {
+		// Задачу успели закрыть, копия не нужна
+		if err := rs.repository.DeleteTask(id); err != nil {
+			log.Printf("⚠️ Ошибка удаления копии задачи #%d: %v", id, err)
+		}
+		return nil, err
+	}
+
+	created, err := rs.repository.GetTaskByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	rs.events.publish(Event{Type: EventTaskCreated, Task: created})
+	return created, nil
+}
+
+// Miss отмечает незакрытую задачу несделанной
+func (rs *RolloverService) Miss(taskID int) (*database.DailyTask, error) {
+	task, err := rs.getTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setStatus(rs.repository, task, database.StatusMissed); err != nil {
+		return nil, err
+	}
+
+	rs.events.publish(Event{Type: EventTaskMissed, Task: task})
+	return task, nil
+}
+
+// SetTemplatePolicy задает правило переноса для шаблона
+func (rs *RolloverService) SetTemplatePolicy(templateID int, policy RolloverPolicy) error {
+	updated, err := rs.repository.UpdateTemplateRollover(templateID, string(policy))
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return NewValidationError(fmt.Sprintf("Шаблон #%d не найден", templateID))
+	}
+	return nil
+}
+
+func (rs *RolloverService) getTask(taskID int) (*database.DailyTask, error) {
+	task, err := rs.repository.GetTaskByID(taskID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTaskNotFound
+	}
+	return task, err
+}
+
+func nextDate(date string) string {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	return day.AddDate(0, 0, 1).Format("2006-01-02")
+}
+
+// SendRollover сообщает о переносе незакрытых задач. В тихие часы
+// сообщение не отправляется: вчерашние хвосты покажет утренний брифинг.
+func (ns *NotificationService) SendRollover(result *RolloverResult) {
+	if result.Empty() {
+		return
+	}
+	if ns.isQuiet(time.Now()) {
+		log.Printf("🔕 Тишина: итоги переноса задач покажет утренний брифинг")
+		return
+	}
+
+	var err error
+	if sender, ok := ns.sender.(RolloverSender); ok {
+		err = sender.SendRollover(result)
+	} else {
+		err = ns.Notify(KindRollover, FormatRollover(ns.repository.Registry(), result))
+	}
+	if err != nil {
+		log.Printf("❌ Ошибка отправки итогов переноса задач: %v", err)
+	}
+}
+
+// FormatRollover текст итогов переноса
+func FormatRollover(registry *database.Registry, result *RolloverResult) string {
+	var message strings.Builder
+	message.WriteString("🌒 <b>Незакрытые задачи за день</b>\n\n")
+
+	sections := []struct {
+		title string
+		tasks []database.DailyTask
+	}{
+		{"➡️ Перенесено на завтра:", result.Carried},
+		{"⌛ Отмечено несделанным:", result.Missed},
+		{"❓ Что сделать с задачами?", result.Ask},
+	}
+	for _, section := range sections {
+		if len(section.tasks) == 0 {
+			continue
+		}
+		message.WriteString("<b>" + section.title + "</b>\n")
+		for _, task := range section.tasks {
+			message.WriteString(fmt.Sprintf("#%d %s %s\n", task.ID, registry.PillarEmoji(task.Pillar), html.EscapeString(task.Description)))
+		}
+		message.WriteString("\n")
+	}
+
+	return strings.TrimRight(message.String(), "\n")
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"five-pillars/internal/database"
+)
+
+// Ограничения тегов и поиска
+const (
+	MaxTags = 10
+	// searchLimit сколько задач и ощущений показывать по умолчанию
+	searchLimit    = 20
+	maxSearchLimit = 100
+)
+
+var (
+	tagPattern = regexp.MustCompile(`^\p{L}[\p{L}\p{N}_-]{0,29}$`)
+
+	ErrTooManyTags = NewValidationError(fmt.Sprintf("У задачи может быть не больше %d тегов", MaxTags))
+	ErrEmptySearch = NewValidationError("Что искать? Слова, #теги, from:YYYY-MM-DD, to:YYYY-MM-DD или status:done")
+)
+
+func invalidTagError(tag string) error {
+	return NewValidationError(fmt.Sprintf(
+		"Неверный тег #%s: тег - слово из букв, цифр, _ и -, начинается с буквы и не длиннее 30 символов", tag))
+}
+
+// ParseTags разбирает теги "#work #family" или "work,family" в строку для
+// хранения "work,family". Регистр не важен, повторы убираются.
+func ParseTags(values ...string) (string, error) {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, value := range values {
+		for _, tag := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+			tag = strings.ToLower(strings.TrimPrefix(tag, "#"))
+			if !tagPattern.MatchString(tag) {
+				return "", invalidTagError(tag)
+			}
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	if len(tags) > MaxTags {
+		return "", ErrTooManyTags
+	}
+	return strings.Join(tags, ","), nil
+}
+
+// ExtractTags вынимает #теги из текста: "Оценка квартиры #work 10:00" ->
+// "Оценка квартиры 10:00" и [work]. Номера вроде #12 остаются в тексте.
+func ExtractTags(text string) (string, []string) {
+	var words, tags []string
+	for _, word := range strings.Fields(text) {
+		if tag := strings.TrimPrefix(word, "#"); tag != word && tagPattern.MatchString(strings.ToLower(tag)) {
+			tags = append(tags, tag)
+			continue
+		}
+		words = append(words, word)
+	}
+	return strings.Join(words, " "), tags
+}
+
+// FormatTags теги для показа: "#work #family"
+func FormatTags(tags string) string {
+	if tags == "" {
+		return ""
+	}
+	return "#" + strings.ReplaceAll(tags, ",", " #")
+}
+
+// SetTags заменяет теги задачи, пустой список убирает их
+func (ts *TaskService) SetTags(taskID int, tags ...string) (*database.DailyTask, error) {
+	value, err := ParseTags(tags...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ts.GetTask(taskID); err != nil {
+		return nil, err
+	}
+
+	if err := ts.repository.UpdateTaskTags(taskID, value); err != nil {
+		return nil, err
+	}
+	return ts.GetTask(taskID)
+}
+
+// SetTemplateTags заменяет теги шаблона. Их получат задачи, созданные по
+// шаблону после изменения.
+func (ts *TaskService) SetTemplateTags(templateID int, tags ...string) error {
+	value, err := ParseTags(tags...)
+	if err != nil {
+		return err
+	}
+
+	updated, err := ts.repository.UpdateTemplateTags(templateID, value)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return NewValidationError(fmt.Sprintf("Шаблон #%d не найден", templateID))
+	}
+	return nil
+}
+
+// ParseSearchQuery разбирает строку поиска: слова, #теги, from:YYYY-MM-DD,
+// to:YYYY-MM-DD, date:YYYY-MM-DD и status:done,skipped
+func ParseSearchQuery(text string) (database.SearchQuery, error) {
+	var query database.SearchQuery
+	for _, word := range strings.Fields(text) {
+		key, value, _ := strings.Cut(word, ":")
+		switch {
+		case strings.HasPrefix(word, "#") && tagPattern.MatchString(strings.ToLower(word[1:])):
+			query.Tags = append(query.Tags, strings.ToLower(word[1:]))
+		case key == "from":
+			query.From = value
+		case key == "to":
+			query.To = value
+		case key == "date":
+			query.From, query.To = value, value
+		case key == "status":
+			for _, name := range strings.Split(value, ",") {
+				status, err := ParseTaskStatus(name)
+				if err != nil {
+					return database.SearchQuery{}, err
+				}
+				query.Statuses = append(query.Statuses, status)
+			}
+		default:
+			query.Words = append(query.Words, word)
+		}
+	}
+	return query, nil
+}
+
+// SearchResult найденные задачи и ощущения, сначала самые поздние
+type SearchResult struct {
+	Tasks    []database.DailyTask     `json:"tasks"`
+	Feelings []database.DailyFeelings `json:"feelings"`
+}
+
+type SearchService struct {
+	repository *database.Repository
+}
+
+func NewSearchService(repo *database.Repository) *SearchService {
+	return &SearchService{repository: repo}
+}
+
+// Find ищет задачи по описанию, заметкам, тегам, периоду и статусу. Заметки
+// и настроение ощущений ищутся, только если в запросе нет тегов и статусов.
+func (ss *SearchService) Find(query database.SearchQuery) (*SearchResult, error) {
+	if len(query.Words) == 0 && len(query.Tags) == 0 && len(query.Statuses) == 0 && query.From == "" && query.To == "" {
+		return nil, ErrEmptySearch
+	}
+	for _, date := range []string{query.From, query.To} {
+		if date == "" {
+			continue
+		}
+		if err := ValidateDate(date); err != nil {
+			return nil, err
+		}
+	}
+	if query.Limit <= 0 {
+		query.Limit = searchLimit
+	}
+	if query.Limit > maxSearchLimit {
+		query.Limit = maxSearchLimit
+	}
+
+	tasks, err := ss.repository.SearchTasks(query)
+	if err != nil {
+		return nil, err
+	}
+	result := &SearchResult{Tasks: tasks}
+
+	if len(query.Tags) == 0 && len(query.Statuses) == 0 {
+		if result.Feelings, err = ss.repository.SearchFeelings(query); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// FormatSearchResult найденное по строке на задачу или день с ощущениями
+func FormatSearchResult(registry *database.Registry, result *SearchResult) string {
+	var b strings.Builder
+	for _, task := range result.Tasks {
+		b.WriteString(fmt.Sprintf("#%d %s %s %s — %s",
+			task.ID, task.Date, registry.PillarEmoji(task.Pillar), task.Description, StatusNames[task.Status]))
+		if tags := FormatTags(task.Tags); tags != "" {
+			b.WriteString(" " + tags)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(result.Feelings) > 0 {
+		if len(result.Tasks) > 0 {
+			b.WriteString("\n")
+		}
+		for _, feelings := range result.Feelings {
+			var parts []string
+			for _, text := range []string{feelings.Mood, feelings.Notes} {
+				// Заметки с дневником многострочные, в выдаче - одной строкой
+				if text = strings.Join(strings.Fields(text), " "); text != "" {
+					parts = append(parts, text)
+				}
+			}
+			b.WriteString(fmt.Sprintf("📊 %s: %s\n", feelings.Date, strings.Join(parts, " · ")))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,370 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"five-pillars/internal/database"
+)
+
+// shortSleepHours ночи короче считаются недосыпом в анализе энергии
+const shortSleepHours = 7
+
+// minCorrelationDays меньше дней со сном и энергией корреляцию не считаем
+const minCorrelationDays = 5
+
+var (
+	ErrInvalidSleepRange = NewValidationError("Сон записывается как отбой-подъем по Москве, например 23:40-07:10")
+	ErrSleepNotFound     = NewValidationError("Сон за этот день не записан")
+)
+
+// IsSleepRange похоже ли значение на отбой-подъем "23:40-07:10", а не на часы
+func IsSleepRange(value string) bool {
+	return strings.Contains(value, ":") && strings.ContainsAny(value, "-–")
+}
+
+// ParseSleepRange разбирает "23:40-07:10" в ночь с пробуждением в date.
+// Подъем раньше отбоя означает, что ночь перешла через полночь.
+func ParseSleepRange(date, value string) (database.SleepEntry, error) {
+	bedtime, wake, found := strings.Cut(strings.ReplaceAll(strings.TrimSpace(value), "–", "-"), "-")
+	if !found {
+		return database.SleepEntry{}, ErrInvalidSleepRange
+	}
+	return NewSleepEntry(date, bedtime, wake)
+}
+
+// NewSleepEntry проверяет время отбоя и подъема и считает длительность сна
+func NewSleepEntry(date, bedtime, wake string) (database.SleepEntry, error) {
+	bed, err := time.Parse("15:04", strings.TrimSpace(bedtime))
+	if err != nil {
+		return database.SleepEntry{}, ErrInvalidSleepRange
+	}
+	up, err := time.Parse("15:04", strings.TrimSpace(wake))
+	if err != nil {
+		return database.SleepEntry{}, ErrInvalidSleepRange
+	}
+
+	minutes := int(up.Sub(bed).Minutes())
+	if minutes <= 0 {
+		minutes += 24 * 60
+	}
+	if minutes >= 24*60 {
+		return database.SleepEntry{}, ErrInvalidSleepRange
+	}
+
+	return database.SleepEntry{
+		Date:     date,
+		Bedtime:  bed.Format("15:04"),
+		WakeTime: up.Format("15:04"),
+		Hours:    math.Round(float64(minutes)/60*100) / 100,
+	}, nil
+}
+
+type SleepService struct {
+	repository *database.Repository
+}
+
+func NewSleepService(repo *database.Repository) *SleepService {
+	return &SleepService{repository: repo}
+}
+
+// Save записывает ночь "23:40-07:10" с пробуждением в date, пустая дата -
+// сегодня по UTC, как у ощущений. Сон в ощущениях за этот день обновляется.
+func (ss *SleepService) Save(date, value string) (*database.SleepEntry, error) {
+	if date == "" {
+		date = Today()
+	}
+	if err := ValidateDate(date); err != nil {
+		return nil, err
+	}
+
+	entry, err := ParseSleepRange(date, value)
+	if err != nil {
+		return nil, err
+	}
+	if err := ss.repository.SaveSleep(entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Delete удаляет ночь из журнала
+func (ss *SleepService) Delete(date string) error {
+	if err := ValidateDate(date); err != nil {
+		return err
+	}
+	deleted, err := ss.repository.DeleteSleep(date)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrSleepNotFound
+	}
+	return nil
+}
+
+// Get ночи за последние days дней, включая сегодня, и режим сна за них
+func (ss *SleepService) Get(days int) ([]database.SleepEntry, *database.SleepStats, error) {
+	to := Today()
+	from := time.Now().UTC().AddDate(0, 0, 1-days).Format("2006-01-02")
+	return ss.GetBetween(from, to)
+}
+
+// GetBetween ночи за период включительно и режим сна за него
+func (ss *SleepService) GetBetween(from, to string) ([]database.SleepEntry, *database.SleepStats, error) {
+	for _, date := range []string{from, to} {
+		if err := ValidateDate(date); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	entries, err := ss.repository.GetSleepBetween(from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	stats, err := sleepStats(ss.repository, from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	return entries, stats, nil
+}
+
+// sleepStats режим сна за период по журналу сна и сну из ощущений, nil - сон
+// не записывали
+func sleepStats(repo *database.Repository, from, to string) (*database.SleepStats, error) {
+	entries, err := repo.GetSleepBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+	feelings, err := repo.GetFeelingsBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return computeSleepStats(entries, feelings), nil
+}
+
+// computeSleepStats считает длительность по журналу сна, а где его нет - по
+// часам из ощущений. Время отбоя, подъема и джетлаг - только по журналу.
+func computeSleepStats(entries []database.SleepEntry, feelings []database.DailyFeelings) *database.SleepStats {
+	hours := make(map[string]float64)
+	for _, f := range feelings {
+		if f.SleepHours > 0 {
+			hours[f.Date] = f.SleepHours
+		}
+	}
+	for _, entry := range entries {
+		hours[entry.Date] = entry.Hours
+	}
+	if len(hours) == 0 {
+		return nil
+	}
+
+	stats := &database.SleepStats{Nights: len(hours)}
+	for _, h := range hours {
+		stats.AvgHours += h
+	}
+	stats.AvgHours /= float64(len(hours))
+
+	if len(entries) > 0 {
+		var bedtimes, wakes, midpoints, weekdays, weekends []float64
+		for _, entry := range entries {
+			bed := clockMinutes(entry.Bedtime)
+			midpoint := bed + entry.Hours*60/2
+			bedtimes = append(bedtimes, bed)
+			wakes = append(wakes, clockMinutes(entry.WakeTime))
+			midpoints = append(midpoints, midpoint)
+
+			// Ночи с пятницы на субботу и с субботы на воскресенье - выходные
+			if day, err := time.Parse("2006-01-02", entry.Date); err == nil &&
+				(day.Weekday() == time.Saturday || day.Weekday() == time.Sunday) {
+				weekends = append(weekends, midpoint)
+			} else {
+				weekdays = append(weekdays, midpoint)
+			}
+		}
+
+		stats.AvgBedtime = formatClock(mean(bedtimes))
+		stats.AvgWake = formatClock(mean(wakes))
+		stats.MidpointSpread = stdDev(midpoints)
+		if len(weekdays) > 0 && len(weekends) > 0 {
+			lag := mean(weekends) - mean(weekdays)
+			stats.SocialJetLag = &lag
+		}
+	}
+
+	var sleepHours, energy, short, long []float64
+	for _, f := range feelings {
+		h, ok := hours[f.Date]
+		if !ok || f.EnergyLevel == 0 {
+			continue
+		}
+		sleepHours = append(sleepHours, h)
+		energy = append(energy, float64(f.EnergyLevel))
+		if h < shortSleepHours {
+			short = append(short, float64(f.EnergyLevel))
+		} else {
+			long = append(long, float64(f.EnergyLevel))
+		}
+	}
+	if len(sleepHours) >= minCorrelationDays {
+		stats.EnergyCorrelation = correlation(sleepHours, energy)
+	}
+	if len(short) > 0 {
+		avg := mean(short)
+		stats.EnergyShort = &avg
+	}
+	if len(long) > 0 {
+		avg := mean(long)
+		stats.EnergyLong = &avg
+	}
+	return stats
+}
+
+// clockMinutes минуты от полудня: ночное время идет подряд, 23:40 и 00:20
+// отличаются на 40 минут, а не на 23 часа
+func clockMinutes(clock string) float64 {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0
+	}
+	minutes := t.Hour()*60 + t.Minute() - 12*60
+	if minutes < 0 {
+		minutes += 24 * 60
+	}
+	return float64(minutes)
+}
+
+// formatClock обратно к clockMinutes: время суток "23:45"
+func formatClock(minutes float64) string {
+	total := (int(math.Round(minutes)) + 12*60) % (24 * 60)
+	return fmt.Sprintf("%02d:%02d", total/60, total%60)
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func stdDev(values []float64) float64 {
+	avg := mean(values)
+	var sum float64
+	for _, v := range values {
+		sum += (v - avg) * (v - avg)
+	}
+	return math.Sqrt(sum / float64(len(values)))
+}
+
+// correlation коэффициент Пирсона, nil - одно из значений не меняется
+func correlation(x, y []float64) *float64 {
+	mx, my := mean(x), mean(y)
+	var sxy, sxx, syy float64
+	for i := range x {
+		sxy += (x[i] - mx) * (y[i] - my)
+		sxx += (x[i] - mx) * (x[i] - mx)
+		syy += (y[i] - my) * (y[i] - my)
+	}
+	if sxx == 0 || syy == 0 {
+		return nil
+	}
+	r := sxy / math.Sqrt(sxx*syy)
+	return &r
+}
+
+// formatDuration минуты как "1 ч 20 мин"
+func formatDuration(minutes float64) string {
+	total := int(math.Round(math.Abs(minutes)))
+	switch {
+	case total < 60:
+		return fmt.Sprintf("%d мин", total)
+	case total%60 == 0:
+		return fmt.Sprintf("%d ч", total/60)
+	default:
+		return fmt.Sprintf("%d ч %d мин", total/60, total%60)
+	}
+}
+
+// FormatSleep ночи по строке: "2026-10-18 23:40 → 07:10 · 7.5 ч"
+func FormatSleep(entries []database.SleepEntry) string {
+	var b strings.Builder
+	for _, entry := range entries {
+		b.WriteString(fmt.Sprintf("%s %s → %s · %s ч\n",
+			entry.Date, entry.Bedtime, entry.WakeTime, database.FormatNumber(math.Round(entry.Hours*10)/10)))
+	}
+	return b.String()
+}
+
+// FormatSleepStats режим сна: длительность, отбой и подъем, ровность режима,
+// социальный джетлаг и связь с энергией
+func FormatSleepStats(stats *database.SleepStats) string {
+	if stats == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("😴 В среднем %.1f ч, ночей: %d\n", stats.AvgHours, stats.Nights))
+	if stats.AvgBedtime != "" {
+		b.WriteString(fmt.Sprintf("🛏 Отбой ~%s, подъем ~%s\n", stats.AvgBedtime, stats.AvgWake))
+
+		consistency := "ровный"
+		switch {
+		case stats.MidpointSpread > 60:
+			consistency = "неровный"
+		case stats.MidpointSpread > 30:
+			consistency = "умеренный"
+		}
+		b.WriteString(fmt.Sprintf("📏 Режим %s: середина сна гуляет ±%s\n", consistency, formatDuration(stats.MidpointSpread)))
+	}
+	if lag := stats.SocialJetLag; lag != nil {
+		direction := "позже"
+		if *lag < 0 {
+			direction = "раньше"
+		}
+		b.WriteString(fmt.Sprintf("🕒 Социальный джетлаг: %s, в выходные спите %s\n", formatDuration(*lag), direction))
+	}
+	if stats.EnergyShort != nil && stats.EnergyLong != nil {
+		b.WriteString(fmt.Sprintf("⚡ Энергия после ночей короче %d ч: %.1f, от %d ч: %.1f\n",
+			shortSleepHours, *stats.EnergyShort, shortSleepHours, *stats.EnergyLong))
+	}
+	if r := stats.EnergyCorrelation; r != nil {
+		b.WriteString(fmt.Sprintf("🔗 Связь сна и энергии: %.2f (%s)\n", *r, correlationStrength(*r)))
+	}
+	return b.String()
+}
+
+func correlationStrength(r float64) string {
+	switch {
+	case r >= 0.5:
+		return "сильная"
+	case r >= 0.3:
+		return "заметная"
+	case r > -0.3:
+		return "слабая"
+	default:
+		return "обратная"
+	}
+}
+
+// sleepInsights выводы о сне для недельной аналитики
+func sleepInsights(stats *database.SleepStats) []string {
+	if stats == nil {
+		return nil
+	}
+
+	var insights []string
+	if stats.AvgHours < shortSleepHours {
+		insights = append(insights, fmt.Sprintf("😴 Сон в среднем %.1f ч - меньше %d часов", stats.AvgHours, shortSleepHours))
+	}
+	if lag := stats.SocialJetLag; lag != nil && math.Abs(*lag) >= 60 {
+		insights = append(insights, fmt.Sprintf("🕒 Социальный джетлаг %s: режим в выходные сдвигается", formatDuration(*lag)))
+	}
+	if stats.EnergyShort != nil && stats.EnergyLong != nil && *stats.EnergyLong-*stats.EnergyShort >= 1 {
+		insights = append(insights, fmt.Sprintf("⚡ После ночей от %d ч энергия выше на %.1f",
+			shortSleepHours, *stats.EnergyLong-*stats.EnergyShort))
+	}
+	return insights
+}
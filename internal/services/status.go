@@ -0,0 +1,137 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"five-pillars/internal/database"
+)
+
+// statusTransitions разрешенные переходы статусов задачи. Выполненные,
+// отмененные и перенесенные задачи закрыты окончательно.
+var statusTransitions = map[database.TaskStatus][]database.TaskStatus{
+	database.StatusPlanned: {database.StatusDue, database.StatusDone, database.StatusSkipped, database.StatusMissed,
+		database.StatusCancelled, database.StatusRescheduled},
+	database.StatusDue: {database.StatusPlanned, database.StatusDone, database.StatusSkipped, database.StatusMissed,
+		database.StatusCancelled, database.StatusRescheduled},
+	// Пропущенную или несделанную задачу можно отметить выполненной задним
+	// числом или перенести
+	database.StatusSkipped: {database.StatusDone, database.StatusRescheduled},
+	database.StatusMissed:  {database.StatusDone, database.StatusSkipped, database.StatusRescheduled},
+}
+
+// StatusNames названия статусов для пользователя
+var StatusNames = map[database.TaskStatus]string{
+	database.StatusPlanned:     "запланирована",
+	database.StatusDue:         "ждет выполнения",
+	database.StatusDone:        "выполнена",
+	database.StatusSkipped:     "пропущена",
+	database.StatusMissed:      "не сделана",
+	database.StatusCancelled:   "отменена",
+	database.StatusRescheduled: "перенесена",
+}
+
+// StatusMarks значки статусов в списках задач
+var StatusMarks = map[database.TaskStatus]string{
+	database.StatusPlanned:     "⬜",
+	database.StatusDue:         "⏰",
+	database.StatusDone:        "✅",
+	database.StatusSkipped:     "➖",
+	database.StatusMissed:      "⌛",
+	database.StatusCancelled:   "✖️",
+	database.StatusRescheduled: "➡️",
+}
+
+// ParseTaskStatus проверяет название статуса
+func ParseTaskStatus(value string) (database.TaskStatus, error) {
+	for _, status := range database.TaskStatuses {
+		if string(status) == value {
+			return status, nil
+		}
+	}
+	return "", NewValidationError("Неизвестный статус. Используйте: planned, due, done, skipped, missed, cancelled, rescheduled")
+}
+
+// CanTransition можно ли перевести задачу из статуса from в to
+func CanTransition(from, to database.TaskStatus) bool {
+	for _, allowed := range statusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// transitionError ошибка недопустимого перехода для пользователя
+func transitionError(task *database.DailyTask, to database.TaskStatus) error {
+	return NewValidationError(fmt.Sprintf("Задача #%d %s, её нельзя отметить как «%s»",
+		task.ID, StatusNames[task.Status], StatusNames[to]))
+}
+
+// DisplayStatus статус для показа: запланированная задача, время которой
+// уже прошло, показывается ждущей выполнения еще до того, как её отметит
+// проверка напоминаний
+func DisplayStatus(task database.DailyTask, now time.Time) database.TaskStatus {
+	if task.Status == database.StatusPlanned && IsOverdue(task, now) {
+		return database.StatusDue
+	}
+	return task.Status
+}
+
+// StatusMark значок статуса задачи на момент now
+func StatusMark(task database.DailyTask, now time.Time) string {
+	return StatusMarks[DisplayStatus(task, now)]
+}
+
+// setStatus проверяет переход и меняет статус задачи. Если статус успели
+// изменить параллельно, переход проверяется заново по свежему статусу.
+func setStatus(repo *database.Repository, task *database.DailyTask, to database.TaskStatus) error {
+	for attempt := 0; attempt < 2; attempt++ {
+		if !CanTransition(task.Status, to) {
+			return transitionError(task, to)
+		}
+
+		updated, err := repo.UpdateTaskStatus(task.ID, task.Status, to)
+		if err != nil {
+			return err
+		}
+		if updated {
+			task.Status = to
+			return nil
+		}
+
+		fresh, err := repo.GetTaskByID(task.ID)
+		if err != nil {
+			return err
+		}
+		*task = *fresh
+	}
+	return transitionError(task, to)
+}
+
+// SetStatus переводит задачу в статус to, если такой переход допустим
+func (ts *TaskService) SetStatus(taskID int, to database.TaskStatus) (*database.DailyTask, error) {
+	switch to {
+	case database.StatusDone:
+		return ts.CompleteTask(taskID)
+	case database.StatusCancelled:
+		return ts.CancelTask(taskID)
+	case database.StatusSkipped:
+		return nil, NewValidationError("Для пропуска укажите причину: POST /api/tasks/{id}/skip")
+	case database.StatusRescheduled:
+		return nil, NewValidationError("Перенесенной задача становится при переносе на другой день")
+	}
+
+	task, err := ts.GetTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := setStatus(ts.repository, task, to); err != nil {
+		return nil, err
+	}
+
+	if to == database.StatusMissed {
+		ts.events.publish(Event{Type: EventTaskMissed, Task: task})
+	}
+	return ts.GetTask(taskID)
+}
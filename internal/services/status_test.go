@@ -0,0 +1,47 @@
+package services
+
+import (
+	"testing"
+
+	"five-pillars/internal/database"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to database.TaskStatus
+		want     bool
+	}{
+		{database.StatusPlanned, database.StatusDue, true},
+		{database.StatusPlanned, database.StatusDone, true},
+		{database.StatusPlanned, database.StatusRescheduled, true},
+		{database.StatusDue, database.StatusPlanned, true},
+		{database.StatusDue, database.StatusMissed, true},
+		{database.StatusSkipped, database.StatusDone, true},
+		{database.StatusSkipped, database.StatusMissed, false},
+		{database.StatusMissed, database.StatusSkipped, true},
+		{database.StatusMissed, database.StatusPlanned, false},
+		{database.StatusDone, database.StatusPlanned, false},
+		{database.StatusDone, database.StatusSkipped, false},
+		{database.StatusCancelled, database.StatusDone, false},
+		{database.StatusRescheduled, database.StatusDone, false},
+		{database.StatusPlanned, database.StatusPlanned, false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			if got := CanTransition(tt.from, tt.to); got != tt.want {
+				t.Errorf("CanTransition(%s, %s) = %v, ожидалось %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+// Закрытые статусы окончательные: из них переходов нет
+func TestFinalStatuses(t *testing.T) {
+	for _, from := range []database.TaskStatus{database.StatusDone, database.StatusCancelled, database.StatusRescheduled} {
+		for _, to := range database.TaskStatuses {
+			if CanTransition(from, to) {
+				t.Errorf("из %s разрешен переход в %s", from, to)
+			}
+		}
+	}
+}
@@ -1,19 +1,512 @@
 package services
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"five-pillars/internal/database"
 )
 
+// DefaultSnoozeMinutes на сколько откладывается задача по кнопке "Отложить"
+const DefaultSnoozeMinutes = 60
+
+var (
+	timePattern = regexp.MustCompile(`^([01][0-9]|2[0-3]):([0-5][0-9])$`)
+	datePattern = regexp.MustCompile(`^\d{4}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01])$`)
+)
+
+// SkipReason причина пропуска задачи
+type SkipReason struct {
+	Code string `json:"code"`
+	Text string `json:"text"`
+}
+
+// SkipReasons причины пропуска в порядке показа пользователю
+var SkipReasons = []SkipReason{
+	{Code: "noenergy", Text: "🔋 Не было энергии"},
+	{Code: "notime", Text: "⏰ Не хватило времени"},
+	{Code: "irrelevant", Text: "🎯 Задача неактуальна"},
+	{Code: "illness", Text: "Болел"},
+}
+
+// NewTaskInput данные для создания задачи
+type NewTaskInput struct {
+	Pillar      string `json:"pillar"`
+	Description string `json:"description"`
+	TimeUTC     string `json:"time_utc"`
+	Date        string `json:"date"`
+	Notes       string `json:"notes"`
+	// StartUTC время начала, если оно позже времени напоминания
+	StartUTC string `json:"start_utc"`
+	// LeadMinutes напоминания заранее: "30,5"
+	LeadMinutes string `json:"lead_minutes"`
+	// Checklist пункты чек-листа по порядку
+	Checklist []string `json:"checklist"`
+	// MetricUnit, MetricTarget и MetricAgg измеримый результат: км, 5, sum
+	MetricUnit   string  `json:"metric_unit"`
+	MetricTarget float64 `json:"metric_target"`
+	MetricAgg    string  `json:"metric_agg"`
+	// Tags теги без #: ["work", "family"]
+	Tags []string `json:"tags"`
+}
+
 type TaskService struct {
 	repository *database.Repository
+	events     *eventBus
 }
 
-func NewTaskService(repo *database.Repository) *TaskService {
+func NewTaskService(repo *database.Repository, events *eventBus) *TaskService {
 	return &TaskService{
 		repository: repo,
+		events:     events,
+	}
+}
+
+// ValidateTime проверяет формат времени HH:mm
+func ValidateTime(value string) error {
+	if !timePattern.MatchString(value) {
+		return ErrInvalidTime
+	}
+	return nil
+}
+
+// ValidateDate проверяет формат даты YYYY-MM-DD
+func ValidateDate(value string) error {
+	if !datePattern.MatchString(value) {
+		return ErrInvalidDate
+	}
+	if _, err := time.Parse("2006-01-02", value); err != nil {
+		return ErrInvalidDate
+	}
+	return nil
+}
+
+// maxLeadMinutes напомнить заранее можно не раньше, чем за сутки
+const maxLeadMinutes = 24 * 60
+
+// NormalizeLeadMinutes проверяет список минут "30, 5" и приводит его к виду
+// "30,5": по убыванию, без повторов
+func NormalizeLeadMinutes(value string) (string, error) {
+	if strings.TrimSpace(value) == "" {
+		return "", nil
+	}
+
+	var minutes []int
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(value, ",") {
+		m, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || m < 1 || m > maxLeadMinutes {
+			return "", NewValidationError("Напоминания заранее - минуты от 1 до 1440 через запятую, например: 30,5")
+		}
+		if !seen[m] {
+			seen[m] = true
+			minutes = append(minutes, m)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(minutes)))
+
+	parts := make([]string, len(minutes))
+	for i, m := range minutes {
+		parts[i] = strconv.Itoa(m)
+	}
+	return strings.Join(parts, ","), nil
+}
+
+// validateStart проверяет время начала (может быть пустым) и напоминания заранее
+func validateStart(startUTC, leadMinutes string) (string, error) {
+	if startUTC != "" {
+		if err := ValidateTime(startUTC); err != nil {
+			return "", err
+		}
+	}
+	return NormalizeLeadMinutes(leadMinutes)
+}
+
+// FindSkipReason ищет причину пропуска по коду
+func FindSkipReason(code string) (SkipReason, bool) {
+	for _, reason := range SkipReasons {
+		if reason.Code == code {
+			return reason, true
+		}
+	}
+	return SkipReason{}, false
+}
+
+// Today текущая дата в UTC в формате задач
+func Today() string {
+	return time.Now().UTC().Format("2006-01-02")
+}
+
+// GetTasks возвращает задачи на дату
+func (ts *TaskService) GetTasks(date string) ([]database.DailyTask, error) {
+	if err := ValidateDate(date); err != nil {
+		return nil, err
+	}
+	return ts.repository.GetTasksByDate(date)
+}
+
+// GetTasksBetween возвращает задачи за период включительно
+func (ts *TaskService) GetTasksBetween(from, to string) ([]database.DailyTask, error) {
+	if err := ValidateDate(from); err != nil {
+		return nil, err
+	}
+	if err := ValidateDate(to); err != nil {
+		return nil, err
 	}
+	return ts.repository.GetTasksBetween(from, to)
+}
+
+// GetTask возвращает задачу по ID
+func (ts *TaskService) GetTask(taskID int) (*database.DailyTask, error) {
+	task, err := ts.repository.GetTaskByID(taskID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTaskNotFound
+	}
+	return task, err
+}
+
+// AddTask создает задачу. Пустая дата означает сегодня.
+func (ts *TaskService) AddTask(input NewTaskInput) (*database.DailyTask, error) {
+	pillar, err := ParsePillar(ts.repository.Registry(), input.Pillar)
+	if err != nil {
+		return nil, err
+	}
+
+	description := strings.TrimSpace(input.Description)
+	if description == "" {
+		return nil, ErrEmptyDescription
+	}
+
+	if err := ValidateTime(input.TimeUTC); err != nil {
+		return nil, err
+	}
+
+	date := input.Date
+	if date == "" {
+		date = Today()
+	}
+	if err := ValidateDate(date); err != nil {
+		return nil, err
+	}
+
+	leads, err := validateStart(input.StartUTC, input.LeadMinutes)
+	if err != nil {
+		return nil, err
+	}
+
+	var checklist []string
+	for _, item := range input.Checklist {
+		checklist = append(checklist, ParseChecklist(item)...)
+	}
+	if len(checklist) > MaxChecklistItems {
+		return nil, ErrChecklistTooLong
+	}
+
+	metric, err := validateMetric(Metric{Unit: input.MetricUnit, Target: input.MetricTarget, Aggregation: input.MetricAgg})
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := ParseTags(input.Tags...)
+	if err != nil {
+		return nil, err
+	}
+
+	task := database.DailyTask{
+		Pillar:      pillar,
+		Description: description,
+		TimeUTC:     input.TimeUTC,
+		Date:        date,
+		Notes:       input.Notes,
+		StartUTC:    input.StartUTC,
+		LeadMinutes: leads,
+		Checklist:   checklistItems(checklist),
+
+		MetricUnit:   metric.Unit,
+		MetricTarget: metric.Target,
+		MetricAgg:    metric.Aggregation,
+		Tags:         tags,
+	}
+
+	id, err := ts.repository.AddTask(task)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := ts.GetTask(id)
+	if err != nil {
+		return nil, err
+	}
+
+	ts.events.publish(Event{Type: EventTaskCreated, Task: created})
+	return created, nil
+}
+
+// ParseAddCommand разбирает аргументы команды /add: "[столп] [описание] #тег HH:mm".
+// Время берется из конца описания, #теги вынимаются из него.
+func ParseAddCommand(args string) (NewTaskInput, error) {
+	parts := strings.SplitN(strings.TrimSpace(args), " ", 2)
+	if len(parts) < 2 {
+		return NewTaskInput{}, NewValidationError("Формат: /add [столп] [описание и время в UTC]")
+	}
+
+	description, tags := ExtractTags(parts[1])
+	if len(description) < 5 {
+		return NewTaskInput{}, ErrInvalidTime
+	}
+
+	return NewTaskInput{
+		Pillar:      parts[0],
+		Description: description,
+		TimeUTC:     description[len(description)-5:],
+		Tags:        tags,
+	}, nil
+}
+
+// CompleteTask отмечает задачу выполненной
+func (ts *TaskService) CompleteTask(taskID int) (*database.DailyTask, error) {
+	task, err := ts.GetTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setStatus(ts.repository, task, database.StatusDone); err != nil {
+		return nil, err
+	}
+
+	task, err = ts.GetTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	ts.events.publish(Event{Type: EventTaskCompleted, Task: task})
+	return task, nil
+}
+
+// SnoozeTask откладывает задачу на указанное число минут
+func (ts *TaskService) SnoozeTask(taskID int, minutes int) (*database.DailyTask, error) {
+	task, err := ts.GetTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if !task.Status.Open() {
+		return nil, NewValidationError(fmt.Sprintf("Задача #%d %s, откладывать нечего", task.ID, StatusNames[task.Status]))
+	}
+
+	newTime, err := shiftedTime(task, minutes)
+	if err != nil {
+		return nil, err
+	}
+	if err := ts.repository.UpdateTaskTime(taskID, newTime); err != nil {
+		return nil, err
+	}
+	if err := ts.replan(task); err != nil {
+		return nil, err
+	}
+
+	task, err = ts.GetTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	ts.events.publish(Event{Type: EventTaskSnoozed, Task: task, SnoozeMinutes: minutes})
+	return task, nil
+}
+
+// SkipTask отмечает задачу пропущенной с указанной причиной
+func (ts *TaskService) SkipTask(taskID int, reasonCode string) (*database.DailyTask, SkipReason, error) {
+	reason, ok := FindSkipReason(reasonCode)
+	if !ok {
+		return nil, SkipReason{}, ErrUnknownSkipReason
+	}
+
+	task, err := ts.GetTask(taskID)
+	if err != nil {
+		return nil, reason, err
+	}
+	if !CanTransition(task.Status, database.StatusSkipped) {
+		return nil, reason, transitionError(task, database.StatusSkipped)
+	}
+
+	updated, err := ts.repository.MarkTaskAsSkipped(taskID, task.Status, reason.Code, reason.Text)
+	if err != nil {
+		return nil, reason, err
+	}
+
+	task, err = ts.GetTask(taskID)
+	if err != nil {
+		return nil, reason, err
+	}
+	if !updated {
+		// Статус успели изменить, пока выбирали причину
+		return nil, reason, transitionError(task, database.StatusSkipped)
+	}
+
+	ts.events.publish(Event{Type: EventTaskSkipped, Task: task, SkipReason: &reason})
+	return task, reason, nil
+}
+
+// ChangeTime меняет время выполнения задачи
+func (ts *TaskService) ChangeTime(taskID int, newTime string) (*database.DailyTask, error) {
+	if err := ValidateTime(newTime); err != nil {
+		return nil, err
+	}
+
+	task, err := ts.GetTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireOpen(task); err != nil {
+		return nil, err
+	}
+
+	if err := ts.repository.UpdateTaskTime(taskID, newTime); err != nil {
+		return nil, err
+	}
+	if err := ts.replan(task); err != nil {
+		return nil, err
+	}
+
+	return ts.GetTask(taskID)
+}
+
+// ShiftTask сдвигает время задачи на minutes в пределах её дня
+func (ts *TaskService) ShiftTask(taskID int, minutes int) (*database.DailyTask, error) {
+	task, err := ts.GetTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	newTime, err := shiftedTime(task, minutes)
+	if err != nil {
+		return nil, err
+	}
+	return ts.ChangeTime(taskID, newTime)
+}
+
+// shiftedTime время задачи, сдвинутое на minutes. Через полночь время не
+// переходит: дата задачи при этом осталась бы прежней.
+func shiftedTime(task *database.DailyTask, minutes int) (string, error) {
+	t, err := time.Parse("15:04", task.TimeUTC)
+	if err != nil {
+		return "", ErrInvalidTime
+	}
+
+	shifted := t.Add(time.Duration(minutes) * time.Minute)
+	if shifted.Day() != t.Day() {
+		return "", NewValidationError("Время выходит за пределы дня, перенесите задачу через /date")
+	}
+	return shifted.Format("15:04"), nil
+}
+
+// requireOpen время и дату можно менять только у незакрытой задачи: закрытая
+// сохранила бы свой статус в новом дне
+func requireOpen(task *database.DailyTask) error {
+	if task.Status.Open() {
+		return nil
+	}
+	return NewValidationError(fmt.Sprintf("Задача #%d %s, время и дату можно менять только у открытых задач",
+		task.ID, StatusNames[task.Status]))
+}
+
+// SetStart задает время начала (пустое - совпадает со временем задачи)
+// и напоминания заранее (пустые - без напоминаний)
+func (ts *TaskService) SetStart(taskID int, startUTC, leadMinutes string) (*database.DailyTask, error) {
+	leads, err := validateStart(startUTC, leadMinutes)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := ts.GetTask(taskID); err != nil {
+		return nil, err
+	}
+
+	if err := ts.repository.UpdateTaskStart(taskID, startUTC, leads); err != nil {
+		return nil, err
+	}
+
+	return ts.GetTask(taskID)
+}
+
+// ChangeDate меняет дату выполнения задачи
+func (ts *TaskService) ChangeDate(taskID int, newDate string) (*database.DailyTask, error) {
+	if err := ValidateDate(newDate); err != nil {
+		return nil, err
+	}
+
+	task, err := ts.GetTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireOpen(task); err != nil {
+		return nil, err
+	}
+
+	if err := ts.repository.UpdateTaskDate(taskID, newDate); err != nil {
+		return nil, err
+	}
+	if err := ts.replan(task); err != nil {
+		return nil, err
+	}
+
+	return ts.GetTask(taskID)
+}
+
+// replan возвращает ждущую выполнения задачу в запланированные после смены
+// времени или даты: если новое время уже прошло, проверка напоминаний снова
+// отметит её ждущей
+func (ts *TaskService) replan(task *database.DailyTask) error {
+	if task.Status != database.StatusDue {
+		return nil
+	}
+	return setStatus(ts.repository, task, database.StatusPlanned)
+}
+
+// IsOverdue задача не закрыта, а её время уже прошло
+func IsOverdue(task database.DailyTask, now time.Time) bool {
+	if !task.Status.Open() {
+		return false
+	}
+
+	taskUTC, err := time.Parse("2006-01-02 15:04", task.Date+" "+task.TimeUTC)
+	if err != nil {
+		return false
+	}
+
+	return now.UTC().After(taskUTC)
+}
+
+// SkipReasonText достает текст причины пропуска из заметок задачи
+func SkipReasonText(task database.DailyTask) string {
+	_, text := parseSkipNotes(task)
+	return text
+}
+
+// SkipReasonCode достает код причины пропуска из заметок задачи
+func SkipReasonCode(task database.DailyTask) string {
+	code, _ := parseSkipNotes(task)
+	return code
+}
+
+// parseSkipNotes разбирает заметку вида "Пропущено: code | text"
+func parseSkipNotes(task database.DailyTask) (string, string) {
+	if task.Status != database.StatusSkipped || task.Notes == "" || !strings.Contains(task.Notes, "Пропущено:") {
+		return "", ""
+	}
+
+	parts := strings.SplitN(task.Notes, "|", 2)
+	if len(parts) < 2 {
+		return "", ""
+	}
+
+	code := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(parts[0]), "Пропущено:"))
+	return code, strings.TrimSpace(parts[1])
 }
 
 func (ts *TaskService) CreateDefaultTasksToday(date string) error {
@@ -22,126 +515,119 @@ func (ts *TaskService) CreateDefaultTasksToday(date string) error {
 		return err
 	}
 
-	err2 := execute(date, err, ts)
-	if err2 != nil {
-		return err2
+	return ts.createFromTemplates(date)
+}
+
+func (ts *TaskService) CreateDefaultTasksNextDay(date string) error {
+	if _, err := ts.repository.GetTasksByDate(date); err != nil {
+		return err
 	}
 
-	return nil
+	return ts.createFromTemplates(date)
 }
 
-func (ts *TaskService) CreateDefaultTasksNextDay(date string) error {
-	_, err := ts.repository.GetTasksByDate(date)
+// PrepareTasks создает задачи по шаблонам на дату один раз: если задачи
+// уже создавали (например, при вечернем планировании) и пользователь
+// часть из них удалил, повторно они не появятся
+func (ts *TaskService) PrepareTasks(date string) error {
+	if err := ValidateDate(date); err != nil {
+		return err
+	}
+
+	prepared, _, err := ts.repository.GetSetting(database.SettingTasksPrepared)
 	if err != nil {
 		return err
 	}
+	if prepared >= date {
+		return nil
+	}
 
-	err2 := execute(date, err, ts)
-	if err2 != nil {
-		return err2
+	if err := ts.createFromTemplates(date); err != nil {
+		return err
 	}
+	return ts.repository.SetSetting(database.SettingTasksPrepared, date)
+}
 
-	return nil
+// CancelTask отменяет задачу: она остается в истории, но не напоминает
+// и не учитывается в аналитике
+func (ts *TaskService) CancelTask(taskID int) (*database.DailyTask, error) {
+	task, err := ts.GetTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setStatus(ts.repository, task, database.StatusCancelled); err != nil {
+		return nil, err
+	}
+
+	// setStatus меняет только статус, флаги для старых клиентов берем из БД
+	task, err = ts.GetTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	ts.events.publish(Event{Type: EventTaskCancelled, Task: task})
+	return task, nil
 }
 
-func execute(date string, err error, ts *TaskService) error {
+// GetTemplates возвращает шаблоны задач
+func (ts *TaskService) GetTemplates(activeOnly bool) ([]database.TaskTemplate, error) {
+	return ts.repository.GetTemplates(activeOnly)
+}
+
+// createFromTemplates создает задачи на дату по действующим шаблонам
+func (ts *TaskService) createFromTemplates(date string) error {
 	taskDate, err := time.Parse("2006-01-02", date)
 	if err != nil {
 		return err
 	}
 	weekday := taskDate.Weekday()
 
-	baseTasks := []database.DailyTask{
-		{
-			Pillar:      database.Energy,
-			Description: "День без алкоголя",
-			Completed:   false,
-			TimeUTC:     "18:00",
-			Date:        date,
-			Notes:       "Вечерний ритуал: кроссовки → активность → контрастный душ",
-		},
+	templates, err := ts.repository.GetTemplates(true)
+	if err != nil {
+		return err
 	}
 
-	baseTasks = append(baseTasks, database.DailyTask{
-		Pillar:      database.Focus,
-		Description: "Уроки Duolingo",
-		Completed:   false,
-		TimeUTC:     "06:00",
-		Date:        date,
-		Notes:       "Уроки Duolingo",
-	})
-
-	switch weekday {
-	case time.Monday, time.Wednesday, time.Friday:
-		desc := "Беговая тренировка в 18:30"
-		if weekday == time.Wednesday {
-			desc = "Силовая тренировка 18:30"
+	for _, template := range templates {
+		if !template.AppliesTo(weekday) {
+			continue
 		}
-		baseTasks = append(baseTasks, database.DailyTask{
-			Pillar:      database.Body,
-			Description: desc,
-			Completed:   false,
-			TimeUTC:     "18:00",
-			Date:        date,
-			Notes:       "Ритм 2+1 - инвестиция в энергию",
-		})
 
-	}
-
-	if weekday >= time.Monday && weekday <= time.Friday {
-		baseTasks = append(baseTasks, database.DailyTask{
-			Pillar:      database.Focus,
-			Description: "Утренний блок 90 мин",
-			Completed:   false,
-			TimeUTC:     "09:00",
-			Date:        date,
-			Notes:       "Самая сложная задача дня",
-		})
-
-		baseTasks = append(baseTasks, database.DailyTask{
-			Pillar:      database.Focus,
-			Description: "Вечерний урок",
-			Completed:   false,
-			TimeUTC:     "18:00",
-			Date:        date,
-			Notes:       "вечерний урок 15 мин",
-		})
-	}
-
-	if weekday == time.Saturday {
-		baseTasks = append(baseTasks, database.DailyTask{
-			Pillar:      database.Life,
-			Description: "Проверяй смету по кваритре, ищи деньги, подбивай таймлайн конца проекта (2 часа)",
-			Completed:   false,
-			TimeUTC:     "08:00",
-			Date:        date,
-			Notes:       "Одно конкретное действие: замер, выбор, упаковка",
-		})
-		baseTasks = append(baseTasks, database.DailyTask{
-			Pillar:      database.Focus,
-			Description: "Провести чекап ситуации с финансами",
-			Completed:   false,
-			TimeUTC:     "12:00",
-			Date:        date,
-			Notes:       "Всегда имей план на будущее по твоим инвестициям - так спокойнее",
-		})
-	}
+		// Повторный запуск на ту же дату не дублирует задачи
+		exists, err := ts.repository.TaskExists(date, template.Pillar, template.Description)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
 
-	if weekday == time.Sunday {
-		baseTasks = append(baseTasks, database.DailyTask{
-			Pillar:      database.Balance,
-			Description: "Ревью недели + план",
-			Completed:   false,
-			TimeUTC:     "11:00",
+		task := database.DailyTask{
+			Pillar:      template.Pillar,
+			Description: template.Description,
+			Status:      database.StatusPlanned,
+			TimeUTC:     template.TimeUTC,
 			Date:        date,
-			Notes:       "30 мин: анализ + корректировка плана",
-		})
-	}
+			Notes:       template.Notes,
+			StartUTC:    template.StartUTC,
+			LeadMinutes: template.LeadMinutes,
+			TemplateID:  template.ID,
+			Checklist:   checklistItems(template.ChecklistItems()),
 
-	for _, task := range baseTasks {
-		if err := ts.repository.AddTask(task); err != nil {
+			MetricUnit:   template.MetricUnit,
+			MetricTarget: template.MetricTarget,
+			MetricAgg:    template.MetricAgg,
+			GoalID:       template.GoalID,
+			Tags:         template.Tags,
+		}
+		id, err := ts.repository.AddTask(task)
+		if err != nil {
 			return err
 		}
+
+		task.ID = id
+		task.CreatedAt = time.Now().UTC()
+		ts.events.publish(Event{Type: EventTaskCreated, Task: &task})
 	}
 	return nil
 }
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"five-pillars/internal/database"
+)
+
+func newTestServices(t *testing.T) *ServiceManager {
+	t.Helper()
+	db, err := database.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewServiceManager(db)
+}
+
+func addTestTask(t *testing.T, sm *ServiceManager, timeUTC string) *database.DailyTask {
+	t.Helper()
+	task, err := sm.Task.AddTask(NewTaskInput{Pillar: "body", Description: "Прогулка", TimeUTC: timeUTC, Date: "2030-01-10"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return task
+}
+
+func TestChangeClosedTask(t *testing.T) {
+	sm := newTestServices(t)
+
+	task := addTestTask(t, sm, "10:00")
+	if _, err := sm.Task.CompleteTask(task.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sm.Task.ChangeDate(task.ID, "2030-01-11"); !IsValidationError(err) {
+		t.Errorf("ChangeDate выполненной задачи: %v, ожидалась ошибка проверки", err)
+	}
+	if _, err := sm.Task.ChangeTime(task.ID, "12:00"); !IsValidationError(err) {
+		t.Errorf("ChangeTime выполненной задачи: %v, ожидалась ошибка проверки", err)
+	}
+
+	open := addTestTask(t, sm, "11:00")
+	moved, err := sm.Task.ChangeDate(open.ID, "2030-01-11")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved.Date != "2030-01-11" || moved.Status != database.StatusPlanned {
+		t.Errorf("открытая задача перенесена как %s/%s", moved.Date, moved.Status)
+	}
+}
+
+func TestSnoozeAndShiftStayWithinDay(t *testing.T) {
+	sm := newTestServices(t)
+	task := addTestTask(t, sm, "23:50")
+
+	if _, err := sm.Task.SnoozeTask(task.ID, 15); !IsValidationError(err) {
+		t.Errorf("SnoozeTask через полночь: %v, ожидалась ошибка проверки", err)
+	}
+	if _, err := sm.Task.ShiftTask(task.ID, 15); !IsValidationError(err) {
+		t.Errorf("ShiftTask через полночь: %v, ожидалась ошибка проверки", err)
+	}
+
+	snoozed, err := sm.Task.SnoozeTask(task.ID, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snoozed.TimeUTC != "23:55" {
+		t.Errorf("после SnoozeTask время %s, ожидалось 23:55", snoozed.TimeUTC)
+	}
+}
+
+// eventRecorder подписчик, который запоминает события
+type eventRecorder struct {
+	events []Event
+}
+
+func (r *eventRecorder) HandleEvent(event Event) {
+	r.events = append(r.events, event)
+}
+
+func TestCancelTaskPublishesFreshTask(t *testing.T) {
+	sm := newTestServices(t)
+	recorder := &eventRecorder{}
+	sm.Subscribe(recorder)
+
+	task := addTestTask(t, sm, "10:00")
+	if _, err := sm.Task.CancelTask(task.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := sm.Task.GetTask(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, event := range recorder.events {
+		if event.Type != EventTaskCancelled {
+			continue
+		}
+		if event.Task.Status != database.StatusCancelled || event.Task.Completed || event.Task.Skipped {
+			t.Errorf("задача в событии отмены: статус %s, completed=%v, skipped=%v",
+				event.Task.Status, event.Task.Completed, event.Task.Skipped)
+		}
+		if !reflect.DeepEqual(event.Task, stored) {
+			t.Errorf("задача в событии отличается от сохраненной:\n%+v\n%+v", *event.Task, *stored)
+		}
+		return
+	}
+	t.Fatal("нет события отмены задачи")
+}
@@ -0,0 +1,419 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"five-pillars/internal/database"
+)
+
+// TransferService выгрузка и загрузка данных трекера
+type TransferService struct {
+	repository *database.Repository
+}
+
+func NewTransferService(repo *database.Repository) *TransferService {
+	return &TransferService{
+		repository: repo,
+	}
+}
+
+// Наборы данных загрузки
+const (
+	DatasetTasks     = "tasks"
+	DatasetFeelings  = "feelings"
+	DatasetTemplates = "templates"
+)
+
+// ImportBatch разобранные данные для загрузки вместе с ошибками разбора
+type ImportBatch struct {
+	Tasks     []ImportTaskRow
+	Feelings  []ImportFeelingsRow
+	Templates []ImportTemplateRow
+	Issues    []ImportIssue
+}
+
+// ImportTaskRow задача с номером строки в исходном файле
+type ImportTaskRow struct {
+	Row   int
+	Value database.DailyTask
+}
+
+// ImportFeelingsRow ощущения с номером строки в исходном файле
+type ImportFeelingsRow struct {
+	Row   int
+	Value database.DailyFeelings
+}
+
+// ImportTemplateRow шаблон с номером строки в исходном файле
+type ImportTemplateRow struct {
+	Row   int
+	Value database.TaskTemplate
+}
+
+// ImportIssue проблема в конкретной строке файла
+type ImportIssue struct {
+	Dataset string `json:"dataset"`
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+// ImportCounts итог загрузки по набору данных
+type ImportCounts struct {
+	Added      int `json:"added"`
+	Duplicates int `json:"duplicates"`
+	Invalid    int `json:"invalid"`
+}
+
+// ImportReport отчет о загрузке. При DryRun ничего не записано.
+type ImportReport struct {
+	DryRun    bool          `json:"dry_run"`
+	Tasks     ImportCounts  `json:"tasks"`
+	Feelings  ImportCounts  `json:"feelings"`
+	Templates ImportCounts  `json:"templates"`
+	Issues    []ImportIssue `json:"issues,omitempty"`
+}
+
+// BatchFromSnapshot превращает JSON выгрузку в данные для загрузки
+func BatchFromSnapshot(snapshot *database.Snapshot) *ImportBatch {
+	batch := &ImportBatch{}
+	for i, task := range snapshot.Tasks {
+		batch.Tasks = append(batch.Tasks, ImportTaskRow{Row: i + 1, Value: task})
+	}
+	for i, feelings := range snapshot.Feelings {
+		batch.Feelings = append(batch.Feelings, ImportFeelingsRow{Row: i + 1, Value: feelings})
+	}
+	for i, template := range snapshot.Templates {
+		batch.Templates = append(batch.Templates, ImportTemplateRow{Row: i + 1, Value: template})
+	}
+	return batch
+}
+
+// Export собирает задачи и ощущения за период. Пустые границы означают всю историю.
+func (ts *TransferService) Export(from, to string) (*database.Snapshot, error) {
+	for _, date := range []string{from, to} {
+		if date == "" {
+			continue
+		}
+		if err := ValidateDate(date); err != nil {
+			return nil, err
+		}
+	}
+
+	tasks, err := ts.repository.GetTasksBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	feelings, err := ts.repository.GetFeelingsBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	journal, err := ts.repository.GetJournalBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	sleep, err := ts.repository.GetSleepBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	analyticsFrom, analyticsTo := from, to
+	if analyticsFrom == "" {
+		analyticsFrom = "0000-01-01"
+	}
+	if analyticsTo == "" {
+		analyticsTo = "9999-12-31"
+	}
+	analytics, err := ts.repository.GetWeeklyAnalytics(analyticsFrom, analyticsTo)
+	if err != nil {
+		return nil, err
+	}
+	if analytics.Sleep, err = sleepStats(ts.repository, analyticsFrom, analyticsTo); err != nil {
+		return nil, err
+	}
+	analytics.StartDate, analytics.EndDate = from, to
+
+	templates, err := ts.repository.GetTemplates(false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &database.Snapshot{
+		ExportedAt:  time.Now().UTC(),
+		From:        from,
+		To:          to,
+		Tasks:       tasks,
+		Feelings:    feelings,
+		Journal:     journal,
+		Sleep:       sleep,
+		Templates:   templates,
+		SkipReasons: skipReasonStats(tasks),
+		Analytics:   analytics,
+	}, nil
+}
+
+// skipReasonStats считает пропуски по причинам в порядке SkipReasons
+func skipReasonStats(tasks []database.DailyTask) []database.SkipReasonStat {
+	counts := make(map[string]int)
+	texts := make(map[string]string)
+	for _, task := range tasks {
+		code := SkipReasonCode(task)
+		if code == "" {
+			continue
+		}
+		counts[code]++
+		texts[code] = SkipReasonText(task)
+	}
+
+	var stats []database.SkipReasonStat
+	for _, reason := range SkipReasons {
+		if counts[reason.Code] > 0 {
+			stats = append(stats, database.SkipReasonStat{Code: reason.Code, Text: reason.Text, Count: counts[reason.Code]})
+			delete(counts, reason.Code)
+		}
+	}
+
+	var other []string
+	for code := range counts {
+		other = append(other, code)
+	}
+	sort.Strings(other)
+	for _, code := range other {
+		stats = append(stats, database.SkipReasonStat{Code: code, Text: texts[code], Count: counts[code]})
+	}
+
+	return stats
+}
+
+// Import проверяет и загружает данные. Задачи с теми же датой, столпом и
+// описанием, ощущения за уже заполненную дату и повторные шаблоны считаются
+// дубликатами и пропускаются. Все строки записываются одной транзакцией,
+// в режиме dryRun ничего не записывается.
+func (ts *TransferService) Import(batch *ImportBatch, dryRun bool) (*ImportReport, error) {
+	report := &ImportReport{DryRun: dryRun, Issues: append([]ImportIssue(nil), batch.Issues...)}
+	for _, issue := range batch.Issues {
+		report.counts(issue.Dataset).Invalid++
+	}
+
+	invalid := func(dataset string, row int, err error) {
+		report.Issues = append(report.Issues, ImportIssue{Dataset: dataset, Row: row, Message: err.Error()})
+		report.counts(dataset).Invalid++
+	}
+
+	// Строки сначала проверяются, потом записываются одной транзакцией
+	var tasks []database.DailyTask
+	var feelingsRows []database.DailyFeelings
+	var templates []database.TaskTemplate
+
+	seenTasks := make(map[string]bool)
+	for _, row := range batch.Tasks {
+		task, err := normalizeImportTask(ts.repository.Registry(), row.Value)
+		if err != nil {
+			invalid(DatasetTasks, row.Row, err)
+			continue
+		}
+
+		key := task.Date + "|" + string(task.Pillar) + "|" + task.Description
+		exists, err := ts.repository.TaskExists(task.Date, task.Pillar, task.Description)
+		if err != nil {
+			return report, err
+		}
+		if exists || seenTasks[key] {
+			report.Tasks.Duplicates++
+			continue
+		}
+		seenTasks[key] = true
+
+		tasks = append(tasks, task)
+		report.Tasks.Added++
+	}
+
+	seenFeelings := make(map[string]bool)
+	for _, row := range batch.Feelings {
+		feelings := row.Value
+		if err := ValidateDate(feelings.Date); err != nil {
+			invalid(DatasetFeelings, row.Row, err)
+			continue
+		}
+		if err := validateFeelings(ts.repository.Registry(), &feelings); err != nil {
+			invalid(DatasetFeelings, row.Row, err)
+			continue
+		}
+
+		exists, err := ts.repository.FeelingsExist(feelings.Date)
+		if err != nil {
+			return report, err
+		}
+		if exists || seenFeelings[feelings.Date] {
+			report.Feelings.Duplicates++
+			continue
+		}
+		seenFeelings[feelings.Date] = true
+
+		feelingsRows = append(feelingsRows, feelings)
+		report.Feelings.Added++
+	}
+
+	seenTemplates := make(map[string]bool)
+	for _, row := range batch.Templates {
+		template, err := normalizeImportTemplate(ts.repository.Registry(), row.Value)
+		if err != nil {
+			invalid(DatasetTemplates, row.Row, err)
+			continue
+		}
+
+		key := string(template.Pillar) + "|" + template.Description + "|" + template.TimeUTC
+		exists, err := ts.repository.TemplateExists(template.Pillar, template.Description, template.TimeUTC)
+		if err != nil {
+			return report, err
+		}
+		if exists || seenTemplates[key] {
+			report.Templates.Duplicates++
+			continue
+		}
+		seenTemplates[key] = true
+
+		templates = append(templates, template)
+		report.Templates.Added++
+	}
+
+	if dryRun {
+		return report, nil
+	}
+	if err := ts.repository.ImportRows(tasks, feelingsRows, templates); err != nil {
+		return nil, fmt.Errorf("ошибка загрузки, ничего не записано: %v", err)
+	}
+	return report, nil
+}
+
+func (r *ImportReport) counts(dataset string) *ImportCounts {
+	switch dataset {
+	case DatasetFeelings:
+		return &r.Feelings
+	case DatasetTemplates:
+		return &r.Templates
+	default:
+		return &r.Tasks
+	}
+}
+
+func normalizeImportTask(registry *database.Registry, task database.DailyTask) (database.DailyTask, error) {
+	pillar, err := ParsePillar(registry, string(task.Pillar))
+	if err != nil {
+		return task, err
+	}
+	task.Pillar = pillar
+
+	task.Description = strings.TrimSpace(task.Description)
+	if task.Description == "" {
+		return task, ErrEmptyDescription
+	}
+	if err := ValidateTime(task.TimeUTC); err != nil {
+		return task, err
+	}
+	if err := ValidateDate(task.Date); err != nil {
+		return task, err
+	}
+	if task.LeadMinutes, err = validateStart(task.StartUTC, task.LeadMinutes); err != nil {
+		return task, err
+	}
+	// Выгрузки до появления статусов содержат только флаги completed и skipped
+	if task.Status == "" {
+		if task.Completed && task.Skipped {
+			return task, NewValidationError("Задача не может быть одновременно выполнена и пропущена")
+		}
+		task.Status = database.StatusFromFlags(task.Completed, task.Skipped)
+	} else if task.Status, err = ParseTaskStatus(string(task.Status)); err != nil {
+		return task, err
+	}
+	// Ссылки на шаблон, перенесенную задачу и цель ведут на ID исходной базы
+	task.TemplateID, task.CarriedFrom, task.GoalID = 0, 0, 0
+
+	var checklist []database.ChecklistItem
+	for _, item := range task.Checklist {
+		if text := strings.TrimSpace(item.Text); text != "" {
+			checklist = append(checklist, database.ChecklistItem{Text: text, Done: item.Done})
+		}
+	}
+	if len(checklist) > MaxChecklistItems {
+		return task, ErrChecklistTooLong
+	}
+	task.Checklist = checklist
+
+	metric, err := validateMetric(Metric{Unit: task.MetricUnit, Target: task.MetricTarget, Aggregation: task.MetricAgg})
+	if err != nil {
+		return task, err
+	}
+	task.MetricUnit, task.MetricTarget, task.MetricAgg = metric.Unit, metric.Target, metric.Aggregation
+	if task.Value != nil && (task.MetricUnit == "" || *task.Value < 0) {
+		return task, ErrInvalidValue
+	}
+	if task.Tags, err = ParseTags(task.Tags); err != nil {
+		return task, err
+	}
+	return task, nil
+}
+
+func normalizeImportTemplate(registry *database.Registry, template database.TaskTemplate) (database.TaskTemplate, error) {
+	pillar, err := ParsePillar(registry, string(template.Pillar))
+	if err != nil {
+		return template, err
+	}
+	template.Pillar = pillar
+
+	template.Description = strings.TrimSpace(template.Description)
+	if template.Description == "" {
+		return template, ErrEmptyDescription
+	}
+	if err := ValidateTime(template.TimeUTC); err != nil {
+		return template, err
+	}
+	if err := ValidateWeekdays(template.Weekdays); err != nil {
+		return template, err
+	}
+	if template.LeadMinutes, err = validateStart(template.StartUTC, template.LeadMinutes); err != nil {
+		return template, err
+	}
+	policy, err := ParseRolloverPolicy(template.Rollover)
+	if err != nil {
+		return template, err
+	}
+	template.Rollover = string(policy)
+	template.GoalID = 0
+
+	items := template.ChecklistItems()
+	if len(items) > MaxChecklistItems {
+		return template, ErrChecklistTooLong
+	}
+	template.Checklist = strings.Join(items, "\n")
+
+	metric, err := validateMetric(Metric{Unit: template.MetricUnit, Target: template.MetricTarget, Aggregation: template.MetricAgg})
+	if err != nil {
+		return template, err
+	}
+	template.MetricUnit, template.MetricTarget, template.MetricAgg = metric.Unit, metric.Target, metric.Aggregation
+	if template.Tags, err = ParseTags(template.Tags); err != nil {
+		return template, err
+	}
+	return template, nil
+}
+
+// ValidateWeekdays проверяет список ISO дней недели вида "1,3,5"
+func ValidateWeekdays(value string) error {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	for _, part := range strings.Split(value, ",") {
+		day, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || day < 1 || day > 7 {
+			return NewValidationError("Дни недели должны быть числами от 1 (пн) до 7 (вс) через запятую")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,451 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"five-pillars/internal/database"
+)
+
+// Ограничения справочника показателей самочувствия в символах
+const (
+	maxWellbeingName  = 30
+	maxWellbeingEmoji = 8
+	maxWellbeingUnit  = 10
+	maxWellbeingText  = 200
+)
+
+var (
+	ErrInvalidWellbeingKey   = NewValidationError("Ключ показателя - латиница, цифры и _, от 2 до 20 символов, например stress")
+	ErrInvalidWellbeingName  = NewValidationError(fmt.Sprintf("Название показателя не может быть пустым и длиннее %d символов", maxWellbeingName))
+	ErrInvalidWellbeingEmoji = NewValidationError(fmt.Sprintf("Эмодзи показателя не может быть пустым и длиннее %d символов", maxWellbeingEmoji))
+	ErrInvalidWellbeingUnit  = NewValidationError(fmt.Sprintf("Единица показателя не длиннее %d символов", maxWellbeingUnit))
+	ErrInvalidWellbeingType  = NewValidationError("Тип показателя: scale (оценка), number (число), bool (да/нет) или text (текст)")
+	ErrInvalidWellbeingRange = NewValidationError("Минимум показателя должен быть не больше максимума, у scale - целые числа")
+	ErrWellbeingMarkup       = NewValidationError("Название, эмодзи и единица показателя не могут содержать символы <, > и &")
+	ErrBuiltinWellbeingRange = NewValidationError("Диапазон встроенного показателя не меняется")
+)
+
+// inputName имя показателя для /feelings имя=значение: название, если оно
+// из одного слова, иначе первый синоним или ключ
+func inputName(metric database.WellbeingMetric) string {
+	for _, name := range metric.Names()[1:] {
+		if !strings.Contains(name, " ") {
+			return name
+		}
+	}
+	return metric.Key
+}
+
+// FeelingsFormat подсказка к /feelings по действующим показателям:
+// "энергия=[1-10] контроль=[1-10] сон=[0-24 ч] настроение=[текст]"
+func FeelingsFormat(registry *database.Registry) string {
+	var parts []string
+	for _, metric := range registry.WellbeingMetrics(false) {
+		parts = append(parts, inputName(metric)+"=["+FeelingsRange(metric)+"]")
+	}
+	return strings.Join(parts, " ")
+}
+
+// FeelingsRange допустимые значения показателя: "1-10", "0-24 ч", "да/нет"
+func FeelingsRange(metric database.WellbeingMetric) string {
+	var value string
+	switch {
+	case metric.Type == database.WellbeingBool:
+		return "да/нет"
+	case metric.Type == database.WellbeingText:
+		return "текст"
+	case metric.Key == database.WellbeingSleep:
+		return "часы или 23:40-07:10"
+	case metric.Min != nil && metric.Max != nil:
+		value = database.FormatNumber(*metric.Min) + "-" + database.FormatNumber(*metric.Max)
+	case metric.Min != nil:
+		value = "от " + database.FormatNumber(*metric.Min)
+	case metric.Max != nil:
+		value = "до " + database.FormatNumber(*metric.Max)
+	default:
+		value = "число"
+	}
+	if metric.Unit != "" {
+		value += " " + metric.Unit
+	}
+	return value
+}
+
+// ParseWellbeingValue проверяет значение показателя и приводит его к виду,
+// в котором оно хранится: числа без лишних нулей, bool - 1 или 0
+func ParseWellbeingValue(metric database.WellbeingMetric, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	invalid := NewValidationError(fmt.Sprintf("%s: нужно значение %s", metric.Name, FeelingsRange(metric)))
+
+	switch metric.Type {
+	case database.WellbeingText:
+		if len([]rune(value)) > maxWellbeingText {
+			return "", NewValidationError(fmt.Sprintf("%s: не длиннее %d символов", metric.Name, maxWellbeingText))
+		}
+		return value, nil
+	case database.WellbeingBool:
+		switch strings.ToLower(value) {
+		case "да", "yes", "true", "1", "+":
+			return "1", nil
+		case "нет", "no", "false", "0", "-":
+			return "0", nil
+		}
+		return "", invalid
+	}
+
+	number, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", "."), 64)
+	if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+		return "", invalid
+	}
+	if metric.Type == database.WellbeingScale && number != math.Trunc(number) {
+		return "", invalid
+	}
+	if (metric.Min != nil && number < *metric.Min) || (metric.Max != nil && number > *metric.Max) {
+		return "", invalid
+	}
+	// Ноль в колонке сна значит «не записано», поэтому сон только положительный
+	if metric.Key == database.WellbeingSleep && number <= 0 {
+		return "", invalid
+	}
+	return database.FormatNumber(number), nil
+}
+
+// SetFeelingsValue записывает проверенное значение показателя: встроенные -
+// в поля ощущений, остальные - в Values
+func SetFeelingsValue(feelings *database.DailyFeelings, key, value string) {
+	switch key {
+	case database.WellbeingEnergy:
+		feelings.EnergyLevel, _ = strconv.Atoi(value)
+	case database.WellbeingControl:
+		feelings.ControlLevel, _ = strconv.Atoi(value)
+	case database.WellbeingSleep:
+		feelings.SleepHours, _ = strconv.ParseFloat(value, 64)
+	case database.WellbeingMood:
+		feelings.Mood = value
+	default:
+		if feelings.Values == nil {
+			feelings.Values = make(map[string]string)
+		}
+		if value == "" {
+			delete(feelings.Values, key)
+		} else {
+			feelings.Values[key] = value
+		}
+	}
+}
+
+// FeelingsLines значения ощущений за день по строке на показатель в порядке
+// справочника: "⚡ Энергия: 8/10". Значения удаленных из справочника
+// показателей идут в конце по ключу.
+func FeelingsLines(registry *database.Registry, feelings database.DailyFeelings) []string {
+	var lines []string
+	known := make(map[string]bool)
+	for _, metric := range registry.WellbeingMetrics(true) {
+		known[metric.Key] = true
+		value := feelings.Value(metric.Key)
+		if value == "" {
+			continue
+		}
+		line := fmt.Sprintf("%s: %s", metric.Title(), metric.FormatValue(value))
+		if metric.Key == database.WellbeingSleep && feelings.Sleep != nil {
+			line += fmt.Sprintf(" (%s-%s)", feelings.Sleep.Bedtime, feelings.Sleep.WakeTime)
+		}
+		lines = append(lines, line)
+	}
+
+	var unknown []string
+	for key := range feelings.Values {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		lines = append(lines, fmt.Sprintf("📌 %s: %s", key, feelings.Values[key]))
+	}
+	return lines
+}
+
+// WellbeingAverageLines средние показателей за период по строке в порядке
+// справочника: "⚡ Энергия: 7.2/10", для bool - "🍷 Алкоголь: 40% дней"
+func WellbeingAverageLines(registry *database.Registry, averages map[string]float64) []string {
+	var lines []string
+	known := make(map[string]bool)
+	for _, metric := range registry.WellbeingMetrics(true) {
+		known[metric.Key] = true
+		avg, ok := averages[metric.Key]
+		if !ok {
+			continue
+		}
+
+		var value string
+		switch {
+		case metric.Type == database.WellbeingBool:
+			value = fmt.Sprintf("%.0f%% дней", avg*100)
+		case math.Abs(avg) >= 100:
+			value = metric.FormatValue(fmt.Sprintf("%.0f", avg))
+		default:
+			value = metric.FormatValue(fmt.Sprintf("%.1f", avg))
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", metric.Title(), value))
+	}
+
+	var unknown []string
+	for key := range averages {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		lines = append(lines, fmt.Sprintf("📌 %s: %.1f", key, averages[key]))
+	}
+	return lines
+}
+
+// WellbeingPatch изменения показателя, nil - поле не меняется
+type WellbeingPatch struct {
+	Name     *string   `json:"name"`
+	Emoji    *string   `json:"emoji"`
+	Unit     *string   `json:"unit"`
+	Aliases  *[]string `json:"aliases"`
+	Min      **float64 `json:"min"`
+	Max      **float64 `json:"max"`
+	Position *int      `json:"position"`
+	Archived *bool     `json:"archived"`
+}
+
+// ParseWellbeingPatch изменение одного поля из команды: name|emoji|unit|aliases|min|max|position.
+// Синонимы перечисляются через запятую, "-" очищает единицу, синонимы и границы.
+func ParseWellbeingPatch(field, value string) (WellbeingPatch, error) {
+	value = strings.TrimSpace(value)
+	if value == "-" {
+		value = ""
+	}
+
+	var patch WellbeingPatch
+	field = strings.ToLower(field)
+	switch field {
+	case "name":
+		patch.Name = &value
+	case "emoji":
+		patch.Emoji = &value
+	case "unit":
+		patch.Unit = &value
+	case "aliases":
+		aliases := strings.Split(value, ",")
+		patch.Aliases = &aliases
+	case "min", "max":
+		var bound *float64
+		if value != "" {
+			number, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", "."), 64)
+			if err != nil {
+				return WellbeingPatch{}, NewValidationError("Граница показателя - число или - без ограничения")
+			}
+			bound = &number
+		}
+		if field == "min" {
+			patch.Min = &bound
+		} else {
+			patch.Max = &bound
+		}
+	case "position":
+		position, err := strconv.Atoi(value)
+		if err != nil || position < 1 {
+			return WellbeingPatch{}, NewValidationError("Позиция показателя - число от 1")
+		}
+		patch.Position = &position
+	default:
+		return WellbeingPatch{}, NewValidationError("Неизвестное поле показателя. Доступны: name, emoji, unit, aliases, min, max, position")
+	}
+	return patch, nil
+}
+
+// WellbeingService справочник показателей самочувствия
+type WellbeingService struct {
+	repository *database.Repository
+}
+
+func NewWellbeingService(repo *database.Repository) *WellbeingService {
+	return &WellbeingService{repository: repo}
+}
+
+// List показатели по порядку, archived - вместе с архивными
+func (ws *WellbeingService) List(archived bool) []database.WellbeingMetric {
+	return ws.repository.Registry().WellbeingMetrics(archived)
+}
+
+// Get показатель по ключу, включая архивные
+func (ws *WellbeingService) Get(key string) (*database.WellbeingMetric, error) {
+	metric, ok := ws.repository.Registry().GetWellbeingMetric(strings.ToLower(strings.TrimSpace(key)))
+	if !ok {
+		return nil, NewValidationError(fmt.Sprintf("Показатель «%s» не найден", key))
+	}
+	return &metric, nil
+}
+
+// Add добавляет показатель, Position 0 - в конец списка. У scale без
+// границ диапазон 1-10.
+func (ws *WellbeingService) Add(metric database.WellbeingMetric) (*database.WellbeingMetric, error) {
+	metric.Key = strings.ToLower(strings.TrimSpace(metric.Key))
+	if !pillarKeyPattern.MatchString(metric.Key) {
+		return nil, ErrInvalidWellbeingKey
+	}
+	if _, exists := ws.repository.Registry().GetWellbeingMetric(metric.Key); exists {
+		return nil, NewValidationError(fmt.Sprintf("Показатель «%s» уже есть", metric.Key))
+	}
+
+	metric.Type = database.WellbeingType(strings.ToLower(strings.TrimSpace(string(metric.Type))))
+	switch metric.Type {
+	case database.WellbeingScale:
+		if metric.Min == nil && metric.Max == nil {
+			low, high := 1.0, 10.0
+			metric.Min, metric.Max = &low, &high
+		}
+	case database.WellbeingNumber:
+	case database.WellbeingBool, database.WellbeingText:
+		metric.Min, metric.Max, metric.Unit = nil, nil, ""
+	default:
+		return nil, ErrInvalidWellbeingType
+	}
+	if metric.Position < 0 {
+		metric.Position = 0
+	}
+	metric.Archived = false
+
+	if err := validateWellbeingMetric(ws.repository.Registry(), &metric); err != nil {
+		return nil, err
+	}
+	if err := ws.repository.AddWellbeingMetric(metric); err != nil {
+		return nil, err
+	}
+	return ws.Get(metric.Key)
+}
+
+// Update меняет поля показателя из patch. Тип не меняется, чтобы старые
+// значения не потеряли смысл.
+func (ws *WellbeingService) Update(key string, patch WellbeingPatch) (*database.WellbeingMetric, error) {
+	current, err := ws.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	metric := *current
+
+	if patch.Name != nil {
+		metric.Name = *patch.Name
+	}
+	if patch.Emoji != nil {
+		metric.Emoji = *patch.Emoji
+	}
+	if patch.Unit != nil {
+		metric.Unit = *patch.Unit
+	}
+	if patch.Aliases != nil {
+		metric.Aliases = *patch.Aliases
+	}
+	if patch.Min != nil || patch.Max != nil {
+		if metric.Builtin() {
+			return nil, ErrBuiltinWellbeingRange
+		}
+		if patch.Min != nil {
+			metric.Min = *patch.Min
+		}
+		if patch.Max != nil {
+			metric.Max = *patch.Max
+		}
+	}
+	if patch.Position != nil {
+		metric.Position = *patch.Position
+	}
+	if patch.Archived != nil {
+		metric.Archived = *patch.Archived
+	}
+
+	if err := validateWellbeingMetric(ws.repository.Registry(), &metric); err != nil {
+		return nil, err
+	}
+	if _, err := ws.repository.UpdateWellbeingMetric(metric); err != nil {
+		return nil, err
+	}
+	return ws.Get(metric.Key)
+}
+
+// validateWellbeingMetric нормализует поля показателя и проверяет, что его
+// названия не совпадают с названиями других показателей
+func validateWellbeingMetric(registry *database.Registry, metric *database.WellbeingMetric) error {
+	metric.Name = strings.TrimSpace(metric.Name)
+	if metric.Name == "" || len([]rune(metric.Name)) > maxWellbeingName {
+		return ErrInvalidWellbeingName
+	}
+	metric.Emoji = strings.TrimSpace(metric.Emoji)
+	if metric.Emoji == "" || len([]rune(metric.Emoji)) > maxWellbeingEmoji {
+		return ErrInvalidWellbeingEmoji
+	}
+	metric.Unit = strings.TrimSpace(metric.Unit)
+	if len([]rune(metric.Unit)) > maxWellbeingUnit {
+		return ErrInvalidWellbeingUnit
+	}
+	if strings.ContainsAny(metric.Name+metric.Emoji+metric.Unit, "<>&") {
+		return ErrWellbeingMarkup
+	}
+
+	if metric.Min != nil && metric.Max != nil && *metric.Min > *metric.Max {
+		return ErrInvalidWellbeingRange
+	}
+	if metric.Type == database.WellbeingScale {
+		for _, bound := range []*float64{metric.Min, metric.Max} {
+			if bound != nil && *bound != math.Trunc(*bound) {
+				return ErrInvalidWellbeingRange
+			}
+		}
+	}
+
+	var aliases []string
+	seen := make(map[string]bool)
+	for _, alias := range metric.Aliases {
+		alias = strings.ToLower(strings.TrimSpace(alias))
+		if alias == "" || seen[alias] || alias == metric.Key || alias == strings.ToLower(metric.Name) {
+			continue
+		}
+		if strings.ContainsAny(alias, " =<>&") || len([]rune(alias)) > maxWellbeingName {
+			return NewValidationError(fmt.Sprintf("Синоним «%s» должен быть одним словом без =, <, > и & не длиннее %d символов", alias, maxWellbeingName))
+		}
+		seen[alias] = true
+		aliases = append(aliases, alias)
+	}
+	metric.Aliases = aliases
+
+	for _, other := range registry.WellbeingMetrics(true) {
+		if other.Key == metric.Key {
+			continue
+		}
+		for _, name := range metric.Names() {
+			for _, taken := range other.Names() {
+				if name == taken {
+					return NewValidationError(fmt.Sprintf("Название «%s» уже занято показателем %s", name, other.Title()))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// FormatWellbeingMetrics справочник показателей: тип, диапазон и имена для /feelings
+func FormatWellbeingMetrics(metrics []database.WellbeingMetric) string {
+	var b strings.Builder
+	for _, metric := range metrics {
+		b.WriteString(fmt.Sprintf("%s (%s) - %s=[%s]", metric.Title(), metric.Key, inputName(metric), FeelingsRange(metric)))
+		if aliases := metric.Names()[2:]; len(aliases) > 0 {
+			b.WriteString(" · " + strings.Join(aliases, ", "))
+		}
+		if metric.Archived {
+			b.WriteString(" · 📦 в архиве")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -3,9 +3,13 @@ package telegram
 import (
 	"context"
 	"encoding/json"
-	"five-pillars/internal/utils"
+	"five-pillars/internal/backup"
+	"five-pillars/internal/ical"
 	"fmt"
+	"html"
+	"io"
 	"log"
+	"net/http"
 	"strconv"
 	"strings"
 	"time"
@@ -16,33 +20,61 @@ import (
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
+const (
+	// maxDownloadSize ограничение Bot API на скачивание файлов ботом
+	maxDownloadSize = 20 << 20
+	// maxUploadSize ограничение Bot API на отправку файлов ботом
+	maxUploadSize = 50 << 20
+)
+
 type Bot struct {
-	bot         *tgbotapi.BotAPI
-	chatID      int64
-	db          *database.Database
-	services    *services.ServiceManager
-	handlers    map[string]func(*tgbotapi.Message)
-	skipReasons map[string]string
+	bot          *tgbotapi.BotAPI
+	chatID       int64
+	fileEndpoint string
+	services     *services.ServiceManager
+	backups      *backup.Manager
+	handlers     map[string]func(*tgbotapi.Message)
+
+	// pendingImport файл, проверенный в режиме dry-run и ожидающий подтверждения
+	pendingImport *pendingFile
+	// pendingValue задача, результат которой ждем числом в чате, и когда
+	// о нем спросили
+	pendingValue   int
+	pendingValueAt time.Time
+	// pendingJournal дата дневника, в который пишутся сообщения после /journal
+	pendingJournal string
+
+	calendarRules ical.Rules
+	calendarDays  int
+
+	// outboxWake будит обработчик исходящей очереди
+	outboxWake chan struct{}
+}
+
+type pendingFile struct {
+	name string
+	data []byte
 }
 
-func NewBot(token string, chatID int64, db *database.Database, serviceManager *services.ServiceManager) (*Bot, error) {
-	botAPI, err := tgbotapi.NewBotAPI(token)
+// NewBot создает бота. apiEndpoint - шаблон адреса Bot API в формате
+// tgbotapi.APIEndpoint; пустая строка означает официальный API Telegram.
+func NewBot(token string, chatID int64, apiEndpoint string, serviceManager *services.ServiceManager) (*Bot, error) {
+	if apiEndpoint == "" {
+		apiEndpoint = tgbotapi.APIEndpoint
+	}
+
+	botAPI, err := tgbotapi.NewBotAPIWithAPIEndpoint(token, apiEndpoint)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка создания бота: %v", err)
 	}
 
 	bot := &Bot{
-		bot:      botAPI,
-		chatID:   chatID,
-		db:       db,
-		services: serviceManager,
-		handlers: make(map[string]func(*tgbotapi.Message)),
-		skipReasons: map[string]string{
-			"noenergy":   "🔋 Не было энергии",
-			"notime":     "⏰ Не хватило времени",
-			"irrelevant": "🎯 Задача неактуальна",
-			"illness":    "Болел",
-		},
+		bot:          botAPI,
+		chatID:       chatID,
+		fileEndpoint: fileEndpointFor(apiEndpoint),
+		services:     serviceManager,
+		handlers:     make(map[string]func(*tgbotapi.Message)),
+		outboxWake:   make(chan struct{}, 1),
 	}
 
 	bot.registerHandlers()
@@ -50,6 +82,30 @@ func NewBot(token string, chatID int64, db *database.Database, serviceManager *s
 	return bot, nil
 }
 
+// SetBackups подключает резервное копирование для команды /backup
+func (b *Bot) SetBackups(backups *backup.Manager) {
+	b.backups = backups
+}
+
+// SetCalendarImport правила выбора столпа для присланных .ics и на сколько
+// дней вперед разворачивать повторения
+func (b *Bot) SetCalendarImport(rules ical.Rules, days int) {
+	b.calendarRules = rules
+	b.calendarDays = days
+}
+
+// fileEndpointFor выводит адрес скачивания файлов из адреса API:
+// https://host/bot%s/%s -> https://host/file/bot%s/%s
+func fileEndpointFor(apiEndpoint string) string {
+	if apiEndpoint == tgbotapi.APIEndpoint {
+		return tgbotapi.FileEndpoint
+	}
+	if i := strings.LastIndex(apiEndpoint, "/bot%s/%s"); i >= 0 {
+		return apiEndpoint[:i] + "/file/bot%s/%s"
+	}
+	return tgbotapi.FileEndpoint
+}
+
 func (b *Bot) registerHandlers() {
 	b.handlers["/start"] = b.handleStart
 	b.handlers["/today"] = b.handleToday
@@ -58,48 +114,94 @@ func (b *Bot) registerHandlers() {
 	b.handlers["/all"] = b.handleAll
 	b.handlers["/time"] = b.handleChangeTime
 	b.handlers["/date"] = b.handleChangeDate
+	b.handlers["/lead"] = b.handleLead
+	b.handlers["/check"] = b.handleChecklist
+	b.handlers["/value"] = b.handleValue
+	b.handlers["/metric"] = b.handleMetric
+	b.handlers["/metrics"] = b.handleMetrics
+	b.handlers["/goals"] = b.handleGoals
+	b.handlers["/goal"] = b.handleGoal
+	b.handlers["/pillars"] = b.handlePillars
+	b.handlers["/pillar"] = b.handlePillar
+	b.handlers["/wellbeing"] = b.handleWellbeing
+	b.handlers["/sleep"] = b.handleSleep
+	b.handlers["/tag"] = b.handleTag
+	b.handlers["/find"] = b.handleFind
+	b.handlers["/journal"] = b.handleJournal
 	b.handlers["/feelings"] = b.handleFeelings
+	b.handlers["/export"] = b.handleExport
+	b.handlers["/backup"] = b.handleBackup
+	b.handlers["/dnd"] = b.handleDND
 	b.handlers["/help"] = b.handleHelp
 }
 
+// SendMessage ставит сообщение в исходящую очередь, отправка идет в фоне
 func (b *Bot) SendMessage(text string) error {
-	msg := tgbotapi.NewMessage(b.chatID, text)
-	msg.ParseMode = "HTML"
-	_, err := b.bot.Send(msg)
-	return err
+	return b.enqueue(database.OutboxMessage{Method: "sendMessage", Text: text})
 }
 
-func (b *Bot) SendTaskNotification(task database.TaskNotification) error {
-	pillarName := utils.GetPillarName(task.Pillar)
-	pillarEmoji := utils.GetPillarEmoji(task.Pillar)
-
-	formattedTime := utils.FormatTimeForDisplay(task.TimeUTC)
-
-	message := fmt.Sprintf(
-		"🔔 <b>%s %s</b>\n\n"+
-			"<i>%s</i>\n\n"+
-			"⏰ Время: %s\n"+
-			"📝 %s",
-		pillarEmoji, pillarName,
-		task.Description,
-		formattedTime,
-		task.Notes,
-	)
+// DownloadFile скачивает файл, присланный пользователем
+func (b *Bot) DownloadFile(fileID string) ([]byte, error) {
+	file, err := b.bot.GetFile(tgbotapi.FileConfig{FileID: fileID})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения файла: %v", err)
+	}
+
+	resp, err := http.Get(fmt.Sprintf(b.fileEndpoint, b.bot.Token, file.FilePath))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка скачивания файла: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ошибка скачивания файла: HTTP %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, maxDownloadSize))
+}
+
+// SendPhoto ставит в исходящую очередь фото, уже загруженное в Telegram
+func (b *Bot) SendPhoto(fileID, caption string) error {
+	return b.enqueue(database.OutboxMessage{Method: "sendPhoto", Text: caption, FileName: fileID})
+}
+
+// SendDocument ставит файл в исходящую очередь
+func (b *Bot) SendDocument(name string, data []byte, caption string) error {
+	return b.enqueue(database.OutboxMessage{
+		Method:   "sendDocument",
+		Text:     caption,
+		FileName: name,
+		FileData: data,
+	})
+}
 
-	b.SendMessage(message)
+// SendFile ставит в очередь файл с диска: в очереди хранится только путь
+func (b *Bot) SendFile(path string, caption string) error {
+	return b.enqueue(database.OutboxMessage{
+		Method:   "sendDocument",
+		Text:     caption,
+		FilePath: path,
+	})
+}
 
-	keyboard := b.createTaskKeyboard(task.ID)
-	actionMsg := tgbotapi.NewMessage(b.chatID, "Выполнено?")
-	actionMsg.ReplyMarkup = keyboard
-	actionMsg.ParseMode = "HTML"
+func (b *Bot) SendTaskNotification(task database.TaskNotification) error {
+	// Пункты чек-листа показываются кнопками под напоминанием
+	checklist := task.Checklist
+	task.Checklist = nil
+	message := services.FormatTaskNotification(b.services.Registry(), task)
 
-	_, err := b.bot.Send(actionMsg)
-	return err
+	if err := b.SendMessage(message); err != nil {
+		return err
+	}
+	prompt := taskPrompt(database.DailyTask{Checklist: checklist})
+	return b.enqueueWithKeyboard(prompt, b.createTaskKeyboard(task.ID, checklist))
 }
 
-// createTaskKeyboard создает клавиатуру для взаимодействия с задачей
-func (b *Bot) createTaskKeyboard(taskID int) tgbotapi.InlineKeyboardMarkup {
-	return tgbotapi.NewInlineKeyboardMarkup(
+// createTaskKeyboard создает клавиатуру для взаимодействия с задачей,
+// над кнопками задачи - пункты чек-листа
+func (b *Bot) createTaskKeyboard(taskID int, checklist []database.ChecklistItem) tgbotapi.InlineKeyboardMarkup {
+	rows := checklistRows(checklist)
+	rows = append(rows,
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("✅ Выполнил", fmt.Sprintf("complete_%d", taskID)),
 		),
@@ -108,16 +210,17 @@ func (b *Bot) createTaskKeyboard(taskID int) tgbotapi.InlineKeyboardMarkup {
 			tgbotapi.NewInlineKeyboardButtonData("❌ Закрыть", fmt.Sprintf("skip_%d", taskID)),
 		),
 	)
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
 }
 
 // createSkipReasonKeyboard создает клавиатуру для выбора причины пропуска
 func (b *Bot) createSkipReasonKeyboard(taskID int) tgbotapi.InlineKeyboardMarkup {
 	var rows [][]tgbotapi.InlineKeyboardButton
 
-	for code, text := range b.skipReasons {
-		callbackData := fmt.Sprintf("skip_reason_%d_%s", taskID, code)
+	for _, reason := range services.SkipReasons {
+		callbackData := fmt.Sprintf("skip_reason_%d_%s", taskID, reason.Code)
 		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData(text, callbackData),
+			tgbotapi.NewInlineKeyboardButtonData(reason.Text, callbackData),
 		))
 	}
 
@@ -135,15 +238,15 @@ func (b *Bot) SendCombinedMissedNotification(missedTasks []database.TaskNotifica
 	message.WriteString("<i>Найдены задачи, которые должны были быть выполнены ранее:</i>\n\n")
 
 	for i, task := range missedTasks {
-		pillarName := utils.GetPillarName(task.Pillar)
+		pillarName := b.services.Registry().PillarName(database.Pillar(task.Pillar))
 
 		message.WriteString(fmt.Sprintf(
 			"%d. <b>%s</b>\n",
-			i+1, pillarName,
+			i+1, html.EscapeString(pillarName),
 		))
 		message.WriteString(fmt.Sprintf(
 			"   <i>%s</i>\n",
-			task.Description,
+			html.EscapeString(task.Description),
 		))
 		message.WriteString(fmt.Sprintf(
 			"   ⏱ Должно было быть: %s UTC\n\n",
@@ -163,9 +266,12 @@ func (b *Bot) Start(ctx context.Context) {
 	u.Timeout = 60
 	updates := b.bot.GetUpdatesChan(u)
 
+	go b.runOutbox(ctx)
+
 	for {
 		select {
 		case <-ctx.Done():
+			b.bot.StopReceivingUpdates()
 			return
 		case update := <-updates:
 			b.handleUpdate(update)
@@ -191,12 +297,25 @@ func (b *Bot) handleUpdate(update tgbotapi.Update) {
 	b.handleMessage(update.Message)
 }
 
-// handleMessage обрабатывает текстовые сообщения
+// handleMessage обрабатывает текстовые сообщения и файлы
 func (b *Bot) handleMessage(msg *tgbotapi.Message) {
+	if msg.Document != nil {
+		b.handleDocument(msg)
+		return
+	}
+	if len(msg.Photo) > 0 {
+		b.handleJournalPhoto(msg)
+		return
+	}
+
 	text := msg.Text
 	if text == "" {
 		return
 	}
+	// Любая команда, кроме /journal, заканчивает запись дневника
+	if strings.HasPrefix(text, "/") && strings.Fields(text)[0] != "/journal" {
+		b.pendingJournal = ""
+	}
 
 	// Обработка команд с префиксами
 	switch {
@@ -208,17 +327,20 @@ func (b *Bot) handleMessage(msg *tgbotapi.Message) {
 		b.handleChangeTime(msg)
 	case strings.HasPrefix(text, "/date "):
 		b.handleChangeDate(msg)
-	default:
-		if strings.HasPrefix(text, "/") {
-			parts := strings.Fields(text)
-			command := parts[0]
-
-			if handler, exists := b.handlers[command]; exists {
-				handler(msg)
-			} else {
-				b.SendMessageOrLogError("❌ Неизвестная команда. Используйте /help")
-			}
+	case strings.HasPrefix(text, "/"):
+		parts := strings.Fields(text)
+		command := parts[0]
+
+		if handler, exists := b.handlers[command]; exists {
+			handler(msg)
+		} else {
+			b.SendMessageOrLogError("❌ Неизвестная команда. Используйте /help")
 		}
+	case b.pendingJournal != "":
+		b.addJournalText(b.pendingJournal, text)
+	default:
+		// Обычный текст - ответ на вопрос о результате задачи
+		b.handlePendingValue(text)
 	}
 }
 
@@ -248,6 +370,21 @@ func (b *Bot) handleCallbackQuery(callback *tgbotapi.CallbackQuery) {
 		b.handleSkipTask(data, callback.Message.MessageID)
 	case strings.HasPrefix(data, "missed_complete_"):
 		b.handleMissedCompleteTask(data, callback.Message.MessageID)
+	case strings.HasPrefix(data, "value_"):
+		b.handleValueButton(data, callback.Message.MessageID)
+	case strings.HasPrefix(data, "check_"):
+		b.handleChecklistToggle(data, callback.Message.MessageID)
+	case strings.HasPrefix(data, "carry_"):
+		b.handleCarry(data)
+	case strings.HasPrefix(data, "rollover_"):
+		b.handleRollover(data)
+	case strings.HasPrefix(data, "plan_"):
+		b.handlePlan(data, callback.Message.MessageID)
+	case data == "import_apply":
+		b.handleImportApply(callback.Message.MessageID)
+	case data == "import_cancel":
+		b.pendingImport = nil
+		b.safeDeleteMessage(callback.Message.MessageID)
 	}
 }
 
@@ -258,11 +395,15 @@ func (b *Bot) handleCompleteTask(data string) {
 		b.SendMessageOrLogError("❌ Ошибка обработки запроса")
 		return
 	}
-	if err := database.NewRepository(b.db).UpdateTaskCompletion(taskID, true); err != nil {
-		b.SendMessageOrLogError("❌ Ошибка обновления задачи")
+	task, err := b.services.Task.CompleteTask(taskID)
+	if err != nil {
+		b.SendError(err, "Ошибка обновления задачи")
 		return
 	}
 	b.SendMessageOrLogError("✅ Задача выполнена!")
+	if task.MetricUnit != "" && task.Value == nil {
+		b.askValue(task)
+	}
 }
 
 // handleSnoozeTask обрабатывает откладывание задачи
@@ -272,28 +413,14 @@ func (b *Bot) handleSnoozeTask(data string) {
 		b.SendMessageOrLogError("❌ Ошибка обработки запроса")
 		return
 	}
-	var currentTime string
-	err = b.db.GetDB().QueryRow("SELECT time_utc FROM tasks WHERE id = ?", taskID).Scan(&currentTime)
-	if err != nil {
-		b.SendMessageOrLogError("❌ Ошибка получения времени задачи")
-		return
-	}
-
-	t, err := time.Parse("15:04", currentTime)
-	if err != nil {
-		b.SendMessageOrLogError("❌ Ошибка парсинга времени")
-		return
-	}
 
-	newTime := t.Add(60 * time.Minute).Format("15:04")
-
-	_, err = b.db.GetDB().Exec("UPDATE tasks SET time_utc = ? WHERE id = ?", newTime, taskID)
+	task, err := b.services.Task.SnoozeTask(taskID, services.DefaultSnoozeMinutes)
 	if err != nil {
-		b.SendMessageOrLogError("❌ Ошибка откладывания задачи")
+		b.SendError(err, "Ошибка откладывания задачи")
 		return
 	}
 
-	b.SendMessageOrLogError(fmt.Sprintf("⏰ Задача отложена до %s UTC", newTime))
+	b.SendMessageOrLogError(fmt.Sprintf("⏰ Задача отложена до %s UTC", task.TimeUTC))
 }
 
 // handleSkipTask обрабатывает начало процесса пропуска задачи
@@ -306,11 +433,9 @@ func (b *Bot) handleSkipTask(data string, messageID int) {
 
 	b.safeDeleteMessage(messageID)
 
-	reasonMsg := tgbotapi.NewMessage(b.chatID, "📝 Почему задача не выполнена?\n(Это поможет аналитике)")
-	reasonMsg.ReplyMarkup = b.createSkipReasonKeyboard(taskID)
-	_, err = b.bot.Send(reasonMsg)
-	if err != nil {
-		return
+	reasonText := "📝 Почему задача не выполнена?\n(Это поможет аналитике)"
+	if err := b.enqueueWithKeyboard(reasonText, b.createSkipReasonKeyboard(taskID)); err != nil {
+		log.Printf("⚠️ %v", err)
 	}
 }
 
@@ -322,17 +447,13 @@ func (b *Bot) handleSkipReason(data string) {
 	}
 
 	taskID, _ := strconv.Atoi(parts[0])
-	reasonCode := parts[1]
-	reasonText := b.skipReasons[reasonCode]
-
-	repo := database.NewRepository(b.db)
-	if err := repo.MarkTaskAsSkipped(taskID, reasonCode, reasonText); err != nil {
-		b.SendMessageOrLogError("❌ Ошибка сохранения пропуска")
-		log.Printf("Ошибка MarkTaskAsSkipped: %v", err)
+	_, reason, err := b.services.Task.SkipTask(taskID, parts[1])
+	if err != nil {
+		b.SendError(err, "Ошибка сохранения пропуска")
 		return
 	}
 
-	b.SendMessageOrLogError(fmt.Sprintf("➖ Задача пропущена\n📝 Причина: %s\n\n💡 Эта информация будет учтена в еженедельном анализе.", reasonText))
+	b.SendMessageOrLogError(fmt.Sprintf("➖ Задача пропущена\n📝 Причина: %s\n\n💡 Эта информация будет учтена в еженедельном анализе.", reason.Text))
 }
 
 // handleMissedCompleteTask обрабатывает завершение пропущенной задачи
@@ -343,8 +464,8 @@ func (b *Bot) handleMissedCompleteTask(data string, messageID int) {
 		return
 	}
 
-	if err := database.NewRepository(b.db).UpdateTaskCompletion(taskID, true); err != nil {
-		b.SendMessageOrLogError("❌ Ошибка обновления задачи")
+	if _, err := b.services.Task.CompleteTask(taskID); err != nil {
+		b.SendError(err, "Ошибка обновления задачи")
 		return
 	}
 
@@ -0,0 +1,255 @@
+package telegram_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"five-pillars/internal/database"
+	"five-pillars/internal/services"
+	"five-pillars/internal/telegram"
+	"five-pillars/internal/telegram/fakeapi"
+)
+
+const (
+	testToken  = "TEST:TOKEN"
+	testChatID = 42
+	waitReply  = 5 * time.Second
+)
+
+// startBot запускает бота против фейкового Telegram API на пустой БД
+func startBot(t *testing.T) (*fakeapi.Server, *services.ServiceManager) {
+	t.Helper()
+
+	fake, err := fakeapi.New(testToken, testChatID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := database.New(filepath.Join(t.TempDir(), "bot.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sm := services.NewServiceManager(db)
+
+	bot, err := telegram.NewBot(testToken, testChatID, fake.Endpoint(), sm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sm.SetNotificationSender(bot)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go bot.Start(ctx)
+	t.Cleanup(func() {
+		cancel()
+		fake.Close()
+		db.Close()
+	})
+	return fake, sm
+}
+
+// reply отправляет команду и ждет очередной ответ бота
+func reply(t *testing.T, fake *fakeapi.Server, text string) fakeapi.SentMessage {
+	t.Helper()
+	sent := len(fake.Messages())
+	fake.SendText(text)
+	messages, err := fake.WaitForMessages(sent+1, waitReply)
+	if err != nil {
+		t.Fatalf("%s: %v", text, err)
+	}
+	return messages[len(messages)-1]
+}
+
+func TestAddTaskAndCompleteByButton(t *testing.T) {
+	fake, sm := startBot(t)
+
+	msg := reply(t, fake, "/add body Пробежка 10:00")
+	if !strings.Contains(msg.Text, "Добавлена задача") || msg.ParseMode != "HTML" {
+		t.Fatalf("ответ на /add: %q (%s)", msg.Text, msg.ParseMode)
+	}
+
+	tasks, err := sm.Task.GetTasks(services.Today())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 || tasks[0].Description != "Пробежка 10:00" || tasks[0].Pillar != "body" {
+		t.Fatalf("задачи в БД после /add: %+v", tasks)
+	}
+	id := tasks[0].ID
+
+	// Напоминание приходит с кнопками, «Выполнил» закрывает задачу в БД
+	sent := len(fake.Messages())
+	sm.Notification.CheckAndSendNotifications()
+	messages, err := fake.WaitForMessages(sent+2, waitReply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prompt := messages[len(messages)-1]
+	button := ""
+	for _, data := range prompt.Buttons() {
+		if strings.HasPrefix(data, "complete_") {
+			button = data
+		}
+	}
+	if button == "" {
+		t.Fatalf("нет кнопки выполнения под напоминанием: %v", prompt.Buttons())
+	}
+
+	fake.PressButton(prompt.MessageID, button)
+	deadline := time.Now().Add(waitReply)
+	for {
+		task, err := sm.Task.GetTask(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if task.Status == database.StatusDone {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("после кнопки задача в статусе %s", task.Status)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestFeelingsWithSleepRange(t *testing.T) {
+	fake, sm := startBot(t)
+
+	msg := reply(t, fake, "/feelings энергия=8 контроль=7 сон=23:40-07:10")
+	if !strings.Contains(msg.Text, "Ощущения сохранены") {
+		t.Fatalf("ответ на /feelings: %q", msg.Text)
+	}
+
+	feelings, err := sm.Feelings.Get(services.Today())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feelings.EnergyLevel != 8 || feelings.SleepHours != 7.5 || feelings.Sleep == nil || feelings.Sleep.Bedtime != "23:40" {
+		t.Errorf("ощущения в БД: %+v, сон %+v", feelings, feelings.Sleep)
+	}
+}
+
+func TestAddTaskEscapesHTML(t *testing.T) {
+	fake, _ := startBot(t)
+
+	msg := reply(t, fake, "/add body Q&A <встреча> 10:00")
+	if !strings.Contains(msg.Text, "Q&amp;A &lt;встреча&gt;") {
+		t.Errorf("описание задачи не экранировано: %q", msg.Text)
+	}
+}
+
+func TestOutboxRetriesAfterRateLimit(t *testing.T) {
+	fake, _ := startBot(t)
+
+	// 429 с retry_after: сообщение остается в очереди и уходит после паузы
+	fake.FailNext("sendMessage", 429, 1)
+	msg := reply(t, fake, "/sleep")
+	if !strings.Contains(msg.Text, "сон не записан") {
+		t.Errorf("ответ на /sleep после 429: %q", msg.Text)
+	}
+}
+
+func TestChecklistEditRetriesAfterRateLimit(t *testing.T) {
+	fake, sm := startBot(t)
+
+	task, err := sm.Task.AddTask(services.NewTaskInput{Pillar: "body", Description: "Зал", TimeUTC: "10:00", Date: services.Today()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sm.Task.AddChecklist(task.ID, "разминка; присед"); err != nil {
+		t.Fatal(err)
+	}
+
+	prompt := reply(t, fake, fmt.Sprintf("/check %d", task.ID))
+	button := ""
+	for _, data := range prompt.Buttons() {
+		if strings.HasPrefix(data, "check_") {
+			button = data
+			break
+		}
+	}
+	if button == "" {
+		t.Fatalf("нет кнопок чек-листа: %v", prompt.Buttons())
+	}
+
+	// Правка сообщения идет через очередь: после 429 она повторяется
+	fake.FailNext("editMessageText", 429, 1)
+	fake.PressButton(prompt.MessageID, button)
+
+	deadline := time.Now().Add(waitReply)
+	for {
+		var edited *fakeapi.SentMessage
+		for _, msg := range fake.Messages() {
+			if msg.MessageID == prompt.MessageID && msg.Edited {
+				edited = &msg
+			}
+		}
+		if edited != nil {
+			if !strings.Contains(edited.Text, "1/2") {
+				t.Errorf("текст после отметки пункта: %q", edited.Text)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("сообщение с чек-листом не обновилось")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	var edits int
+	for _, call := range fake.Calls() {
+		if call.Method == "editMessageText" {
+			edits++
+		}
+	}
+	if edits != 2 {
+		t.Errorf("вызовов editMessageText: %d, ожидалось 2 (429 и повтор)", edits)
+	}
+}
+
+func TestPendingValueClearedByText(t *testing.T) {
+	fake, sm := startBot(t)
+
+	task, err := sm.Task.AddTask(services.NewTaskInput{
+		Pillar: "body", Description: "Пробежка", TimeUTC: "10:00", Date: services.Today(),
+		MetricUnit: "км", MetricTarget: 5, MetricAgg: "sum",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sent := len(fake.Messages())
+	fake.PressButton(0, fmt.Sprintf("complete_%d", task.ID))
+	messages, err := fake.WaitForMessages(sent+2, waitReply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if question := messages[len(messages)-1]; !strings.Contains(question.Text, "Сколько км") {
+		t.Fatalf("вопрос о результате: %q", question.Text)
+	}
+
+	msg := reply(t, fake, "отличная погода")
+	if !strings.Contains(msg.Text, "Результат не записан") {
+		t.Fatalf("ответ на текст вместо числа: %q", msg.Text)
+	}
+
+	// Вопрос снят: следующее число - просто текст в чате
+	sent = len(fake.Messages())
+	fake.SendText("7")
+	reply(t, fake, "/metrics")
+	for _, msg := range fake.Messages()[sent:] {
+		if strings.Contains(msg.Text, "✅ Задача") {
+			t.Errorf("число после снятого вопроса записано как результат: %q", msg.Text)
+		}
+	}
+
+	got, err := sm.Task.GetTask(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Value != nil {
+		t.Errorf("результат задачи: %v, ожидалось не записан", *got.Value)
+	}
+}
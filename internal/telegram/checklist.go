@@ -0,0 +1,109 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"five-pillars/internal/database"
+	"five-pillars/internal/services"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// taskPrompt подпись к кнопкам задачи: прогресс чек-листа, если он есть
+func taskPrompt(task database.DailyTask) string {
+	if progress := services.FormatChecklistProgress(task); progress != "" {
+		return progress + "\nВыполнено?"
+	}
+	return "Выполнено?"
+}
+
+// checklistRows кнопки пунктов чек-листа, по пункту в строке
+func checklistRows(items []database.ChecklistItem) [][]tgbotapi.InlineKeyboardButton {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, item := range items {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				shorten(services.ChecklistMark(item)+" "+item.Text, 40),
+				fmt.Sprintf("check_%d", item.ID),
+			),
+		))
+	}
+	return rows
+}
+
+// handleChecklistToggle отмечает пункт чек-листа и обновляет кнопки
+// под напоминанием. Когда отмечены все пункты, задача выполнена.
+func (b *Bot) handleChecklistToggle(data string, messageID int) {
+	itemID, err := strconv.Atoi(strings.TrimPrefix(data, "check_"))
+	if err != nil {
+		b.SendMessageOrLogError("❌ Ошибка обработки запроса")
+		return
+	}
+
+	task, err := b.services.Task.ToggleChecklistItem(itemID)
+	if err != nil {
+		b.SendError(err, "Ошибка отметки пункта чек-листа")
+		return
+	}
+
+	if task.Status == database.StatusDone {
+		text := fmt.Sprintf("✅ Чек-лист пройден, задача #%d выполнена!", task.ID)
+		if err := b.enqueueEdit(messageID, text, nil); err != nil {
+			b.SendError(err, "Ошибка обновления чек-листа")
+		}
+		return
+	}
+
+	keyboard := b.createTaskKeyboard(task.ID, task.Checklist)
+	if err := b.enqueueEdit(messageID, taskPrompt(*task), &keyboard); err != nil {
+		b.SendError(err, "Ошибка обновления чек-листа")
+	}
+}
+
+// handleChecklist чек-лист задачи: /check [id] показывает пункты кнопками,
+// /check [id] пункт; пункт - добавляет пункты
+func (b *Bot) handleChecklist(msg *tgbotapi.Message) {
+	args := strings.SplitN(strings.TrimSpace(strings.TrimPrefix(msg.Text, "/check")), " ", 2)
+	if args[0] == "" {
+		b.SendMessageOrLogError("❌ Формат: /check [id] - показать чек-лист\n" +
+			"/check [id] пункт; пункт - добавить пункты")
+		return
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		b.SendMessageOrLogError("❌ id должен быть числовой")
+		return
+	}
+
+	var task *database.DailyTask
+	if len(args) > 1 && strings.TrimSpace(args[1]) != "" {
+		task, err = b.services.Task.AddChecklist(id, args[1])
+	} else {
+		task, err = b.services.Task.GetTask(id)
+	}
+	if err != nil {
+		b.SendError(err, "Ошибка изменения чек-листа")
+		return
+	}
+
+	if len(task.Checklist) == 0 {
+		b.SendMessageOrLogError(fmt.Sprintf("📋 У задачи #%d нет чек-листа. Добавить: /check %d пункт; пункт", task.ID, task.ID))
+		return
+	}
+
+	title := fmt.Sprintf("📋 <b>#%d %s</b>\n\n", task.ID, html.EscapeString(task.Description))
+	if !task.Status.Open() {
+		// Закрытую задачу уже не отметить, пункты показываются текстом
+		b.SendMessageOrLogError(title + "Задача " + services.StatusNames[task.Status] + "\n" +
+			html.EscapeString(services.FormatChecklist(task.Checklist)))
+		return
+	}
+	text := title + taskPrompt(*task)
+	if err := b.enqueueWithKeyboard(text, b.createTaskKeyboard(task.ID, task.Checklist)); err != nil {
+		b.SendError(err, "Ошибка отправки чек-листа")
+	}
+}
@@ -0,0 +1,605 @@
+// Package fakeapi - локальная подмена Telegram Bot API для сквозных тестов.
+//
+// Сервер поднимается в том же процессе на 127.0.0.1, понимает методы,
+// которые использует бот (getMe, getUpdates, sendMessage, answerCallbackQuery,
+// deleteMessage, editMessageText, sendPhoto, sendDocument, getFile), запоминает всё,
+// что отправил бот, и позволяет "от имени пользователя" писать команды и
+// нажимать кнопки.
+package fakeapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// SentMessage сообщение, отправленное ботом в чат
+type SentMessage struct {
+	MessageID   int
+	Method      string
+	ChatID      int64
+	Text        string
+	ParseMode   string
+	ReplyMarkup *tgbotapi.InlineKeyboardMarkup
+	FileName    string
+	FileData    []byte
+	Deleted     bool
+	Edited      bool
+}
+
+// Buttons возвращает callback_data всех inline-кнопок сообщения
+func (m SentMessage) Buttons() []string {
+	var data []string
+	if m.ReplyMarkup == nil {
+		return data
+	}
+	for _, row := range m.ReplyMarkup.InlineKeyboard {
+		for _, button := range row {
+			if button.CallbackData != nil {
+				data = append(data, *button.CallbackData)
+			}
+		}
+	}
+	return data
+}
+
+// Call запись о вызове метода API
+type Call struct {
+	Method string
+	Params map[string]string
+}
+
+type Server struct {
+	mu       sync.Mutex
+	token    string
+	chatID   int64
+	botUser  tgbotapi.User
+	user     tgbotapi.User
+	listener net.Listener
+	server   *http.Server
+
+	updates       []tgbotapi.Update
+	nextUpdateID  int
+	nextMessageID int
+	newUpdate     chan struct{}
+
+	messages []*SentMessage
+	calls    []Call
+	changed  chan struct{}
+
+	files map[string][]byte
+
+	// failures ошибки, которые вернут следующие вызовы метода
+	failures map[string][]failure
+}
+
+type failure struct {
+	code       int
+	retryAfter int
+}
+
+// New запускает фейковый API для указанного токена и чата
+func New(token string, chatID int64) (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запуска фейкового API: %v", err)
+	}
+
+	s := &Server{
+		token:         token,
+		chatID:        chatID,
+		botUser:       tgbotapi.User{ID: 1, IsBot: true, FirstName: "Five Pillars", UserName: "five_pillars_test_bot"},
+		user:          tgbotapi.User{ID: chatID, FirstName: "Tester", UserName: "tester"},
+		listener:      listener,
+		nextUpdateID:  1,
+		nextMessageID: 1,
+		newUpdate:     make(chan struct{}),
+		changed:       make(chan struct{}),
+		files:         make(map[string][]byte),
+		failures:      make(map[string][]failure),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/bot"+token+"/", s.handleMethod)
+	mux.HandleFunc("/file/bot"+token+"/", s.handleFile)
+	s.server = &http.Server{Handler: mux}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Printf("fake telegram api: %v\n", err)
+		}
+	}()
+
+	return s, nil
+}
+
+// Endpoint возвращает шаблон адреса API в формате tgbotapi.APIEndpoint
+func (s *Server) Endpoint() string {
+	return "http://" + s.listener.Addr().String() + "/bot%s/%s"
+}
+
+func (s *Server) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
+
+// FailNext заставляет следующий вызов method вернуть ошибку с кодом code.
+// retryAfter > 0 добавляет parameters.retry_after, как при 429 от Telegram.
+func (s *Server) FailNext(method string, code int, retryAfter int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[method] = append(s.failures[method], failure{code: code, retryAfter: retryAfter})
+}
+
+// SendText имитирует текстовое сообщение пользователя и возвращает его ID
+func (s *Server) SendText(text string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msg := s.userMessage(text)
+	if strings.HasPrefix(text, "/") {
+		command := strings.Fields(text)[0]
+		msg.Entities = []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(command)}}
+	}
+
+	s.pushUpdate(tgbotapi.Update{Message: msg})
+	return msg.MessageID
+}
+
+// SendDocument имитирует загрузку файла пользователем и возвращает ID сообщения
+func (s *Server) SendDocument(name string, data []byte, caption string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msg := s.userMessage("")
+	msg.Caption = caption
+	fileID := fmt.Sprintf("user-file-%d", msg.MessageID)
+	s.files[fileID] = data
+	msg.Document = &tgbotapi.Document{
+		FileID:       fileID,
+		FileUniqueID: fileID,
+		FileName:     name,
+		FileSize:     len(data),
+	}
+
+	s.pushUpdate(tgbotapi.Update{Message: msg})
+	return msg.MessageID
+}
+
+// SendPhoto имитирует фото от пользователя и возвращает ID сообщения
+func (s *Server) SendPhoto(data []byte, caption string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msg := s.userMessage("")
+	msg.Caption = caption
+	fileID := fmt.Sprintf("user-photo-%d", msg.MessageID)
+	s.files[fileID] = data
+	msg.Photo = []tgbotapi.PhotoSize{
+		{FileID: fileID + "-small", FileUniqueID: fileID + "-small", Width: 90, Height: 90},
+		{FileID: fileID, FileUniqueID: fileID, Width: 1280, Height: 1280, FileSize: len(data)},
+	}
+
+	s.pushUpdate(tgbotapi.Update{Message: msg})
+	return msg.MessageID
+}
+
+// PressButton имитирует нажатие inline-кнопки под сообщением бота
+func (s *Server) PressButton(messageID int, data string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	message := &tgbotapi.Message{
+		MessageID: messageID,
+		From:      &s.botUser,
+		Date:      int(time.Now().Unix()),
+		Chat:      s.chat(),
+	}
+	if sent := s.findMessage(messageID); sent != nil {
+		message.Text = sent.Text
+		message.ReplyMarkup = sent.ReplyMarkup
+	}
+
+	s.pushUpdate(tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+		ID:      strconv.Itoa(s.nextUpdateID),
+		From:    &s.user,
+		Message: message,
+		Data:    data,
+	}})
+}
+
+// Messages возвращает копию всех сообщений, отправленных ботом
+func (s *Server) Messages() []SentMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]SentMessage, 0, len(s.messages))
+	for _, m := range s.messages {
+		result = append(result, *m)
+	}
+	return result
+}
+
+// Calls возвращает копию журнала вызовов API
+func (s *Server) Calls() []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Call(nil), s.calls...)
+}
+
+// WaitForMessages ждёт, пока бот отправит не меньше n сообщений
+func (s *Server) WaitForMessages(n int, timeout time.Duration) ([]SentMessage, error) {
+	deadline := time.After(timeout)
+	for {
+		s.mu.Lock()
+		count := len(s.messages)
+		changed := s.changed
+		s.mu.Unlock()
+
+		if count >= n {
+			return s.Messages(), nil
+		}
+
+		select {
+		case <-changed:
+		case <-deadline:
+			return s.Messages(), fmt.Errorf("ожидалось %d сообщений, получено %d", n, count)
+		}
+	}
+}
+
+// LastMessage возвращает последнее сообщение бота
+func (s *Server) LastMessage() (SentMessage, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.messages) == 0 {
+		return SentMessage{}, false
+	}
+	return *s.messages[len(s.messages)-1], true
+}
+
+func (s *Server) chat() *tgbotapi.Chat {
+	return &tgbotapi.Chat{ID: s.chatID, Type: "private", UserName: s.user.UserName}
+}
+
+func (s *Server) userMessage(text string) *tgbotapi.Message {
+	id := s.nextMessageID
+	s.nextMessageID++
+	return &tgbotapi.Message{
+		MessageID: id,
+		From:      &s.user,
+		Date:      int(time.Now().Unix()),
+		Chat:      s.chat(),
+		Text:      text,
+	}
+}
+
+func (s *Server) pushUpdate(update tgbotapi.Update) {
+	update.UpdateID = s.nextUpdateID
+	s.nextUpdateID++
+	s.updates = append(s.updates, update)
+
+	close(s.newUpdate)
+	s.newUpdate = make(chan struct{})
+}
+
+func (s *Server) notifyChanged() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+func (s *Server) findMessage(messageID int) *SentMessage {
+	for _, m := range s.messages {
+		if m.MessageID == messageID {
+			return m
+		}
+	}
+	return nil
+}
+
+func (s *Server) handleMethod(w http.ResponseWriter, r *http.Request) {
+	method := strings.TrimPrefix(r.URL.Path, "/bot"+s.token+"/")
+
+	params, files, err := readParams(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	s.calls = append(s.calls, Call{Method: method, Params: params})
+	var fail *failure
+	if queued := s.failures[method]; len(queued) > 0 {
+		fail, s.failures[method] = &queued[0], queued[1:]
+	}
+	s.mu.Unlock()
+
+	if fail != nil {
+		writeFailure(w, *fail)
+		return
+	}
+
+	switch method {
+	case "getMe":
+		writeResult(w, s.botUser)
+	case "getUpdates":
+		s.handleGetUpdates(w, r, params)
+	case "sendMessage":
+		s.handleSend(w, method, params, nil)
+	case "sendPhoto":
+		photo := files["photo"]
+		if photo == nil && params["photo"] != "" {
+			// Фото, уже загруженное в Telegram, передается по file_id
+			photo = &uploadedFile{name: params["photo"], data: s.files[params["photo"]]}
+		}
+		s.handleSend(w, method, params, photo)
+	case "sendDocument":
+		s.handleSend(w, method, params, files["document"])
+	case "editMessageText":
+		s.handleEdit(w, params)
+	case "deleteMessage":
+		s.handleDelete(w, params)
+	case "answerCallbackQuery":
+		writeResult(w, true)
+	case "getFile":
+		s.handleGetFile(w, params)
+	default:
+		writeError(w, http.StatusNotFound, "Not Found: method "+method+" is not supported by fake API")
+	}
+}
+
+func (s *Server) handleGetUpdates(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	offset, _ := strconv.Atoi(params["offset"])
+	timeout, _ := strconv.Atoi(params["timeout"])
+	deadline := time.After(time.Duration(timeout) * time.Second)
+
+	for {
+		s.mu.Lock()
+		var pending []tgbotapi.Update
+		for _, update := range s.updates {
+			if update.UpdateID >= offset {
+				pending = append(pending, update)
+			}
+		}
+		wait := s.newUpdate
+		s.mu.Unlock()
+
+		if len(pending) > 0 || timeout == 0 {
+			if pending == nil {
+				pending = []tgbotapi.Update{}
+			}
+			writeResult(w, pending)
+			return
+		}
+
+		select {
+		case <-wait:
+		case <-deadline:
+			writeResult(w, []tgbotapi.Update{})
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func (s *Server) handleSend(w http.ResponseWriter, method string, params map[string]string, file *uploadedFile) {
+	chatID, err := strconv.ParseInt(params["chat_id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Bad Request: chat_id is empty")
+		return
+	}
+
+	s.mu.Lock()
+	sent := &SentMessage{
+		MessageID:   s.nextMessageID,
+		Method:      method,
+		ChatID:      chatID,
+		Text:        params["text"],
+		ParseMode:   params["parse_mode"],
+		ReplyMarkup: parseMarkup(params["reply_markup"]),
+	}
+	s.nextMessageID++
+	if file != nil {
+		sent.FileName = file.name
+		sent.FileData = file.data
+		sent.Text = params["caption"]
+	}
+	s.messages = append(s.messages, sent)
+	result := s.toMessage(sent)
+	s.notifyChanged()
+	s.mu.Unlock()
+
+	writeResult(w, result)
+}
+
+func (s *Server) handleEdit(w http.ResponseWriter, params map[string]string) {
+	messageID, _ := strconv.Atoi(params["message_id"])
+
+	s.mu.Lock()
+	sent := s.findMessage(messageID)
+	if sent == nil {
+		s.mu.Unlock()
+		writeError(w, http.StatusBadRequest, "Bad Request: message to edit not found")
+		return
+	}
+	sent.Text = params["text"]
+	sent.ParseMode = params["parse_mode"]
+	sent.ReplyMarkup = parseMarkup(params["reply_markup"])
+	sent.Edited = true
+	result := s.toMessage(sent)
+	s.notifyChanged()
+	s.mu.Unlock()
+
+	writeResult(w, result)
+}
+
+func (s *Server) handleDelete(w http.ResponseWriter, params map[string]string) {
+	messageID, _ := strconv.Atoi(params["message_id"])
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sent := s.findMessage(messageID)
+	if sent == nil {
+		writeError(w, http.StatusBadRequest, "Bad Request: message to delete not found")
+		return
+	}
+	sent.Deleted = true
+	s.notifyChanged()
+
+	writeResult(w, true)
+}
+
+func (s *Server) handleGetFile(w http.ResponseWriter, params map[string]string) {
+	fileID := params["file_id"]
+
+	s.mu.Lock()
+	data, ok := s.files[fileID]
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Bad Request: invalid file_id")
+		return
+	}
+
+	writeResult(w, tgbotapi.File{
+		FileID:       fileID,
+		FileUniqueID: fileID,
+		FileSize:     len(data),
+		FilePath:     "documents/" + fileID,
+	})
+}
+
+// handleFile отдает содержимое файла по пути из getFile
+func (s *Server) handleFile(w http.ResponseWriter, r *http.Request) {
+	fileID := strings.TrimPrefix(r.URL.Path, "/file/bot"+s.token+"/documents/")
+
+	s.mu.Lock()
+	data, ok := s.files[fileID]
+	s.mu.Unlock()
+
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Write(data)
+}
+
+func (s *Server) toMessage(sent *SentMessage) tgbotapi.Message {
+	message := tgbotapi.Message{
+		MessageID:   sent.MessageID,
+		From:        &s.botUser,
+		Date:        int(time.Now().Unix()),
+		Chat:        &tgbotapi.Chat{ID: sent.ChatID, Type: "private"},
+		Text:        sent.Text,
+		ReplyMarkup: sent.ReplyMarkup,
+	}
+	if sent.FileName != "" {
+		message.Document = &tgbotapi.Document{
+			FileID:   fmt.Sprintf("file-%d", sent.MessageID),
+			FileName: sent.FileName,
+			FileSize: len(sent.FileData),
+		}
+	}
+	return message
+}
+
+type uploadedFile struct {
+	name string
+	data []byte
+}
+
+// readParams разбирает параметры запроса: form-urlencoded или multipart
+func readParams(r *http.Request) (map[string]string, map[string]*uploadedFile, error) {
+	params := make(map[string]string)
+	files := make(map[string]*uploadedFile)
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			return nil, nil, err
+		}
+		for key, values := range r.MultipartForm.Value {
+			if len(values) > 0 {
+				params[key] = values[0]
+			}
+		}
+		for key, headers := range r.MultipartForm.File {
+			if len(headers) == 0 {
+				continue
+			}
+			f, err := headers[0].Open()
+			if err != nil {
+				return nil, nil, err
+			}
+			data, err := io.ReadAll(f)
+			f.Close()
+			if err != nil {
+				return nil, nil, err
+			}
+			files[key] = &uploadedFile{name: headers[0].Filename, data: data}
+		}
+		return params, files, nil
+	}
+
+	if err := r.ParseForm(); err != nil {
+		return nil, nil, err
+	}
+	for key, values := range r.PostForm {
+		if len(values) > 0 {
+			params[key] = values[0]
+		}
+	}
+	return params, files, nil
+}
+
+func parseMarkup(raw string) *tgbotapi.InlineKeyboardMarkup {
+	if raw == "" {
+		return nil
+	}
+	var markup tgbotapi.InlineKeyboardMarkup
+	if err := json.Unmarshal([]byte(raw), &markup); err != nil || len(markup.InlineKeyboard) == 0 {
+		return nil
+	}
+	return &markup
+}
+
+func writeResult(w http.ResponseWriter, result interface{}) {
+	raw, err := json.Marshal(result)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tgbotapi.APIResponse{Ok: true, Result: raw})
+}
+
+func writeError(w http.ResponseWriter, code int, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(tgbotapi.APIResponse{Ok: false, ErrorCode: code, Description: description})
+}
+
+func writeFailure(w http.ResponseWriter, fail failure) {
+	response := tgbotapi.APIResponse{Ok: false, ErrorCode: fail.code, Description: http.StatusText(fail.code)}
+	if fail.retryAfter > 0 {
+		response.Description = fmt.Sprintf("Too Many Requests: retry after %d", fail.retryAfter)
+		response.Parameters = &tgbotapi.ResponseParameters{RetryAfter: fail.retryAfter}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(fail.code)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,180 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"five-pillars/internal/database"
+	"five-pillars/internal/services"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const goalUsage = "❌ Формат:\n" +
+	"/goal add [столп] [срок YYYY-MM-DD] [цель] [описание] - цель 500км или 50 (задач)\n" +
+	"/goal [id] - прогресс цели\n" +
+	"/goal link [id] template|task [id] - привязать шаблон или задачу\n" +
+	"/goal unlink template|task [id] - отвязать\n" +
+	"/goal archive|restore [id] - убрать в архив или вернуть"
+
+// handleGoals /goals [all] - прогресс по целям, all - вместе с архивными
+func (b *Bot) handleGoals(msg *tgbotapi.Message) {
+	args := strings.Fields(msg.Text)[1:]
+	archived := len(args) > 0 && args[0] == "all"
+
+	goals, err := b.services.Goals.GetGoals(archived)
+	if err != nil {
+		b.SendError(err, "Ошибка получения целей")
+		return
+	}
+
+	if len(goals) == 0 {
+		b.SendMessageOrLogError("🎯 Целей пока нет. Добавить: /goal add body 2026-12-31 500км Пробежать за год")
+		return
+	}
+	b.SendMessageOrLogError("🎯 <b>Цели</b>\n\n" + html.EscapeString(services.FormatGoals(b.services.Registry(), goals, time.Now())))
+}
+
+// handleGoal управление целью, форматы в goalUsage
+func (b *Bot) handleGoal(msg *tgbotapi.Message) {
+	args := strings.Fields(msg.Text)[1:]
+	if len(args) == 0 {
+		b.SendMessageOrLogError(goalUsage)
+		return
+	}
+
+	switch args[0] {
+	case "add":
+		b.handleGoalAdd(args[1:])
+	case "link", "unlink":
+		b.handleGoalLink(args)
+	case "archive", "restore":
+		if len(args) != 2 {
+			b.SendMessageOrLogError(goalUsage)
+			return
+		}
+		id, err := strconv.Atoi(args[1])
+		if err != nil {
+			b.SendMessageOrLogError("❌ id должен быть числовой")
+			return
+		}
+		if err := b.services.Goals.Archive(id, args[0] == "archive"); err != nil {
+			b.SendError(err, "Ошибка изменения цели")
+			return
+		}
+		if args[0] == "archive" {
+			b.SendMessageOrLogError(fmt.Sprintf("📦 Цель #%d в архиве", id))
+		} else {
+			b.SendMessageOrLogError(fmt.Sprintf("✅ Цель #%d снова активна", id))
+		}
+	default:
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			b.SendMessageOrLogError(goalUsage)
+			return
+		}
+		goal, err := b.services.Goals.GetGoal(id)
+		if err != nil {
+			b.SendError(err, "Ошибка получения цели")
+			return
+		}
+		b.sendGoal(goal)
+	}
+}
+
+func (b *Bot) handleGoalAdd(args []string) {
+	if len(args) < 4 {
+		b.SendMessageOrLogError(goalUsage)
+		return
+	}
+
+	target, unit, err := services.ParseGoalTarget(args[2])
+	if err != nil {
+		b.SendError(err, "Ошибка добавления цели")
+		return
+	}
+
+	goal, err := b.services.Goals.AddGoal(services.NewGoalInput{
+		Pillar:      args[0],
+		Deadline:    args[1],
+		Target:      target,
+		Unit:        unit,
+		Description: strings.Join(args[3:], " "),
+	})
+	if err != nil {
+		b.SendError(err, "Ошибка добавления цели")
+		return
+	}
+
+	b.SendMessageOrLogError(fmt.Sprintf("✅ Цель #%d добавлена\n\n%s\nПривяжите шаблон: /goal link %d template [id]",
+		goal.ID, html.EscapeString(services.FormatGoal(b.services.Registry(), *goal, time.Now())), goal.ID))
+}
+
+// handleGoalLink /goal link [id] template|task [id], /goal unlink template|task [id]
+func (b *Bot) handleGoalLink(args []string) {
+	goalID := 0
+	if args[0] == "link" {
+		if len(args) != 4 {
+			b.SendMessageOrLogError(goalUsage)
+			return
+		}
+		id, err := strconv.Atoi(args[1])
+		if err != nil {
+			b.SendMessageOrLogError("❌ id должен быть числовой")
+			return
+		}
+		goalID = id
+		args = args[1:]
+	}
+	if len(args) != 3 || (args[1] != "template" && args[1] != "task") {
+		b.SendMessageOrLogError(goalUsage)
+		return
+	}
+	id, err := strconv.Atoi(args[2])
+	if err != nil {
+		b.SendMessageOrLogError("❌ id должен быть числовой")
+		return
+	}
+
+	if goalID == 0 {
+		if args[1] == "template" {
+			err = b.services.Goals.UnlinkTemplate(id)
+		} else {
+			err = b.services.Goals.UnlinkTask(id)
+		}
+		if err != nil {
+			b.SendError(err, "Ошибка отвязки от цели")
+			return
+		}
+		b.SendMessageOrLogError("✅ Отвязано от цели")
+		return
+	}
+
+	var goal *database.Goal
+	if args[1] == "template" {
+		goal, err = b.services.Goals.LinkTemplate(goalID, id)
+	} else {
+		goal, err = b.services.Goals.LinkTask(goalID, id)
+	}
+	if err != nil {
+		b.SendError(err, "Ошибка привязки к цели")
+		return
+	}
+	b.sendGoal(goal)
+}
+
+func (b *Bot) sendGoal(goal *database.Goal) {
+	text := html.EscapeString(strings.TrimRight(services.FormatGoal(b.services.Registry(), *goal, time.Now()), "\n"))
+	text += fmt.Sprintf("\n🗓 %s - %s, выполнено задач: %d из %d", goal.StartDate, goal.Deadline, goal.Progress.Done, goal.Progress.Tasks)
+	if len(goal.Templates) > 0 {
+		ids := make([]string, len(goal.Templates))
+		for i, id := range goal.Templates {
+			ids[i] = "#" + strconv.Itoa(id)
+		}
+		text += "\n🔗 Шаблоны: " + strings.Join(ids, ", ")
+	}
+	b.SendMessageOrLogError(text)
+}
@@ -1,14 +1,22 @@
 package telegram
 
 import (
+	"errors"
 	"five-pillars/internal/utils"
 	"fmt"
-	"regexp"
+	"html"
+	"log"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
+	"five-pillars/internal/backup"
 	"five-pillars/internal/database"
+	"five-pillars/internal/export"
+	"five-pillars/internal/ical"
+	"five-pillars/internal/importer"
+	"five-pillars/internal/services"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -27,6 +35,10 @@ func (b *Bot) handleStart(msg *tgbotapi.Message) {
 /time - изменить время выполнения задачи
 /date - изменить время выполнения задачи
 /feelings - Оценить свои ощущения
+/sleep - Журнал сна
+/journal - Дневник дня
+/export - Выгрузить историю
+/dnd - Не беспокоить
 /help - Помощь
 
 Пример:
@@ -37,11 +49,9 @@ func (b *Bot) handleStart(msg *tgbotapi.Message) {
 }
 
 func (b *Bot) handleToday(msg *tgbotapi.Message) {
-	today := time.Now().UTC().Format("2006-01-02")
-	repo := database.NewRepository(b.db)
-	tasks, err := repo.GetTasksByDate(today)
+	tasks, err := b.services.Task.GetTasks(services.Today())
 	if err != nil {
-		b.SendMessageOrLogError("❌ Ошибка получения задач")
+		b.SendError(err, "Ошибка получения задач")
 		return
 	}
 
@@ -55,52 +65,60 @@ func (b *Bot) handleToday(msg *tgbotapi.Message) {
 	message.WriteString(utils.GetTimezoneInfo() + "\n\n")
 
 	for _, task := range tasks {
-		pillarName := utils.GetPillarName(string(task.Pillar))
+		pillarName := b.services.Registry().PillarName(task.Pillar)
 
 		displayTime := utils.FormatTimeForDisplay(task.TimeUTC)
 
-		var status string
-		if task.Completed {
-			status = "✅"
-		} else if task.Skipped {
-			status = "➖"
-		} else {
-			taskTime, _ := time.Parse("15:04", task.TimeUTC)
-			currentUTC := time.Now().UTC()
-			taskUTC := time.Date(currentUTC.Year(), currentUTC.Month(), currentUTC.Day(),
-				taskTime.Hour(), taskTime.Minute(), 0, 0, time.UTC)
-
-			status = "⬜"
-			if currentUTC.After(taskUTC) {
-				status = "⏰"
-			}
-		}
+		status := services.StatusMark(task, time.Now())
 
 		message.WriteString(fmt.Sprintf(
 			"%s <b>%s</b>\n"+
 				"⏰ %s\n"+
+				"%s"+
 				"<i>%s</i>\n\n",
-			status, pillarName,
-			displayTime, task.Description,
+			status, html.EscapeString(pillarName),
+			displayTime, formatStart(task), html.EscapeString(task.Description),
 		))
 
-		if task.Skipped && task.Notes != "" && strings.Contains(task.Notes, "Пропущено:") {
-			parts := strings.SplitN(task.Notes, "|", 2)
-			if len(parts) > 1 {
-				message.WriteString(fmt.Sprintf("📝 <i>%s</i>\n\n", strings.TrimSpace(parts[1])))
-			}
+		if reason := services.SkipReasonText(task); reason != "" {
+			message.WriteString(fmt.Sprintf("📝 <i>%s</i>\n\n", html.EscapeString(reason)))
+		}
+		if task.CarriedFrom != 0 {
+			message.WriteString(fmt.Sprintf("↩️ <i>Перенесено с #%d</i>\n\n", task.CarriedFrom))
+		}
+		if tags := services.FormatTags(task.Tags); tags != "" {
+			message.WriteString(fmt.Sprintf("🏷 %s\n\n", html.EscapeString(tags)))
+		}
+		if progress := services.FormatChecklistProgress(task); progress != "" {
+			message.WriteString(fmt.Sprintf("%s · /check %d\n\n", progress, task.ID))
+		}
+		if value := services.FormatTaskValue(task); value != "" {
+			message.WriteString(fmt.Sprintf("%s · /value %d\n\n", html.EscapeString(value), task.ID))
 		}
 	}
 
 	b.SendMessageOrLogError(message.String())
 }
 
+// formatStart строка о времени начала и напоминаниях заранее, если они заданы
+func formatStart(task database.DailyTask) string {
+	var parts []string
+	if task.StartUTC != "" && task.StartUTC != task.TimeUTC {
+		parts = append(parts, "🏁 Начало: "+utils.FormatTimeForDisplay(task.StartUTC))
+	}
+	if task.LeadMinutes != "" {
+		parts = append(parts, fmt.Sprintf("⏳ за %s мин", strings.ReplaceAll(task.LeadMinutes, ",", ", ")))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return strings.Join(parts, " · ") + "\n"
+}
+
 func (b *Bot) handleSummary(msg *tgbotapi.Message) {
-	today := time.Now().UTC().Format("2006-01-02")
-	repo := database.NewRepository(b.db)
-	summary, err := repo.GetDailySummary(today)
+	summary, err := b.services.Analytics.GetDailySummary(services.Today())
 	if err != nil {
-		b.SendMessageOrLogError("❌ Ошибка получения сводки")
+		b.SendError(err, "Ошибка получения сводки")
 		return
 	}
 
@@ -111,57 +129,32 @@ func (b *Bot) handleSummary(msg *tgbotapi.Message) {
 			"<b>По столпам:</b>\n",
 		utils.GetCurrentMSKDate(),
 		utils.GetTimezoneInfo(),
-		summary["completed"].(int),
-		summary["total"].(int),
-		summary["percentage"].(float64),
+		summary.Completed,
+		summary.Total,
+		summary.Percentage,
 	)
 
-	if stats, ok := summary["pillar_stats"].(map[string]int); ok {
-		for pillar, count := range stats {
-			emoji := utils.GetPillarEmoji(pillar)
-			pillarName := utils.GetPillarName(pillar)
-			message += fmt.Sprintf("%s %s: %d\n", emoji, pillarName, count)
-		}
+	for _, pillar := range database.SortedPillars(b.services.Registry(), summary.PillarStats) {
+		message += fmt.Sprintf("%s: %d\n", html.EscapeString(b.services.Registry().PillarName(database.Pillar(pillar))), summary.PillarStats[pillar])
 	}
 
-	feelings, err := repo.GetFeelings(today)
-	if err == nil {
-		message += fmt.Sprintf(
-			"\n<b>Ощущения:</b>\n"+
-				"⚡ Энергия: %d/10\n"+
-				"🎯 Контроль: %d/10\n",
-			feelings.EnergyLevel,
-			feelings.ControlLevel,
-		)
-		if feelings.SleepHours > 0 {
-			message += fmt.Sprintf("😴 Сон: %.1f ч\n", feelings.SleepHours)
-		}
-		if feelings.Mood != "" {
-			message += fmt.Sprintf("😊 Настроение: %s\n", feelings.Mood)
-		}
+	if feelings := summary.Feelings; feelings != nil {
+		message += "\n<b>Ощущения:</b>\n" + b.formatFeelings(*feelings, "\n") + "\n"
 	}
 
 	b.SendMessageOrLogError(message)
 }
 
 func (b *Bot) handleAll(msg *tgbotapi.Message) {
-	today := time.Now().UTC().Format("2006-01-02")
-	repo := database.NewRepository(b.db)
-	all, err := repo.GetTasksByDate(today)
+	all, err := b.services.Task.GetTasks(services.Today())
 	if err != nil {
-		b.SendMessageOrLogError("❌ Ошибка получения сводки")
+		b.SendError(err, "Ошибка получения сводки")
 		return
 	}
 
 	message := "📅 <b>Сегодня:</b>\n\n"
 	for _, t := range all {
-		status := "❌"
-		if t.Completed {
-			status = "✅"
-		} else if t.Skipped {
-			status = "💤"
-		}
-		message += fmt.Sprintf("id: %d, %s %s\n", t.ID, t.Description, status)
+		message += fmt.Sprintf("id: %d, %s %s\n", t.ID, html.EscapeString(t.Description), services.StatusMark(t, time.Now()))
 	}
 	b.SendMessageOrLogError(message)
 }
@@ -177,23 +170,59 @@ func (b *Bot) handleChangeTime(msg *tgbotapi.Message) {
 	id, err := strconv.Atoi(parts[0])
 	if err != nil {
 		b.SendMessageOrLogError("❌ id должен быть числовой")
-	}
-	time2do := parts[1]
-
-	re := regexp.MustCompile(`^([01][0-9]|2[0-3]):([0-5][0-9])$`)
-	if !re.MatchString(time2do) {
-		b.SendMessageOrLogError("❌ Время в HH:mm в UTC")
 		return
 	}
 
-	repo := database.NewRepository(b.db)
-	if err := repo.UpdateTaskTime(id, time2do); err != nil {
-		b.SendMessageOrLogError("❌ Ошибка изменения времени задачи")
+	task, err := b.services.Task.ChangeTime(id, strings.TrimSpace(parts[1]))
+	if err != nil {
+		b.SendError(err, "Ошибка изменения времени задачи")
 		return
 	}
 	b.SendMessageOrLogError(fmt.Sprintf(
 		"✅ Время задачи id: %v обновлено на ⏰ %s UTC",
-		id, time2do))
+		task.ID, task.TimeUTC))
+}
+
+// handleLead время начала и напоминания заранее:
+// /lead [id] [начало UTC] [минуты], /lead [id] [минуты], /lead [id] off
+func (b *Bot) handleLead(msg *tgbotapi.Message) {
+	args := strings.Fields(msg.Text)[1:]
+	if len(args) < 2 {
+		b.SendMessageOrLogError("❌ Формат: /lead [id] [начало в UTC] [за сколько минут, например 30,5]\n" +
+			"Без начала напоминания считаются от времени задачи, /lead [id] off - выключить")
+		return
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		b.SendMessageOrLogError("❌ id должен быть числовой")
+		return
+	}
+
+	var start, leads string
+	switch {
+	case strings.EqualFold(args[1], "off"):
+	case strings.Contains(args[1], ":"):
+		start = args[1]
+		if len(args) > 2 {
+			leads = args[2]
+		}
+	default:
+		leads = args[1]
+	}
+
+	task, err := b.services.Task.SetStart(id, start, leads)
+	if err != nil {
+		b.SendError(err, "Ошибка изменения напоминаний задачи")
+		return
+	}
+
+	if task.StartUTC == "" && task.LeadMinutes == "" {
+		b.SendMessageOrLogError(fmt.Sprintf("✅ Напоминания заранее для задачи #%d выключены", task.ID))
+		return
+	}
+	b.SendMessageOrLogError(fmt.Sprintf("✅ Задача #%d: %s\n⏰ %s\n%s",
+		task.ID, html.EscapeString(task.Description), utils.FormatTimeForDisplay(task.TimeUTC), formatStart(*task)))
 }
 
 func (b *Bot) handleChangeDate(msg *tgbotapi.Message) {
@@ -207,131 +236,52 @@ func (b *Bot) handleChangeDate(msg *tgbotapi.Message) {
 	id, err := strconv.Atoi(parts[0])
 	if err != nil {
 		b.SendMessageOrLogError("❌ id должен быть числовой")
-	}
-	date2do := parts[1]
-
-	re := regexp.MustCompile(`^\d{4}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01])$`)
-	if !re.MatchString(date2do) {
-		b.SendMessageOrLogError("❌ Время должно быть в YYYY-MM-DD")
 		return
 	}
 
-	repo := database.NewRepository(b.db)
-	if err := repo.UpdateTaskDate(id, date2do); err != nil {
-		b.SendMessageOrLogError("❌ Ошибка изменения даты задачи")
+	task, err := b.services.Task.ChangeDate(id, strings.TrimSpace(parts[1]))
+	if err != nil {
+		b.SendError(err, "Ошибка изменения даты задачи")
 		return
 	}
 	b.SendMessageOrLogError(fmt.Sprintf(
-		"✅ Дата задачи #%v обновлена. 📅 %s ", id, date2do))
+		"✅ Дата задачи #%v обновлена. 📅 %s ", task.ID, task.Date))
 }
 
 func (b *Bot) handleWeek(msg *tgbotapi.Message) {
 	analytics, err := b.services.Analytics.GetWeeklyAnalytics()
 	if err != nil {
-		b.SendMessageOrLogError("❌ Ошибка получения сводки за неделю")
+		b.SendError(err, "Ошибка получения сводки за неделю")
 		return
 	}
 
-	message := fmt.Sprintf(
-		"📈 <b>Аналитика за неделю %d</b>\n\n"+
-			"📅 %s - %s\n\n"+
-			"✅ Выполнено: %d/%d (%.0f%%)\n\n"+
-			"<b>Эффективность по столпам:</b>\n",
-		analytics.WeekNumber,
-		analytics.StartDate,
-		analytics.EndDate,
-		analytics.TotalDone,
-		analytics.TotalTasks,
-		float64(analytics.TotalDone)/float64(analytics.TotalTasks)*100,
-	)
-
-	for pillar, stats := range analytics.PillarStats {
-		p := database.Pillar(pillar)
-		percentage := 0.0
-		if stats.Total > 0 {
-			percentage = float64(stats.Completed) / float64(stats.Total) * 100
-		}
-		message += fmt.Sprintf(
-			"%s: %d/%d (%.0f%%), пропущено задач: %d\n",
-			database.PillarNames[p],
-			stats.Completed,
-			stats.Total,
-			percentage,
-			stats.Skipped,
-		)
-	}
-
-	if len(analytics.AvgFeelings) > 0 {
-		message += "\n<b>Средние ощущения:</b>\n"
-		for pillar, avg := range analytics.AvgFeelings {
-			p := database.Pillar(pillar)
-			message += fmt.Sprintf("%s %s: %.1f/10\n", database.PillarEmojis[p], database.PillarNames[p], avg)
-		}
-	}
-
-	if analytics.Insights != "" {
-		message += fmt.Sprintf("\n<b>💡 Инсайты:</b>\n%s", analytics.Insights)
-	}
-
-	b.SendMessageOrLogError(message)
+	b.SendMessageOrLogError(services.FormatWeeklyReport(b.services.Registry(), analytics))
 }
 
 func (b *Bot) handleAddTask(msg *tgbotapi.Message) {
-	text := strings.TrimPrefix(msg.Text, "/add ")
-	parts := strings.SplitN(text, " ", 2)
-	if len(parts) < 2 {
-		b.SendMessageOrLogError("❌ Формат: /add [столп] [описание и время в UTC]")
-		return
-	}
-
-	pillarStr := parts[0]
-	description := parts[1]
-	time2do := description[len(description)-5:]
-
-	re := regexp.MustCompile(`^([01][0-9]|2[0-3]):([0-5][0-9])$`)
-	if !re.MatchString(time2do) {
-		b.SendMessageOrLogError("❌ Время в HH:mm в UTC")
+	input, err := services.ParseAddCommand(strings.TrimPrefix(msg.Text, "/add "))
+	if err != nil {
+		b.SendError(err, "Ошибка добавления задачи")
 		return
 	}
+	input.Notes = "Добавлено через Telegram"
 
-	var pillar database.Pillar
-	switch strings.ToLower(pillarStr) {
-	case "энергия", "energy":
-		pillar = database.Energy
-	case "тело", "body":
-		pillar = database.Body
-	case "фокус", "focus":
-		pillar = database.Focus
-	case "быт", "life":
-		pillar = database.Life
-	case "баланс", "balance":
-		pillar = database.Balance
-	default:
-		b.SendMessageOrLogError("❌ Неизвестный столп. Используйте: энергия, тело, фокус, быт, баланс")
+	task, err := b.services.Task.AddTask(input)
+	if err != nil {
+		b.SendError(err, "Ошибка добавления задачи")
 		return
 	}
 
-	repo := database.NewRepository(b.db)
-	task := database.DailyTask{
-		Pillar:      pillar,
-		Description: description,
-		Completed:   false,
-		TimeUTC:     time2do,
-		Date:        time.Now().UTC().Format("2006-01-02"),
-		Notes:       "Добавлено через Telegram",
-	}
-
-	if err := repo.AddTask(task); err != nil {
-		b.SendMessageOrLogError("❌ Ошибка добавления задачи")
-		return
+	tags := ""
+	if task.Tags != "" {
+		tags = "\n🏷 " + html.EscapeString(services.FormatTags(task.Tags))
 	}
-
 	b.SendMessageOrLogError(fmt.Sprintf(
-		"✅ Добавлена задача:\n%s %s\n%s\n⏰ %s UTC",
-		database.PillarEmojis[pillar],
-		database.PillarNames[pillar],
-		description,
+		"✅ Добавлена задача:\n%s\n%s\n⏰ %s UTC%s",
+		html.EscapeString(b.services.Registry().PillarName(task.Pillar)),
+		html.EscapeString(task.Description),
 		task.TimeUTC,
+		tags,
 	))
 }
 
@@ -339,91 +289,191 @@ func (b *Bot) handleFeelings(msg *tgbotapi.Message) {
 	message := `📊 <b>Оцените свои ощущения за день</b>
 
 Формат:
-/feelings энергия=[1-10] контроль=[1-10] сон=[часы] настроение=[текст]
+/feelings ` + services.FeelingsFormat(b.services.Registry()) + `
 
 Пример:
-/feelings энергия=8 контроль=7 сон=7.5 настроение=Сосредоточен`
+/feelings энергия=8 контроль=7 сон=7.5 настроение=Сосредоточен
+Сон можно указать временем отбоя и подъема: сон=23:40-07:10
+
+Показатели: /wellbeing`
 
 	b.SendMessageOrLogError(message)
 }
 
 func (b *Bot) handleFeelingsCommand(msg *tgbotapi.Message) {
-	text := strings.TrimPrefix(msg.Text, "/feelings ")
-	metrics := make(map[string]string)
-	pairs := strings.Fields(text)
-
-	for _, pair := range pairs {
-		parts := strings.Split(pair, "=")
-		if len(parts) == 2 {
-			key := strings.ToLower(strings.TrimSpace(parts[0]))
-			value := strings.TrimSpace(parts[1])
-			metrics[key] = value
-		}
+	input, err := services.ParseFeelings(b.services.Registry(), strings.TrimPrefix(msg.Text, "/feelings "))
+	if err != nil {
+		b.SendError(err, "Ошибка сохранения ощущений")
+		return
 	}
 
-	var energy, control int
-	var sleep float64
-	var mood string
-	var err error
+	feelings, err := b.services.Feelings.Save(input)
+	if err != nil {
+		b.SendError(err, "Ошибка сохранения ощущений")
+		return
+	}
 
-	if val, ok := metrics["энергия"]; ok {
-		energy, err = strconv.Atoi(val)
-		if err != nil || energy < 1 || energy > 10 {
-			b.SendMessageOrLogError("❌ Энергия должна быть от 1 до 10")
-			return
-		}
+	message := "✅ Ощущения сохранены:\n\n" + b.formatFeelings(*feelings, "\n") +
+		"\n\n📓 Мысли о дне можно записать в дневник: /journal"
+
+	b.SendMessageOrLogError(message)
+}
+
+// handleExport выгрузка истории: /export [csv|json|md] [с YYYY-MM-DD] [по YYYY-MM-DD]
+func (b *Bot) handleExport(msg *tgbotapi.Message) {
+	args := strings.Fields(msg.Text)[1:]
+
+	formatName := "md"
+	if len(args) > 0 {
+		formatName = args[0]
+	}
+	format, err := export.ParseFormat(formatName)
+	if err != nil {
+		b.SendError(err, "Ошибка выгрузки")
+		return
 	}
 
-	if val, ok := metrics["контроль"]; ok {
-		control, err = strconv.Atoi(val)
-		if err != nil || control < 1 || control > 10 {
-			b.SendMessageOrLogError("❌ Контроль должен быть от 1 до 10")
-			return
-		}
+	to := services.Today()
+	from := time.Now().UTC().AddDate(0, 0, -29).Format("2006-01-02")
+	if len(args) > 1 {
+		from = args[1]
+	}
+	if len(args) > 2 {
+		to = args[2]
+	}
+
+	snapshot, err := b.services.Transfer.Export(from, to)
+	if err != nil {
+		b.SendError(err, "Ошибка выгрузки")
+		return
 	}
 
-	if val, ok := metrics["сон"]; ok {
-		sleep, err = strconv.ParseFloat(val, 64)
-		if err != nil || sleep <= 0 {
-			b.SendMessageOrLogError("❌ Сон должен быть положительным числом")
+	files, err := export.RenderAll(b.services.Registry(), snapshot, format)
+	if err != nil {
+		b.SendError(err, "Ошибка выгрузки")
+		return
+	}
+
+	for _, file := range files {
+		caption := fmt.Sprintf("📦 Выгрузка %s — %s", from, to)
+		if err := b.SendDocument(file.Name, file.Data, caption); err != nil {
+			b.SendError(err, "Ошибка отправки файла")
 			return
 		}
 	}
+}
+
+// handleDocument загрузка присланного файла. Без подписи /import файл только
+// проверяется (dry-run) и загружается после подтверждения кнопкой.
+func (b *Bot) handleDocument(msg *tgbotapi.Message) {
+	name := msg.Document.FileName
 
-	if val, ok := metrics["настроение"]; ok {
-		mood = val
+	data, err := b.DownloadFile(msg.Document.FileID)
+	if err != nil {
+		b.SendError(err, "Ошибка получения файла")
+		return
+	}
+
+	batch, err := b.parseUpload(name, data)
+	if err != nil {
+		b.SendError(err, "Ошибка разбора файла")
+		return
 	}
 
-	date := time.Now().UTC().Format("2006-01-02")
-	repo := database.NewRepository(b.db)
-	feelings := database.DailyFeelings{
-		Date:         date,
-		EnergyLevel:  energy,
-		ControlLevel: control,
-		SleepHours:   sleep,
-		Mood:         mood,
+	apply := strings.HasPrefix(strings.TrimSpace(msg.Caption), "/import")
+	report, err := b.services.Transfer.Import(batch, !apply)
+	if err != nil {
+		b.SendError(err, "Ошибка загрузки данных")
+		return
 	}
 
-	if err := repo.SaveFeelings(feelings); err != nil {
-		b.SendMessageOrLogError("❌ Ошибка сохранения ощущений")
+	text := fmt.Sprintf("📄 <b>%s</b>\n\n%s", html.EscapeString(name), html.EscapeString(importer.Summary(report, 10)))
+	if apply {
+		b.pendingImport = nil
+		b.SendMessageOrLogError(text)
 		return
 	}
 
-	message := fmt.Sprintf(
-		"✅ Ощущения сохранены:\n\n"+
-			"⚡ Энергия: %d/10\n"+
-			"🎯 Контроль: %d/10\n",
-		energy, control,
+	b.pendingImport = &pendingFile{name: name, data: data}
+	confirm := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Загрузить", "import_apply"),
+			tgbotapi.NewInlineKeyboardButtonData("❌ Отмена", "import_cancel"),
+		),
 	)
+	if err := b.enqueueWithKeyboard(text, confirm); err != nil {
+		log.Printf("⚠️ Ошибка отправки отчета о загрузке: %v", err)
+	}
+}
+
+// parseUpload разбирает присланный файл: .ics через правила календаря,
+// остальное - как выгрузку CSV/JSON
+func (b *Bot) parseUpload(name string, data []byte) (*services.ImportBatch, error) {
+	if strings.EqualFold(filepath.Ext(name), ".ics") {
+		now := time.Now().UTC()
+		return ical.Batch(data, ical.ImportOptions{
+			Rules:    b.calendarRules,
+			From:     now.Format("2006-01-02"),
+			To:       now.AddDate(0, 0, b.calendarDays).Format("2006-01-02"),
+			Location: utils.MoscowLocation(),
+		})
+	}
+	return importer.Parse(b.services.Registry(), name, data)
+}
 
-	if sleep > 0 {
-		message += fmt.Sprintf("😴 Сон: %.1f ч\n", sleep)
+// handleImportApply загружает файл, проверенный ранее в режиме dry-run
+func (b *Bot) handleImportApply(messageID int) {
+	pending := b.pendingImport
+	if pending == nil {
+		b.SendMessageOrLogError("❌ Нет файла для загрузки. Пришлите файл еще раз")
+		return
 	}
-	if mood != "" {
-		message += fmt.Sprintf("😊 Настроение: %s\n", mood)
+	b.pendingImport = nil
+	b.safeDeleteMessage(messageID)
+
+	batch, err := b.parseUpload(pending.name, pending.data)
+	if err != nil {
+		b.SendError(err, "Ошибка разбора файла")
+		return
 	}
 
-	b.SendMessageOrLogError(message)
+	report, err := b.services.Transfer.Import(batch, false)
+	if err != nil {
+		b.SendError(err, "Ошибка загрузки данных")
+		return
+	}
+
+	b.SendMessageOrLogError(fmt.Sprintf("📄 <b>%s</b>\n\n%s", html.EscapeString(pending.name), html.EscapeString(importer.Summary(report, 10))))
+}
+
+// handleBackup отправляет последнюю резервную копию БД: /backup [new]
+func (b *Bot) handleBackup(msg *tgbotapi.Message) {
+	if b.backups == nil {
+		b.SendMessageOrLogError("❌ Резервное копирование не настроено")
+		return
+	}
+
+	args := strings.Fields(msg.Text)[1:]
+
+	snapshot, err := b.backups.Latest()
+	if errors.Is(err, backup.ErrNoSnapshots) || (err == nil && len(args) > 0 && args[0] == "new") {
+		snapshot, err = b.backups.Create()
+	}
+	if err != nil {
+		b.SendError(err, "Ошибка резервного копирования")
+		return
+	}
+
+	if snapshot.Size > maxUploadSize {
+		b.SendMessageOrLogError(fmt.Sprintf("⚠️ Копия %s слишком большая для отправки (%.1f МБ), она лежит в %s",
+			snapshot.Name, float64(snapshot.Size)/(1<<20), html.EscapeString(b.backups.Dir())))
+		return
+	}
+
+	caption := fmt.Sprintf("💾 Резервная копия от %s", utils.FormatDateTimeForDisplay(snapshot.CreatedAt))
+	if err := b.SendFile(snapshot.Path, caption); err != nil {
+		b.SendError(err, "Ошибка отправки файла")
+	}
 }
 
 func (b *Bot) handleHelp(msg *tgbotapi.Message) {
@@ -447,6 +497,40 @@ func (b *Bot) handleHelp(msg *tgbotapi.Message) {
 /date [id] [YYYY-mm-DD] - Изменить дату выполнения задачи
 Пример: /change 3 2026-01-10
 
+/lead [id] [начало в UTC] [минуты] - Напомнить заранее до начала
+Пример: /lead 3 18:30 30,5
+
+/check [id] [пункт; пункт] - Чек-лист задачи: показать кнопками или добавить пункты
+Пример: /check 3 кроссовки; активность; контрастный душ
+
+/metric [id] [единица] [цель] [sum|avg|max] - Измеримый результат задачи, off - убрать
+Пример: /metric 3 км 5 sum
+
+/value [id] [число] - Записать результат, задача станет выполненной
+Пример: /value 3 5,2
+После «✅ Выполнил» бот сам спросит результат - ответьте числом.
+
+/metrics [week|month] - Прогресс измеримых целей за неделю или месяц
+
+/tag [id] [#тег ...] - Теги задачи, off - убрать
+Пример: /tag 3 #work #отчеты
+Теги можно указать и при добавлении: /add focus Отчет 10:00 #work
+
+<b>Поиск:</b>
+/find [слова] [#тег] [from:YYYY-MM-DD] [to:YYYY-MM-DD] [status:done] - Поиск по задачам, заметкам и ощущениям
+Пример: /find отчет #work from:2026-01-01
+
+<b>Долгосрочные цели:</b>
+/goals - Прогресс по целям, /goals all - вместе с архивными
+/goal add [столп] [срок] [цель] [описание] - Добавить цель
+Пример: /goal add body 2026-12-31 500км Пробежать за год
+Без единицы цель - число выполненных задач: /goal add focus 2026-06-30 100 Сто утренних блоков
+/goal [id] - Прогресс цели и привязанные шаблоны
+/goal link [id] template|task [id] - Привязать шаблон или задачу
+/goal unlink template|task [id] - Отвязать
+/goal archive|restore [id] - Убрать в архив или вернуть
+Первого числа месяца бот присылает прогресс по целям.
+
 
 <b>Управление задачами:</b>
 /add [столп] [описание] - Добавить задачу
@@ -456,13 +540,112 @@ func (b *Bot) handleHelp(msg *tgbotapi.Message) {
 <b>Отслеживание ощущений:</b>
 /feelings - Оценить свои ощущения за день
 Пример: /feelings энергия=8 контроль=7 сон=7.5
+/wellbeing - Показатели для /feelings, /wellbeing all - вместе с архивными
+/wellbeing restore [ключ] - Включить показатель: stress, focus, alcohol, steps, weight, screen
+/wellbeing add [ключ] scale|number|bool|text [эмодзи] [название] - Свой показатель
+Пример: /wellbeing add water number 💧 Вода, затем /wellbeing set water unit л
+/wellbeing set [ключ] name|emoji|unit|aliases|min|max|position [значение] - Изменить
+/wellbeing archive [ключ] - Перестать спрашивать
+
+<b>Сон:</b>
+/sleep 23:40-07:10 - Записать прошлую ночь: отбой и подъем по Москве
+/sleep 23:40-07:10 [YYYY-MM-DD] - Ночь, после которой проснулись в этот день
+/sleep [дней] - Журнал сна, ровность режима, социальный джетлаг и связь с энергией
+/sleep del [YYYY-MM-DD] - Удалить ночь
+В /feelings сон=23:40-07:10 тоже попадает в журнал сна.
+
+<b>Дневник:</b>
+/journal - Начать запись: сообщения и фото после команды сохранятся в дневник за сегодня
+/journal [текст] - Добавить запись за сегодня
+/journal [YYYY-MM-DD] - Задачи, ощущения и дневник за день
+/journal del [id] - Удалить запись, /journal done - закончить запись
+Фото, присланное без команды, тоже попадает в дневник за сегодня.
+
+<b>Выгрузка:</b>
+/export [csv|json|md] [с] [по] - Выгрузить задачи, ощущения и аналитику (по умолчанию md за 30 дней)
+Пример: /export csv 2026-01-01 2026-01-31
+
+<b>Резервные копии:</b>
+/backup - Прислать последнюю копию базы
+/backup new - Сделать копию сейчас и прислать её
+
+<b>Тишина:</b>
+/dnd 2h - Не беспокоить 2 часа (30m, 1ч30м)
+/dnd until 14:00 - Не беспокоить до 14:00 МСК
+/dnd off - Выключить, /dnd - состояние
+Напоминания в тишине копятся и приходят одним сообщением после.
+
+<b>Загрузка:</b>
+Пришлите файл .csv или .json - бот проверит его и предложит загрузить.
+Файл календаря .ics превращается в задачи: столп выбирается по категории
+события или ключевым словам, повторения разворачиваются на ближайшие дни.
+С подписью /import файл загружается сразу.
 
 <b>Столпы:</b>
-⚖️ Энергия - energy, энергия
-🏃 Тело - body, тело
-🧠 Фокус - focus, фокус
-🏠 Быт - life, быт
-🔄 Баланс - balance, баланс`
+/pillars - Справочник столпов, /pillars all - вместе с архивными
+/pillar add [ключ] [эмодзи] [название] - Новый столп
+Пример: /pillar add finance 💰 Финансы
+/pillar set [ключ] name|emoji|color|aliases|position [значение] - Изменить
+Пример: /pillar set finance aliases деньги,бюджет
+/pillar archive|restore [ключ] - Убрать в архив или вернуть
+
+` + services.FormatPillars(b.services.Pillars.List(false))
 
 	b.SendMessageOrLogError(message)
 }
+
+// handleDND режим "не беспокоить": /dnd 2h, /dnd until 14:00, /dnd off
+func (b *Bot) handleDND(msg *tgbotapi.Message) {
+	arg := strings.TrimSpace(strings.TrimPrefix(msg.Text, "/dnd"))
+	now := time.Now()
+
+	switch strings.ToLower(arg) {
+	case "":
+		b.sendQuietState(now)
+		return
+	case "off", "выкл":
+		if err := b.services.Quiet.ClearDND(); err != nil {
+			b.SendError(err, "Ошибка выключения режима \"не беспокоить\"")
+			return
+		}
+		b.sendQuietState(now)
+		// Если тихие часы тоже не действуют, накопленное приходит сразу
+		if b.services.Notification != nil {
+			b.services.Notification.SendDeferred()
+		}
+		return
+	}
+
+	until, err := services.ParseDND(arg, now)
+	if err != nil {
+		b.SendError(err, "Ошибка разбора времени")
+		return
+	}
+	if err := b.services.Quiet.SetDND(until); err != nil {
+		b.SendError(err, "Ошибка включения режима \"не беспокоить\"")
+		return
+	}
+	b.sendQuietState(now)
+}
+
+func (b *Bot) sendQuietState(now time.Time) {
+	state, err := b.services.Quiet.State(now)
+	if err != nil {
+		b.SendError(err, "Ошибка чтения режима \"не беспокоить\"")
+		return
+	}
+
+	var message strings.Builder
+	if state.Active {
+		message.WriteString(fmt.Sprintf("🔕 Тишина до %s\n", utils.FormatDateTimeForDisplay(state.Until)))
+		message.WriteString("Напоминания придут одним сообщением, когда она закончится.\n")
+	} else {
+		message.WriteString("🔔 Уведомления включены\n")
+	}
+	if !state.DNDUntil.IsZero() {
+		message.WriteString(fmt.Sprintf("\nНе беспокоить: до %s (/dnd off - выключить)", utils.FormatDateTimeForDisplay(state.DNDUntil)))
+	}
+	message.WriteString(fmt.Sprintf("\nТихие часы: %s", b.services.Quiet.Hours()))
+
+	b.SendMessageOrLogError(message.String())
+}
@@ -0,0 +1,174 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"five-pillars/internal/services"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const journalUsage = "❌ Формат:\n" +
+	"/journal - начать запись: следующие сообщения и фото попадут в дневник за сегодня\n" +
+	"/journal [текст] - добавить запись за сегодня\n" +
+	"/journal [YYYY-MM-DD] - дневник, задачи и ощущения за день\n" +
+	"/journal [YYYY-MM-DD] [текст] - добавить запись за другой день\n" +
+	"/journal del [id] - удалить запись, /journal done - закончить запись"
+
+// handleJournal дневник дня, форматы в journalUsage
+func (b *Bot) handleJournal(msg *tgbotapi.Message) {
+	// Текст берется как есть, с переносами строк
+	text := strings.TrimSpace(strings.TrimPrefix(msg.Text, "/journal"))
+	args := strings.Fields(text)
+
+	switch {
+	case len(args) == 0:
+		b.startJournal()
+	case args[0] == "done":
+		b.stopJournal()
+	case args[0] == "del" && len(args) == 2:
+		id, err := strconv.Atoi(args[1])
+		if err != nil {
+			b.SendMessageOrLogError("❌ id должен быть числовой")
+			return
+		}
+		if err := b.services.Journal.Delete(id); err != nil {
+			b.SendError(err, "Ошибка удаления записи дневника")
+			return
+		}
+		b.SendMessageOrLogError(fmt.Sprintf("🗑 Запись дневника #%d удалена", id))
+	case len(args[0]) == len("2006-01-02") && args[0][4] == '-':
+		if err := services.ValidateDate(args[0]); err != nil {
+			b.SendError(err, "Ошибка получения дневника")
+			return
+		}
+		if len(args) == 1 {
+			b.showJournal(args[0])
+			return
+		}
+		b.addJournalText(args[0], strings.TrimSpace(strings.TrimPrefix(text, args[0])))
+	case args[0] == "del":
+		b.SendMessageOrLogError(journalUsage)
+	default:
+		b.addJournalText(services.Today(), text)
+	}
+}
+
+// startJournal включает запись дневника за сегодня: обычные сообщения и фото
+// сохраняются, пока не придет /journal done или другая команда
+func (b *Bot) startJournal() {
+	date := services.Today()
+	entries, err := b.services.Journal.Get(date)
+	if err != nil {
+		b.SendError(err, "Ошибка получения дневника")
+		return
+	}
+
+	b.pendingJournal = date
+	b.pendingValue = 0
+
+	message := fmt.Sprintf("📓 <b>Дневник за %s</b>\n\n", date)
+	if len(entries) > 0 {
+		message += html.EscapeString(services.FormatJournal(entries)) + "\n"
+	}
+	message += "Пишите, как прошел день: каждое сообщение и фото сохранятся в дневник.\n" +
+		"Закончить: /journal done или любая другая команда"
+	b.SendMessageOrLogError(message)
+}
+
+func (b *Bot) stopJournal() {
+	if b.pendingJournal == "" {
+		b.SendMessageOrLogError("📓 Запись дневника не ведется. Начать: /journal")
+		return
+	}
+
+	date := b.pendingJournal
+	b.pendingJournal = ""
+	b.SendMessageOrLogError(fmt.Sprintf("✅ Запись закончена. Дневник за день: /journal %s", date))
+}
+
+func (b *Bot) addJournalText(date, text string) {
+	entry, err := b.services.Journal.Add(date, text, "")
+	if err != nil {
+		b.SendError(err, "Ошибка записи в дневник")
+		return
+	}
+	b.SendMessageOrLogError(fmt.Sprintf("📝 Записано в дневник за %s (#%d)", entry.Date, entry.ID))
+}
+
+// handleJournalPhoto сохраняет фото с подписью в дневник: за день, который
+// сейчас записывается, иначе за сегодня
+func (b *Bot) handleJournalPhoto(msg *tgbotapi.Message) {
+	date := b.pendingJournal
+	if date == "" {
+		date = services.Today()
+	}
+
+	// Telegram присылает фото в нескольких размерах, последнее - самое большое
+	photo := msg.Photo[len(msg.Photo)-1]
+	entry, err := b.services.Journal.Add(date, msg.Caption, photo.FileID)
+	if err != nil {
+		b.SendError(err, "Ошибка записи фото в дневник")
+		return
+	}
+	b.SendMessageOrLogError(fmt.Sprintf("📷 Фото добавлено в дневник за %s (#%d)", entry.Date, entry.ID))
+}
+
+// showJournal день целиком: задачи, ощущения и записи дневника, затем фото
+func (b *Bot) showJournal(date string) {
+	tasks, err := b.services.Task.GetTasks(date)
+	if err != nil {
+		b.SendError(err, "Ошибка получения задач")
+		return
+	}
+	feelings, err := b.services.Feelings.Get(date)
+	if err != nil && !errors.Is(err, services.ErrFeelingsNotFound) {
+		b.SendError(err, "Ошибка получения ощущений")
+		return
+	}
+	entries, err := b.services.Journal.Get(date)
+	if err != nil {
+		b.SendError(err, "Ошибка получения дневника")
+		return
+	}
+
+	var message strings.Builder
+	message.WriteString(fmt.Sprintf("📓 <b>%s</b>\n\n", date))
+
+	if len(tasks) > 0 {
+		message.WriteString("<b>Задачи</b>\n")
+		now := time.Now()
+		for _, task := range tasks {
+			message.WriteString(fmt.Sprintf("%s %s %s\n",
+				services.StatusMark(task, now), b.services.Registry().PillarEmoji(task.Pillar), html.EscapeString(task.Description)))
+		}
+		message.WriteString("\n")
+	}
+
+	if feelings != nil {
+		message.WriteString("<b>Ощущения</b>\n" + b.formatFeelings(*feelings, " · ") + "\n\n")
+	}
+
+	if len(entries) > 0 {
+		message.WriteString("<b>Дневник</b>\n" + html.EscapeString(services.FormatJournal(entries)))
+	} else {
+		message.WriteString("Записей в дневнике нет. Добавить: /journal " + date + " [текст]")
+	}
+	b.SendMessageOrLogError(strings.TrimRight(message.String(), "\n"))
+
+	for _, entry := range entries {
+		if entry.PhotoID == "" {
+			continue
+		}
+		caption := fmt.Sprintf("#%d %s", entry.ID, html.EscapeString(entry.Text))
+		if err := b.SendPhoto(entry.PhotoID, strings.TrimSpace(caption)); err != nil {
+			b.SendError(err, "Ошибка отправки фото из дневника")
+			return
+		}
+	}
+}
@@ -1,10 +1,31 @@
 package telegram
 
-import "log"
+import (
+	"errors"
+	"html"
+	"log"
+
+	"five-pillars/internal/services"
+)
 
 func (b *Bot) SendMessageOrLogError(message string) {
-	err := b.SendMessage(message)
-	if err != nil {
-		log.Fatalf(err.Error())
+	if err := b.SendMessage(message); err != nil {
+		log.Printf("⚠️ %v", err)
+	}
+}
+
+// SendError сообщает пользователю об ошибке сервиса. Ошибки ввода и
+// ненайденные записи показываются как есть, остальные - текстом fallback.
+func (b *Bot) SendError(err error, fallback string) {
+	switch {
+	case services.IsValidationError(err):
+		b.SendMessageOrLogError("❌ " + html.EscapeString(err.Error()))
+	case errors.Is(err, services.ErrTaskNotFound):
+		b.SendMessageOrLogError("❌ Задача не найдена")
+	case errors.Is(err, services.ErrFeelingsNotFound):
+		b.SendMessageOrLogError("📭 Ощущения за день еще не заполнены")
+	default:
+		log.Printf("⚠️ %s: %v", fallback, err)
+		b.SendMessageOrLogError("❌ " + fallback)
 	}
 }
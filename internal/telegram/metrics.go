@@ -0,0 +1,176 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"five-pillars/internal/database"
+	"five-pillars/internal/services"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// pendingValueTTL сколько ждем ответа числом на вопрос о результате
+const pendingValueTTL = 30 * time.Minute
+
+// askValue спрашивает результат выполненной измеримой задачи. Ответ
+// числом в чате или кнопкой с целью записывается в задачу.
+func (b *Bot) askValue(task *database.DailyTask) {
+	b.pendingValue = task.ID
+	b.pendingValueAt = time.Now()
+	b.pendingJournal = ""
+
+	text := fmt.Sprintf("📏 Сколько %s? Пришлите число", html.EscapeString(task.MetricUnit))
+	if task.MetricTarget <= 0 {
+		b.SendMessageOrLogError(text)
+		return
+	}
+
+	target := services.FormatAmount(task.MetricTarget)
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🎯 "+target+" "+task.MetricUnit, fmt.Sprintf("value_%d_%s", task.ID, target)),
+	))
+	if err := b.enqueueWithKeyboard(text, keyboard); err != nil {
+		b.SendError(err, "Ошибка отправки вопроса о результате")
+	}
+}
+
+// handleValueButton записывает результат, выбранный кнопкой
+func (b *Bot) handleValueButton(data string, messageID int) {
+	parts := strings.SplitN(strings.TrimPrefix(data, "value_"), "_", 2)
+	if len(parts) != 2 {
+		b.SendMessageOrLogError("❌ Ошибка обработки запроса")
+		return
+	}
+
+	taskID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		b.SendMessageOrLogError("❌ Ошибка обработки запроса")
+		return
+	}
+	value, err := services.ParseValue(parts[1])
+	if err != nil {
+		b.SendError(err, "Ошибка записи результата")
+		return
+	}
+
+	b.safeDeleteMessage(messageID)
+	b.recordValue(taskID, value)
+}
+
+// handlePendingValue принимает число в ответ на вопрос о результате,
+// если результат не ждали или ответа не было дольше pendingValueTTL -
+// сообщение игнорируется
+func (b *Bot) handlePendingValue(text string) {
+	if b.pendingValue == 0 {
+		return
+	}
+	taskID := b.pendingValue
+	if time.Since(b.pendingValueAt) > pendingValueTTL {
+		b.pendingValue = 0
+		return
+	}
+
+	value, err := services.ParseValue(text)
+	if err != nil {
+		// Ответ не числом - вопрос больше не ждет ответа, иначе любой
+		// текст в чате дальше считался бы результатом
+		b.pendingValue = 0
+		b.SendMessageOrLogError(fmt.Sprintf("❌ Результат не записан. Записать позже: /value %d [число]", taskID))
+		return
+	}
+	b.recordValue(taskID, value)
+}
+
+func (b *Bot) recordValue(taskID int, value float64) {
+	task, err := b.services.Task.RecordValue(taskID, value)
+	if err != nil {
+		b.SendError(err, "Ошибка записи результата")
+		return
+	}
+	if b.pendingValue == taskID {
+		b.pendingValue = 0
+	}
+
+	b.SendMessageOrLogError(fmt.Sprintf("✅ Задача #%d: %s", task.ID, html.EscapeString(services.FormatTaskValue(*task))))
+}
+
+// handleValue /value [id] [число] - записать результат задачи
+func (b *Bot) handleValue(msg *tgbotapi.Message) {
+	args := strings.Fields(msg.Text)[1:]
+	if len(args) != 2 {
+		b.SendMessageOrLogError("❌ Формат: /value [id] [число]\nПример: /value 3 5,2")
+		return
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		b.SendMessageOrLogError("❌ id должен быть числовой")
+		return
+	}
+	value, err := services.ParseValue(args[1])
+	if err != nil {
+		b.SendError(err, "Ошибка записи результата")
+		return
+	}
+	b.recordValue(id, value)
+}
+
+// handleMetric /metric [id] [единица] [цель] [sum|avg|max] - задать метрику
+// задачи, /metric [id] off - убрать
+func (b *Bot) handleMetric(msg *tgbotapi.Message) {
+	args := strings.Fields(msg.Text)[1:]
+	if len(args) < 2 {
+		b.SendMessageOrLogError("❌ Формат: /metric [id] [единица] [цель] [sum|avg|max]\n" +
+			"Пример: /metric 3 км 5 sum, /metric [id] off - убрать")
+		return
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		b.SendMessageOrLogError("❌ id должен быть числовой")
+		return
+	}
+	metric, err := services.ParseMetricArgs(args[1:])
+	if err != nil {
+		b.SendError(err, "Ошибка изменения метрики")
+		return
+	}
+
+	task, err := b.services.Task.SetMetric(id, metric)
+	if err != nil {
+		b.SendError(err, "Ошибка изменения метрики")
+		return
+	}
+
+	if task.MetricUnit == "" {
+		b.SendMessageOrLogError(fmt.Sprintf("✅ Метрика задачи #%d убрана", task.ID))
+		return
+	}
+	b.SendMessageOrLogError(fmt.Sprintf("✅ Задача #%d: %s\n%s",
+		task.ID, html.EscapeString(task.Description), html.EscapeString(services.FormatTaskValue(*task))))
+}
+
+// handleMetrics /metrics [week|month] - прогресс измеримых задач
+func (b *Bot) handleMetrics(msg *tgbotapi.Message) {
+	period := "week"
+	if args := strings.Fields(msg.Text)[1:]; len(args) > 0 {
+		period = strings.ToLower(args[0])
+	}
+
+	report, err := b.services.Analytics.GetMetrics(period, time.Now())
+	if err != nil {
+		b.SendError(err, "Ошибка получения метрик")
+		return
+	}
+
+	if len(report.Metrics) == 0 {
+		b.SendMessageOrLogError(fmt.Sprintf("📏 За %s - %s измеримых задач нет. Задайте метрику: /metric [id] км 5", report.From, report.To))
+		return
+	}
+	b.SendMessageOrLogError(fmt.Sprintf("📏 <b>Измеримые цели</b>\n📅 %s - %s\n\n%s",
+		report.From, report.To, html.EscapeString(services.FormatMetrics(b.services.Registry(), report.Metrics))))
+}
@@ -0,0 +1,271 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"five-pillars/internal/database"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// outboxMaxAttempts после стольких неудач сообщение считается недоставленным
+	outboxMaxAttempts = 8
+
+	outboxFirstRetry = 5 * time.Second
+	outboxMaxRetry   = 30 * time.Minute
+	outboxPoll       = 2 * time.Second
+	outboxBatch      = 20
+	// outboxKeepSent сколько хранить отправленные сообщения
+	outboxKeepSent = 7 * 24 * time.Hour
+
+	// deadLetterPrefix начало уведомления о недоставленном сообщении,
+	// о недоставке самих уведомлений повторно не сообщаем
+	deadLetterPrefix = "⚠️ Сообщение не доставлено"
+)
+
+// enqueue ставит сообщение в исходящую очередь и будит обработчик
+func (b *Bot) enqueue(msg database.OutboxMessage) error {
+	msg.ChatID = b.chatID
+	if _, err := b.services.Outbox.Enqueue(msg); err != nil {
+		return fmt.Errorf("ошибка постановки сообщения в очередь: %v", err)
+	}
+
+	select {
+	case b.outboxWake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// enqueueWithKeyboard сообщение с inline-клавиатурой
+func (b *Bot) enqueueWithKeyboard(text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
+	markup, err := json.Marshal(keyboard)
+	if err != nil {
+		return fmt.Errorf("ошибка подготовки клавиатуры: %v", err)
+	}
+
+	return b.enqueue(database.OutboxMessage{
+		Method:      "sendMessage",
+		Text:        text,
+		ReplyMarkup: string(markup),
+	})
+}
+
+// enqueueEdit ставит в очередь правку отправленного сообщения: новый текст
+// и клавиатуру, nil - без клавиатуры
+func (b *Bot) enqueueEdit(messageID int, text string, keyboard *tgbotapi.InlineKeyboardMarkup) error {
+	msg := database.OutboxMessage{
+		Method:    "editMessageText",
+		Text:      text,
+		MessageID: messageID,
+	}
+	if keyboard != nil {
+		markup, err := json.Marshal(keyboard)
+		if err != nil {
+			return fmt.Errorf("ошибка подготовки клавиатуры: %v", err)
+		}
+		msg.ReplyMarkup = string(markup)
+	}
+	return b.enqueue(msg)
+}
+
+// runOutbox отправляет очередь, пока не отменен ctx
+func (b *Bot) runOutbox(ctx context.Context) {
+	ticker := time.NewTicker(outboxPoll)
+	defer ticker.Stop()
+	cleanup := time.NewTicker(24 * time.Hour)
+	defer cleanup.Stop()
+
+	b.cleanupOutbox()
+	for {
+		b.flushOutbox()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-b.outboxWake:
+		case <-cleanup.C:
+			b.cleanupOutbox()
+		}
+	}
+}
+
+// flushOutbox отправляет сообщения по порядку. Если очередное сообщение
+// ждет повтора, следующие тоже ждут, чтобы не нарушать порядок в чате.
+func (b *Bot) flushOutbox() {
+	for {
+		messages, err := b.services.Outbox.Pending(outboxBatch)
+		if err != nil {
+			log.Printf("⚠️ Ошибка чтения исходящей очереди: %v", err)
+			return
+		}
+
+		for _, msg := range messages {
+			if msg.NextAttemptAt.After(time.Now()) {
+				return
+			}
+			if !b.deliver(msg) {
+				return
+			}
+		}
+
+		if len(messages) < outboxBatch {
+			return
+		}
+	}
+}
+
+// deliver отправляет одно сообщение. false - отправка отложена и очередь
+// нужно остановить до следующей попытки.
+func (b *Bot) deliver(msg database.OutboxMessage) bool {
+	attempts := msg.Attempts + 1
+
+	err := b.sendOutbox(msg)
+	var apiErr *tgbotapi.Error
+	isAPIErr := errors.As(err, &apiErr)
+
+	// Правка без изменений: нужный текст уже в чате
+	if err == nil || (isAPIErr && msg.Method == "editMessageText" && notModified(apiErr)) {
+		if err := b.services.Outbox.MarkSent(msg, attempts); err != nil {
+			log.Printf("⚠️ Ошибка записи статуса сообщения #%d: %v", msg.ID, err)
+		}
+		return true
+	}
+
+	switch {
+	case isAPIErr && apiErr.RetryAfter > 0:
+		// 429: Telegram сам говорит, сколько ждать, попытку не считаем
+		wait := time.Duration(apiErr.RetryAfter) * time.Second
+		log.Printf("⏳ Telegram ограничил отправку, повтор через %s", wait)
+		b.retryOutbox(msg, msg.Attempts, time.Now().Add(wait), err)
+		return false
+
+	case isAPIErr && permanentError(apiErr), attempts >= outboxMaxAttempts:
+		log.Printf("❌ Сообщение #%d не доставлено после %d попыток: %v", msg.ID, attempts, err)
+		if err := b.services.Outbox.Bury(msg, attempts, err.Error()); err != nil {
+			log.Printf("⚠️ Ошибка записи статуса сообщения #%d: %v", msg.ID, err)
+		}
+		b.reportDeadLetter(msg, apiErr, err)
+		return true
+
+	default:
+		b.retryOutbox(msg, attempts, time.Now().Add(outboxRetryDelay(attempts)), err)
+		return false
+	}
+}
+
+func (b *Bot) sendOutbox(msg database.OutboxMessage) error {
+	var keyboard *tgbotapi.InlineKeyboardMarkup
+	if msg.ReplyMarkup != "" {
+		keyboard = &tgbotapi.InlineKeyboardMarkup{}
+		if err := json.Unmarshal([]byte(msg.ReplyMarkup), keyboard); err != nil {
+			return fmt.Errorf("неверная клавиатура: %v", err)
+		}
+	}
+
+	var chattable tgbotapi.Chattable
+	switch msg.Method {
+	case "sendDocument":
+		var file tgbotapi.RequestFileData = tgbotapi.FileBytes{Name: msg.FileName, Bytes: msg.FileData}
+		if msg.FilePath != "" {
+			file = tgbotapi.FilePath(msg.FilePath)
+		}
+		doc := tgbotapi.NewDocument(msg.ChatID, file)
+		doc.Caption = msg.Text
+		doc.ParseMode = "HTML"
+		if keyboard != nil {
+			doc.ReplyMarkup = keyboard
+		}
+		chattable = doc
+	case "editMessageText":
+		edit := tgbotapi.NewEditMessageText(msg.ChatID, msg.MessageID, msg.Text)
+		edit.ParseMode = "HTML"
+		edit.ReplyMarkup = keyboard
+		chattable = edit
+	case "sendPhoto":
+		photo := tgbotapi.NewPhoto(msg.ChatID, tgbotapi.FileID(msg.FileName))
+		photo.Caption = msg.Text
+		photo.ParseMode = "HTML"
+		chattable = photo
+	default:
+		text := tgbotapi.NewMessage(msg.ChatID, msg.Text)
+		text.ParseMode = "HTML"
+		if keyboard != nil {
+			text.ReplyMarkup = keyboard
+		}
+		chattable = text
+	}
+
+	_, err := b.bot.Send(chattable)
+	return err
+}
+
+func (b *Bot) retryOutbox(msg database.OutboxMessage, attempts int, next time.Time, sendErr error) {
+	if err := b.services.Outbox.Retry(msg, attempts, next, sendErr.Error()); err != nil {
+		log.Printf("⚠️ Ошибка записи статуса сообщения #%d: %v", msg.ID, err)
+	}
+}
+
+// reportDeadLetter сообщает в чат о недоставленном сообщении, если чат
+// доступен: например, Telegram отверг разметку сообщения
+func (b *Bot) reportDeadLetter(msg database.OutboxMessage, apiErr *tgbotapi.Error, sendErr error) {
+	if apiErr == nil || apiErr.Code != http.StatusBadRequest || strings.HasPrefix(msg.Text, deadLetterPrefix) {
+		return
+	}
+
+	preview := []rune(msg.Text)
+	if len(preview) > 200 {
+		preview = append(preview[:200], '…')
+	}
+
+	notice := fmt.Sprintf("%s (#%d): %s\n\n%s", deadLetterPrefix, msg.ID,
+		html.EscapeString(sendErr.Error()), html.EscapeString(string(preview)))
+	if err := b.enqueue(database.OutboxMessage{Method: "sendMessage", Text: notice}); err != nil {
+		log.Printf("⚠️ %v", err)
+	}
+}
+
+func (b *Bot) cleanupOutbox() {
+	count, err := b.services.Outbox.Cleanup(outboxKeepSent)
+	if err != nil {
+		log.Printf("⚠️ Ошибка очистки исходящей очереди: %v", err)
+		return
+	}
+	if count > 0 {
+		log.Printf("🧹 Удалено отправленных сообщений: %d", count)
+	}
+}
+
+// permanentError ошибки, которые не исправятся повтором: неверный запрос,
+// бот заблокирован, чат не найден
+func permanentError(err *tgbotapi.Error) bool {
+	return err.Code == http.StatusBadRequest || err.Code == http.StatusForbidden || err.Code == http.StatusNotFound
+}
+
+// notModified Telegram отказался менять сообщение, потому что текст и
+// клавиатура не изменились
+func notModified(err *tgbotapi.Error) bool {
+	return err.Code == http.StatusBadRequest && strings.Contains(err.Message, "message is not modified")
+}
+
+// outboxRetryDelay экспоненциальная пауза перед следующей попыткой
+func outboxRetryDelay(attempts int) time.Duration {
+	delay := outboxFirstRetry
+	for i := 1; i < attempts && delay < outboxMaxRetry; i++ {
+		delay *= 2
+	}
+	if delay > outboxMaxRetry {
+		delay = outboxMaxRetry
+	}
+	return delay
+}
@@ -0,0 +1,45 @@
+package telegram
+
+import (
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestOutboxRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, 5 * time.Second},
+		{1, 5 * time.Second},
+		{2, 10 * time.Second},
+		{3, 20 * time.Second},
+		{4, 40 * time.Second},
+		{9, 1280 * time.Second},
+		{10, 30 * time.Minute},
+		{100, 30 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := outboxRetryDelay(tt.attempts); got != tt.want {
+			t.Errorf("outboxRetryDelay(%d) = %s, ожидалось %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	tests := []struct {
+		err  tgbotapi.Error
+		want bool
+	}{
+		{tgbotapi.Error{Code: 400, Message: "Bad Request: message is not modified: specified new message content and reply markup are exactly the same"}, true},
+		{tgbotapi.Error{Code: 400, Message: "Bad Request: message to edit not found"}, false},
+		{tgbotapi.Error{Code: 429, Message: "Too Many Requests: message is not modified"}, false},
+	}
+	for _, tt := range tests {
+		if got := notModified(&tt.err); got != tt.want {
+			t.Errorf("notModified(%q) = %v, ожидалось %v", tt.err.Message, got, tt.want)
+		}
+	}
+}
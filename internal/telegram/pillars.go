@@ -0,0 +1,70 @@
+package telegram
+
+import (
+	"html"
+	"strings"
+
+	"five-pillars/internal/database"
+	"five-pillars/internal/services"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const pillarUsage = "❌ Формат:\n" +
+	"/pillar add [ключ] [эмодзи] [название] - новый столп, например /pillar add finance 💰 Финансы\n" +
+	"/pillar set [ключ] name|emoji|color|aliases|position [значение] - изменить столп\n" +
+	"/pillar archive|restore [ключ] - убрать в архив или вернуть"
+
+// handlePillars /pillars [all] - справочник столпов, all - вместе с архивными
+func (b *Bot) handlePillars(msg *tgbotapi.Message) {
+	args := strings.Fields(msg.Text)[1:]
+	archived := len(args) > 0 && args[0] == "all"
+
+	b.SendMessageOrLogError("🏛 <b>Столпы</b>\n\n" + services.FormatPillars(b.services.Pillars.List(archived)) +
+		"\nИзменить: /pillar set [ключ] name|emoji|color|aliases|position [значение]")
+}
+
+// handlePillar управление справочником столпов, форматы в pillarUsage
+func (b *Bot) handlePillar(msg *tgbotapi.Message) {
+	args := strings.Fields(msg.Text)[1:]
+	if len(args) == 0 {
+		b.SendMessageOrLogError(pillarUsage)
+		return
+	}
+
+	var pillar *database.PillarInfo
+	var err error
+	switch {
+	case args[0] == "add" && len(args) >= 4:
+		pillar, err = b.services.Pillars.Add(database.PillarInfo{
+			Key:   database.Pillar(args[1]),
+			Emoji: args[2],
+			Name:  strings.Join(args[3:], " "),
+		})
+	case args[0] == "set" && len(args) >= 4:
+		var patch services.PillarPatch
+		if patch, err = services.ParsePillarPatch(args[2], strings.Join(args[3:], " ")); err == nil {
+			pillar, err = b.services.Pillars.Update(args[1], patch)
+		}
+	case (args[0] == "archive" || args[0] == "restore") && len(args) == 2:
+		archived := args[0] == "archive"
+		pillar, err = b.services.Pillars.Update(args[1], services.PillarPatch{Archived: &archived})
+	default:
+		b.SendMessageOrLogError(pillarUsage)
+		return
+	}
+	if err != nil {
+		b.SendError(err, "Ошибка изменения столпа")
+		return
+	}
+
+	switch args[0] {
+	case "add":
+		b.SendMessageOrLogError("✅ Столп добавлен\n" + services.FormatPillars([]database.PillarInfo{*pillar}) +
+			"Теперь его можно указывать в /add и /goal add")
+	case "archive":
+		b.SendMessageOrLogError("📦 Столп " + html.EscapeString(pillar.Title()) + " в архиве, его задачи остаются в истории")
+	default:
+		b.SendMessageOrLogError("✅ " + strings.TrimRight(services.FormatPillars([]database.PillarInfo{*pillar}), "\n"))
+	}
+}
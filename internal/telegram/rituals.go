@@ -0,0 +1,226 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"five-pillars/internal/database"
+	"five-pillars/internal/services"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// planShiftMinutes шаг кнопок сдвига задачи в вечернем планировании
+const planShiftMinutes = 60
+
+// SendMorningBriefing брифинг с кнопками переноса вчерашних задач на сегодня
+func (b *Bot) SendMorningBriefing(briefing *services.MorningBriefing) error {
+	text := services.FormatMorningBriefing(b.services.Registry(), briefing)
+	if len(briefing.Unfinished) == 0 {
+		return b.SendMessage(text)
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, task := range briefing.Unfinished {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("➡️ #%d %s", task.ID, shorten(task.Description, 30)),
+				fmt.Sprintf("carry_%d", task.ID)),
+		))
+	}
+	if len(briefing.Unfinished) > 1 {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("➡️ Перенести все на сегодня", "carry_all"),
+		))
+	}
+
+	return b.enqueueWithKeyboard(text, tgbotapi.NewInlineKeyboardMarkup(rows...))
+}
+
+// SendEveningPlan план на завтра с кнопками сдвига и удаления задач
+func (b *Bot) SendEveningPlan(plan *services.EveningPlan) error {
+	text := services.FormatEveningPlan(b.services.Registry(), plan)
+	if len(plan.Tasks) == 0 {
+		return b.SendMessage(text)
+	}
+	return b.enqueueWithKeyboard(text, planKeyboard(plan.Tasks))
+}
+
+func planKeyboard(tasks []database.DailyTask) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, task := range tasks {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("#%d ⏪ −1ч", task.ID), fmt.Sprintf("plan_earlier_%d", task.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("⏩ +1ч", fmt.Sprintf("plan_later_%d", task.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("🗑", fmt.Sprintf("plan_drop_%d", task.ID)),
+		))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// handleCarry переносит вчерашнюю задачу (или все) на сегодня: создает
+// копию, связанную с исходной
+func (b *Bot) handleCarry(data string) {
+	today := services.Today()
+
+	var ids []int
+	if data == "carry_all" {
+		yesterday, err := b.services.Task.GetTasks(previousDate(today))
+		if err != nil {
+			b.SendError(err, "Ошибка получения задач")
+			return
+		}
+		for _, task := range yesterday {
+			if task.Status.Open() {
+				ids = append(ids, task.ID)
+			}
+		}
+	} else {
+		id, err := strconv.Atoi(strings.TrimPrefix(data, "carry_"))
+		if err != nil {
+			b.SendMessageOrLogError("❌ Ошибка обработки запроса")
+			return
+		}
+		ids = append(ids, id)
+	}
+
+	var moved []string
+	for _, id := range ids {
+		task, err := b.services.Rollover.Carry(id, today)
+		if err != nil {
+			b.SendError(err, "Ошибка переноса задачи")
+			continue
+		}
+		moved = append(moved, fmt.Sprintf("#%d %s", task.ID, html.EscapeString(task.Description)))
+	}
+
+	if len(moved) > 0 {
+		b.SendMessageOrLogError("➡️ Перенесено на сегодня:\n" + strings.Join(moved, "\n"))
+	}
+}
+
+// SendRollover итоги переноса незакрытых задач с кнопками для тех, по
+// которым нужно решение
+func (b *Bot) SendRollover(result *services.RolloverResult) error {
+	text := services.FormatRollover(b.services.Registry(), result)
+	if len(result.Ask) == 0 {
+		return b.SendMessage(text)
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, task := range result.Ask {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("➡️ #%d %s", task.ID, shorten(task.Description, 20)),
+				fmt.Sprintf("rollover_carry_%d", task.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("⌛ Не сделано", fmt.Sprintf("rollover_miss_%d", task.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("✅", fmt.Sprintf("rollover_done_%d", task.ID)),
+		))
+	}
+
+	return b.enqueueWithKeyboard(text, tgbotapi.NewInlineKeyboardMarkup(rows...))
+}
+
+// handleRollover решение по незакрытой задаче: перенести, отметить
+// несделанной или выполненной
+func (b *Bot) handleRollover(data string) {
+	action, idText, _ := strings.Cut(strings.TrimPrefix(data, "rollover_"), "_")
+	id, err := strconv.Atoi(idText)
+	if err != nil {
+		b.SendMessageOrLogError("❌ Ошибка обработки запроса")
+		return
+	}
+
+	switch action {
+	case "carry":
+		task, err := b.services.Rollover.Carry(id, "")
+		if err != nil {
+			b.SendError(err, "Ошибка переноса задачи")
+			return
+		}
+		if task.ID == id {
+			b.SendMessageOrLogError(fmt.Sprintf("⌛ Задача #%d уже есть на следующий день, эта отмечена несделанной", id))
+			return
+		}
+		b.SendMessageOrLogError(fmt.Sprintf("➡️ Задача перенесена на %s: #%d %s", task.Date, task.ID, html.EscapeString(task.Description)))
+	case "miss":
+		task, err := b.services.Rollover.Miss(id)
+		if err != nil {
+			b.SendError(err, "Ошибка обновления задачи")
+			return
+		}
+		b.SendMessageOrLogError(fmt.Sprintf("⌛ Задача #%d отмечена несделанной: %s", task.ID, html.EscapeString(task.Description)))
+	case "done":
+		task, err := b.services.Task.CompleteTask(id)
+		if err != nil {
+			b.SendError(err, "Ошибка обновления задачи")
+			return
+		}
+		b.SendMessageOrLogError(fmt.Sprintf("✅ Задача #%d выполнена: %s", task.ID, html.EscapeString(task.Description)))
+	}
+}
+
+// handlePlan сдвигает или удаляет задачу из вечернего плана и обновляет
+// сообщение с планом
+func (b *Bot) handlePlan(data string, messageID int) {
+	action, idText, _ := strings.Cut(strings.TrimPrefix(data, "plan_"), "_")
+	id, err := strconv.Atoi(idText)
+	if err != nil {
+		b.SendMessageOrLogError("❌ Ошибка обработки запроса")
+		return
+	}
+
+	var task *database.DailyTask
+	switch action {
+	case "earlier":
+		task, err = b.services.Task.ShiftTask(id, -planShiftMinutes)
+	case "later":
+		task, err = b.services.Task.ShiftTask(id, planShiftMinutes)
+	case "drop":
+		task, err = b.services.Task.CancelTask(id)
+	default:
+		return
+	}
+	if err != nil {
+		b.SendError(err, "Ошибка изменения плана")
+		return
+	}
+
+	if b.services.Notification == nil {
+		return
+	}
+	plan, err := b.services.Notification.EveningPlan(task.Date)
+	if err != nil {
+		b.SendError(err, "Ошибка получения плана")
+		return
+	}
+
+	var keyboard *tgbotapi.InlineKeyboardMarkup
+	if len(plan.Tasks) > 0 {
+		markup := planKeyboard(plan.Tasks)
+		keyboard = &markup
+	}
+	if err := b.enqueueEdit(messageID, services.FormatEveningPlan(b.services.Registry(), plan), keyboard); err != nil {
+		b.SendError(err, "Ошибка обновления плана")
+	}
+}
+
+func previousDate(date string) string {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	return day.AddDate(0, 0, -1).Format("2006-01-02")
+}
+
+// shorten обрезает текст кнопки до limit символов
+func shorten(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
@@ -0,0 +1,78 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"five-pillars/internal/services"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleFind /find [запрос] - поиск по задачам и заметкам ощущений
+func (b *Bot) handleFind(msg *tgbotapi.Message) {
+	text := strings.TrimSpace(strings.TrimPrefix(msg.Text, "/find"))
+	if text == "" {
+		b.SendMessageOrLogError("❌ Формат: /find [слова] [#тег] [from:YYYY-MM-DD] [to:YYYY-MM-DD] [status:done,skipped]\n" +
+			"Пример: /find отчет #work from:2026-01-01")
+		return
+	}
+
+	query, err := services.ParseSearchQuery(text)
+	if err != nil {
+		b.SendError(err, "Ошибка поиска")
+		return
+	}
+	result, err := b.services.Search.Find(query)
+	if err != nil {
+		b.SendError(err, "Ошибка поиска")
+		return
+	}
+
+	if len(result.Tasks) == 0 && len(result.Feelings) == 0 {
+		b.SendMessageOrLogError("🔍 Ничего не найдено")
+		return
+	}
+	b.SendMessageOrLogError(fmt.Sprintf("🔍 <b>Найдено</b>: задач %d, дней с ощущениями %d\n\n%s",
+		len(result.Tasks), len(result.Feelings), html.EscapeString(strings.TrimRight(services.FormatSearchResult(b.services.Registry(), result), "\n"))))
+}
+
+// handleTag /tag [id] [#тег ...] - заменить теги задачи, /tag [id] off - убрать,
+// /tag [id] - показать
+func (b *Bot) handleTag(msg *tgbotapi.Message) {
+	args := strings.Fields(msg.Text)[1:]
+	if len(args) == 0 {
+		b.SendMessageOrLogError("❌ Формат: /tag [id] [#тег ...]\n" +
+			"Пример: /tag 3 #work #отчеты, /tag [id] off - убрать теги")
+		return
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		b.SendMessageOrLogError("❌ id должен быть числовой")
+		return
+	}
+
+	tags := args[1:]
+	if len(tags) == 1 && strings.EqualFold(tags[0], "off") {
+		tags = nil
+	}
+
+	task, err := b.services.Task.GetTask(id)
+	if err == nil && len(args) > 1 {
+		task, err = b.services.Task.SetTags(id, tags...)
+	}
+	if err != nil {
+		b.SendError(err, "Ошибка изменения тегов")
+		return
+	}
+
+	if task.Tags == "" {
+		b.SendMessageOrLogError(fmt.Sprintf("🏷 У задачи #%d нет тегов", task.ID))
+		return
+	}
+	b.SendMessageOrLogError(fmt.Sprintf("🏷 Задача #%d: %s\n%s",
+		task.ID, html.EscapeString(task.Description), html.EscapeString(services.FormatTags(task.Tags))))
+}
@@ -0,0 +1,78 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"five-pillars/internal/services"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// sleepDays за сколько дней /sleep показывает ночи по умолчанию
+const sleepDays = 7
+
+const sleepUsage = "❌ Формат:\n" +
+	"/sleep 23:40-07:10 - записать прошлую ночь: отбой и подъем по Москве\n" +
+	"/sleep 23:40-07:10 [YYYY-MM-DD] - ночь, после которой проснулись в этот день\n" +
+	"/sleep [дней] - журнал сна и режим, по умолчанию за 7 дней\n" +
+	"/sleep del [YYYY-MM-DD] - удалить ночь"
+
+// handleSleep журнал сна, форматы в sleepUsage
+func (b *Bot) handleSleep(msg *tgbotapi.Message) {
+	args := strings.Fields(msg.Text)[1:]
+
+	switch {
+	case len(args) == 0:
+		b.showSleep(sleepDays)
+	case args[0] == "del" && len(args) == 2:
+		if err := b.services.Sleep.Delete(args[1]); err != nil {
+			b.SendError(err, "Ошибка удаления сна")
+			return
+		}
+		b.SendMessageOrLogError(fmt.Sprintf("🗑 Сон за %s удален из журнала", args[1]))
+	case services.IsSleepRange(args[0]) && len(args) <= 2:
+		date := ""
+		if len(args) == 2 {
+			date = args[1]
+		}
+		entry, err := b.services.Sleep.Save(date, args[0])
+		if err != nil {
+			b.SendError(err, "Ошибка записи сна")
+			return
+		}
+		b.SendMessageOrLogError(fmt.Sprintf("😴 Сон за %s: %s → %s, %.1f ч\nЖурнал и режим: /sleep",
+			entry.Date, entry.Bedtime, entry.WakeTime, entry.Hours))
+	case len(args) == 1:
+		days, err := strconv.Atoi(args[0])
+		if err != nil || days < 1 || days > 366 {
+			b.SendMessageOrLogError(sleepUsage)
+			return
+		}
+		b.showSleep(days)
+	default:
+		b.SendMessageOrLogError(sleepUsage)
+	}
+}
+
+// showSleep ночи за последние days дней и режим сна за них
+func (b *Bot) showSleep(days int) {
+	entries, stats, err := b.services.Sleep.Get(days)
+	if err != nil {
+		b.SendError(err, "Ошибка получения сна")
+		return
+	}
+	if stats == nil {
+		b.SendMessageOrLogError(fmt.Sprintf("😴 За %d дн. сон не записан. Утром: /sleep 23:40-07:10", days))
+		return
+	}
+
+	message := fmt.Sprintf("😴 <b>Сон за %d дн.</b>\n\n", days)
+	if len(entries) > 0 {
+		message += html.EscapeString(services.FormatSleep(entries)) + "\n"
+	}
+	message += html.EscapeString(strings.TrimRight(services.FormatSleepStats(stats), "\n"))
+	b.SendMessageOrLogError(message)
+}
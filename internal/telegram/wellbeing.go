@@ -0,0 +1,71 @@
+package telegram
+
+import (
+	"html"
+	"strings"
+
+	"five-pillars/internal/database"
+	"five-pillars/internal/services"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const wellbeingUsage = "❌ Формат:\n" +
+	"/wellbeing add [ключ] scale|number|bool|text [эмодзи] [название] - новый показатель, например /wellbeing add water number 💧 Вода\n" +
+	"/wellbeing set [ключ] name|emoji|unit|aliases|min|max|position [значение] - изменить показатель\n" +
+	"/wellbeing archive|restore [ключ] - перестать спрашивать или вернуть"
+
+// formatFeelings показатели ощущений за день через sep, текст экранирован
+func (b *Bot) formatFeelings(feelings database.DailyFeelings, sep string) string {
+	return html.EscapeString(strings.Join(services.FeelingsLines(b.services.Registry(), feelings), sep))
+}
+
+// handleWellbeing справочник показателей самочувствия, /wellbeing all - вместе
+// с архивными, остальные форматы в wellbeingUsage
+func (b *Bot) handleWellbeing(msg *tgbotapi.Message) {
+	args := strings.Fields(msg.Text)[1:]
+	if len(args) == 0 || args[0] == "all" {
+		b.SendMessageOrLogError("🩺 <b>Показатели самочувствия</b>\n\n" +
+			html.EscapeString(services.FormatWellbeingMetrics(b.services.Wellbeing.List(len(args) > 0))) +
+			"\nВключить показатель из архива: /wellbeing restore [ключ]\n" +
+			"Изменить: /wellbeing set [ключ] name|emoji|unit|aliases|min|max|position [значение]")
+		return
+	}
+
+	var metric *database.WellbeingMetric
+	var err error
+	switch {
+	case args[0] == "add" && len(args) >= 5:
+		metric, err = b.services.Wellbeing.Add(database.WellbeingMetric{
+			Key:   args[1],
+			Type:  database.WellbeingType(args[2]),
+			Emoji: args[3],
+			Name:  strings.Join(args[4:], " "),
+		})
+	case args[0] == "set" && len(args) >= 4:
+		var patch services.WellbeingPatch
+		if patch, err = services.ParseWellbeingPatch(args[2], strings.Join(args[3:], " ")); err == nil {
+			metric, err = b.services.Wellbeing.Update(args[1], patch)
+		}
+	case (args[0] == "archive" || args[0] == "restore") && len(args) == 2:
+		archived := args[0] == "archive"
+		metric, err = b.services.Wellbeing.Update(args[1], services.WellbeingPatch{Archived: &archived})
+	default:
+		b.SendMessageOrLogError(wellbeingUsage)
+		return
+	}
+	if err != nil {
+		b.SendError(err, "Ошибка изменения показателя")
+		return
+	}
+
+	line := html.EscapeString(strings.TrimRight(services.FormatWellbeingMetrics([]database.WellbeingMetric{*metric}), "\n"))
+	switch args[0] {
+	case "add", "restore":
+		b.SendMessageOrLogError("✅ " + line + "\nТеперь его можно указывать в /feelings")
+	case "archive":
+		b.SendMessageOrLogError("📦 Показатель " + html.EscapeString(metric.Title()) + " в архиве, старые значения остаются в истории")
+	default:
+		b.SendMessageOrLogError("✅ " + line)
+	}
+}
@@ -0,0 +1,56 @@
+package tui
+
+import (
+	"five-pillars/internal/database"
+	"five-pillars/internal/services"
+)
+
+// Backend источник данных терминального клиента: локальная БД или HTTP API.
+// api.Client реализует этот интерфейс напрямую.
+type Backend interface {
+	Tasks(date string) ([]database.DailyTask, error)
+	CompleteTask(id int) (*database.DailyTask, error)
+	SnoozeTask(id int, minutes int) (*database.DailyTask, error)
+	SkipTask(id int, reason string) (*database.DailyTask, error)
+	SkipReasons() ([]services.SkipReason, error)
+	SaveFeelings(feelings database.DailyFeelings) (*database.DailyFeelings, error)
+	WeeklyAnalytics() (*database.WeeklyAnalytics, error)
+}
+
+// LocalBackend работает с локальной БД через те же сервисы, что и бот
+type LocalBackend struct {
+	services *services.ServiceManager
+}
+
+func NewLocalBackend(serviceManager *services.ServiceManager) *LocalBackend {
+	return &LocalBackend{services: serviceManager}
+}
+
+func (lb *LocalBackend) Tasks(date string) ([]database.DailyTask, error) {
+	return lb.services.Task.GetTasks(date)
+}
+
+func (lb *LocalBackend) CompleteTask(id int) (*database.DailyTask, error) {
+	return lb.services.Task.CompleteTask(id)
+}
+
+func (lb *LocalBackend) SnoozeTask(id int, minutes int) (*database.DailyTask, error) {
+	return lb.services.Task.SnoozeTask(id, minutes)
+}
+
+func (lb *LocalBackend) SkipTask(id int, reason string) (*database.DailyTask, error) {
+	task, _, err := lb.services.Task.SkipTask(id, reason)
+	return task, err
+}
+
+func (lb *LocalBackend) SkipReasons() ([]services.SkipReason, error) {
+	return services.SkipReasons, nil
+}
+
+func (lb *LocalBackend) SaveFeelings(feelings database.DailyFeelings) (*database.DailyFeelings, error) {
+	return lb.services.Feelings.Save(feelings)
+}
+
+func (lb *LocalBackend) WeeklyAnalytics() (*database.WeeklyAnalytics, error) {
+	return lb.services.Analytics.GetWeeklyAnalytics()
+}
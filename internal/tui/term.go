@@ -0,0 +1,148 @@
+package tui
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Клавиши, которые приходят не одним символом
+const (
+	keyUp    = "up"
+	keyDown  = "down"
+	keyEnter = "enter"
+	keyEsc   = "esc"
+	keyQuit  = "quit"
+)
+
+// terminal переключает терминал между посимвольным вводом (клавиши
+// срабатывают без Enter) и обычным построчным для форм. Внешних библиотек
+// нет, поэтому режим меняется через stty. Если ввод не терминал или stty
+// недоступен, все команды читаются построчно.
+type terminal struct {
+	in   *bufio.Reader
+	file *os.File
+	// saved настройки терминала до запуска, пустые - режим не менялся
+	saved string
+	raw   bool
+}
+
+func newTerminal(in io.Reader) *terminal {
+	t := &terminal{in: bufio.NewReader(in)}
+
+	file, ok := in.(*os.File)
+	if !ok {
+		return t
+	}
+	if info, err := file.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return t
+	}
+
+	saved, err := stty(file, "-g")
+	if err != nil {
+		return t
+	}
+	t.file = file
+	t.saved = strings.TrimSpace(saved)
+	return t
+}
+
+// rawMode посимвольный ввод без эха. Ctrl+C тоже приходит клавишей, чтобы
+// перед выходом вернуть терминал в исходное состояние.
+func (t *terminal) rawMode() {
+	if t.saved == "" {
+		return
+	}
+	if _, err := stty(t.file, "-icanon", "-echo", "-isig", "min", "1"); err == nil {
+		t.raw = true
+	}
+}
+
+// lineMode обычный ввод строки с эхом
+func (t *terminal) lineMode() {
+	if !t.raw {
+		return
+	}
+	if _, err := stty(t.file, "icanon", "echo", "isig"); err == nil {
+		t.raw = false
+	}
+}
+
+// restore возвращает настройки терминала, которые были до запуска
+func (t *terminal) restore() {
+	if t.saved == "" {
+		return
+	}
+	stty(t.file, t.saved)
+	t.raw = false
+}
+
+// readKey читает одну клавишу. В построчном режиме клавиша - первый
+// символ строки, пустая строка - Enter.
+func (t *terminal) readKey() (string, bool) {
+	if !t.raw {
+		line, err := t.in.ReadString('\n')
+		if err != nil && line == "" {
+			return "", false
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			return keyEnter, true
+		}
+		return parseKey(line), true
+	}
+
+	r, _, err := t.in.ReadRune()
+	if err != nil {
+		return "", false
+	}
+	// Стрелки приходят последовательностью ESC [ A одним чтением
+	if r == '\x1b' && t.in.Buffered() >= 2 {
+		seq := make([]byte, 2)
+		if _, err := io.ReadFull(t.in, seq); err != nil {
+			return "", false
+		}
+		return parseKey("\x1b" + string(seq)), true
+	}
+	return parseKey(string(r)), true
+}
+
+// readLine читает строку с эхом, на время ввода выключая посимвольный режим
+func (t *terminal) readLine() (string, bool) {
+	if t.raw {
+		t.lineMode()
+		defer t.rawMode()
+	}
+
+	line, err := t.in.ReadString('\n')
+	if err != nil && line == "" {
+		return "", false
+	}
+	return strings.TrimSpace(line), true
+}
+
+func parseKey(s string) string {
+	switch {
+	case strings.HasPrefix(s, "\x1b[A"):
+		return keyUp
+	case strings.HasPrefix(s, "\x1b[B"):
+		return keyDown
+	case s == "\x1b":
+		return keyEsc
+	case s == "\r" || s == "\n":
+		return keyEnter
+	case s == "\x03" || s == "\x04":
+		// Ctrl+C и Ctrl+D
+		return keyQuit
+	}
+	return string([]rune(s)[0])
+}
+
+func stty(file *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = file
+	out, err := cmd.Output()
+	return string(out), err
+}
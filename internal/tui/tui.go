@@ -0,0 +1,350 @@
+// Package tui - интерактивный терминальный клиент трекера.
+//
+// Экран перерисовывается после каждой клавиши: стрелками или j/k
+// выбирается задача, действия вызываются одной клавишей (c, s, z, f, w, q)
+// без Enter. Построчно вводятся только поля форм.
+package tui
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"five-pillars/internal/database"
+	"five-pillars/internal/services"
+	"five-pillars/internal/utils"
+)
+
+const clearScreen = "\033[H\033[2J"
+
+type App struct {
+	backend Backend
+	// registry столпы и показатели самочувствия для показа и формы ощущений
+	registry *database.Registry
+	term     *terminal
+	out      io.Writer
+	date     string
+	status   string
+
+	// tasks задачи на экране в порядке показа, cursor - выбранная
+	tasks  []database.DailyTask
+	cursor int
+}
+
+func New(backend Backend, registry *database.Registry, in io.Reader, out io.Writer) *App {
+	return &App{
+		backend:  backend,
+		registry: registry,
+		term:     newTerminal(in),
+		out:      out,
+		date:     services.Today(),
+	}
+}
+
+// Run основной цикл: показать задачи, прочитать клавишу, выполнить
+func (a *App) Run() error {
+	a.term.rawMode()
+	defer a.term.restore()
+
+	for {
+		a.renderTasks()
+
+		key, ok := a.term.readKey()
+		if !ok {
+			return nil
+		}
+
+		switch key {
+		case "q", keyQuit:
+			return nil
+		case "j", keyDown:
+			a.move(1)
+		case "k", keyUp:
+			a.move(-1)
+		case "c":
+			a.complete()
+		case "s":
+			a.skip()
+		case "z":
+			a.snooze()
+		case "f":
+			a.feelingsForm()
+		case "w":
+			a.weekView()
+		case "d":
+			a.changeDate()
+		case "r", keyEnter, keyEsc:
+			a.status = ""
+		case "?", "h":
+			a.helpView()
+		default:
+			a.status = "❌ Неизвестная клавиша. Нажмите ? для справки"
+		}
+	}
+}
+
+func (a *App) printf(format string, args ...interface{}) {
+	fmt.Fprintf(a.out, format, args...)
+}
+
+func (a *App) prompt(label string) (string, bool) {
+	a.printf("%s", label)
+	return a.term.readLine()
+}
+
+func (a *App) waitKey() {
+	a.printf("\nЛюбая клавиша - назад")
+	a.term.readKey()
+}
+
+// move сдвигает выбор задачи, по краям списка выбор переходит на другой конец
+func (a *App) move(delta int) {
+	if len(a.tasks) == 0 {
+		return
+	}
+	a.cursor = (a.cursor + delta + len(a.tasks)) % len(a.tasks)
+	a.status = ""
+}
+
+// selected выбранная задача, nil - задач на экране нет
+func (a *App) selected() *database.DailyTask {
+	if a.cursor < 0 || a.cursor >= len(a.tasks) {
+		a.status = "❌ Нет выбранной задачи"
+		return nil
+	}
+	return &a.tasks[a.cursor]
+}
+
+func (a *App) renderTasks() {
+	a.printf("%s", clearScreen)
+	a.printf("🎯 5 Столпов - задачи на %s\n", a.date)
+	a.printf("%s\n\n", utils.GetTimezoneInfo())
+
+	tasks, err := a.backend.Tasks(a.date)
+	if err != nil {
+		a.printf("❌ Ошибка получения задач: %v\n", err)
+	} else if len(tasks) == 0 {
+		a.printf("📭 Задач нет\n")
+	}
+
+	byPillar := make(map[database.Pillar][]database.DailyTask)
+	for _, task := range tasks {
+		byPillar[task.Pillar] = append(byPillar[task.Pillar], task)
+	}
+
+	a.tasks = a.tasks[:0]
+	for _, pillar := range database.SortedPillars(a.registry, byPillar) {
+		a.tasks = append(a.tasks, byPillar[pillar]...)
+	}
+	if a.cursor >= len(a.tasks) {
+		a.cursor = len(a.tasks) - 1
+	}
+	if a.cursor < 0 {
+		a.cursor = 0
+	}
+
+	n := 0
+	for _, pillar := range database.SortedPillars(a.registry, byPillar) {
+		a.printf("%s\n", a.registry.PillarName(pillar))
+		for _, task := range byPillar[pillar] {
+			marker := " "
+			if n == a.cursor {
+				marker = "▶"
+			}
+			n++
+
+			a.printf("%s %s #%-4d %s  %s\n",
+				marker, statusIcon(task), task.ID, utils.FormatTimeForDisplay(task.TimeUTC), task.Description)
+			if reason := services.SkipReasonText(task); reason != "" {
+				a.printf("          📝 %s\n", reason)
+			}
+		}
+		a.printf("\n")
+	}
+
+	if a.status != "" {
+		a.printf("%s\n\n", a.status)
+	}
+	a.printf("[↑↓/jk] выбор  [c] выполнить  [s] пропустить  [z] отложить  [f] ощущения  [w] неделя  [d] день  [?] справка  [q] выход\n")
+}
+
+func statusIcon(task database.DailyTask) string {
+	return services.StatusMark(task, time.Now())
+}
+
+func (a *App) complete() {
+	selected := a.selected()
+	if selected == nil {
+		return
+	}
+
+	task, err := a.backend.CompleteTask(selected.ID)
+	if err != nil {
+		a.status = "❌ " + err.Error()
+		return
+	}
+	a.status = fmt.Sprintf("✅ Задача выполнена: %s", task.Description)
+}
+
+func (a *App) snooze() {
+	selected := a.selected()
+	if selected == nil {
+		return
+	}
+
+	minutes := services.DefaultSnoozeMinutes
+	line, _ := a.prompt(fmt.Sprintf("\n⏰ Отложить #%d на сколько минут? (Enter - %d): ", selected.ID, minutes))
+	if line != "" {
+		m, err := strconv.Atoi(line)
+		if err != nil || m <= 0 {
+			a.status = "❌ Минуты должны быть положительным числом"
+			return
+		}
+		minutes = m
+	}
+
+	task, err := a.backend.SnoozeTask(selected.ID, minutes)
+	if err != nil {
+		a.status = "❌ " + err.Error()
+		return
+	}
+	a.status = fmt.Sprintf("⏰ Задача отложена до %s", utils.FormatTimeForDisplay(task.TimeUTC))
+}
+
+func (a *App) skip() {
+	selected := a.selected()
+	if selected == nil {
+		return
+	}
+
+	reasons, err := a.backend.SkipReasons()
+	if err != nil {
+		a.status = "❌ " + err.Error()
+		return
+	}
+
+	a.printf("\n📝 Почему задача #%d не выполнена?\n", selected.ID)
+	for i, reason := range reasons {
+		a.printf("  %d. %s\n", i+1, reason.Text)
+	}
+
+	line, _ := a.prompt("Номер причины: ")
+	n, err := strconv.Atoi(line)
+	if err != nil || n < 1 || n > len(reasons) {
+		a.status = "Пропуск отменен"
+		return
+	}
+
+	if _, err := a.backend.SkipTask(selected.ID, reasons[n-1].Code); err != nil {
+		a.status = "❌ " + err.Error()
+		return
+	}
+	a.status = fmt.Sprintf("➖ Задача пропущена: %s", reasons[n-1].Text)
+}
+
+func (a *App) changeDate() {
+	line, _ := a.prompt("\n📅 Дата YYYY-MM-DD (Enter - сегодня): ")
+	a.cursor = 0
+	if line == "" {
+		a.date = services.Today()
+		a.status = ""
+		return
+	}
+	if err := services.ValidateDate(line); err != nil {
+		a.status = "❌ " + err.Error()
+		return
+	}
+	a.date = line
+	a.status = ""
+}
+
+func (a *App) feelingsForm() {
+	a.printf("%s📊 Оцените свои ощущения за %s\n\n", clearScreen, services.Today())
+
+	// Форма строится по справочнику: каждый действующий показатель - свой вопрос
+	var feelings database.DailyFeelings
+	for _, metric := range a.registry.WellbeingMetrics(false) {
+		question := fmt.Sprintf("%s [%s] (Enter - пропустить)", metric.Title(), services.FeelingsRange(metric))
+
+		line, _ := a.prompt(question + ": ")
+		if line == "" {
+			continue
+		}
+		if metric.Key == database.WellbeingSleep && services.IsSleepRange(line) {
+			entry, err := services.ParseSleepRange("", line)
+			if err != nil {
+				a.status = "❌ " + err.Error()
+				return
+			}
+			feelings.Sleep = &entry
+			continue
+		}
+		value, err := services.ParseWellbeingValue(metric, line)
+		if err != nil {
+			a.status = "❌ " + err.Error()
+			return
+		}
+		services.SetFeelingsValue(&feelings, metric.Key, value)
+	}
+
+	if _, err := a.backend.SaveFeelings(feelings); err != nil {
+		a.status = "❌ " + err.Error()
+		return
+	}
+	a.status = "✅ Ощущения сохранены"
+}
+
+func (a *App) weekView() {
+	analytics, err := a.backend.WeeklyAnalytics()
+	if err != nil {
+		a.status = "❌ " + err.Error()
+		return
+	}
+
+	a.printf("%s📈 Аналитика за неделю %d\n", clearScreen, analytics.WeekNumber)
+	a.printf("📅 %s - %s\n\n", analytics.StartDate, analytics.EndDate)
+	a.printf("✅ Выполнено: %d/%d (%.0f%%), пропущено: %d\n\n",
+		analytics.TotalDone, analytics.Resolved(), analytics.CompletionRate(), analytics.TotalSkipped)
+
+	a.printf("Эффективность по столпам:\n")
+	for _, pillar := range database.SortedPillars(a.registry, analytics.PillarStats) {
+		stats := analytics.PillarStats[pillar]
+		a.printf("  %s: %d/%d (%.0f%%), пропущено: %d\n",
+			a.registry.PillarName(database.Pillar(pillar)), stats.Completed, stats.Resolved(), stats.CompletionRate(), stats.Skipped)
+	}
+
+	if len(analytics.AvgFeelings) > 0 {
+		a.printf("\nСредние ощущения:\n")
+		for _, line := range services.WellbeingAverageLines(a.registry, analytics.AvgFeelings) {
+			a.printf("  %s\n", line)
+		}
+	}
+
+	if analytics.Sleep != nil {
+		a.printf("\nСон:\n%s", services.FormatSleepStats(analytics.Sleep))
+	}
+
+	if analytics.Insights != "" {
+		a.printf("\n💡 Инсайты:\n%s\n", analytics.Insights)
+	}
+
+	a.waitKey()
+}
+
+func (a *App) helpView() {
+	a.printf(`%s📚 Клавиши
+
+  ↑ ↓ или j k  - выбрать задачу
+  c            - отметить выбранную задачу выполненной
+  s            - пропустить выбранную задачу с причиной
+  z            - отложить выбранную задачу (по умолчанию на %d мин)
+  f            - внести ощущения за день
+  w            - аналитика за неделю
+  d            - показать другой день (Enter - сегодня)
+  r            - обновить
+  q            - выход
+`, clearScreen, services.DefaultSnoozeMinutes)
+
+	a.waitKey()
+}
@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"five-pillars/internal/database"
+	"five-pillars/internal/services"
+)
+
+func TestKeysActOnSelectedTask(t *testing.T) {
+	db, err := database.New(filepath.Join(t.TempDir(), "tui.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	sm := services.NewServiceManager(db)
+
+	var ids []int
+	for _, timeUTC := range []string{"09:00", "10:00"} {
+		task, err := sm.Task.AddTask(services.NewTaskInput{Pillar: "body", Description: "Зарядка " + timeUTC, TimeUTC: timeUTC, Date: services.Today()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, task.ID)
+	}
+
+	// Не терминал: клавиши читаются построчно, стрелка - последовательностью ESC
+	input := strings.Join([]string{"\x1b[B", "c", "k", "s", "1", "q"}, "\n") + "\n"
+	app := New(NewLocalBackend(sm), sm.Registry(), strings.NewReader(input), io.Discard)
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[int]database.TaskStatus{ids[0]: database.StatusSkipped, ids[1]: database.StatusDone}
+	for id, status := range want {
+		task, err := sm.Task.GetTask(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if task.Status != status {
+			t.Errorf("задача #%d в статусе %s, ожидался %s", id, task.Status, status)
+		}
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	tests := map[string]string{
+		"\x1b[A": keyUp,
+		"\x1b[B": keyDown,
+		"\x1b":   keyEsc,
+		"\r":     keyEnter,
+		"\x03":   keyQuit,
+		"c":      "c",
+		"ц":      "ц",
+	}
+	for in, want := range tests {
+		if got := parseKey(in); got != want {
+			t.Errorf("parseKey(%q) = %q, ожидалось %q", in, got, want)
+		}
+	}
+}
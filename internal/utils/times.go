@@ -19,6 +19,11 @@ func init() {
 	}
 }
 
+// MoscowLocation часовой пояс, в котором показывается время
+func MoscowLocation() *time.Location {
+	return moscowLocation
+}
+
 // UTCTimeToMSK конвертирует время UTC в МСК для отображения
 func UTCTimeToMSK(utcTime string) (string, error) {
 	// Парсим время UTC
@@ -54,6 +59,11 @@ func FormatTimeForDisplay(utcTime string) string {
 	return fmt.Sprintf("%s МСК (%s UTC, +%d)", mskTime, utcTime, diffHours)
 }
 
+// FormatDateTimeForDisplay форматирует момент времени в МСК
+func FormatDateTimeForDisplay(t time.Time) string {
+	return t.In(moscowLocation).Format("2006-01-02 15:04") + " МСК"
+}
+
 // GetCurrentMSKTime возвращает текущее время в МСК
 func GetCurrentMSKTime() string {
 	now := time.Now().In(moscowLocation)
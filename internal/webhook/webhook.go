@@ -0,0 +1,288 @@
+// Package webhook - исходящие вебхуки на события задач и ощущений.
+//
+// События сначала записываются в очередь webhook_deliveries, а фоновый
+// обработчик отправляет их с повторами, поэтому доставка переживает
+// перезапуск. Каждый запрос подписан HMAC-SHA256 секретом получателя.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"five-pillars/internal/database"
+	"five-pillars/internal/services"
+)
+
+// Заголовки запроса вебхука
+const (
+	HeaderEvent     = "X-Five-Pillars-Event"
+	HeaderDelivery  = "X-Five-Pillars-Delivery"
+	HeaderTimestamp = "X-Five-Pillars-Timestamp"
+	// HeaderSignature "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + тело))
+	HeaderSignature = "X-Five-Pillars-Signature"
+)
+
+const (
+	// MaxAttempts после стольких неудач доставка помечается failed
+	MaxAttempts = 12
+
+	firstRetryDelay = 30 * time.Second
+	maxRetryDelay   = 6 * time.Hour
+	pollInterval    = 15 * time.Second
+	batchSize       = 20
+)
+
+// Endpoint получатель вебхуков
+type Endpoint struct {
+	URL    string
+	Secret string
+	// Events на какие события подписан, пустой - на все
+	Events map[services.EventType]bool
+}
+
+func (e Endpoint) wants(event services.EventType) bool {
+	return len(e.Events) == 0 || e.Events[event]
+}
+
+// ParseEndpoints разбирает список получателей: записи через ";" вида
+// url|события через запятую|секрет. События и секрет можно не указывать:
+// тогда подписка на все события и секрет defaultSecret.
+func ParseEndpoints(spec, defaultSecret string) ([]Endpoint, error) {
+	var endpoints []Endpoint
+
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.Split(entry, "|")
+		endpoint := Endpoint{URL: strings.TrimSpace(parts[0]), Secret: defaultSecret}
+		if !strings.HasPrefix(endpoint.URL, "http://") && !strings.HasPrefix(endpoint.URL, "https://") {
+			return nil, fmt.Errorf("неверный адрес вебхука: %q", endpoint.URL)
+		}
+
+		if len(parts) > 1 {
+			events, err := parseEvents(parts[1])
+			if err != nil {
+				return nil, err
+			}
+			endpoint.Events = events
+		}
+		if len(parts) > 2 && strings.TrimSpace(parts[2]) != "" {
+			endpoint.Secret = strings.TrimSpace(parts[2])
+		}
+		if endpoint.Secret == "" {
+			return nil, fmt.Errorf("для вебхука %s не задан секрет (WEBHOOK_SECRET)", endpoint.URL)
+		}
+
+		endpoints = append(endpoints, endpoint)
+	}
+
+	return endpoints, nil
+}
+
+func parseEvents(value string) (map[services.EventType]bool, error) {
+	events := make(map[services.EventType]bool)
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || name == "*" {
+			continue
+		}
+
+		known := false
+		for _, eventType := range services.EventTypes {
+			if string(eventType) == name {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("неизвестное событие вебхука: %s", name)
+		}
+		events[services.EventType(name)] = true
+	}
+	return events, nil
+}
+
+// Sign подпись тела запроса
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatcher ставит события в очередь и отправляет их получателям
+type Dispatcher struct {
+	repository *database.Repository
+	endpoints  []Endpoint
+	client     *http.Client
+	wake       chan struct{}
+}
+
+func NewDispatcher(db *database.Database, endpoints []Endpoint) *Dispatcher {
+	return &Dispatcher{
+		repository: database.NewRepository(db),
+		endpoints:  endpoints,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+// HandleEvent реализует services.EventListener: записывает событие
+// в очередь для каждого подписанного получателя
+func (d *Dispatcher) HandleEvent(event services.Event) {
+	var payload []byte
+	for _, endpoint := range d.endpoints {
+		if !endpoint.wants(event.Type) {
+			continue
+		}
+
+		if payload == nil {
+			var err error
+			if payload, err = json.Marshal(event); err != nil {
+				log.Printf("⚠️ Ошибка подготовки вебхука %s: %v", event.Type, err)
+				return
+			}
+		}
+
+		if _, err := d.repository.EnqueueWebhook(endpoint.URL, string(event.Type), string(payload), time.Now()); err != nil {
+			log.Printf("⚠️ Ошибка постановки вебхука в очередь: %v", err)
+		}
+	}
+
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run отправляет очередь, пока не отменен ctx
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		d.DeliverDue()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// DeliverDue отправляет доставки, время которых наступило
+func (d *Dispatcher) DeliverDue() {
+	for {
+		deliveries, err := d.repository.GetDueWebhooks(time.Now(), batchSize)
+		if err != nil {
+			log.Printf("⚠️ Ошибка чтения очереди вебхуков: %v", err)
+			return
+		}
+
+		for _, delivery := range deliveries {
+			d.deliver(delivery)
+		}
+
+		if len(deliveries) < batchSize {
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) deliver(delivery database.WebhookDelivery) {
+	attempts := delivery.Attempts + 1
+	now := time.Now()
+
+	endpoint, ok := d.endpoint(delivery.URL)
+	if !ok {
+		// Получателя убрали из настроек - повторять некуда
+		d.reschedule(delivery, database.WebhookFailed, attempts, now, "получатель удален из настроек")
+		return
+	}
+
+	if err := d.send(endpoint, delivery, now); err != nil {
+		status := database.WebhookPending
+		if attempts >= MaxAttempts {
+			status = database.WebhookFailed
+			log.Printf("❌ Вебхук #%d (%s → %s) не доставлен после %d попыток: %v", delivery.ID, delivery.Event, delivery.URL, attempts, err)
+		}
+		d.reschedule(delivery, status, attempts, now.Add(retryDelay(attempts)), err.Error())
+		return
+	}
+
+	if err := d.repository.MarkWebhookDelivered(delivery.ID, attempts, now); err != nil {
+		log.Printf("⚠️ Ошибка записи статуса вебхука #%d: %v", delivery.ID, err)
+	}
+}
+
+func (d *Dispatcher) send(endpoint Endpoint, delivery database.WebhookDelivery, now time.Time) error {
+	body := []byte(delivery.Payload)
+	timestamp := now.Unix()
+
+	req, err := http.NewRequest(http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "five-pillars-webhook")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, strconv.Itoa(delivery.ID))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(endpoint.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (d *Dispatcher) reschedule(delivery database.WebhookDelivery, status string, attempts int, next time.Time, lastError string) {
+	if err := d.repository.RescheduleWebhook(delivery.ID, status, attempts, next, lastError); err != nil {
+		log.Printf("⚠️ Ошибка записи статуса вебхука #%d: %v", delivery.ID, err)
+	}
+}
+
+func (d *Dispatcher) endpoint(url string) (Endpoint, bool) {
+	for _, endpoint := range d.endpoints {
+		if endpoint.URL == url {
+			return endpoint, true
+		}
+	}
+	return Endpoint{}, false
+}
+
+// retryDelay экспоненциальная пауза перед следующей попыткой
+func retryDelay(attempts int) time.Duration {
+	delay := firstRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
@@ -0,0 +1,46 @@
+package webhook
+
+import "testing"
+
+func TestSign(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		body      string
+		want      string
+	}{
+		{
+			name:      "событие",
+			secret:    "secret",
+			timestamp: 1700000000,
+			body:      `{"event":"task.done"}`,
+			want:      "sha256=618ebeb71345705e05500d49484e65e00d25ea65c5649753fd2bfc31d9466144",
+		},
+		{
+			name: "пустые значения",
+			want: "sha256=b849d5a581847b281957065739df36df2463d1977ea8d6e1e4e6cf33fadc68c3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sign(tt.secret, tt.timestamp, []byte(tt.body)); got != tt.want {
+				t.Errorf("Sign() = %s, ожидалось %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// Подпись зависит от секрета, времени и тела
+func TestSignChanges(t *testing.T) {
+	base := Sign("secret", 1700000000, []byte("body"))
+	for name, other := range map[string]string{
+		"секрет": Sign("other", 1700000000, []byte("body")),
+		"время":  Sign("secret", 1700000001, []byte("body")),
+		"тело":   Sign("secret", 1700000000, []byte("body!")),
+	} {
+		if other == base {
+			t.Errorf("подпись не изменилась при смене: %s", name)
+		}
+	}
+}
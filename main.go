@@ -1,12 +1,14 @@
 package main
 
 import (
+	"errors"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"five-pillars/internal/app"
+	"five-pillars/internal/cli"
 	"five-pillars/internal/config"
 )
 
@@ -16,6 +18,25 @@ func main() {
 		log.Fatalf("❌ Ошибка загрузки конфигурации: %v", err)
 	}
 
+	args := os.Args[1:]
+	if len(args) == 0 || args[0] == "serve" {
+		serve(cfg)
+		return
+	}
+
+	if err := cli.Run(cfg, args, os.Stdout); err != nil {
+		if errors.Is(err, cli.ErrUsage) {
+			os.Exit(2)
+		}
+		log.Fatalf("❌ %v", err)
+	}
+}
+
+func serve(cfg *config.Config) {
+	if err := cfg.RequireTelegram(); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
 	application, err := app.New(cfg)
 	if err != nil {
 		log.Fatalf("❌ Ошибка создания приложения: %v", err)